@@ -0,0 +1,197 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/spf13/cobra"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/config/cmdconst"
+	"github.com/tofuutils/tenv/v2/pkg/audit"
+	"github.com/tofuutils/tenv/v2/pkg/fileperm"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager/builder"
+)
+
+const (
+	auditFormatCycloneDX = "cyclonedx"
+	auditFormatJSON      = "json"
+	auditFormatMarkdown  = "markdown"
+	auditHelp            = "Generate a compliance report of every installed version."
+)
+
+var (
+	errAuditFormat     = errors.New("unknown audit format, expected json, markdown or cyclonedx")
+	errAuditSignFormat = errors.New("signature is only supported with json and markdown formats")
+)
+
+func newAuditCmd(conf *config.Config, builders map[string]builder.BuilderFunc, hclParser *hclparse.Parser) *cobra.Command {
+	format, outputPath, signKeyPath, strict := auditFormatJSON, "", "", false
+	cve, failOn := false, ""
+
+	auditCmd := &cobra.Command{
+		Use:   "audit",
+		Short: auditHelp,
+		Long: auditHelp + `
+
+The report list for each installed version the SHA-256 digests of its files, its verification results
+(checksum and signature recorded at install time), its download source and its compliance status
+(compliant only when both checksum and signature have been verified).
+
+With --sign-key, the report is signed with an ed25519 private key (PKCS #8 PEM format), the signature
+of a JSON report can be checked with the verify subcommand against the trusted public key.
+
+With --cve, installed versions are cross-referenced with published security advisories (GitHub advisory
+database or TENV_ADVISORY_URL feed), the cyclonedx format exports them as a CycloneDX VEX document.
+With --fail-on, the command fails when the version pinned for working directory has an advisory with
+a severity at or above the given one (low, medium, high or critical).`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			conf.InitDisplayer(false)
+
+			switch {
+			case format != auditFormatJSON && format != auditFormatMarkdown && format != auditFormatCycloneDX:
+				exitOnError(errAuditFormat)
+			case format == auditFormatCycloneDX && signKeyPath != "":
+				exitOnError(errAuditSignFormat)
+			case failOn != "" && !audit.ValidSeverity(failOn):
+				exitOnError(audit.ErrSeverity)
+			}
+			cve = cve || failOn != "" || format == auditFormatCycloneDX
+
+			var entries []audit.Entry
+			for _, name := range []string{cmdconst.TofuName, cmdconst.TerraformName, cmdconst.TerragruntName, cmdconst.AtmosName, cmdconst.ConftestName, cmdconst.OpaName} {
+				versionManager := builders[name](conf, hclParser)
+				toolEntries, err := versionManager.Audit()
+				if err == nil && cve {
+					toolEntries, err = versionManager.WithAdvisories(toolEntries)
+				}
+				if err != nil {
+					exitOnError(err)
+				}
+				entries = append(entries, toolEntries...)
+			}
+
+			report := audit.MakeReport(version, entries)
+			if signKeyPath != "" {
+				keyData, err := os.ReadFile(signKeyPath)
+				if err != nil {
+					exitOnError(err)
+				}
+
+				if err = report.Sign(keyData); err != nil {
+					exitOnError(err)
+				}
+			}
+
+			var data []byte
+			var err error
+			switch format {
+			case auditFormatMarkdown:
+				data = []byte(report.Markdown())
+			case auditFormatCycloneDX:
+				data, err = report.CycloneDX(version)
+			default:
+				data, err = report.Marshal()
+			}
+			if err != nil {
+				exitOnError(err)
+			}
+
+			if outputPath == "" {
+				loghelper.StdDisplay(string(data))
+			} else if err = os.WriteFile(outputPath, data, fileperm.FileMode()); err != nil {
+				exitOnError(err)
+			}
+
+			if strict && !report.Compliant() {
+				exitOnError(audit.ErrNonCompliant)
+			}
+
+			if failOn != "" {
+				if err = report.CheckPinned(failOn); err != nil {
+					exitOnError(err)
+				}
+			}
+		},
+	}
+
+	flags := auditCmd.Flags()
+	flags.StringVarP(&format, "format", "f", format, "report format (json, markdown or cyclonedx, which implies --cve)")
+	flags.StringVarP(&outputPath, "output", "o", "", "write report in this file instead of standard output")
+	flags.StringVarP(&signKeyPath, "sign-key", "k", "", "path of an ed25519 private key (PKCS #8 PEM) to sign the report")
+	flags.BoolVarP(&strict, "strict", "s", false, "exit with verification error code when a version is not compliant")
+	flags.BoolVar(&cve, "cve", false, "add published security advisories affecting installed versions")
+	flags.StringVar(&failOn, "fail-on", "", "exit with verification error code when the pinned version has an advisory of this severity or above (implies --cve)")
+
+	auditCmd.AddCommand(newAuditVerifyCmd())
+
+	return auditCmd
+}
+
+func newAuditVerifyCmd() *cobra.Command {
+	publicKeyPath := ""
+
+	verifyCmd := &cobra.Command{
+		Use:   "verify report",
+		Short: "Check the signature of a JSON audit report.",
+		Long: `Check the signature of a JSON audit report.
+
+The trusted public key (ed25519, PKIX PEM format or base64 encoded) must be obtained out of band :
+the public key embedded in the report is not trusted, anyone able to edit the report could sign it again.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			keyData, err := os.ReadFile(publicKeyPath)
+			if err != nil {
+				exitOnError(err)
+			}
+
+			trustedKey, err := audit.ParsePublicKey(keyData)
+			if err != nil {
+				exitOnError(err)
+			}
+
+			data, err := os.ReadFile(args[0])
+			if err != nil {
+				exitOnError(err)
+			}
+
+			var report audit.Report
+			if err = json.Unmarshal(data, &report); err != nil {
+				exitOnError(err)
+			}
+
+			if err = report.Verify(trustedKey); err != nil {
+				exitOnError(err)
+			}
+			loghelper.StdDisplay(loghelper.Concat("Valid signature from public key ", base64.StdEncoding.EncodeToString(trustedKey)))
+		},
+	}
+
+	verifyCmd.Flags().StringVarP(&publicKeyPath, "public-key", "p", "", "path of the trusted ed25519 public key (PKIX PEM or base64)")
+	_ = verifyCmd.MarkFlagRequired("public-key")
+
+	return verifyCmd
+}
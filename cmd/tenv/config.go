@@ -0,0 +1,99 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+)
+
+const configHelp = "Manage tenv configuration files."
+
+var errEmptySecret = errors.New("no secret value read on standard input")
+
+func newConfigCmd(conf *config.Config) *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: configHelp,
+		Long:  configHelp,
+	}
+
+	profiles := false
+	setSecretCmd := &cobra.Command{
+		Use:   "set-secret section key",
+		Short: "Encrypt a value read on standard input and store it in a configuration file.",
+		Long: `Encrypt a value read on standard input and store it in a configuration file.
+
+By default the value is written in remote configuration file (section is tenv, tofu, terraform, terragrunt, atmos, conftest or opa,
+e.g. "tenv github_token" or "terraform password"), with --profiles it is written in profiles file (section is the profile
+name and key an environment variable name).
+
+The value is encrypted with AES-256-GCM and stored with the "enc:" prefix, the key is generated on first use and kept
+in OS keychain (macOS security or libsecret secret-tool). Encrypted values are transparently decrypted when loading files.`,
+		Args: cobra.ExactArgs(2),
+		Run: func(_ *cobra.Command, args []string) {
+			conf.InitDisplayer(false)
+
+			value, err := readSecretValue()
+			if err != nil {
+				exitOnError(err)
+			}
+
+			if profiles {
+				err = config.SetProfileSecret(args[0], args[1], value)
+			} else {
+				err = conf.SetRemoteSecret(args[0], args[1], value)
+			}
+			if err != nil {
+				exitOnError(err)
+			}
+			conf.Displayer.Display(loghelper.Concat("Stored encrypted value for ", args[0], " ", args[1]))
+		},
+	}
+
+	flags := setSecretCmd.Flags()
+	flags.BoolVarP(&profiles, "profiles", "p", false, "write in profiles file instead of remote configuration file")
+	flags.StringVarP(&conf.RemoteConfPath, "remote-conf", "c", conf.RemoteConfPath, "path to remote configuration file (advanced settings)")
+
+	configCmd.AddCommand(setSecretCmd)
+
+	return configCmd
+}
+
+// read from standard input (first line) rather than argument to keep the secret out of shell history.
+func readSecretValue() (string, error) {
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", errEmptySecret
+	}
+
+	value := strings.TrimRight(line, "\r\n")
+	if value == "" {
+		return "", errEmptySecret
+	}
+
+	return value, nil
+}
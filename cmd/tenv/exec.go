@@ -0,0 +1,149 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/cmdproxy"
+	"github.com/tofuutils/tenv/v2/pkg/exitcode"
+	"github.com/tofuutils/tenv/v2/pkg/junit"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager"
+	"github.com/tofuutils/tenv/v2/versionmanager/proxy"
+)
+
+const execVersionEnvName = "TENV_EXEC_VERSION"
+
+func newExecCmd(conf *config.Config, versionManager versionmanager.VersionManager, params subCmdParams) *cobra.Command {
+	var descBuilder strings.Builder
+	descBuilder.WriteString("Execute a command with ")
+	descBuilder.WriteString(versionManager.FolderName)
+	descBuilder.WriteString(` in PATH, or once for each version matching a constraint.
+
+Without --versions, the version is detected like proxies do (and installed when missing), then its directory is prepended
+to PATH, so the command (like terragrunt, terratest or make) calls it without relying on proxies.
+
+With --versions, the constraint is expanded to the highest matching versions (available at `)
+	descBuilder.WriteString(params.remoteEnvName)
+	descBuilder.WriteString(" url, limited by --max), missing ones are installed, then the command is executed with ")
+	descBuilder.WriteString(versionManager.VersionEnvName)
+	descBuilder.WriteString(" and ")
+	descBuilder.WriteString(execVersionEnvName)
+	descBuilder.WriteString(" set to the current version (so proxies use it).")
+
+	constraint, junitPath, maxVersions, stableOnly := "", "", 5, false
+
+	execCmd := &cobra.Command{
+		Use:   "exec [--versions constraint] -- command [args]",
+		Short: loghelper.Concat("Execute a command with ", versionManager.FolderName, " in PATH, or once for each version matching a constraint."),
+		Long:  descBuilder.String(),
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			if maxVersions <= 0 {
+				exitOnError(versionmanager.ErrMaxCount)
+			}
+
+			if constraint == "" {
+				execDetected(conf, versionManager, args)
+
+				return
+			}
+
+			conf.InitDisplayer(false)
+
+			versions, err := versionManager.ListMatching(constraint, maxVersions, stableOnly)
+			if err != nil {
+				exitOnError(err)
+			}
+
+			suite := junit.MakeSuite(loghelper.Concat("tenv exec ", versionManager.FolderName))
+			start := time.Now()
+			for _, execVersion := range versions {
+				duration, failureMessage := execForVersion(versionManager, execVersion, args)
+				suite.Add(execVersion, duration, failureMessage)
+			}
+
+			if junitPath != "" {
+				if err = suite.Write(junitPath, time.Since(start)); err != nil {
+					exitOnError(err)
+				}
+			}
+
+			loghelper.StdDisplay(loghelper.Concat(strconv.Itoa(suite.Tests-suite.Failures), "/", strconv.Itoa(suite.Tests), " ", versionManager.FolderName, " version(s) succeeded"))
+			if suite.Failures != 0 {
+				os.Exit(exitcode.Generic)
+			}
+		},
+	}
+
+	flags := execCmd.Flags()
+	flags.StringVarP(&constraint, "versions", "V", "", "version constraint expression to expand")
+	flags.IntVarP(&maxVersions, "max", "m", maxVersions, "maximum number of versions (highest ones are kept)")
+	flags.BoolVarP(&stableOnly, "stable-only", "o", false, "use only stable versions")
+	flags.StringVarP(&junitPath, "junit", "j", "", "path of JUnit report to write")
+	addInstallationFlags(flags, conf, params)
+	addRemoteFlags(flags, conf, params)
+
+	return execCmd
+}
+
+// proxy.ExecWith handles detection, installation and nested proxy calls, only the called command differs.
+func execDetected(conf *config.Config, versionManager versionmanager.VersionManager, args []string) {
+	conf.InitDisplayer(true)
+
+	os.Exit(proxy.ExecWith(conf, versionManager, versionManager.ExecName(), args, prependPathRun(args, cmdproxy.Run)))
+}
+
+// the returned function calls args command (instead of detected binary) with binary directory prepended to PATH.
+func prependPathRun(args []string, run proxy.RunFunc) proxy.RunFunc {
+	return func(binaryPath string, _ []string, env []string, gha bool) {
+		pathValue := filepath.Dir(binaryPath) + string(os.PathListSeparator) + os.Getenv(pathEnvName)
+		run(args[0], args[1:], append(env, pathEnvName+"="+pathValue), gha)
+	}
+}
+
+func execForVersion(versionManager versionmanager.VersionManager, execVersion string, args []string) (time.Duration, string) {
+	start := time.Now()
+	if err := versionManager.Install(execVersion); err != nil {
+		return time.Since(start), err.Error()
+	}
+
+	loghelper.StdDisplay(loghelper.Concat("Execute with ", versionManager.FolderName, " ", execVersion))
+
+	//nolint
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Env = append(os.Environ(), versionManager.VersionEnvName+"="+execVersion, execVersionEnvName+"="+execVersion)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return time.Since(start), err.Error()
+	}
+
+	return time.Since(start), ""
+}
@@ -0,0 +1,72 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/exitcode"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager"
+	"github.com/tofuutils/tenv/v2/versionmanager/proxy"
+)
+
+// modify environment, so not parallel.
+func TestExecDetectedPath(t *testing.T) { //nolint
+	previousPath := filepath.Join(t.TempDir(), "bin")
+	t.Setenv("PATH", previousPath)
+	t.Setenv("TENV_TEST_EXEC_VERSION", "1.6.2")
+
+	conf := &config.Config{Displayer: loghelper.InertDisplayer, NoInstall: true, RootPath: t.TempDir()}
+	versionPath := filepath.Join(conf.RootPath, "OpenTofu", "1.6.2")
+	if err := os.MkdirAll(versionPath, 0o755); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(versionPath, "tofu"), nil, 0o755); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	manager := versionmanager.Make(conf, "", "tofu", "OpenTofu", nil, nil, fakeRetriever{}, "TENV_TEST_EXEC_VERSION", "", nil)
+
+	var calledPath string
+	var calledArgs, calledEnv []string
+	var run proxy.RunFunc = func(execPath string, cmdArgs []string, env []string, _ bool) {
+		calledPath, calledArgs, calledEnv = execPath, cmdArgs, env
+	}
+
+	args := []string{"make", "plan", "-j2"}
+	if code := proxy.ExecWith(conf, manager, manager.ExecName(), args, prependPathRun(args, run)); code != exitcode.Success {
+		t.Fatal("Unexpected exit code :", code)
+	}
+
+	if calledPath != "make" || !slices.Equal(calledArgs, []string{"plan", "-j2"}) {
+		t.Error("Unmatching called command, get :", calledPath, calledArgs)
+	}
+
+	// last value wins when the child environment is built
+	wantPath := "PATH=" + versionPath + string(os.PathListSeparator) + previousPath
+	if len(calledEnv) == 0 || calledEnv[len(calledEnv)-1] != wantPath {
+		t.Error("Unmatching PATH in environment, get :", calledEnv)
+	}
+}
@@ -0,0 +1,222 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/spf13/cobra"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/config/cmdconst"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager/builder"
+)
+
+const (
+	hookEnvHelp = "Display shell code exporting resolved versions each time the prompt is displayed."
+
+	// hookStateEnvName keeps variables exported by the hook (as name=value separated by semicolons),
+	// they are recomputed on each call unless they have been changed since.
+	hookStateEnvName = "TENV_HOOK_STATE"
+	hookStateSep     = ";"
+
+	bashShell = "bash"
+	fishShell = "fish"
+	pwshShell = "pwsh"
+	zshShell  = "zsh"
+)
+
+var errHookShell = errors.New("unknown shell, expected bash, zsh, fish or pwsh")
+
+type shellSyntax struct {
+	hook   func(tenvPath string) string
+	export func(name string, value string) string
+	unset  func(name string) string
+}
+
+var shellSyntaxes = map[string]shellSyntax{ //nolint
+	bashShell: {hook: bashHook, export: posixExport, unset: posixUnset},
+	fishShell: {hook: fishHook, export: fishExport, unset: fishUnset},
+	pwshShell: {hook: pwshHook, export: pwshExport, unset: pwshUnset},
+	zshShell:  {hook: zshHook, export: posixExport, unset: posixUnset},
+}
+
+func newHookEnvCmd(conf *config.Config, builders map[string]builder.BuilderFunc, hclParser *hclparse.Parser) *cobra.Command {
+	exportMode, shell := false, bashShell
+
+	hookEnvCmd := &cobra.Command{
+		Use:   "hook-env",
+		Short: hookEnvHelp,
+		Long: hookEnvHelp + `
+
+Add the evaluation of its output to your shell configuration, for example in ~/.bashrc :
+
+  eval "$(tenv hook-env --shell bash)"
+
+On each prompt (and directory change with zsh and fish), version files are read again (only local information is used,
+no remote call, no installation) and resolved versions are exported in the tools version env var (like TOFUENV_TOFU_VERSION),
+so proxies do not need to search version files. A version env var set by the user is never overridden.`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			conf.ForceQuiet = true
+			conf.InitDisplayer(false)
+
+			syntax, ok := shellSyntaxes[shell]
+			if !ok {
+				exitOnError(errHookShell)
+			}
+
+			if !exportMode {
+				tenvPath, err := os.Executable()
+				if err != nil {
+					exitOnError(err)
+				}
+				loghelper.StdDisplay(syntax.hook(tenvPath))
+
+				return
+			}
+
+			loghelper.StdDisplay(hookExports(conf, builders, hclParser, syntax))
+		},
+	}
+
+	flags := hookEnvCmd.Flags()
+	flags.StringVarP(&shell, "shell", "s", shell, "target shell (bash, zsh, fish or pwsh)")
+	flags.BoolVar(&exportMode, "export", false, "display export statements for current directory (called by the hook)")
+	_ = flags.MarkHidden("export")
+
+	return hookEnvCmd
+}
+
+func hookExports(conf *config.Config, builders map[string]builder.BuilderFunc, hclParser *hclparse.Parser, syntax shellSyntax) string {
+	// forget previous exports still owned by the hook, to read version files again
+	previousState := map[string]string{}
+	for _, entry := range strings.Split(os.Getenv(hookStateEnvName), hookStateSep) {
+		name, value, found := strings.Cut(entry, "=")
+		if found && os.Getenv(name) == value {
+			previousState[name] = value
+			os.Unsetenv(name)
+		}
+	}
+
+	var lines, state []string
+	for _, name := range []string{cmdconst.TofuName, cmdconst.TerraformName, cmdconst.TerragruntName, cmdconst.AtmosName, cmdconst.ConftestName, cmdconst.OpaName} {
+		versionManager := builders[name](conf, hclParser)
+		envName := versionManager.VersionEnvName
+		if os.Getenv(envName) != "" {
+			continue // set by user
+		}
+
+		version, _, err := versionManager.ResolveLocal()
+		if err != nil || version == "" || strings.Contains(version, hookStateSep) {
+			if _, exported := previousState[envName]; exported {
+				lines = append(lines, syntax.unset(envName))
+			}
+
+			continue
+		}
+
+		state = append(state, loghelper.Concat(envName, "=", version))
+		if previousState[envName] != version {
+			lines = append(lines, syntax.export(envName, version))
+		}
+	}
+
+	return strings.Join(append(lines, syntax.export(hookStateEnvName, strings.Join(state, hookStateSep))), "\n")
+}
+
+func bashHook(tenvPath string) string {
+	return `_tenv_hook() {
+  local previous_exit_status=$?
+  eval "$(` + posixQuote(tenvPath) + ` hook-env --shell bash --export)"
+  return $previous_exit_status
+}
+if [[ ";${PROMPT_COMMAND:-};" != *";_tenv_hook;"* ]]; then
+  PROMPT_COMMAND="_tenv_hook${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+fi`
+}
+
+func zshHook(tenvPath string) string {
+	return `_tenv_hook() {
+  eval "$(` + posixQuote(tenvPath) + ` hook-env --shell zsh --export)"
+}
+typeset -ag precmd_functions chpwd_functions
+if (( ! ${precmd_functions[(I)_tenv_hook]} )); then
+  precmd_functions=(_tenv_hook $precmd_functions)
+fi
+if (( ! ${chpwd_functions[(I)_tenv_hook]} )); then
+  chpwd_functions=(_tenv_hook $chpwd_functions)
+fi`
+}
+
+func fishHook(tenvPath string) string {
+	return `function _tenv_hook --on-event fish_prompt --on-variable PWD
+    ` + fishQuote(tenvPath) + ` hook-env --shell fish --export | source
+end`
+}
+
+func pwshHook(tenvPath string) string {
+	return `if (-not $global:TenvPreviousPrompt) {
+    $global:TenvPreviousPrompt = $function:prompt
+    function global:prompt {
+        & ` + pwshQuote(tenvPath) + ` hook-env --shell pwsh --export | Out-String | Invoke-Expression
+        & $global:TenvPreviousPrompt
+    }
+}`
+}
+
+func posixExport(name string, value string) string {
+	return loghelper.Concat("export ", name, "=", posixQuote(value))
+}
+
+func posixUnset(name string) string {
+	return "unset " + name
+}
+
+func posixQuote(value string) string {
+	return loghelper.Concat("'", strings.ReplaceAll(value, "'", `'\''`), "'")
+}
+
+func fishExport(name string, value string) string {
+	return loghelper.Concat("set -gx ", name, " ", fishQuote(value))
+}
+
+func fishUnset(name string) string {
+	return "set -e " + name
+}
+
+func fishQuote(value string) string {
+	return loghelper.Concat("'", strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(value), "'")
+}
+
+func pwshExport(name string, value string) string {
+	return loghelper.Concat("$env:", name, " = ", pwshQuote(value))
+}
+
+func pwshUnset(name string) string {
+	return loghelper.Concat("Remove-Item Env:", name, " -ErrorAction SilentlyContinue")
+}
+
+func pwshQuote(value string) string {
+	return loghelper.Concat("'", strings.ReplaceAll(value, "'", "''"), "'")
+}
@@ -0,0 +1,89 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// path with spaces and quotes, to check hook quoting.
+const hookTenvPath = `/opt/my tools/it's "tenv"`
+
+func TestHookGolden(t *testing.T) {
+	t.Parallel()
+
+	for _, shell := range []string{bashShell, fishShell, pwshShell, zshShell} {
+		shell := shell
+		t.Run(shell, func(t *testing.T) {
+			t.Parallel()
+
+			syntax := shellSyntaxes[shell]
+			got := strings.Join([]string{
+				syntax.hook(hookTenvPath),
+				syntax.export("TOFUENV_TOFU_VERSION", "1.6.2"),
+				syntax.export("TFENV_TERRAFORM_VERSION", `it's a "quoted" \ value`),
+				syntax.unset("TG_VERSION"),
+			}, "\n") + "\n"
+
+			want, err := os.ReadFile(filepath.Join("testdata", "hookenv", shell+".golden"))
+			if err != nil {
+				t.Fatal("Unexpected error :", err)
+			}
+
+			if got != string(want) {
+				t.Errorf("Unmatching result, get :\n%s\nwant :\n%s", got, want)
+			}
+		})
+	}
+}
+
+// evaluate the bash hook calling a fake tenv in a directory with spaces and quotes.
+func TestBashHookEval(t *testing.T) {
+	t.Parallel()
+
+	bashPath, err := exec.LookPath(bashShell)
+	if err != nil {
+		t.Skip("bash not available")
+	}
+
+	dirPath := filepath.Join(t.TempDir(), `my tools`, `it's "here"`)
+	if err = os.MkdirAll(dirPath, 0o755); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	syntax := shellSyntaxes[bashShell]
+	tenvPath := filepath.Join(dirPath, "tenv")
+	script := "#!/bin/sh\necho " + posixQuote(syntax.export("TFENV_TERRAFORM_VERSION", `it's a "quoted" \ value`)) + "\n"
+	if err = os.WriteFile(tenvPath, []byte(script), 0o755); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	output, err := exec.Command(bashPath, "-c", syntax.hook(tenvPath)+"\n_tenv_hook\nprintf %s \"$TFENV_TERRAFORM_VERSION\"").CombinedOutput()
+	if err != nil {
+		t.Fatal("Unexpected error :", err, string(output))
+	}
+
+	if string(output) != `it's a "quoted" \ value` {
+		t.Error("Unmatching result, get :", string(output))
+	}
+}
@@ -0,0 +1,165 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/httpclient"
+	"github.com/tofuutils/tenv/v2/pkg/jobqueue"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+)
+
+const (
+	defaultJobsAddress = "127.0.0.1:9101"
+	jobsHelp           = "Inspect, enqueue and cancel install jobs of a watch daemon (started with --jobs-address)."
+)
+
+var errJobTool = errors.New("unknown tool, expected tofu, terraform, terragrunt, atmos, conftest or opa")
+
+func newJobsCmd(conf *config.Config) *cobra.Command {
+	address := defaultJobsAddress
+
+	jobsCmd := &cobra.Command{
+		Use:   "jobs",
+		Short: jobsHelp,
+		Long: jobsHelp + `
+
+Without subcommand, list jobs (pending, running and recently ended ones).`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			var jobs []jobqueue.Job
+			if err := callJobs(conf, http.MethodGet, address, "", nil, &jobs); err != nil {
+				exitOnError(err)
+			}
+
+			for _, job := range jobs {
+				displayJob(job)
+			}
+		},
+	}
+
+	jobsCmd.PersistentFlags().StringVarP(&address, "address", "a", address, "address of watch daemon install queue")
+
+	prefetch, wait := false, false
+	addCmd := &cobra.Command{
+		Use:   "add tool version",
+		Short: "Enqueue an install request (tool is tofu, terraform, terragrunt, atmos, conftest or opa, version can be a constraint).",
+		Args:  cobra.ExactArgs(2),
+		Run: func(_ *cobra.Command, args []string) {
+			priority := jobqueue.PriorityInteractive
+			if prefetch {
+				priority = jobqueue.PriorityPrefetch
+			}
+
+			body, err := json.Marshal(jobqueue.Request{Priority: priority, Tool: args[0], Version: args[1]})
+			if err != nil {
+				exitOnError(err)
+			}
+
+			var job jobqueue.Job
+			if err = callJobs(conf, http.MethodPost, address, "", body, &job); err != nil {
+				exitOnError(err)
+			}
+
+			if wait {
+				if err = callJobs(conf, http.MethodGet, address, strconv.FormatInt(job.ID, 10)+"?wait=true", nil, &job); err != nil {
+					exitOnError(err)
+				}
+			}
+			displayJob(job)
+
+			if job.Status == jobqueue.StatusFailed {
+				exitOnError(errors.New(job.Error))
+			}
+		},
+	}
+
+	addFlags := addCmd.Flags()
+	addFlags.BoolVarP(&prefetch, "prefetch", "p", false, "use prefetch priority (executed after interactive requests)")
+	addFlags.BoolVarP(&wait, "wait", "w", false, "wait for the job end")
+
+	jobsCmd.AddCommand(addCmd)
+	jobsCmd.AddCommand(&cobra.Command{
+		Use:   "cancel id",
+		Short: "Cancel a pending job.",
+		Args:  cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			var job jobqueue.Job
+			if err := callJobs(conf, http.MethodDelete, address, args[0], nil, &job); err != nil {
+				exitOnError(err)
+			}
+			displayJob(job)
+		},
+	})
+
+	return jobsCmd
+}
+
+func callJobs(conf *config.Config, method string, address string, subPath string, body []byte, result any) error {
+	callURL := loghelper.Concat("http://", address, jobqueue.JobsPath)
+	if subPath != "" {
+		callURL = loghelper.Concat(callURL, "/", subPath)
+	}
+
+	// written by the watch daemon at start
+	token, err := jobqueue.ReadToken(filepath.Join(conf.RootPath, jobqueue.TokenFileName))
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest(method, callURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", "Bearer "+token)
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := httpclient.Client().Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= http.StatusBadRequest {
+		data, _ := io.ReadAll(response.Body)
+
+		return errors.New(strings.TrimSpace(string(data)))
+	}
+
+	return json.NewDecoder(response.Body).Decode(result)
+}
+
+func displayJob(job jobqueue.Job) {
+	line := loghelper.Concat(strconv.FormatInt(job.ID, 10), " ", job.Status, " ", job.Priority, " ", job.Tool, " ", job.Version)
+	if job.Error != "" {
+		line = loghelper.Concat(line, " : ", job.Error)
+	}
+	loghelper.StdDisplay(line)
+}
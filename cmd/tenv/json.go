@@ -0,0 +1,104 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager"
+)
+
+var errJSONTemplate = errors.New("--json and --template flags can not be combined")
+
+// entry displayed by list with --json.
+type localJSONEntry struct {
+	Archived  bool   `json:"archived"`
+	Installed bool   `json:"installed"`
+	UseCount  int    `json:"use_count"`
+	UseDate   string `json:"use_date,omitempty"` // empty when never used
+	Used      bool   `json:"used"`
+	UsedBy    string `json:"used_by,omitempty"`
+	Version   string `json:"version"`
+}
+
+// entry displayed by list-remote with --json.
+type remoteJSONEntry struct {
+	Checksum    *bool  `json:"checksum,omitempty"` // only set with --verify-available
+	Installed   bool   `json:"installed"`
+	PublishDate string `json:"publish_date,omitempty"` // empty when unknown
+	Signature   *bool  `json:"signature,omitempty"`    // only set with --verify-available
+	Stable      bool   `json:"stable"`
+	Version     string `json:"version"`
+}
+
+// step of resolution chain displayed by detect with --json and --explain.
+type stepJSONEntry struct {
+	Detail string `json:"detail"`
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+}
+
+// result displayed by detect with --json.
+type detectJSONResult struct {
+	Chain        []stepJSONEntry `json:"chain,omitempty"` // only set with --explain
+	Installed    bool            `json:"installed"`
+	ResolvedFrom string          `json:"resolved_from,omitempty"` // environment variable or version file, empty with fallback strategy
+	Source       string          `json:"source"`                  // exact, local or remote
+	Tool         string          `json:"tool"`
+	Version      string          `json:"version"`
+}
+
+func checkJSONTemplate(jsonOutput bool, templateStr string) {
+	if jsonOutput && templateStr != "" {
+		exitOnError(errJSONTemplate)
+	}
+}
+
+func makeStepJSONEntries(steps []versionmanager.PrecedenceStep) []stepJSONEntry {
+	if len(steps) == 0 {
+		return nil
+	}
+
+	entries := make([]stepJSONEntry, 0, len(steps))
+	for _, step := range steps {
+		entries = append(entries, stepJSONEntry{Detail: step.Detail, Kind: step.Kind, Name: step.Name})
+	}
+
+	return entries
+}
+
+func displayJSON(value any) {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		exitOnError(err)
+	}
+	loghelper.StdDisplay(string(data))
+}
+
+// zero time (unknown date or never used version) is omitted.
+func formatJSONDate(value time.Time) string {
+	if value.IsZero() {
+		return ""
+	}
+
+	return value.Format(time.RFC3339)
+}
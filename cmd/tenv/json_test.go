@@ -0,0 +1,158 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/versionmanager"
+)
+
+type fakeRetriever []string
+
+func (fakeRetriever) InstallRelease(string, string) error {
+	return nil
+}
+
+func (r fakeRetriever) ListReleases() ([]string, error) {
+	return slices.Clone(r), nil
+}
+
+// replace standard output during call (process wide, so callers are not parallel).
+func captureStdout(t *testing.T, call func()) string {
+	t.Helper()
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	outChan := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(reader)
+		outChan <- data
+	}()
+
+	previous := os.Stdout
+	os.Stdout = writer
+	defer func() {
+		os.Stdout = previous
+	}()
+
+	call()
+	writer.Close()
+
+	return string(<-outChan)
+}
+
+// modify environment and standard output, so not parallel (displays go to standard error with --json).
+func TestJSONOutput(t *testing.T) { //nolint
+	t.Setenv("TENV_TEST_JSON_VERSION", "1.6.2")
+
+	conf := &config.Config{JSONOutput: true, NoInstall: true, RootPath: t.TempDir()}
+	installPath := filepath.Join(conf.RootPath, "OpenTofu")
+	for _, version := range []string{"1.6.2", "1.7.0"} {
+		if err := os.MkdirAll(filepath.Join(installPath, version), 0o755); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+	}
+
+	releases := fakeRetriever{"1.7.0", "1.5.0", "1.6.2", "1.8.0-rc1"}
+	manager := versionmanager.Make(conf, "", "tofu", "OpenTofu", nil, nil, releases, "TENV_TEST_JSON_VERSION", "", nil)
+	emptyManager := versionmanager.Make(conf, "", "tofu", "OpenTofu", nil, nil, fakeRetriever{}, "", "", nil)
+	rootVersionPath := manager.RootVersionFilePath()
+	if err := os.WriteFile(rootVersionPath, []byte("1.6.2\n"), 0o600); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(installPath, "release-dates.json"), []byte(`{"1.6.2": "2024-01-10T12:00:00Z"}`), 0o600); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	params := subCmdParams{remoteEnvName: config.TofuRemoteURLEnvName, pRemote: &conf.Tofu.RemoteURL, pPublicKeyPath: &conf.TofuKeyPath}
+
+	tests := []struct {
+		name   string
+		newCmd func() *cobra.Command
+		want   any
+	}{
+		{
+			name:   "List",
+			newCmd: func() *cobra.Command { return newListCmd(conf, manager) },
+			want: []any{
+				map[string]any{"archived": false, "installed": true, "use_count": 0.0, "used": true, "used_by": rootVersionPath, "version": "1.6.2"},
+				map[string]any{"archived": false, "installed": true, "use_count": 0.0, "used": false, "version": "1.7.0"},
+			},
+		},
+		{
+			name:   "ListRemote",
+			newCmd: func() *cobra.Command { return newListRemoteCmd(conf, manager, params) },
+			want: []any{
+				map[string]any{"installed": false, "stable": true, "version": "1.5.0"},
+				map[string]any{"installed": true, "publish_date": "2024-01-10T12:00:00Z", "stable": true, "version": "1.6.2"},
+				map[string]any{"installed": true, "stable": true, "version": "1.7.0"},
+				map[string]any{"installed": false, "stable": false, "version": "1.8.0-rc1"},
+			},
+		},
+		{
+			name:   "ListRemoteEmpty",
+			newCmd: func() *cobra.Command { return newListRemoteCmd(conf, emptyManager, params) },
+			want:   []any{},
+		},
+		{
+			name:   "Detect",
+			newCmd: func() *cobra.Command { return newDetectCmd(conf, manager, params) },
+			want:   map[string]any{"installed": true, "resolved_from": "TENV_TEST_JSON_VERSION", "source": "exact", "tool": "OpenTofu", "version": "1.6.2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := tt.newCmd()
+			cmd.SetArgs(nil)
+
+			var err error
+			output := captureStdout(t, func() {
+				err = cmd.Execute()
+			})
+			if err != nil {
+				t.Fatal("Unexpected error :", err)
+			}
+
+			// whole standard output must be parsable
+			var res any
+			if err = json.Unmarshal([]byte(output), &res); err != nil {
+				t.Fatal("Unparsable output :", output, err)
+			}
+
+			if !reflect.DeepEqual(res, tt.want) {
+				t.Error("Unmatching results, get :", output)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/spf13/cobra"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/config/cmdconst"
+	"github.com/tofuutils/tenv/v2/versionmanager/builder"
+)
+
+const linkAllHelp = "Generate a directory of versioned symlinks (like tofu-1.7.4) for all installed versions."
+
+func newLinkAllCmd(conf *config.Config, builders map[string]builder.BuilderFunc, hclParser *hclparse.Parser) *cobra.Command {
+	return &cobra.Command{
+		Use:   "link-all directory",
+		Short: linkAllHelp,
+		Long: linkAllHelp + `
+
+Scripts can then call exact versions directly without proxy overhead. The directory is registered
+(in TENV_ROOT/link-dirs file) and its symlinks are kept in sync after installs and uninstalls.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			conf.InitDisplayer(false)
+
+			for _, name := range []string{cmdconst.TofuName, cmdconst.TerraformName, cmdconst.TerragruntName, cmdconst.AtmosName, cmdconst.ConftestName, cmdconst.OpaName} {
+				if err := builders[name](conf, hclParser).LinkAll(args[0]); err != nil {
+					exitOnError(err)
+				}
+			}
+		},
+	}
+}
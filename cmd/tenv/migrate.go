@@ -0,0 +1,70 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"strconv"
+
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/spf13/cobra"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/config/cmdconst"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager/builder"
+)
+
+const migrateRootHelp = "Move caches and use dates of current user to the shared root layout (require TENV_SHARED_ROOT)."
+
+func newMigrateRootCmd(conf *config.Config, builders map[string]builder.BuilderFunc, hclParser *hclparse.Parser) *cobra.Command {
+	return &cobra.Command{
+		Use:   "migrate-root",
+		Short: migrateRootHelp,
+		Long: migrateRootHelp + `
+
+With TENV_SHARED_ROOT, remote pins, release dates, telemetry counters and use dates are stored in TENV_ROOT/users/<uid>,
+this command move the files previously written directly in TENV_ROOT to the directory of current user
+(each user of a shared root should run it once, installed versions are not moved).`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			conf.InitDisplayer(false)
+
+			count := 0
+			for _, fileName := range []string{config.RemotePinsFileName, telemetryFileName} {
+				moved, err := conf.MoveToUserStatePath(fileName)
+				if err != nil {
+					exitOnError(err)
+				}
+
+				if moved {
+					count++
+				}
+			}
+
+			for _, name := range []string{cmdconst.TofuName, cmdconst.TerraformName, cmdconst.TerragruntName, cmdconst.AtmosName, cmdconst.ConftestName, cmdconst.OpaName} {
+				toolCount, err := builders[name](conf, hclParser).MigrateUserState()
+				count += toolCount
+				if err != nil {
+					exitOnError(err)
+				}
+			}
+			conf.Displayer.Display(loghelper.Concat("Migrated ", strconv.Itoa(count), " files to ", conf.UserStatePath()))
+		},
+	}
+}
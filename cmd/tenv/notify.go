@@ -0,0 +1,163 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/spf13/cobra"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/config/cmdconst"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager"
+	"github.com/tofuutils/tenv/v2/versionmanager/builder"
+)
+
+const (
+	notifyHelp = "Watch remote listings and notify when new versions matching a level appear."
+
+	notifyLevelEnvName    = "TENV_NOTIFY_LEVEL"
+	notifyToolEnvName     = "TENV_NOTIFY_TOOL"
+	notifyVersionsEnvName = "TENV_NOTIFY_VERSIONS"
+)
+
+var errNotifyDesktop = errors.New("desktop notification not supported on this platform")
+
+func newNotifyCmd(conf *config.Config, builders map[string]builder.BuilderFunc, hclParser *hclparse.Parser) *cobra.Command {
+	desktop, execStr, interval, level, once := false, "", time.Hour, versionmanager.NotifyMinor, false
+	tools := []string{cmdconst.TofuName}
+
+	notifyCmd := &cobra.Command{
+		Use:   "notify",
+		Short: notifyHelp,
+		Long: notifyHelp + `
+
+Remote versions of each tool (tofu, terraform, terragrunt, atmos, conftest or opa) are listed at each interval,
+versions newer than the last seen one (kept in TENV_ROOT/<Tool>/notify-seen) and matching the level are reported :
+- new-major : stable version with a higher major
+- new-minor : stable version with a higher major or minor
+- new-patch : any newer stable version
+- new-pre : any newer version, including pre-releases
+
+The first check of a tool only records its newest version. New versions are displayed, and with --exec a command
+(split on spaces, without shell) is executed with TENV_NOTIFY_TOOL, TENV_NOTIFY_LEVEL and TENV_NOTIFY_VERSIONS
+(comma separated) env vars, with --desktop a desktop notification is sent (notify-send on Linux, osascript on macOS).
+New versions are recorded as seen only when notification succeeds (a failed one is retried at next check).
+
+With --once, only one check is done (to be scheduled with cron or a CI pipeline).`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			conf.InitDisplayer(false)
+
+			managers := make([]versionmanager.VersionManager, 0, len(tools))
+			for _, tool := range tools {
+				builderFunc, ok := builders[tool]
+				if !ok {
+					exitOnError(errPromptTool)
+				}
+				managers = append(managers, builderFunc(conf, hclParser))
+			}
+
+			for {
+				for index, manager := range managers {
+					err := checkNewReleases(manager, tools[index], level, execStr, desktop)
+					if err == nil {
+						continue
+					}
+
+					if once || errors.Is(err, versionmanager.ErrNotifyLevel) {
+						exitOnError(err)
+					}
+					loghelper.StdDisplay(loghelper.Concat("Failed to check new ", manager.FolderName, " versions : ", err.Error()))
+				}
+
+				if once {
+					return
+				}
+
+				time.Sleep(interval)
+			}
+		},
+	}
+
+	flags := notifyCmd.Flags()
+	flags.BoolVarP(&desktop, "desktop", "d", false, "send a desktop notification")
+	flags.StringVarP(&execStr, "exec", "e", "", "command executed on new versions")
+	flags.DurationVarP(&interval, "interval", "p", interval, "polling interval")
+	flags.StringVar(&level, "on", level, "notification level (new-major, new-minor, new-patch or new-pre)")
+	flags.BoolVar(&once, "once", false, "check once then exit")
+	flags.StringSliceVarP(&tools, "tool", "t", tools, "tool to watch (can be repeated)")
+
+	return notifyCmd
+}
+
+func checkNewReleases(manager versionmanager.VersionManager, tool string, level string, execStr string, desktop bool) error {
+	return manager.NewReleases(level, func(news []string) error {
+		return notifyNewReleases(manager.FolderName, news, tool, level, execStr, desktop)
+	})
+}
+
+func notifyNewReleases(folderName string, news []string, tool string, level string, execStr string, desktop bool) error {
+	joined := strings.Join(news, ", ")
+	message := loghelper.Concat("New ", folderName, " version(s) : ", joined)
+	loghelper.StdDisplay(message)
+
+	if desktop {
+		if err := sendDesktopNotification(message); err != nil {
+			return err
+		}
+	}
+
+	commandParts := strings.Fields(execStr)
+	if len(commandParts) == 0 {
+		return nil
+	}
+
+	cmd := exec.Command(commandParts[0], commandParts[1:]...)
+	cmd.Env = append(os.Environ(), notifyLevelEnvName+"="+level, notifyToolEnvName+"="+tool, notifyVersionsEnvName+"="+strings.Join(news, ","))
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
+}
+
+func sendDesktopNotification(message string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("osascript", "-e", loghelper.Concat("display notification ", quoteAppleScript(message), " with title \"tenv\""))
+	case "linux", "freebsd", "openbsd", "netbsd":
+		cmd = exec.Command("notify-send", "tenv", message)
+	default:
+		return errNotifyDesktop
+	}
+
+	return cmd.Run()
+}
+
+func quoteAppleScript(value string) string {
+	return `"` + strings.ReplaceAll(strings.ReplaceAll(value, `\`, `\\`), `"`, `\"`) + `"`
+}
@@ -0,0 +1,70 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"strconv"
+
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/spf13/cobra"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/config/cmdconst"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager/builder"
+)
+
+const precedenceHelp = "Display ordered sources consulted to resolve version of each tool."
+
+func newPrecedenceCmd(conf *config.Config, builders map[string]builder.BuilderFunc, hclParser *hclparse.Parser) *cobra.Command {
+	precedenceCmd := &cobra.Command{
+		Use:   "precedence [tool]...",
+		Short: precedenceHelp,
+		Long: precedenceHelp + `
+
+The list is generated from current configuration (env vars, boundary markers, skipped IaC scanning, root path),
+the first source giving a version wins, the default strategy is then evaluated with constraint sources.
+Tools can be restricted with arguments (tofu, terraform, terragrunt, atmos, conftest or opa).`,
+		Run: func(_ *cobra.Command, args []string) {
+			conf.InitDisplayer(false)
+
+			names := args
+			if len(names) == 0 {
+				names = []string{cmdconst.TofuName, cmdconst.TerraformName, cmdconst.TerragruntName, cmdconst.AtmosName, cmdconst.ConftestName, cmdconst.OpaName}
+			}
+
+			for index, name := range names {
+				builderFunc, ok := builders[name]
+				if !ok {
+					exitOnError(errPromptTool)
+				}
+
+				if index != 0 {
+					loghelper.StdDisplay("")
+				}
+				loghelper.StdDisplay(name + ":")
+				for rank, step := range builderFunc(conf, hclParser).Precedence() {
+					loghelper.StdDisplay(loghelper.Concat("  ", strconv.Itoa(rank+1), ". [", step.Kind, "] ", step.Name, " : ", step.Detail))
+				}
+			}
+		},
+	}
+
+	return precedenceCmd
+}
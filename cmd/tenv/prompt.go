@@ -0,0 +1,88 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/spf13/cobra"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/config/cmdconst"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager/builder"
+)
+
+const promptHelp = "Display resolved versions for current directory in a compact format for shell prompts."
+
+var errPromptTool = errors.New("unknown tool, expected tofu, terraform, terragrunt, atmos, conftest or opa")
+
+func newPromptCmd(conf *config.Config, builders map[string]builder.BuilderFunc, hclParser *hclparse.Parser) *cobra.Command {
+	missingMarker, separator := "!", " "
+
+	promptCmd := &cobra.Command{
+		Use:   "prompt [tool]...",
+		Short: promptHelp,
+		Long: promptHelp + `
+
+Only local information is used (no remote call, no installation), each tool with a version file or env var
+in current directory is displayed as "<tool>:<version>", followed by the missing marker when no installed version match.
+Tools can be restricted with arguments (tofu, terraform, terragrunt, atmos, conftest or opa), resolution errors are silently skipped.`,
+		Run: func(_ *cobra.Command, args []string) {
+			conf.ForceQuiet = true
+			conf.InitDisplayer(false)
+
+			names := args
+			if len(names) == 0 {
+				names = []string{cmdconst.TofuName, cmdconst.TerraformName, cmdconst.TerragruntName, cmdconst.AtmosName, cmdconst.ConftestName, cmdconst.OpaName}
+			}
+
+			entries := make([]string, 0, len(names))
+			for _, name := range names {
+				builderFunc, ok := builders[name]
+				if !ok {
+					exitOnError(errPromptTool)
+				}
+
+				version, installed, err := builderFunc(conf, hclParser).ResolveLocal()
+				if err != nil || version == "" {
+					continue
+				}
+
+				entry := loghelper.Concat(name, ":", version)
+				if !installed {
+					entry = loghelper.Concat(entry, missingMarker)
+				}
+				entries = append(entries, entry)
+			}
+
+			if len(entries) != 0 {
+				loghelper.StdDisplay(strings.Join(entries, separator))
+			}
+		},
+	}
+
+	flags := promptCmd.Flags()
+	flags.StringVarP(&missingMarker, "missing-marker", "m", missingMarker, "suffix added to a version not installed")
+	flags.StringVarP(&separator, "separator", "s", separator, "separator between tools")
+
+	return promptCmd
+}
@@ -0,0 +1,151 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"errors"
+	"os"
+
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/spf13/cobra"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/config/cmdconst"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/pkg/updatebot"
+	"github.com/tofuutils/tenv/v2/versionmanager"
+	"github.com/tofuutils/tenv/v2/versionmanager/builder"
+)
+
+const reconcileHelp = "Compare version files with Renovate and Dependabot constraints of current directory."
+
+var (
+	errReconcileDrift = errors.New("version files drift from update bot configuration")
+	errReconcileTool  = errors.New("unknown tool, expected tofu, terraform or terragrunt")
+)
+
+var botDepNames = map[string][]string{
+	cmdconst.TofuName:       {"opentofu/opentofu", cmdconst.TofuName},
+	cmdconst.TerraformName:  {"hashicorp/terraform", cmdconst.TerraformName},
+	cmdconst.TerragruntName: {"gruntwork-io/terragrunt", cmdconst.TerragruntName},
+}
+
+func newReconcileCmd(conf *config.Config, builders map[string]builder.BuilderFunc, hclParser *hclparse.Parser) *cobra.Command {
+	fix := false
+
+	reconcileCmd := &cobra.Command{
+		Use:   "reconcile [tool]...",
+		Short: reconcileHelp,
+		Long: reconcileHelp + `
+
+Read allowedVersions of Renovate packageRules (renovate.json, .renovaterc, .renovaterc.json, .github/renovate.json
+or .gitlab/renovate.json) and ignored versions of Dependabot (.github/dependabot.yml or .github/dependabot.yaml)
+concerning tofu, terraform or terragrunt (matched by dependency name, like hashicorp/terraform),
+then report versions found in version files which are not allowed by them.
+
+With --fix, the latest stable version respecting these constraints is written in the version file of working directory.
+Tools can be restricted with arguments (tofu, terraform or terragrunt).`,
+		Run: func(_ *cobra.Command, args []string) {
+			conf.InitDisplayer(false)
+
+			names := args
+			if len(names) == 0 {
+				names = []string{cmdconst.TofuName, cmdconst.TerraformName, cmdconst.TerragruntName}
+			}
+
+			dirPath, err := os.Getwd()
+			if err != nil {
+				exitOnError(err)
+			}
+
+			drift := false
+			for _, name := range names {
+				depNames, ok := botDepNames[name]
+				if !ok {
+					exitOnError(errReconcileTool)
+				}
+
+				rules, skipped, err := updatebot.Read(dirPath, depNames)
+				if err != nil {
+					exitOnError(err)
+				}
+
+				for _, rule := range skipped {
+					loghelper.StdDisplay(loghelper.Concat(name, " : ignoring unsupported constraint ", rule.Constraint, " from ", rule.Source))
+				}
+
+				if len(rules) != 0 && reconcileTool(builders[name](conf, hclParser), name, rules, fix) {
+					drift = true
+				}
+			}
+
+			if drift {
+				exitOnError(errReconcileDrift)
+			}
+		},
+	}
+
+	reconcileCmd.Flags().BoolVarP(&fix, "fix", "f", false, "write latest stable version respecting update bot constraints in version file")
+
+	return reconcileCmd
+}
+
+// return true when drift remains.
+func reconcileTool(versionManager versionmanager.VersionManager, name string, rules []updatebot.Rule, fix bool) bool {
+	versionStr, violated, err := versionManager.CheckBotRules(rules)
+	if err != nil {
+		if !errors.Is(err, versionmanager.ErrNotPinned) {
+			exitOnError(err)
+		}
+
+		if versionStr == "" {
+			loghelper.StdDisplay(loghelper.Concat(name, " : no version file"))
+		} else {
+			loghelper.StdDisplay(loghelper.Concat(name, " : ", versionStr, " is not an exact version, skipped"))
+		}
+
+		return false
+	}
+
+	if len(violated) == 0 {
+		loghelper.StdDisplay(loghelper.Concat(name, " : ", versionStr, " respects update bot constraints"))
+
+		return false
+	}
+
+	for _, rule := range violated {
+		if rule.Exclude {
+			loghelper.StdDisplay(loghelper.Concat(name, " : ", versionStr, " is ignored by ", rule.Constraint, " from ", rule.Source))
+		} else {
+			loghelper.StdDisplay(loghelper.Concat(name, " : ", versionStr, " is not allowed by ", rule.Constraint, " from ", rule.Source))
+		}
+	}
+
+	if !fix {
+		return true
+	}
+
+	fixedVersion, err := versionManager.FixBotRules(rules)
+	if err != nil {
+		exitOnError(err)
+	}
+	loghelper.StdDisplay(loghelper.Concat(name, " : ", fixedVersion, " written in ", versionManager.UseFileName()))
+
+	return false
+}
@@ -20,21 +20,65 @@ package main
 
 import (
 	"bytes"
+	"errors"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/exitcode"
 	"github.com/tofuutils/tenv/v2/pkg/loghelper"
 	"github.com/tofuutils/tenv/v2/versionmanager"
 	"github.com/tofuutils/tenv/v2/versionmanager/semantic"
+	"github.com/tofuutils/tenv/v2/versionmanager/semantic/types"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
 
-func newConstraintCmd(conf *config.Config, versionManager versionmanager.VersionManager) *cobra.Command {
+var (
+	errLatestPerMinorArg = errors.New("--latest-per-minor can not be used with a version parameter")
+	errPruneDisabled     = errors.New("no retention window, use --unused-for or set TENV_PRUNE_AFTER")
+)
+
+func newArchiveCmd(conf *config.Config, versionManager versionmanager.VersionManager) *cobra.Command {
+	var descBuilder strings.Builder
+	descBuilder.WriteString("Compress installed versions of ")
+	descBuilder.WriteString(versionManager.FolderName)
+	descBuilder.WriteString(` in place to reclaim disk space.
+
+Without argument, archive versions not used since TENV_ARCHIVE_AFTER (policy also applied after each installation).
+Archived versions stay listed and are expanded on demand by the next proxy call.`)
+
+	archiveCmd := &cobra.Command{
+		Use:   "archive [version]...",
+		Short: loghelper.Concat("Compress installed versions of ", versionManager.FolderName, "."),
+		Long:  descBuilder.String(),
+		Run: func(_ *cobra.Command, args []string) {
+			conf.InitDisplayer(false)
+
+			if len(args) == 0 {
+				if err := versionManager.ArchiveUnused(); err != nil {
+					exitOnError(err)
+				}
+
+				return
+			}
+
+			for _, version := range args {
+				if err := versionManager.Archive(version); err != nil {
+					exitOnError(err)
+				}
+			}
+		},
+	}
+
+	return archiveCmd
+}
+
+func newConstraintCmd(conf *config.Config, versionManager versionmanager.VersionManager, params subCmdParams) *cobra.Command {
 	var descBuilder strings.Builder
 	descBuilder.WriteString("Set a default constraint expression for ")
 	descBuilder.WriteString(versionManager.FolderName)
@@ -44,7 +88,15 @@ func newConstraintCmd(conf *config.Config, versionManager versionmanager.Version
 
 Without expression reset the default constraint.
 
-The default constraint is added while using latest-allowed, min-required or custom constraint.`)
+The default constraint is added while using latest-allowed, min-required or custom constraint.
+
+With the warn: prefix (like "warn:>= 1.6"), the constraint is not used to select a version, a warning is displayed when the resolved version does not match it.
+
+A project default constraint can be set in `)
+	descBuilder.WriteString(versionManager.ProjectConstraintFileName())
+	descBuilder.WriteString(" file (searched like version files) and overrides the TENV_ROOT one, ")
+	descBuilder.WriteString(versionManager.ConstraintEnvName())
+	descBuilder.WriteString(" environment variable overrides both.")
 
 	constraintCmd := &cobra.Command{
 		Use:   "constraint [expression]",
@@ -55,29 +107,124 @@ The default constraint is added while using latest-allowed, min-required or cust
 			conf.InitDisplayer(false)
 
 			if len(args) == 0 || args[0] == "" {
-				if err := versionManager.ResetConstraint(); err != nil {
-					loghelper.StdDisplay(err.Error())
+				if err := versionManager.ResetConstraint(false); err != nil {
+					exitOnError(err)
 				}
 
 				return
 			}
 
-			if err := versionManager.SetConstraint(args[0]); err != nil {
-				loghelper.StdDisplay(err.Error())
+			if err := versionManager.SetConstraint(args[0], false); err != nil {
+				exitOnError(err)
 			}
 		},
 	}
 
+	constraintCmd.AddCommand(newConstraintShowCmd(conf, versionManager))
+	constraintCmd.AddCommand(newConstraintSetCmd(conf, versionManager))
+	constraintCmd.AddCommand(newConstraintUnsetCmd(conf, versionManager))
+	constraintCmd.AddCommand(newConstraintValidateCmd(conf, versionManager, params))
+
 	return constraintCmd
 }
 
+func newConstraintSetCmd(conf *config.Config, versionManager versionmanager.VersionManager) *cobra.Command {
+	workingDir := false
+
+	setCmd := &cobra.Command{
+		Use:   "set expression",
+		Short: loghelper.Concat("Set the default constraint expression for ", versionManager.FolderName, "."),
+		Long:  loghelper.Concat("Set the default constraint expression for ", versionManager.FolderName, " (in TENV_ROOT/", versionManager.FolderName, "/constraint file or in ", versionManager.ProjectConstraintFileName(), " file with --working-dir)."),
+		Args:  cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			conf.InitDisplayer(false)
+
+			if err := versionManager.SetConstraint(args[0], workingDir); err != nil {
+				exitOnError(err)
+			}
+		},
+	}
+
+	setCmd.Flags().BoolVarP(&workingDir, "working-dir", "w", false, loghelper.Concat("create ", versionManager.ProjectConstraintFileName(), " file in working directory"))
+
+	return setCmd
+}
+
+func newConstraintShowCmd(conf *config.Config, versionManager versionmanager.VersionManager) *cobra.Command {
+	return &cobra.Command{
+		Use:   "show",
+		Short: loghelper.Concat("Display the default constraint expression for ", versionManager.FolderName, " and its origin."),
+		Args:  cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			conf.InitDisplayer(false)
+
+			constraint, source := versionManager.ReadDefaultConstraintWithSource()
+			if constraint == "" {
+				loghelper.StdDisplay(loghelper.Concat("No default constraint for ", versionManager.FolderName))
+			} else {
+				loghelper.StdDisplay(loghelper.Concat("Default constraint for ", versionManager.FolderName, " : ", constraint, " (from ", source, ")"))
+			}
+
+			switch envName, projectFileName := versionManager.ConstraintEnvName(), versionManager.ProjectConstraintFileName(); {
+			case source == envName:
+			case filepath.Base(source) == projectFileName:
+				loghelper.StdDisplay(loghelper.Concat("Can be overridden with ", envName, " environment variable"))
+			default:
+				loghelper.StdDisplay(loghelper.Concat("Can be overridden with ", envName, " environment variable or ", projectFileName, " file"))
+			}
+		},
+	}
+}
+
+func newConstraintUnsetCmd(conf *config.Config, versionManager versionmanager.VersionManager) *cobra.Command {
+	workingDir := false
+
+	unsetCmd := &cobra.Command{
+		Use:   "unset",
+		Short: loghelper.Concat("Remove the default constraint expression for ", versionManager.FolderName, "."),
+		Args:  cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			conf.InitDisplayer(false)
+
+			if err := versionManager.ResetConstraint(workingDir); err != nil {
+				exitOnError(err)
+			}
+		},
+	}
+
+	unsetCmd.Flags().BoolVarP(&workingDir, "working-dir", "w", false, loghelper.Concat("remove ", versionManager.ProjectConstraintFileName(), " file in working directory"))
+
+	return unsetCmd
+}
+
+func newConstraintValidateCmd(conf *config.Config, versionManager versionmanager.VersionManager, params subCmdParams) *cobra.Command {
+	validateCmd := &cobra.Command{
+		Use:   "validate expression",
+		Short: loghelper.Concat("Check that a constraint expression is parsable and matches at least one remote ", versionManager.FolderName, " version."),
+		Args:  cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			conf.InitDisplayer(false)
+
+			versions, err := versionManager.ListMatching(args[0], 1, false)
+			if err != nil {
+				exitOnError(err)
+			}
+			loghelper.StdDisplay(loghelper.Concat("Constraint ", args[0], " is valid, highest matching version : ", versions[0]))
+		},
+	}
+
+	addRemoteFlags(validateCmd.Flags(), conf, params)
+
+	return validateCmd
+}
+
 func newDetectCmd(conf *config.Config, versionManager versionmanager.VersionManager, params subCmdParams) *cobra.Command {
 	var descBuilder strings.Builder
 	descBuilder.WriteString("Display ")
 	descBuilder.WriteString(versionManager.FolderName)
-	descBuilder.WriteString(" current version.")
+	descBuilder.WriteString(" current version.\n\nWith --explain, the resolution chain is also displayed : source of the requested version, constraints combined to evaluate it, then the evaluation decision.")
 
-	forceInstall, forceNoInstall := false, false
+	explain, forceInstall, forceNoInstall, preferLocal, preferRemote := false, false, false, false, false
 
 	detectCmd := &cobra.Command{
 		Use:   "detect",
@@ -87,22 +234,49 @@ func newDetectCmd(conf *config.Config, versionManager versionmanager.VersionMana
 		Run: func(_ *cobra.Command, _ []string) {
 			conf.InitDisplayer(false)
 			conf.InitInstall(forceInstall, forceNoInstall)
+			conf.InitSearch(preferLocal, preferRemote)
 
-			detectedVersion, err := versionManager.Detect(false)
+			var evaluation versionmanager.Evaluation
+			var steps []versionmanager.PrecedenceStep
+			var err error
+			recorder := &types.SourceRecorder{Displayer: conf.Displayer}
+			conf.Displayer = recorder
+			if explain {
+				evaluation, steps, err = versionManager.Explain()
+			} else {
+				evaluation, err = versionManager.DetectResult(false)
+			}
+			conf.Displayer = recorder.Displayer
 			if err != nil {
-				loghelper.StdDisplay(err.Error())
+				exitOnError(err)
+			}
 
-				if err != versionmanager.ErrNoCompatibleLocally {
-					return
+			if conf.JSONOutput {
+				displayJSON(detectJSONResult{
+					Chain: makeStepJSONEntries(steps), Installed: evaluation.Installed, ResolvedFrom: recorder.Source,
+					Source: evaluation.Source, Tool: versionManager.FolderName, Version: evaluation.Version,
+				})
+
+				return
+			}
+
+			if explain {
+				loghelper.StdDisplay("Resolution chain :")
+				for _, step := range steps {
+					loghelper.StdDisplay(loghelper.Concat("  ", step.Kind, " ", step.Name, " : ", step.Detail))
 				}
 			}
-			loghelper.StdDisplay(loghelper.Concat(versionManager.FolderName, " ", detectedVersion, " will be run from this directory."))
+			if !evaluation.Installed {
+				loghelper.StdDisplay(versionmanager.ErrNoCompatibleLocally.Error())
+			}
+			loghelper.StdDisplay(loghelper.Concat(versionManager.FolderName, " ", evaluation.Version, " will be run from this directory."))
 		},
 	}
 
 	flags := detectCmd.Flags()
+	flags.BoolVarP(&explain, "explain", "e", false, "display the resolution chain leading to the detected version")
 	addInstallationFlags(flags, conf, params)
-	addOptionalInstallationFlags(flags, conf, params, &forceInstall, &forceNoInstall)
+	addOptionalInstallationFlags(flags, conf, params, &forceInstall, &forceNoInstall, &preferLocal, &preferRemote)
 	addRemoteFlags(flags, conf, params)
 
 	return detectCmd
@@ -129,6 +303,9 @@ If a parameter is passed, available options:
 	descBuilder.WriteString(" url)\n- latest:<re> or min:<re> to get first version matching with <re> as a regexp after a version sort\n- latest-allowed or min-required to scan your ")
 	descBuilder.WriteString(versionManager.FolderName)
 	descBuilder.WriteString(" files to detect which version is maximally allowed or minimally required")
+	descBuilder.WriteString("\n\nWith --latest-per-minor, the newest stable patch of each minor line matching the constraint is installed (like \">= 1.5, < 1.9\").")
+
+	latestPerMinor := ""
 
 	installCmd := &cobra.Command{
 		Use:   "install [version]",
@@ -138,28 +315,46 @@ If a parameter is passed, available options:
 		Run: func(_ *cobra.Command, args []string) {
 			conf.InitDisplayer(false)
 
+			if latestPerMinor != "" {
+				if len(args) != 0 {
+					exitOnError(errLatestPerMinorArg)
+				}
+
+				versions, err := versionManager.ListLatestPerMinor(latestPerMinor)
+				if err != nil {
+					exitOnError(err)
+				}
+
+				for _, version := range versions {
+					if err = versionManager.Install(version); err != nil {
+						exitOnError(err)
+					}
+				}
+
+				return
+			}
+
 			if len(args) == 0 {
 				version, err := versionManager.Resolve(semantic.LatestKey)
 				if err != nil {
-					loghelper.StdDisplay(err.Error())
-
-					return
+					exitOnError(err)
 				}
 
 				if err = versionManager.Install(version); err != nil {
-					loghelper.StdDisplay(err.Error())
+					exitOnError(err)
 				}
 
 				return
 			}
 
 			if err := versionManager.Install(args[0]); err != nil {
-				loghelper.StdDisplay(err.Error())
+				exitOnError(err)
 			}
 		},
 	}
 
 	flags := installCmd.Flags()
+	flags.StringVarP(&latestPerMinor, "latest-per-minor", "m", "", "install the newest patch of each minor line matching this constraint")
 	addInstallationFlags(flags, conf, params)
 	addRemoteFlags(flags, conf, params)
 
@@ -170,9 +365,10 @@ func newListCmd(conf *config.Config, versionManager versionmanager.VersionManage
 	var descBuilder strings.Builder
 	descBuilder.WriteString("List installed ")
 	descBuilder.WriteString(versionManager.FolderName)
-	descBuilder.WriteString(" versions (located in TENV_ROOT directory), sorted in ascending version order.")
+	descBuilder.WriteString(" versions (located in TENV_ROOT directory), sorted in ascending version order.\n\nHidden directories and directories without a version name are ignored.")
 
-	reverseOrder := false
+	displayAll, reverseOrder := false, false
+	templateStr := ""
 
 	listCmd := &cobra.Command{
 		Use:   "list",
@@ -181,46 +377,94 @@ func newListCmd(conf *config.Config, versionManager versionmanager.VersionManage
 		Args:  cobra.NoArgs,
 		Run: func(_ *cobra.Command, _ []string) {
 			conf.InitDisplayer(false)
+			checkJSONTemplate(conf.JSONOutput, templateStr)
 
-			datedVersions, err := versionManager.ListLocal(reverseOrder)
+			tmpl, err := parseTemplate(templateStr)
 			if err != nil {
-				loghelper.StdDisplay(err.Error())
+				exitOnError(err)
+			}
 
-				return
+			datedVersions, err := versionManager.ListLocal(reverseOrder)
+			if err != nil {
+				exitOnError(err)
 			}
 
 			filePath := versionManager.RootVersionFilePath()
 			data, err := os.ReadFile(filePath)
-			if err != nil && conf.DisplayVerbose {
+			if err != nil && conf.DisplayVerbose && !conf.JSONOutput {
 				loghelper.StdDisplay("Can not read used version : " + err.Error())
 			}
 			usedVersion := string(bytes.TrimSpace(data))
 
+			if conf.JSONOutput {
+				entries := make([]localJSONEntry, 0, len(datedVersions))
+				for _, datedVersion := range datedVersions {
+					entry := localJSONEntry{
+						Archived: datedVersion.Archived, Installed: true, UseCount: datedVersion.UseCount,
+						UseDate: formatJSONDate(datedVersion.UseDate), Used: usedVersion == datedVersion.Version, Version: datedVersion.Version,
+					}
+					if entry.Used {
+						entry.UsedBy = filePath
+					}
+					entries = append(entries, entry)
+				}
+				displayJSON(entries)
+
+				return
+			}
+
 			nilTime := time.Time{}
 			for _, datedVersion := range datedVersions {
 				useDate := datedVersion.UseDate
 				version := datedVersion.Version
 				noUseDate := useDate == nilTime
+				archived := ""
+				if datedVersion.Archived {
+					archived = ", archived"
+				}
 				switch {
+				case tmpl != nil:
+					data := localTemplateData{Archived: datedVersion.Archived, UseCount: datedVersion.UseCount, UseDate: useDate, Used: usedVersion == version, Version: version}
+					if data.Used {
+						data.UsedBy = filePath
+					}
+					data.Manifest, data.HasManifest = versionManager.ReadManifest(version)
+					displayTemplate(tmpl, data)
 				case usedVersion == version:
 					if noUseDate {
-						loghelper.StdDisplay(loghelper.Concat("* ", version, " (never used, set by ", filePath, ")"))
+						loghelper.StdDisplay(loghelper.Concat("* ", version, " (never used, set by ", filePath, archived, ")"))
 					} else {
-						loghelper.StdDisplay(loghelper.Concat("* ", version, " (used ", useDate.Format(time.DateOnly), ", set by ", filePath, ")")) //nolint
+						loghelper.StdDisplay(loghelper.Concat("* ", version, " (used ", useDate.Format(time.DateOnly), ", set by ", filePath, archived, ")")) //nolint
 					}
 				case noUseDate:
-					loghelper.StdDisplay(loghelper.Concat("  ", version, " (never used)"))
+					loghelper.StdDisplay(loghelper.Concat("  ", version, " (never used", archived, ")"))
 				default:
-					loghelper.StdDisplay(loghelper.Concat("  ", version, " (used ", useDate.Format(time.DateOnly), ")")) //nolint
+					loghelper.StdDisplay(loghelper.Concat("  ", version, " (used ", useDate.Format(time.DateOnly), archived, ")")) //nolint
 				}
 			}
 			if conf.DisplayVerbose {
 				loghelper.StdDisplay(loghelper.Concat("found ", strconv.Itoa(len(datedVersions)), " ", versionManager.FolderName, " version(s) managed by tenv."))
 			}
+
+			if !displayAll {
+				return
+			}
+
+			ignoreds, err := versionManager.ListIgnored()
+			if err != nil {
+				exitOnError(err)
+			}
+
+			for _, ignored := range ignoreds {
+				loghelper.StdDisplay(loghelper.Concat("  ", ignored.Name, " (ignored, ", ignored.Reason, ")"))
+			}
 		},
 	}
 
-	addDescendingFlag(listCmd.Flags(), &reverseOrder)
+	flags := listCmd.Flags()
+	flags.BoolVarP(&displayAll, "all", "A", false, "also display ignored entries of installation directory with reasons")
+	addDescendingFlag(flags, &reverseOrder)
+	addTemplateFlag(flags, &templateStr)
 
 	return listCmd
 }
@@ -232,9 +476,11 @@ func newListRemoteCmd(conf *config.Config, versionManager versionmanager.Version
 	descBuilder.WriteString(" versions (from ")
 	descBuilder.WriteString(params.remoteEnvName)
 	descBuilder.WriteString(" url), sorted in ascending version order.")
+	descBuilder.WriteString("\n\nPublish dates (used by --since, --until and --dates) come from API list mode and are kept in a local index, so they stay available in html list mode once fetched.")
+	descBuilder.WriteString("\n\nWith --verify-available, each displayed version requires extra requests to check its checksum and signature files, combine it with filters to limit them.")
 
-	filterStable := false
-	reverseOrder := false
+	filterInstalled, filterNotInstalled, filterStable, reverseOrder, displayDates, verifyAvailable := false, false, false, false, false, false
+	sinceStr, templateStr, untilStr := "", "", ""
 
 	listRemoteCmd := &cobra.Command{
 		Use:   "list-remote",
@@ -243,15 +489,36 @@ func newListRemoteCmd(conf *config.Config, versionManager versionmanager.Version
 		Args:  cobra.NoArgs,
 		Run: func(_ *cobra.Command, _ []string) {
 			conf.InitDisplayer(false)
+			checkJSONTemplate(conf.JSONOutput, templateStr)
+
+			since, err := parseDateFlag(sinceStr, false)
+			if err != nil {
+				exitOnError(err)
+			}
+
+			until, err := parseDateFlag(untilStr, true)
+			if err != nil {
+				exitOnError(err)
+			}
+
+			tmpl, err := parseTemplate(templateStr)
+			if err != nil {
+				exitOnError(err)
+			}
 
 			versions, err := versionManager.ListRemote(reverseOrder)
 			if err != nil {
-				loghelper.StdDisplay(err.Error())
+				exitOnError(err)
+			}
 
-				return
+			var dates map[string]time.Time
+			var entries []remoteJSONEntry
+			filterDate := !since.IsZero() || !until.IsZero()
+			if filterDate || displayDates || conf.JSONOutput {
+				dates = versionManager.ReleaseDates()
 			}
 
-			countSkipped := 0
+			countSkipped, countInstallSkipped, countDateSkipped := 0, 0, 0
 			localSet := versionManager.LocalSet()
 			for _, version := range versions {
 				if filterStable && !semantic.StableVersion(version) {
@@ -260,17 +527,78 @@ func newListRemoteCmd(conf *config.Config, versionManager versionmanager.Version
 					continue
 				}
 
-				if _, installed := localSet[version]; installed {
-					loghelper.StdDisplay(version + " (installed)")
+				installed, hidden := installState(localSet, version, filterInstalled, filterNotInstalled)
+				if hidden {
+					countInstallSkipped++
+
+					continue
+				}
+
+				date, dated := dates[version]
+				if filterDate && (!dated || (!since.IsZero() && date.Before(since)) || (!until.IsZero() && date.After(until))) {
+					countDateSkipped++
+
+					continue
+				}
+
+				if conf.JSONOutput {
+					entry := remoteJSONEntry{Installed: installed, PublishDate: formatJSONDate(date), Stable: semantic.StableVersion(version), Version: version}
+					if verifyAvailable {
+						if checksum, signature, err := versionManager.ProbeSidecars(version); err == nil {
+							entry.Checksum, entry.Signature = &checksum, &signature
+						}
+					}
+					entries = append(entries, entry)
+
+					continue
+				}
+
+				if tmpl != nil {
+					data := remoteTemplateData{Installed: installed, PublishDate: date, Stable: semantic.StableVersion(version), Version: version}
+					if verifyAvailable {
+						data.Checksum, data.Signature, _ = versionManager.ProbeSidecars(version)
+					}
+					displayTemplate(tmpl, data)
+
+					continue
+				}
+
+				display := version
+				if displayDates && dated {
+					display = loghelper.Concat(version, " (", date.Format(time.DateOnly), ")")
+				}
+				if verifyAvailable {
+					display += sidecarsMarker(versionManager, version)
+				}
+
+				// markers are useless when only one kind of version is displayed
+				if installed && !filterInstalled {
+					loghelper.StdDisplay(display + " (installed)")
 				} else {
-					loghelper.StdDisplay(version)
+					loghelper.StdDisplay(display)
+				}
+			}
+			if conf.JSONOutput {
+				if entries == nil {
+					entries = []remoteJSONEntry{}
 				}
+				displayJSON(entries)
+
+				return
 			}
 			if conf.DisplayVerbose {
 				loghelper.StdDisplay(loghelper.Concat("found ", strconv.Itoa(len(versions)), " ", versionManager.FolderName, " version(s) (on ", params.remoteEnvName, ")."))
 				if filterStable {
 					loghelper.StdDisplay(strconv.Itoa(countSkipped) + " result(s) hidden (version not stable).")
 				}
+				if filterInstalled {
+					loghelper.StdDisplay(strconv.Itoa(countInstallSkipped) + " result(s) hidden (version not installed).")
+				} else if filterNotInstalled {
+					loghelper.StdDisplay(strconv.Itoa(countInstallSkipped) + " result(s) hidden (version installed).")
+				}
+				if filterDate {
+					loghelper.StdDisplay(strconv.Itoa(countDateSkipped) + " result(s) hidden (publish date out of range or unknown).")
+				}
 			}
 
 			return
@@ -281,10 +609,89 @@ func newListRemoteCmd(conf *config.Config, versionManager versionmanager.Version
 	addDescendingFlag(flags, &reverseOrder)
 	addRemoteFlags(flags, conf, params)
 	flags.BoolVarP(&filterStable, "stable", "s", false, "display only stable version")
+	flags.BoolVarP(&filterInstalled, "installed-only", "I", false, "display only installed version")
+	flags.BoolVarP(&filterNotInstalled, "not-installed", "N", false, "display only version not installed")
+	flags.BoolVarP(&displayDates, "dates", "D", false, "display publish date of versions (when known)")
+	flags.BoolVarP(&verifyAvailable, "verify-available", "V", false, "check that checksum and signature files are published for each displayed version")
+	addTemplateFlag(flags, &templateStr)
+	flags.StringVar(&sinceStr, "since", "", "display only version published since this date (like 2024-01-01 or RFC 3339 format)")
+	flags.StringVar(&untilStr, "until", "", "display only version published until this date (included, like 2024-06-30 or RFC 3339 format)")
+	listRemoteCmd.MarkFlagsMutuallyExclusive("installed-only", "not-installed")
 
 	return listRemoteCmd
 }
 
+// returns whether version is installed and whether it is hidden by --installed-only or --not-installed flags.
+func installState(localSet map[string]struct{}, version string, filterInstalled bool, filterNotInstalled bool) (bool, bool) {
+	_, installed := localSet[version]
+
+	return installed, (filterInstalled && !installed) || (filterNotInstalled && installed)
+}
+
+func sidecarsMarker(versionManager versionmanager.VersionManager, version string) string {
+	checksum, signature, err := versionManager.ProbeSidecars(version)
+	if err != nil {
+		return " (checksum: unknown, signature: unknown)"
+	}
+
+	return loghelper.Concat(" (checksum: ", strconv.FormatBool(checksum), ", signature: ", strconv.FormatBool(signature), ")")
+}
+
+func newPruneCmd(conf *config.Config, versionManager versionmanager.VersionManager) *cobra.Command {
+	var descBuilder strings.Builder
+	descBuilder.WriteString("Uninstall versions of ")
+	descBuilder.WriteString(versionManager.FolderName)
+	descBuilder.WriteString(` not used within a retention window.
+
+The retention window is given in days or months with --unused-for (like "90d" or "3m"), or defaults to TENV_PRUNE_AFTER
+(policy also applied after each installation). Never used versions are dated by their installation, the default version
+(set with use command) is kept.`)
+
+	dryRun, unusedFor := false, ""
+
+	pruneCmd := &cobra.Command{
+		Use:   "prune",
+		Short: loghelper.Concat("Uninstall versions of ", versionManager.FolderName, " not used within a retention window."),
+		Long:  descBuilder.String(),
+		Args:  cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			conf.InitDisplayer(false)
+
+			var limit time.Time
+			switch {
+			case unusedFor != "":
+				var err error
+				if limit, err = semantic.UnusedForLimit(unusedFor); err != nil {
+					exitOnError(err)
+				}
+			case conf.PruneAfter > 0:
+				limit = time.Now().Add(-conf.PruneAfter)
+			default:
+				exitOnError(errPruneDisabled)
+			}
+
+			selected, err := versionManager.Prune(limit, dryRun)
+			if err != nil {
+				exitOnError(err)
+			}
+
+			switch {
+			case len(selected) == 0:
+				conf.Displayer.Display(loghelper.Concat("No unused ", versionManager.FolderName, " versions"))
+			case dryRun:
+				conf.Displayer.Display(loghelper.Concat("Would uninstall ", versionManager.FolderName, " versions :"))
+				conf.Displayer.Display(strings.Join(selected, ", "))
+			}
+		},
+	}
+
+	flags := pruneCmd.Flags()
+	flags.BoolVarP(&dryRun, "dry-run", "n", false, "only display versions which would be uninstalled")
+	flags.StringVarP(&unusedFor, "unused-for", "u", "", "retention window in days or months (like \"90d\" or \"3m\")")
+
+	return pruneCmd
+}
+
 func newResetCmd(conf *config.Config, versionManager versionmanager.VersionManager) *cobra.Command {
 	var descBuilder strings.Builder
 	descBuilder.WriteString("Reset used version of ")
@@ -302,7 +709,7 @@ func newResetCmd(conf *config.Config, versionManager versionmanager.VersionManag
 			conf.InitDisplayer(false)
 
 			if err := versionManager.ResetVersion(); err != nil {
-				loghelper.StdDisplay(err.Error())
+				exitOnError(err)
 			}
 		},
 	}
@@ -310,6 +717,68 @@ func newResetCmd(conf *config.Config, versionManager versionmanager.VersionManag
 	return resetCmd
 }
 
+func newRestoreCmd(conf *config.Config, versionManager versionmanager.VersionManager) *cobra.Command {
+	var descBuilder strings.Builder
+	descBuilder.WriteString("Restore a version of ")
+	descBuilder.WriteString(versionManager.FolderName)
+	descBuilder.WriteString(` previously uninstalled.
+
+Uninstalled versions are kept in TENV_ROOT/.trash directory during TENV_TRASH_TTL (default 7 days).`)
+
+	restoreCmd := &cobra.Command{
+		Use:   "restore version",
+		Short: loghelper.Concat("Restore a version of ", versionManager.FolderName, " previously uninstalled."),
+		Long:  descBuilder.String(),
+		Args:  cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			conf.InitDisplayer(false)
+
+			if err := versionManager.Restore(args[0]); err != nil {
+				exitOnError(err)
+			}
+		},
+	}
+
+	return restoreCmd
+}
+
+func newTouchCmd(conf *config.Config, versionManager versionmanager.VersionManager) *cobra.Command {
+	var descBuilder strings.Builder
+	descBuilder.WriteString("Mark an installed version of ")
+	descBuilder.WriteString(versionManager.FolderName)
+	descBuilder.WriteString(` as used, without counting a call.
+
+Useful to keep a version when uninstalling with not-used-for:<duration> or not-used-since:<date>.`)
+
+	dateStr := ""
+
+	touchCmd := &cobra.Command{
+		Use:   "touch version",
+		Short: loghelper.Concat("Mark an installed version of ", versionManager.FolderName, " as used."),
+		Long:  descBuilder.String(),
+		Args:  cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			conf.InitDisplayer(false)
+
+			date := time.Now().UTC().Truncate(time.Second)
+			if dateStr != "" {
+				var err error
+				if date, err = time.Parse(time.DateOnly, dateStr); err != nil { //nolint
+					exitOnError(err)
+				}
+			}
+
+			if err := versionManager.Touch(args[0], date); err != nil {
+				exitOnError(err)
+			}
+		},
+	}
+
+	touchCmd.Flags().StringVarP(&dateStr, "date", "d", "", "use date to record instead of now (format is YYYY-MM-DD, like \"2024-06-30\")")
+
+	return touchCmd
+}
+
 func newUninstallCmd(conf *config.Config, versionManager versionmanager.VersionManager) *cobra.Command {
 	var descBuilder strings.Builder
 	descBuilder.WriteString("Uninstall versions of ")
@@ -324,7 +793,9 @@ If a parameter is passed, available parameter options:
 - all
 - but-last (all versions except the highest installed)
 - not-used-for:<duration>, <duration> in days or months, like "14d" or "2m"
-- not-used-since:<date>, <date> format is YYYY-MM-DD, like "2024-06-30"`)
+- not-used-since:<date>, <date> format is YYYY-MM-DD, like "2024-06-30"
+
+Other options ask for a confirmation, skipped with --yes flag or TENV_AUTO_APPROVE.`)
 
 	uninstallCmd := &cobra.Command{
 		Use:   "uninstall version",
@@ -342,14 +813,58 @@ If a parameter is passed, available parameter options:
 			}
 
 			if err != nil {
-				loghelper.StdDisplay(err.Error())
+				exitOnError(err)
 			}
 		},
 	}
 
+	flags := uninstallCmd.Flags()
+	flags.BoolVarP(&conf.AutoApprove, "yes", "y", conf.AutoApprove, "uninstall selected versions without confirmation")
+	flags.BoolVar(&conf.AutoApprove, "force", conf.AutoApprove, "alias of --yes")
+
 	return uninstallCmd
 }
 
+func newUsageCmd(conf *config.Config, versionManager versionmanager.VersionManager) *cobra.Command {
+	var descBuilder strings.Builder
+	descBuilder.WriteString("Display last use date and number of proxy calls of installed ")
+	descBuilder.WriteString(versionManager.FolderName)
+	descBuilder.WriteString(` versions, sorted in ascending version order.
+
+Usage is stored in last-use.json file of each version directory (in TENV_ROOT/users/<uid> with TENV_SHARED_ROOT, where
+displayed values aggregate all users), files written by previous tenv versions only contain a date and are migrated on next use.`)
+
+	reverseOrder := false
+
+	usageCmd := &cobra.Command{
+		Use:   "usage",
+		Short: loghelper.Concat("Display usage of installed ", versionManager.FolderName, " versions."),
+		Long:  descBuilder.String(),
+		Args:  cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			conf.InitDisplayer(false)
+
+			datedVersions, err := versionManager.ListLocal(reverseOrder)
+			if err != nil {
+				exitOnError(err)
+			}
+
+			for _, datedVersion := range datedVersions {
+				count := strconv.Itoa(datedVersion.UseCount)
+				if datedVersion.UseDate.IsZero() {
+					loghelper.StdDisplay(loghelper.Concat(datedVersion.Version, " : never used, ", count, " call(s)"))
+				} else {
+					loghelper.StdDisplay(loghelper.Concat(datedVersion.Version, " : last used ", datedVersion.UseDate.Format(time.DateOnly), ", ", count, " call(s)")) //nolint
+				}
+			}
+		},
+	}
+
+	addDescendingFlag(usageCmd.Flags(), &reverseOrder)
+
+	return usageCmd
+}
+
 func newUseCmd(conf *config.Config, versionManager versionmanager.VersionManager, params subCmdParams) *cobra.Command {
 	var descBuilder strings.Builder
 	descBuilder.WriteString("Switch the default ")
@@ -367,7 +882,8 @@ Available parameter options:
 	descBuilder.WriteString(versionManager.FolderName)
 	descBuilder.WriteString(" files to detect which version is maximally allowed or minimally required")
 
-	forceInstall, forceNoInstall, workingDir := false, false, false
+	forceInstall, forceNoInstall, preferLocal, preferRemote, workingDir := false, false, false, false, false
+	fileName, dirPath := "", ""
 
 	useCmd := &cobra.Command{
 		Use:   "use version",
@@ -377,26 +893,75 @@ Available parameter options:
 		Run: func(_ *cobra.Command, args []string) {
 			conf.InitDisplayer(false)
 			conf.InitInstall(forceInstall, forceNoInstall)
+			conf.InitSearch(preferLocal, preferRemote)
+
+			var err error
+			if fileName == "" && dirPath == "" {
+				err = versionManager.Use(args[0], workingDir)
+			} else {
+				err = versionManager.UseInFile(args[0], useFilePath(versionManager, fileName, dirPath))
+			}
 
-			if err := versionManager.Use(args[0], workingDir); err != nil {
-				loghelper.StdDisplay(err.Error())
+			if err != nil {
+				exitOnError(err)
 			}
 		},
 	}
 
 	flags := useCmd.Flags()
 	addInstallationFlags(flags, conf, params)
-	addOptionalInstallationFlags(flags, conf, params, &forceInstall, &forceNoInstall)
+	addOptionalInstallationFlags(flags, conf, params, &forceInstall, &forceNoInstall, &preferLocal, &preferRemote)
 	addRemoteFlags(flags, conf, params)
-	flags.BoolVarP(&workingDir, "working-dir", "w", false, loghelper.Concat("create ", versionManager.VersionFiles[0].Name, " file in working directory"))
+	flags.BoolVarP(&workingDir, "working-dir", "w", false, loghelper.Concat("create ", versionManager.VersionFiles[0].Name, " file in working directory (see TENV_USE_FILE)"))
+	flags.StringVar(&fileName, "file", "", "version file name or path to create (like .tool-versions, implies --working-dir)")
+	flags.StringVar(&dirPath, "dir", "", "directory where the version file is created (implies --working-dir)")
 
 	return useCmd
 }
 
+// a relative fileName is joined to dirPath, an absolute one is kept.
+func useFilePath(versionManager versionmanager.VersionManager, fileName string, dirPath string) string {
+	if fileName == "" {
+		fileName = versionManager.UseFileName()
+	}
+
+	if dirPath == "" || filepath.IsAbs(fileName) {
+		return fileName
+	}
+
+	return filepath.Join(dirPath, fileName)
+}
+
+// display the error and exit with the code matching its category (see pkg/exitcode).
+func exitOnError(err error) {
+	loghelper.StdDisplay(err.Error())
+	code := exitcode.FromError(err)
+	recordError(code)
+	os.Exit(code)
+}
+
 func addDescendingFlag(flags *pflag.FlagSet, pReverseOrder *bool) {
 	flags.BoolVarP(pReverseOrder, "descending", "d", false, "display list in descending version order")
 }
 
+// a date only value is a whole day, so with endOfDay the returned time is the last instant of the day.
+func parseDateFlag(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Parse(time.RFC3339, value)
+	}
+
+	if endOfDay {
+		date = date.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+
+	return date, nil
+}
+
 func addInstallationFlags(flags *pflag.FlagSet, conf *config.Config, params subCmdParams) {
 	flags.StringVarP(&conf.Arch, "arch", "a", conf.Arch, "specify arch for binaries downloading")
 	if params.pPublicKeyPath != nil {
@@ -405,10 +970,12 @@ func addInstallationFlags(flags *pflag.FlagSet, conf *config.Config, params subC
 	}
 }
 
-func addOptionalInstallationFlags(flags *pflag.FlagSet, conf *config.Config, params subCmdParams, pInstall *bool, pNoInstall *bool) {
+func addOptionalInstallationFlags(flags *pflag.FlagSet, conf *config.Config, params subCmdParams, pInstall *bool, pNoInstall *bool, pPreferLocal *bool, pPreferRemote *bool) {
 	flags.BoolVarP(&conf.ForceRemote, "force-remote", "f", conf.ForceRemote, loghelper.Concat("force search on versions available at ", params.remoteEnvName, " url"))
 	flags.BoolVarP(pInstall, "install", "i", false, "enable installation of missing version")
 	flags.BoolVarP(pNoInstall, "no-install", "n", false, "disable installation of missing version")
+	flags.BoolVar(pPreferLocal, "prefer-local", false, "search installed versions first, even with TENV_FORCE_REMOTE (higher priority than --prefer-remote)")
+	flags.BoolVar(pPreferRemote, "prefer-remote", false, loghelper.Concat("search on versions available at ", params.remoteEnvName, " url, like --force-remote"))
 }
 
 func addRemoteFlags(flags *pflag.FlagSet, conf *config.Config, params subCmdParams) {
@@ -417,4 +984,6 @@ func addRemoteFlags(flags *pflag.FlagSet, conf *config.Config, params subCmdPara
 		flags.StringVarP(&conf.GithubToken, "github-token", "t", conf.GithubToken, "GitHub token (increases GitHub REST API rate limits)")
 	}
 	flags.StringVarP(params.pRemote, "remote-url", "u", "", "remote url to install from")
+	flags.BoolVar(&conf.NoCache, "no-cache", false, "do not use remote releases list cache (see TENV_REMOTE_CACHE_TTL)")
+	flags.BoolVar(&conf.RefreshCache, "refresh", false, "fetch remote releases list and refresh its cache")
 }
@@ -0,0 +1,89 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager"
+)
+
+func TestInstallState(t *testing.T) {
+	t.Parallel()
+
+	localSet := map[string]struct{}{"1.6.2": {}, "1.7.0": {}}
+	versions := []string{"1.5.0", "1.6.2", "1.7.0", "1.8.0"}
+
+	tests := []struct {
+		name               string
+		filterInstalled    bool
+		filterNotInstalled bool
+		want               []string
+	}{
+		{name: "NoFilter", want: versions},
+		{name: "InstalledOnly", filterInstalled: true, want: []string{"1.6.2", "1.7.0"}},
+		{name: "NotInstalled", filterNotInstalled: true, want: []string{"1.5.0", "1.8.0"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var displayed []string
+			for _, version := range versions {
+				installed, hidden := installState(localSet, version, tt.filterInstalled, tt.filterNotInstalled)
+				if _, wantInstalled := localSet[version]; installed != wantInstalled {
+					t.Error("Unmatching installed state for", version, ", get :", installed)
+				}
+
+				if !hidden {
+					displayed = append(displayed, version)
+				}
+			}
+
+			if !slices.Equal(displayed, tt.want) {
+				t.Error("Unmatching results, get :", displayed)
+			}
+		})
+	}
+}
+
+func TestListRemoteExclusiveFilters(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.Config{Displayer: loghelper.InertDisplayer, RootPath: t.TempDir()}
+	manager := versionmanager.Make(conf, "", "tofu", "OpenTofu", nil, nil, nil, "", "", nil)
+	params := subCmdParams{remoteEnvName: config.TofuRemoteURLEnvName, pRemote: &conf.Tofu.RemoteURL, pPublicKeyPath: &conf.TofuKeyPath}
+
+	listRemoteCmd := newListRemoteCmd(conf, manager, params)
+	listRemoteCmd.SetArgs([]string{"--installed-only", "--not-installed"})
+	listRemoteCmd.SetOut(io.Discard)
+	listRemoteCmd.SetErr(io.Discard)
+
+	// rejected before run (a nil retriever would fail)
+	if err := listRemoteCmd.Execute(); err == nil || !strings.Contains(err.Error(), "installed-only") {
+		t.Error("Should fail on combined filters, get :", err)
+	}
+}
@@ -0,0 +1,150 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/exitcode"
+	"github.com/tofuutils/tenv/v2/pkg/fileperm"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/pkg/telemetry"
+)
+
+const (
+	telemetryFileName = "telemetry.json"
+	telemetryName     = "telemetry"
+	telemetryHelp     = "Manage opt-in anonymous usage statistics (enabled with TENV_TELEMETRY)."
+)
+
+var errNoTelemetryURL = errors.New("no destination configured, set TENV_TELEMETRY_URL")
+
+// set when usage statistics are enabled, allows exitOnError to count error categories.
+var telemetryPath string //nolint
+
+func newTelemetryCmd(conf *config.Config) *cobra.Command {
+	telemetryCmd := &cobra.Command{
+		Use:   telemetryName,
+		Short: telemetryHelp,
+		Long: telemetryHelp + `
+
+Only aggregated counters (commands, tool types, error categories, tenv version, os and arch) are stored in TENV_ROOT/telemetry.json,
+they are never sent automatically : the send subcommand post them to TENV_TELEMETRY_URL.`,
+	}
+
+	telemetryCmd.AddCommand(&cobra.Command{
+		Use:   "preview",
+		Short: "Display exactly what would be sent.",
+		Args:  cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			report, err := telemetry.Read(conf.UserStatePath(telemetryFileName), version)
+			if err != nil {
+				exitOnError(err)
+			}
+
+			data, err := report.Marshal()
+			if err != nil {
+				exitOnError(err)
+			}
+			loghelper.StdDisplay(string(data))
+		},
+	})
+
+	telemetryCmd.AddCommand(&cobra.Command{
+		Use:   "send",
+		Short: "Send aggregated statistics to TENV_TELEMETRY_URL and reset them.",
+		Args:  cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			if conf.TelemetryURL == "" {
+				exitOnError(errNoTelemetryURL)
+			}
+
+			filePath := conf.UserStatePath(telemetryFileName)
+			report, err := telemetry.Read(filePath, version)
+			if err != nil {
+				exitOnError(err)
+			}
+
+			if err = telemetry.Send(conf.TelemetryURL, report); err != nil {
+				exitOnError(err)
+			}
+
+			if err = removeTelemetry(filePath); err != nil {
+				exitOnError(err)
+			}
+			loghelper.StdDisplay(loghelper.Concat("Usage statistics sent to ", conf.TelemetryURL))
+		},
+	})
+
+	telemetryCmd.AddCommand(&cobra.Command{
+		Use:   "reset",
+		Short: "Remove locally aggregated statistics.",
+		Args:  cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			if err := removeTelemetry(conf.UserStatePath(telemetryFileName)); err != nil {
+				exitOnError(err)
+			}
+		},
+	})
+
+	return telemetryCmd
+}
+
+// best effort, usage statistics must never disturb a command.
+func recordCommand(conf *config.Config, cmd *cobra.Command) {
+	if !conf.Telemetry {
+		return
+	}
+
+	tool, parent := "", cmd.Parent()
+	if parent != nil && parent.HasParent() {
+		if parent.Name() == telemetryName {
+			return
+		}
+		tool = parent.Name()
+	}
+
+	filePath := conf.UserStatePath(telemetryFileName)
+	if cmd.Name() == telemetryName || os.MkdirAll(filepath.Dir(filePath), fileperm.DirMode()) != nil {
+		return
+	}
+
+	telemetryPath = filePath
+	_ = telemetry.Record(telemetryPath, version, cmd.Name(), tool, "")
+}
+
+func recordError(code int) {
+	if telemetryPath != "" {
+		_ = telemetry.Record(telemetryPath, version, "", "", exitcode.Name(code))
+	}
+}
+
+func removeTelemetry(filePath string) error {
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager/manifest"
+)
+
+// data available in list template.
+type localTemplateData struct {
+	Archived    bool // compressed, expanded on next proxy call
+	Manifest    manifest.Manifest
+	HasManifest bool
+	UseCount    int
+	UseDate     time.Time
+	Used        bool
+	UsedBy      string // file setting used version, empty when not used
+	Version     string
+}
+
+// data available in list-remote template.
+type remoteTemplateData struct {
+	Checksum    bool // only set with --verify-available
+	Installed   bool
+	PublishDate time.Time
+	Signature   bool // only set with --verify-available
+	Stable      bool
+	Version     string
+}
+
+var templateFuncs = template.FuncMap{ //nolint
+	"date": formatTemplateDate,
+}
+
+func addTemplateFlag(flags *pflag.FlagSet, pTemplate *string) {
+	flags.StringVar(pTemplate, "template", "", "format each displayed line with a Go template (like '{{.Version}} {{date .UseDate}}')")
+}
+
+// returns nil when templateStr is empty.
+func parseTemplate(templateStr string) (*template.Template, error) {
+	if templateStr == "" {
+		return nil, nil
+	}
+
+	return template.New("output").Funcs(templateFuncs).Parse(templateStr)
+}
+
+func displayTemplate(tmpl *template.Template, data any) {
+	line, err := renderTemplate(tmpl, data)
+	if err != nil {
+		exitOnError(err)
+	}
+	loghelper.StdDisplay(line)
+}
+
+func renderTemplate(tmpl *template.Template, data any) (string, error) {
+	var builder strings.Builder
+	err := tmpl.Execute(&builder, data)
+
+	return builder.String(), err
+}
+
+// zero time (unknown date or never used version) is displayed as an empty string.
+func formatTemplateDate(value time.Time) string {
+	if value.IsZero() {
+		return ""
+	}
+
+	return value.Format(time.DateOnly)
+}
@@ -0,0 +1,84 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tofuutils/tenv/v2/versionmanager/manifest"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	t.Parallel()
+
+	publishDate := time.Date(2024, time.March, 5, 10, 0, 0, 0, time.UTC)
+	localData := localTemplateData{HasManifest: true, Manifest: manifest.Manifest{Checksum: true, Signature: manifest.SignatureCosign}, UseCount: 3, Used: true, UsedBy: ".opentofu-version", Version: "1.6.2"}
+	remoteData := remoteTemplateData{Installed: true, PublishDate: publishDate, Stable: true, Version: "1.6.2"}
+
+	tests := []struct {
+		name          string
+		templateStr   string
+		data          any
+		want          string
+		wantParseErr  bool
+		wantRenderErr bool
+	}{
+		{name: "Local", templateStr: "{{.Version}} {{.UseCount}} {{.UsedBy}} {{.Manifest.Signature}}", data: localData, want: "1.6.2 3 .opentofu-version cosign"},
+		{name: "LocalNeverUsed", templateStr: "{{.Version}}:{{date .UseDate}}", data: localData, want: "1.6.2:"},
+		{name: "Remote", templateStr: "{{.Version}} {{date .PublishDate}}{{if .Installed}} *{{end}}", data: remoteData, want: "1.6.2 2024-03-05 *"},
+		{name: "RemoteUnknownDate", templateStr: "{{.Version}} [{{date .PublishDate}}]", data: remoteTemplateData{Version: "1.7.0"}, want: "1.7.0 []"},
+		{name: "InvalidSyntax", templateStr: "{{.Version", data: localData, wantParseErr: true},
+		{name: "UnknownFunction", templateStr: "{{upper .Version}}", data: localData, wantParseErr: true},
+		{name: "UnknownField", templateStr: "{{.PublishDate}}", data: localData, wantRenderErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tmpl, err := parseTemplate(tt.templateStr)
+			if (err != nil) != tt.wantParseErr {
+				t.Fatal("Unmatching parse error, get :", err)
+			}
+
+			if tt.wantParseErr {
+				return
+			}
+
+			res, err := renderTemplate(tmpl, tt.data)
+			if (err != nil) != tt.wantRenderErr {
+				t.Fatal("Unmatching render error, get :", err)
+			}
+
+			if !tt.wantRenderErr && res != tt.want {
+				t.Error("Unmatching results, get :", res)
+			}
+		})
+	}
+}
+
+func TestParseEmptyTemplate(t *testing.T) {
+	t.Parallel()
+
+	if tmpl, err := parseTemplate(""); tmpl != nil || err != nil {
+		t.Error("Unmatching results, get :", tmpl, err)
+	}
+}
@@ -21,6 +21,7 @@ package main
 import (
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/hashicorp/hcl/v2/hclparse"
@@ -28,6 +29,11 @@ import (
 
 	"github.com/tofuutils/tenv/v2/config"
 	"github.com/tofuutils/tenv/v2/config/cmdconst"
+	"github.com/tofuutils/tenv/v2/pkg/exitcode"
+	"github.com/tofuutils/tenv/v2/pkg/feature"
+	"github.com/tofuutils/tenv/v2/pkg/fileperm"
+	"github.com/tofuutils/tenv/v2/pkg/github"
+	"github.com/tofuutils/tenv/v2/pkg/httpclient"
 	"github.com/tofuutils/tenv/v2/pkg/loghelper"
 	"github.com/tofuutils/tenv/v2/versionmanager"
 	"github.com/tofuutils/tenv/v2/versionmanager/builder"
@@ -39,13 +45,18 @@ const (
 	rootVersionHelp = "Display tenv current version."
 	updatePathHelp  = "Display PATH updated with tenv directory location first."
 
-	helpPrefix = "Subcommand to manage several versions of "
-	atmosHelp  = helpPrefix + "Atmos (https://atmos.tools)."
-	tfHelp     = helpPrefix + "Terraform (https://www.terraform.io)."
-	tgHelp     = helpPrefix + "Terragrunt (https://terragrunt.gruntwork.io)."
-	tofuHelp   = helpPrefix + "OpenTofu (https://opentofu.org)."
+	helpPrefix   = "Subcommand to manage several versions of "
+	atmosHelp    = helpPrefix + "Atmos (https://atmos.tools)."
+	conftestHelp = helpPrefix + "Conftest (https://www.conftest.dev)."
+	opaHelp      = helpPrefix + "Open Policy Agent (https://www.openpolicyagent.org)."
+	tfHelp       = helpPrefix + "Terraform (https://www.terraform.io)."
+	tgHelp       = helpPrefix + "Terragrunt (https://terragrunt.gruntwork.io)."
+	tofuHelp     = helpPrefix + "OpenTofu (https://opentofu.org)."
 
-	pathEnvName = "PATH"
+	githubCacheDirName = ".github-cache"
+	pathEnvName        = "PATH"
+
+	profileFlagName = "profile"
 )
 
 // can be overridden with ldflags.
@@ -59,10 +70,30 @@ type subCmdParams struct {
 }
 
 func main() {
+	if err := config.ApplyProfile(profileFromArgs(os.Args[1:])); err != nil {
+		loghelper.StdDisplay(loghelper.Concat("Configuration error : ", err.Error()))
+		os.Exit(exitcode.Generic)
+	}
+
 	conf, err := config.InitConfigFromEnv()
 	if err != nil {
 		loghelper.StdDisplay(loghelper.Concat("Configuration error : ", err.Error()))
-		os.Exit(1)
+		os.Exit(exitcode.Generic)
+	}
+
+	if conf.Umask >= 0 {
+		fileperm.SetUmask(os.FileMode(conf.Umask))
+	}
+	github.SetAPIBudget(conf.GithubAPIBudget)
+	github.SetRateLimitRetries(conf.GithubRetry)
+	setGithubCacheDir(&conf)
+	httpOptions := httpclient.Options{
+		CABundlePath: conf.CABundle, DisableHTTP2: conf.NoHTTP2, InsecureSkipVerify: conf.Insecure,
+		MaxConnsPerHost: int(conf.HTTPConnLimit), UserAgent: loghelper.Concat(cmdconst.TenvName, "/", version),
+	}
+	if err = httpclient.Configure(httpOptions); err != nil {
+		loghelper.StdDisplay(loghelper.Concat("Configuration error : ", err.Error()))
+		os.Exit(exitcode.Generic)
 	}
 
 	builders := map[string]builder.BuilderFunc{
@@ -70,31 +101,62 @@ func main() {
 		cmdconst.TerraformName:  builder.BuildTfManager,
 		cmdconst.TerragruntName: builder.BuildTgManager,
 		cmdconst.AtmosName:      builder.BuildAtmosManager,
+		cmdconst.ConftestName:   builder.BuildConftestManager,
+		cmdconst.OpaName:        builder.BuildOpaManager,
 	}
 
 	hclParser := hclparse.NewParser()
 	manageHiddenCallCmd(&conf, builders, hclParser) // proxy call use os.Exit when called
 
+	// subcommands exit by themselves on failure, so remaining errors come from cobra (unknown command, invalid flag or arguments)
 	if err = initRootCmd(&conf, builders, hclParser).Execute(); err != nil {
 		loghelper.StdDisplay(err.Error())
-		os.Exit(1)
+		os.Exit(exitcode.Usage)
 	}
 }
 
 func initRootCmd(conf *config.Config, builders map[string]builder.BuilderFunc, hclParser *hclparse.Parser) *cobra.Command {
 	rootCmd := &cobra.Command{
 		Use:     cmdconst.TenvName,
-		Long:    "tenv help manage several versions of OpenTofu (https://opentofu.org), Terraform (https://www.terraform.io), Terragrunt (https://terragrunt.gruntwork.io), and Atmos (https://atmos.tools/).",
+		Long:    "tenv help manage several versions of OpenTofu (https://opentofu.org), Terraform (https://www.terraform.io), Terragrunt (https://terragrunt.gruntwork.io), Atmos (https://atmos.tools/), Conftest (https://www.conftest.dev), and Open Policy Agent (https://www.openpolicyagent.org).",
 		Version: version,
+		PersistentPreRun: func(cmd *cobra.Command, _ []string) {
+			setGithubCacheDir(conf) // root path and cache flags are parsed
+			recordCommand(conf, cmd)
+		},
+		PersistentPostRun: func(_ *cobra.Command, _ []string) {
+			if calls := github.APICalls(); conf.DisplayVerbose && calls != 0 {
+				summary := loghelper.Concat("GitHub API calls : ", strconv.FormatInt(calls, 10))
+				if conf.GithubAPIBudget > 0 {
+					summary = loghelper.Concat(summary, " (budget ", strconv.FormatInt(conf.GithubAPIBudget, 10), ")")
+				}
+				conf.Displayer.Display(summary)
+			}
+		},
 	}
 
 	flags := rootCmd.PersistentFlags()
 	flags.BoolVarP(&conf.ForceQuiet, "quiet", "q", conf.ForceQuiet, "no unnecessary output (and no log)")
-	flags.StringVarP(&conf.RootPath, "root-path", "r", conf.RootPath, "local path to install versions of OpenTofu, Terraform, Terragrunt, and Atmos")
+	flags.StringVarP(&conf.RootPath, "root-path", "r", conf.RootPath, "local path to install versions of OpenTofu, Terraform, Terragrunt, Atmos, Conftest, and OPA")
+	flags.StringVar(&conf.SearchBoundary, "boundary", conf.SearchBoundary, "comma separated marker names stopping version files search in parent directories (\"none\" to disable)")
 	flags.BoolVarP(&conf.DisplayVerbose, "verbose", "v", false, "verbose output (and set log level to Trace)")
+	flags.BoolVar(&conf.JSONOutput, "json", false, "display list, list-remote and detect results as JSON")
+	flags.String(profileFlagName, "", "configuration profile to apply (override TENV_PROFILE)")
 
 	rootCmd.AddCommand(newVersionCmd())
 	rootCmd.AddCommand(newUpdatePathCmd(conf.GithubActions))
+	rootCmd.AddCommand(newWatchCmd(conf, builders, hclParser))
+	rootCmd.AddCommand(newJobsCmd(conf))
+	rootCmd.AddCommand(newAuditCmd(conf, builders, hclParser))
+	rootCmd.AddCommand(newLinkAllCmd(conf, builders, hclParser))
+	rootCmd.AddCommand(newTelemetryCmd(conf))
+	rootCmd.AddCommand(newConfigCmd(conf))
+	rootCmd.AddCommand(newPromptCmd(conf, builders, hclParser))
+	rootCmd.AddCommand(newHookEnvCmd(conf, builders, hclParser))
+	rootCmd.AddCommand(newPrecedenceCmd(conf, builders, hclParser))
+	rootCmd.AddCommand(newReconcileCmd(conf, builders, hclParser))
+	rootCmd.AddCommand(newMigrateRootCmd(conf, builders, hclParser))
+	rootCmd.AddCommand(newNotifyCmd(conf, builders, hclParser))
 
 	tofuCmd := &cobra.Command{
 		Use:     cmdconst.TofuName,
@@ -154,9 +216,66 @@ func initRootCmd(conf *config.Config, builders map[string]builder.BuilderFunc, h
 
 	rootCmd.AddCommand(atmosCmd)
 
+	conftestCmd := &cobra.Command{
+		Use:   cmdconst.ConftestName,
+		Short: conftestHelp,
+		Long:  conftestHelp,
+	}
+
+	conftestParams := subCmdParams{
+		needToken: true, remoteEnvName: config.ConftestRemoteURLEnvName, pRemote: &conf.Conftest.RemoteURL,
+	}
+	initSubCmds(conftestCmd, conf, builders[cmdconst.ConftestName](conf, hclParser), conftestParams)
+
+	rootCmd.AddCommand(conftestCmd)
+
+	opaCmd := &cobra.Command{
+		Use:   cmdconst.OpaName,
+		Short: opaHelp,
+		Long:  opaHelp,
+	}
+
+	opaParams := subCmdParams{
+		needToken: true, remoteEnvName: config.OpaRemoteURLEnvName, pRemote: &conf.Opa.RemoteURL,
+	}
+	initSubCmds(opaCmd, conf, builders[cmdconst.OpaName](conf, hclParser), opaParams)
+
+	rootCmd.AddCommand(opaCmd)
+
 	return rootCmd
 }
 
+// profile is applied before cobra parsing, because it changes environment used to initialize configuration.
+// GitHub API responses are stored by user (they depend on token).
+func setGithubCacheDir(conf *config.Config) {
+	if conf.NoCache {
+		github.SetCacheDir("")
+
+		return
+	}
+
+	github.SetCacheDir(conf.UserStatePath(githubCacheDirName))
+}
+
+func profileFromArgs(args []string) string {
+	if len(args) != 0 && args[0] == cmdconst.CallSubCmd {
+		return "" // proxied arguments belong to called tool
+	}
+
+	for index, arg := range args {
+		switch {
+		case arg == "--":
+			return ""
+		case arg == "--"+profileFlagName && index+1 < len(args):
+			return args[index+1]
+		case strings.HasPrefix(arg, "--"+profileFlagName+"="):
+			return arg[len(profileFlagName)+3:]
+		}
+	}
+
+	return ""
+}
+
 func manageHiddenCallCmd(conf *config.Config, builders map[string]builder.BuilderFunc, hclParser *hclparse.Parser) {
 	if len(os.Args) < 3 || os.Args[1] != cmdconst.CallSubCmd {
 		return
@@ -171,15 +290,24 @@ func manageHiddenCallCmd(conf *config.Config, builders map[string]builder.Builde
 }
 
 func newVersionCmd() *cobra.Command {
-	return &cobra.Command{
+	var displayFeatures bool
+
+	versionCmd := &cobra.Command{
 		Use:   versionName,
 		Short: rootVersionHelp,
 		Long:  rootVersionHelp,
 		Args:  cobra.NoArgs,
 		Run: func(_ *cobra.Command, _ []string) {
 			loghelper.StdDisplay(loghelper.Concat(cmdconst.TenvName, " ", versionName, " ", version))
+			if displayFeatures {
+				loghelper.StdDisplay(loghelper.Concat("features : ", strings.Join(feature.List(), ", ")))
+			}
 		},
 	}
+
+	versionCmd.Flags().BoolVarP(&displayFeatures, "features", "f", false, "display compiled-in backends")
+
+	return versionCmd
 }
 
 func newUpdatePathCmd(gha bool) *cobra.Command {
@@ -191,16 +319,14 @@ func newUpdatePathCmd(gha bool) *cobra.Command {
 		Run: func(_ *cobra.Command, _ []string) {
 			execPath, err := os.Executable()
 			if err != nil {
-				loghelper.StdDisplay(err.Error())
-
-				return
+				exitOnError(err)
 			}
 
 			execDirPath := filepath.Dir(execPath)
 			if gha {
 				pathfilePath := os.Getenv("GITHUB_PATH")
 				if pathfilePath != "" {
-					pathfile, err := os.OpenFile(pathfilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+					pathfile, err := os.OpenFile(pathfilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, fileperm.FileMode())
 					if err != nil {
 						return
 					}
@@ -223,12 +349,18 @@ func newUpdatePathCmd(gha bool) *cobra.Command {
 }
 
 func initSubCmds(cmd *cobra.Command, conf *config.Config, versionManager versionmanager.VersionManager, params subCmdParams) {
-	cmd.AddCommand(newConstraintCmd(conf, versionManager))
+	cmd.AddCommand(newArchiveCmd(conf, versionManager))
+	cmd.AddCommand(newConstraintCmd(conf, versionManager, params))
 	cmd.AddCommand(newDetectCmd(conf, versionManager, params))
+	cmd.AddCommand(newExecCmd(conf, versionManager, params))
 	cmd.AddCommand(newInstallCmd(conf, versionManager, params))
 	cmd.AddCommand(newListCmd(conf, versionManager))
 	cmd.AddCommand(newListRemoteCmd(conf, versionManager, params))
+	cmd.AddCommand(newPruneCmd(conf, versionManager))
 	cmd.AddCommand(newResetCmd(conf, versionManager))
+	cmd.AddCommand(newRestoreCmd(conf, versionManager))
+	cmd.AddCommand(newTouchCmd(conf, versionManager))
 	cmd.AddCommand(newUninstallCmd(conf, versionManager))
+	cmd.AddCommand(newUsageCmd(conf, versionManager))
 	cmd.AddCommand(newUseCmd(conf, versionManager, params))
 }
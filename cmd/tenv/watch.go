@@ -0,0 +1,311 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"io/fs"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/spf13/cobra"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/config/cmdconst"
+	"github.com/tofuutils/tenv/v2/pkg/fileperm"
+	"github.com/tofuutils/tenv/v2/pkg/jobqueue"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/pkg/metrics"
+	"github.com/tofuutils/tenv/v2/versionmanager"
+	"github.com/tofuutils/tenv/v2/versionmanager/builder"
+)
+
+const watchHelp = "Watch version files and IAC files in working directory and pre-install newly required versions."
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+type watchMetrics struct {
+	cacheHits       *metrics.Counter
+	installFailures *metrics.Counter
+	installs        *metrics.Counter
+	registry        *metrics.Registry
+	resolution      *metrics.Histogram
+}
+
+func newWatchCmd(conf *config.Config, builders map[string]builder.BuilderFunc, hclParser *hclparse.Parser) *cobra.Command {
+	interval, jobsAddress, metricsAddress, workers := 2*time.Second, "", "", 2
+
+	watchCmd := &cobra.Command{
+		Use:   "watch",
+		Short: watchHelp,
+		Long: watchHelp + `
+
+Files of working directory and version files read from its parents and user home are polled, after each change the version required by each tool is resolved
+(only when found in version files) and installed when missing, so the next call does not pay the install latency.
+
+With --metrics-address, Prometheus metrics are exposed on /metrics (for agents running watch as a daemon).
+
+With --jobs-address, installations go through a queue exposed on /jobs : clients (see jobs command) can enqueue
+install requests, which are deduplicated, executed by priority (interactive before prefetch of watched files)
+with bounded concurrency (--workers), inspected and canceled while pending.`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			conf.InitDisplayer(false)
+			conf.NoInstall = false
+
+			names := []string{cmdconst.TofuName, cmdconst.TerraformName, cmdconst.TerragruntName, cmdconst.AtmosName, cmdconst.ConftestName, cmdconst.OpaName}
+			managers := make([]versionmanager.VersionManager, 0, len(builders))
+			for _, name := range names {
+				managers = append(managers, builders[name](conf, hclParser))
+			}
+
+			var collector *watchMetrics
+			if metricsAddress != "" {
+				collector = serveMetrics(conf, metricsAddress, managers)
+			}
+
+			var queue *jobqueue.Queue
+			if jobsAddress != "" {
+				// loaded before concurrent installations
+				if err := conf.InitRemoteConf(); err != nil {
+					exitOnError(err)
+				}
+
+				queue = serveJobs(conf, jobsAddress, workers, names, builders, collector)
+			}
+
+			var previous map[string]fileState
+			for {
+				current, err := readWatchState(managers)
+				if err != nil {
+					exitOnError(err)
+				}
+
+				if !maps.Equal(previous, current) {
+					previous = current
+					preInstall(conf, names, managers, collector, queue)
+				}
+
+				time.Sleep(interval)
+			}
+		},
+	}
+
+	flags := watchCmd.Flags()
+	flags.DurationVarP(&interval, "interval", "p", interval, "polling interval")
+	flags.StringVarP(&jobsAddress, "jobs-address", "j", "", "listen address to expose install queue on /jobs (like 127.0.0.1:9101)")
+	flags.StringVarP(&metricsAddress, "metrics-address", "m", "", "listen address to expose Prometheus metrics on /metrics (like :9100)")
+	flags.IntVarP(&workers, "workers", "w", workers, "maximum number of concurrent installations of install queue")
+
+	return watchCmd
+}
+
+func preInstall(conf *config.Config, names []string, managers []versionmanager.VersionManager, collector *watchMetrics, queue *jobqueue.Queue) {
+	for index, manager := range managers {
+		requestedVersion, err := manager.ResolveWithVersionFiles()
+		if err != nil {
+			loghelper.StdDisplay(err.Error())
+
+			continue
+		}
+
+		if requestedVersion == "" {
+			continue
+		}
+
+		if queue == nil {
+			_ = evaluateAndRecord(manager, requestedVersion, collector)
+
+			continue
+		}
+
+		if _, err = queue.Enqueue(names[index], requestedVersion, jobqueue.PriorityPrefetch); err != nil {
+			loghelper.StdDisplay(err.Error())
+		}
+	}
+	conf.Displayer.Display("Waiting for changes...")
+}
+
+func evaluateAndRecord(manager versionmanager.VersionManager, requestedVersion string, collector *watchMetrics) error {
+	start := time.Now()
+	localSet := manager.LocalSet()
+	detectedVersion, err := manager.Evaluate(requestedVersion, false)
+	if err != nil {
+		loghelper.StdDisplay(loghelper.Concat("Failed to pre-install ", manager.FolderName, " : ", err.Error()))
+	}
+
+	if collector != nil {
+		collector.record(manager.FolderName, localSet, detectedVersion, err, time.Since(start))
+	}
+
+	return err
+}
+
+func serveJobs(conf *config.Config, address string, workers int, names []string, builders map[string]builder.BuilderFunc, collector *watchMetrics) *jobqueue.Queue {
+	queue := jobqueue.Make(workers, func(tool string, version string) error {
+		if !slices.Contains(names, tool) {
+			return errJobTool
+		}
+
+		// concurrent jobs do not share config or parser
+		jobConf := *conf
+
+		return evaluateAndRecord(builders[tool](&jobConf, hclparse.NewParser()), version, collector)
+	})
+
+	tokenPath := filepath.Join(conf.RootPath, jobqueue.TokenFileName)
+	if err := os.MkdirAll(conf.RootPath, fileperm.DirMode()); err != nil {
+		exitOnError(err)
+	}
+
+	token, err := jobqueue.WriteToken(tokenPath)
+	if err != nil {
+		exitOnError(err)
+	}
+
+	handler := jobqueue.Authorize(queue, token)
+	mux := http.NewServeMux()
+	mux.Handle(jobqueue.JobsPath, handler)
+	mux.Handle(jobqueue.JobsPath+"/", handler)
+	go func() {
+		server := &http.Server{Addr: address, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		if err := server.ListenAndServe(); err != nil {
+			exitOnError(err)
+		}
+	}()
+	conf.Displayer.Display(loghelper.Concat("Install queue exposed on ", address, jobqueue.JobsPath, " (token in ", tokenPath, ")"))
+
+	return queue
+}
+
+func serveMetrics(conf *config.Config, address string, managers []versionmanager.VersionManager) *watchMetrics {
+	registry := &metrics.Registry{}
+	collector := &watchMetrics{
+		cacheHits:       registry.NewCounter("tenv_cache_hits_total", "Number of required versions already installed.", "tool"),
+		installFailures: registry.NewCounter("tenv_install_failures_total", "Number of failed resolutions or installations.", "tool"),
+		installs:        registry.NewCounter("tenv_installs_total", "Number of installed versions.", "tool"),
+		registry:        registry,
+		resolution:      registry.NewHistogram("tenv_resolution_duration_seconds", "Duration of version resolution (including installation).", "tool", []float64{0.01, 0.1, 0.5, 1, 5, 10, 30, 60, 120}),
+	}
+
+	registry.NewGaugeFunc("tenv_disk_usage_bytes", "Disk usage of installed versions.", "tool", func() map[string]float64 {
+		usages := make(map[string]float64, len(managers))
+		for _, manager := range managers {
+			usages[manager.FolderName] = float64(dirSize(filepath.Join(conf.RootPath, manager.FolderName)))
+		}
+
+		return usages
+	})
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", registry)
+	go func() {
+		server := &http.Server{Addr: address, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		if err := server.ListenAndServe(); err != nil {
+			exitOnError(err)
+		}
+	}()
+	conf.Displayer.Display(loghelper.Concat("Metrics exposed on ", address, "/metrics"))
+
+	return collector
+}
+
+func (m *watchMetrics) record(folderName string, localSet map[string]struct{}, detectedVersion string, err error, duration time.Duration) {
+	m.registry.Observe(m.resolution, folderName, duration.Seconds())
+	switch _, installed := localSet[detectedVersion]; {
+	case err != nil:
+		m.registry.Inc(m.installFailures, folderName)
+	case installed:
+		m.registry.Inc(m.cacheHits, folderName)
+	default:
+		m.registry.Inc(m.installs, folderName)
+	}
+}
+
+func dirSize(dirPath string) int64 {
+	var size int64
+	_ = filepath.WalkDir(dirPath, func(_ string, entry fs.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() {
+			if info, err := entry.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+
+		return nil // best effort
+	})
+
+	return size
+}
+
+// readWatchState returns the state of working directory files and of version files searched elsewhere
+// (a parent or user home version file can change the resolved versions).
+func readWatchState(managers []versionmanager.VersionManager) (map[string]fileState, error) {
+	state, err := readDirState()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, manager := range managers {
+		filePaths, err := manager.VersionFilePaths()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, filePath := range filePaths {
+			if _, ok := state[filePath]; ok {
+				continue
+			}
+
+			if info, err := os.Stat(filePath); err == nil {
+				state[filePath] = fileState{modTime: info.ModTime(), size: info.Size()}
+			}
+		}
+	}
+
+	return state, nil
+}
+
+func readDirState() (map[string]fileState, error) {
+	entries, err := os.ReadDir(".")
+	if err != nil {
+		return nil, err
+	}
+
+	state := make(map[string]fileState, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue // removed since ReadDir call
+		}
+		state[entry.Name()] = fileState{modTime: info.ModTime(), size: info.Size()}
+	}
+
+	return state, nil
+}
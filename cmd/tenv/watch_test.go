@@ -0,0 +1,95 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager"
+	flatparser "github.com/tofuutils/tenv/v2/versionmanager/semantic/parser/flat"
+	"github.com/tofuutils/tenv/v2/versionmanager/semantic/types"
+)
+
+// change working directory, so not parallel.
+func TestReadWatchState(t *testing.T) { //nolint
+	basePath := t.TempDir()
+	for _, relPath := range []string{"home/.keep", "repo/.git/HEAD", "repo/sub/.keep"} {
+		filePath := filepath.Join(basePath, relPath)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+
+		if err := os.WriteFile(filePath, nil, 0o600); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+	}
+
+	previousPath, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+	defer os.Chdir(previousPath) //nolint
+
+	if err = os.Chdir(filepath.Join(basePath, "repo", "sub")); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	conf := &config.Config{Displayer: loghelper.InertDisplayer, RootPath: t.TempDir(), SearchBoundary: ".git", UserPath: filepath.Join(basePath, "home")}
+	versionFiles := []types.VersionFile{{Name: ".opentofu-version", Parser: flatparser.RetrieveVersion}}
+	managers := []versionmanager.VersionManager{versionmanager.Make(conf, "", "tofu", "OpenTofu", nil, nil, nil, "", "", versionFiles)}
+
+	previous, err := readWatchState(managers)
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	// sequential, each step compares with the state of the previous one
+	tests := []struct {
+		name       string
+		relPath    string
+		wantChange bool
+	}{
+		{name: "WorkingDir", relPath: "repo/sub/.opentofu-version", wantChange: true},
+		{name: "Parent", relPath: "repo/.opentofu-version", wantChange: true},
+		{name: "UserHome", relPath: "home/.opentofu-version", wantChange: true},
+		{name: "BeyondBoundary", relPath: ".opentofu-version"},
+		{name: "OtherFileInParent", relPath: "repo/README.md"},
+	}
+
+	for _, tt := range tests {
+		if err = os.WriteFile(filepath.Join(basePath, tt.relPath), []byte("1.6.2"), 0o600); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+
+		current, err := readWatchState(managers)
+		if err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+
+		if changed := !maps.Equal(previous, current); changed != tt.wantChange {
+			t.Error(tt.name, "unmatching results, get :", changed)
+		}
+		previous = current
+	}
+}
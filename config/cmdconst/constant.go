@@ -21,10 +21,14 @@ package cmdconst
 const (
 	AgnosticName   = "tf"
 	AtmosName      = "atmos"
+	ConftestName   = "conftest"
+	OpaName        = "opa"
 	TenvName       = "tenv"
 	TerraformName  = "terraform"
 	TerragruntName = "terragrunt"
 	TofuName       = "tofu"
 
 	CallSubCmd = "call"
+
+	ResolvedEnvPrefix = "TENV_RESOLVED_" // followed by upper case exec name
 )
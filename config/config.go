@@ -19,36 +19,54 @@
 package config
 
 import (
+	"crypto/tls"
 	"errors"
 	"io/fs"
+	"net/url"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/hashicorp/go-hclog"
+	"github.com/mattn/go-isatty"
 	"gopkg.in/yaml.v3"
 
 	"github.com/tofuutils/tenv/v2/config/cmdconst"
 	configutils "github.com/tofuutils/tenv/v2/config/utils"
+	"github.com/tofuutils/tenv/v2/pkg/download"
+	"github.com/tofuutils/tenv/v2/pkg/httpclient"
 	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/pkg/secret"
+	"github.com/tofuutils/tenv/v2/pkg/tlspin"
 )
 
 const (
 	githubActionsEnvName = "GITHUB_ACTIONS"
 
+	defaultSearchBoundary = ".git"
+	noSearchBoundary      = "none"
+
+	DefaultPluginTimeout = 10 * time.Second
+
 	archEnvName        = "ARCH"
 	autoInstallEnvName = "AUTO_INSTALL"
 	defaultConstraint  = "DEFAULT_CONSTRAINT"
 	defaultVersion     = "DEFAULT_" + version
+	detectIaCEnvName   = "DETECT_IAC"
 	forceRemoteEnvName = "FORCE_REMOTE"
 	installModeEnvName = "INSTALL_MODE"
 	listModeEnvName    = "LIST_MODE"
 	listURLEnvName     = "LIST_URL"
 	logEnvName         = "LOG"
+	bucketURLEnvName   = "_S3_URL"
+	mirrorURLEnvName   = "_MIRROR_URL"
 	quietEnvName       = "QUIET"
 	remoteURLEnvName   = "REMOTE"
 	rootPathEnvName    = "ROOT"
+	ghTokenEnvName     = "GH_TOKEN"     //nolint
 	tokenEnvName       = "GITHUB_TOKEN" //nolint
 	version            = "VERSION"
 
@@ -58,30 +76,110 @@ const (
 	atmosInstallModeEnvName       = atmosPrefix + installModeEnvName
 	atmosListModeEnvName          = atmosPrefix + listModeEnvName
 	atmosListURLEnvName           = atmosPrefix + listURLEnvName
+	atmosBucketURLEnvName         = tenvPrefix + "ATMOS" + bucketURLEnvName
+	atmosMirrorURLEnvName         = tenvPrefix + "ATMOS" + mirrorURLEnvName
 	AtmosRemoteURLEnvName         = atmosPrefix + remoteURLEnvName
 	AtmosVersionEnvName           = atmosPrefix + version
 
-	tenvPrefix             = "TENV_"
-	tenvArchEnvName        = tenvPrefix + archEnvName
-	tenvAutoInstallEnvName = tenvPrefix + autoInstallEnvName
-	tenvForceRemoteEnvName = tenvPrefix + forceRemoteEnvName
-	tenvLogEnvName         = tenvPrefix + logEnvName
-	tenvQuietEnvName       = tenvPrefix + quietEnvName
-	tenvRemoteConfEnvName  = tenvPrefix + "REMOTE_CONF"
-	tenvRootPathEnvName    = tenvPrefix + rootPathEnvName
-	tenvTokenEnvName       = tenvPrefix + tokenEnvName
+	conftestPrefix                   = "CONFTEST_"
+	ConftestDefaultConstraintEnvName = conftestPrefix + defaultConstraint
+	ConftestDefaultVersionEnvName    = conftestPrefix + defaultVersion
+	conftestInstallModeEnvName       = conftestPrefix + installModeEnvName
+	conftestListModeEnvName          = conftestPrefix + listModeEnvName
+	conftestListURLEnvName           = conftestPrefix + listURLEnvName
+	conftestBucketURLEnvName         = tenvPrefix + "CONFTEST" + bucketURLEnvName
+	conftestMirrorURLEnvName         = tenvPrefix + "CONFTEST" + mirrorURLEnvName
+	ConftestRemoteURLEnvName         = conftestPrefix + remoteURLEnvName
+	ConftestVersionEnvName           = conftestPrefix + version
+
+	opaPrefix                   = "OPA_"
+	OpaDefaultConstraintEnvName = opaPrefix + defaultConstraint
+	OpaDefaultVersionEnvName    = opaPrefix + defaultVersion
+	opaInstallModeEnvName       = opaPrefix + installModeEnvName
+	opaListModeEnvName          = opaPrefix + listModeEnvName
+	opaListURLEnvName           = opaPrefix + listURLEnvName
+	opaBucketURLEnvName         = tenvPrefix + "OPA" + bucketURLEnvName
+	opaMirrorURLEnvName         = tenvPrefix + "OPA" + mirrorURLEnvName
+	OpaRemoteURLEnvName         = opaPrefix + remoteURLEnvName
+	OpaVersionEnvName           = opaPrefix + version
+
+	tenvPrefix                 = "TENV_"
+	tenvAdvisoryURLEnvName     = tenvPrefix + "ADVISORY_URL"
+	tenvAgnosticPolicyEnvName  = tenvPrefix + "AGNOSTIC_POLICY"
+	tenvArchEnvName            = tenvPrefix + archEnvName
+	tenvArchiveAfterEnvName    = tenvPrefix + "ARCHIVE_AFTER"
+	tenvAutoApproveEnvName     = tenvPrefix + "AUTO_APPROVE"
+	tenvAutoInstallEnvName     = tenvPrefix + autoInstallEnvName
+	tenvCABundleEnvName        = tenvPrefix + "CA_BUNDLE"
+	tenvCheckModulesEnvName    = tenvPrefix + "CHECK_MODULES"
+	tenvConstraintSetsEnvName  = tenvPrefix + "CONSTRAINT_SETS"
+	tenvConstraintKeyEnvName   = tenvConstraintSetsEnvName + "_KEY"
+	tenvDeltaURLEnvName        = tenvPrefix + "DELTA_URL"
+	tenvDetectIaCEnvName       = tenvPrefix + detectIaCEnvName
+	tenvDryRunEnvName          = tenvPrefix + "DRY_RUN"
+	tenvForceRemoteEnvName     = tenvPrefix + forceRemoteEnvName
+	tenvGithubAPIBudgetEnvName = tenvPrefix + "GITHUB_API_BUDGET"
+	tenvGithubAssetAPIEnvName  = tenvPrefix + "GITHUB_ASSET_API"
+	tenvGithubRetryEnvName     = tenvPrefix + "GITHUB_RETRY"
+	tenvHTTP2EnvName           = tenvPrefix + "HTTP2"
+	tenvHTTPConnLimitEnvName   = tenvPrefix + "HTTP_MAX_CONNS_PER_HOST"
+	tenvIaCScanDepthEnvName    = tenvPrefix + "IAC_SCAN_DEPTH"
+	tenvInsecureEnvName        = tenvPrefix + "INSECURE_SKIP_VERIFY"
+	tenvInstallHelperEnvName   = tenvPrefix + "INSTALL_HELPER"
+	tenvKeepVersionsEnvName    = tenvPrefix + "KEEP_VERSIONS"
+	tenvLockTimeoutEnvName     = tenvPrefix + "LOCK_TIMEOUT"
+	tenvLogEnvName             = tenvPrefix + logEnvName
+	tenvMirrorPrefix           = tenvPrefix + "MIRROR_"
+	tenvMirrorHeaderEnvName    = tenvMirrorPrefix + "AUTH_HEADER"
+	tenvMirrorPasswordEnvName  = tenvMirrorPrefix + "PASSWORD" //nolint
+	tenvMirrorTokenEnvName     = tenvMirrorPrefix + "TOKEN"    //nolint
+	tenvMirrorUsernameEnvName  = tenvMirrorPrefix + "USERNAME"
+	tenvOfflineSourceEnvName   = tenvPrefix + "OFFLINE_SOURCE"
+	tenvPinRemoteEnvName       = tenvPrefix + "PIN_REMOTE"
+	tenvPluginTimeoutEnvName   = tenvPrefix + "PLUGIN_TIMEOUT"
+	tenvPromptDefaultEnvName   = tenvPrefix + "PROMPT_DEFAULT"
+	tenvPromptTimeoutEnvName   = tenvPrefix + "PROMPT_TIMEOUT"
+	tenvPruneAfterEnvName      = tenvPrefix + "PRUNE_AFTER"
+	tenvQuietEnvName           = tenvPrefix + quietEnvName
+	tenvRemoteCacheTTLEnvName  = tenvPrefix + "REMOTE_CACHE_TTL"
+	tenvRemoteConfEnvName      = tenvPrefix + "REMOTE_CONF"
+	tenvRegistryEnvName        = tenvPrefix + "REGISTRY_FILE"
+	tenvRetractedPrefix        = tenvPrefix + "RETRACTED_"
+	tenvRetractedPolicyEnvName = tenvRetractedPrefix + "POLICY"
+	tenvRetractedURLEnvName    = tenvRetractedPrefix + "URL"
+	tenvRootPathEnvName        = tenvPrefix + rootPathEnvName
+	tenvSearchBoundaryEnvName  = tenvPrefix + "SEARCH_BOUNDARY"
+	tenvSharedRootEnvName      = tenvPrefix + "SHARED_ROOT"
+	tenvShellCompletionEnvName = tenvPrefix + "SHELL_COMPLETION"
+	tenvStreamEnvName          = tenvPrefix + "STREAM_EXTRACT"
+	tenvStrictVerifyEnvName    = tenvPrefix + "STRICT_VERIFY"
+	tenvStrictFilesEnvName     = tenvPrefix + "STRICT_VERSION_FILES"
+	tenvTelemetryEnvName       = tenvPrefix + "TELEMETRY"
+	tenvTelemetryURLEnvName    = tenvTelemetryEnvName + "_URL"
+	tenvTokenEnvName           = tenvPrefix + tokenEnvName
+	tenvTrashTTLEnvName        = tenvPrefix + "TRASH_TTL"
+	tenvUmaskEnvName           = tenvPrefix + "UMASK"
+	tenvUseFileEnvName         = tenvPrefix + "USE_FILE"
+	tenvProvenanceEnvName      = tenvPrefix + "VERIFY_PROVENANCE"
+	tenvTokenAutoEnvName       = tenvTokenEnvName + "_AUTO"
+	tenvTokenSourceEnvName     = tenvTokenEnvName + "_SOURCE"
+	tenvWarnUnverifiedEnvName  = tenvPrefix + "WARN_UNVERIFIED"
 
 	tfenvPrefix                = "TFENV_"
 	tfenvTerraformPrefix       = tfenvPrefix + "TERRAFORM_"
+	tfAmd64FallbackEnvName     = tfenvPrefix + "AMD64_FALLBACK"
 	tfArchEnvName              = tfenvPrefix + archEnvName
 	tfAutoInstallEnvName       = tfenvPrefix + autoInstallEnvName
 	TfDefaultConstraintEnvName = tfenvTerraformPrefix + defaultConstraint
 	TfDefaultVersionEnvName    = tfenvTerraformPrefix + defaultVersion
+	tfDetectIaCEnvName         = tfenvPrefix + detectIaCEnvName
 	tfForceRemoteEnvName       = tfenvPrefix + forceRemoteEnvName
 	tfHashicorpPGPKeyEnvName   = tfenvPrefix + "HASHICORP_PGP_KEY"
 	tfInstallModeEnvName       = tfenvPrefix + installModeEnvName
 	tfListModeEnvName          = tfenvPrefix + listModeEnvName
 	tfListURLEnvName           = tfenvPrefix + listURLEnvName
+	tfBucketURLEnvName         = tenvPrefix + "TERRAFORM" + bucketURLEnvName
+	tfMirrorURLEnvName         = tenvPrefix + "TERRAFORM" + mirrorURLEnvName
 	TfRemoteURLEnvName         = tfenvPrefix + remoteURLEnvName
 	tfRootPathEnvName          = tfenvPrefix + rootPathEnvName
 	TfVersionEnvName           = tfenvTerraformPrefix + version
@@ -92,6 +190,9 @@ const (
 	tgInstallModeEnvName       = tgPrefix + installModeEnvName
 	tgListModeEnvName          = tgPrefix + listModeEnvName
 	tgListURLEnvName           = tgPrefix + listURLEnvName
+	tgBucketURLEnvName         = tenvPrefix + "TERRAGRUNT" + bucketURLEnvName
+	tgMirrorURLEnvName         = tenvPrefix + "TERRAGRUNT" + mirrorURLEnvName
+	tgProxyTfBinaryEnvName     = tgPrefix + "PROXY_TF_BINARY"
 	TgRemoteURLEnvName         = tgPrefix + remoteURLEnvName
 	TgVersionEnvName           = tgPrefix + version
 
@@ -101,37 +202,99 @@ const (
 	tofuAutoInstallEnvName       = tofuenvPrefix + autoInstallEnvName
 	TofuDefaultConstraintEnvName = tofuenvTofuPrefix + defaultConstraint
 	TofuDefaultVersionEnvName    = tofuenvTofuPrefix + defaultVersion
+	tofuDetectIaCEnvName         = tofuenvPrefix + detectIaCEnvName
 	tofuForceRemoteEnvName       = tofuenvPrefix + forceRemoteEnvName
 	tofuInstallModeEnvName       = tofuenvPrefix + installModeEnvName
 	tofuListModeEnvName          = tofuenvPrefix + listModeEnvName
 	tofuListURLEnvName           = tofuenvPrefix + listURLEnvName
+	tofuBucketURLEnvName         = tenvPrefix + "TOFU" + bucketURLEnvName
+	tofuMirrorURLEnvName         = tenvPrefix + "TOFU" + mirrorURLEnvName
 	tofuOpenTofuPGPKeyEnvName    = tofuenvPrefix + "OPENTOFU_PGP_KEY"
+	tofuReleaseManifestEnvName   = tofuenvPrefix + "RELEASE_MANIFEST"
 	TofuRemoteURLEnvName         = tofuenvPrefix + remoteURLEnvName
 	tofuRootPathEnvName          = tofuenvPrefix + rootPathEnvName
+	tofuStrictSignatureEnvName   = tofuenvPrefix + "STRICT_SIGNATURE"
 	tofuTokenEnvName             = tofuenvPrefix + tokenEnvName
 	TofuVersionEnvName           = tofuenvTofuPrefix + version
 )
 
 type Config struct {
+	AdvisoryURL      string // security advisories feed used by audit (GitHub API when empty)
+	AgnosticPolicy   string // choice between OpenTofu and Terraform in tf proxy (prefer-tofu when empty)
+	ambientToken     string // value of GITHUB_TOKEN or GH_TOKEN, only sent to GitHub hosts
 	Arch             string
+	ArchiveAfter     time.Duration // versions unused during this duration are compressed (disabled when 0)
 	Atmos            RemoteConfig
+	AutoApprove      bool   // skip confirmation prompts (like uninstall with a constraint)
+	CABundle         string // PEM file of additional trusted certificates
+	CheckModules     bool
+	CheckProvenance  bool   // check SLSA provenance attestation when published (OpenTofu and Terragrunt)
+	ConstraintSets   string // base location (URL or directory) of shared constraint sets referenced with @<name>
+	ConstraintKey    string // PGP public key file checking shared constraint sets signature (.sig file)
+	Conftest         RemoteConfig
+	DeltaURL         string
 	Displayer        loghelper.Displayer
 	DisplayVerbose   bool
+	DryRun           bool // proxies display what they would call instead of running it
 	ForceQuiet       bool
 	ForceRemote      bool
 	GithubActions    bool
+	GithubAPIBudget  int64
+	GithubAssetAPI   bool
+	GithubRetry      int64 // retries when GitHub API rate limit is reached
 	GithubToken      string
+	HTTPConnLimit    int64 // maximum concurrent connections toward a host (unlimited when 0)
+	IaCScanDepth     int64 // subdirectories levels scanned to find required_version (only current directory when 0)
+	Insecure         bool  // skip TLS certificate verification
+	InstallHelper    string
+	JSONOutput       bool  // list, list-remote and detect commands display JSON
+	KeepVersions     int64 // installed versions kept after each installation, least recently used are uninstalled (unlimited when 0)
+	LockTimeout      time.Duration
+	MirrorAuth       download.Credential // sent to mirror hosts without credential in remote configuration file
+	NoCache          bool                // neither read nor write remote releases cache
+	NoHTTP2          bool
 	NoInstall        bool
+	Opa              RemoteConfig
+	PinRemote        bool
+	PluginTimeout    time.Duration // maximum duration of version resolution plugin commands
+	PromptDefault    bool          // decision of confirmation prompts without answer
+	PromptTimeout    time.Duration // delay before confirmation prompts use their default decision (disabled when 0)
+	PruneAfter       time.Duration
+	RefreshCache     bool   // ignore remote releases cache content (still updated)
+	RegistryPath     string // file listing installed versions for configuration management tools (disabled when empty)
+	RemoteCacheTTL   time.Duration
 	remoteConfLoaded bool
 	RemoteConfPath   string
+	RetractedPolicy  string // behaviour when a retracted version is pinned (warn when empty, or block)
+	RetractedURL     string // document listing retracted versions, merged with local one
 	RootPath         string
+	SearchBoundary   string // comma separated marker names stopping version files search in parents
+	SharedRoot       bool   // RootPath used by several users, caches and use dates are stored per user
+	ShellCompletion  bool   // register shell completion of managed tools through their proxy after installation
 	SkipSignature    bool
+	StreamExtract    bool // extract archives while downloading them (no full archive in memory)
+	StrictFiles      bool // conflicting version files in a directory are an error instead of a warning
+	StrictVerify     bool // refuse Terraform installation without PGP signature verification (and unverifiable Terragrunt releases)
+	Telemetry        bool
+	TelemetryURL     string
 	Tf               RemoteConfig
+	TfAmd64Fallback  bool // install amd64 build on Apple Silicon for versions without darwin arm64 build
 	TfKeyPath        string
+	TfSkipIaC        bool // disable scanning of Terraform files (required_version)
 	Tg               RemoteConfig
+	TgProxyTfBinary  bool
+	TokenAuto        bool // use GITHUB_TOKEN or GH_TOKEN when no token is configured
+	TokenSource      string
+	TrashTTL         time.Duration
 	Tofu             RemoteConfig
 	TofuKeyPath      string
+	TofuSkipIaC      bool   // disable scanning of OpenTofu files (required_version)
+	TofuNoManifest   bool   // disable use of release manifest (artifact names are built from version and platform)
+	TofuStrictSig    bool   // refuse OpenTofu installation without signature verification
+	Umask            int64  // mask of created files and directories permissions (-1 when not configured)
+	UseFile          string // version file name (or path) written by use command in working directory
 	UserPath         string
+	WarnUnverified   bool
 }
 
 func InitConfigFromEnv() (Config, error) {
@@ -145,16 +308,36 @@ func InitConfigFromEnv() (Config, error) {
 		arch = runtime.GOARCH
 	}
 
+	autoApprove, err := configutils.GetenvBool(false, tenvAutoApproveEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
 	autoInstall, err := configutils.GetenvBoolFallback(false, tenvAutoInstallEnvName, tofuAutoInstallEnvName, tfAutoInstallEnvName)
 	if err != nil {
 		return Config{}, err
 	}
 
+	checkModules, err := configutils.GetenvBool(false, tenvCheckModulesEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	dryRun, err := configutils.GetenvBool(false, tenvDryRunEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
 	forceRemote, err := configutils.GetenvBoolFallback(false, tenvForceRemoteEnvName, tofuForceRemoteEnvName, tfForceRemoteEnvName)
 	if err != nil {
 		return Config{}, err
 	}
 
+	searchBoundary, ok := os.LookupEnv(tenvSearchBoundaryEnvName)
+	if !ok {
+		searchBoundary = defaultSearchBoundary
+	}
+
 	rootPath := configutils.GetenvFallback(tenvRootPathEnvName, tofuRootPathEnvName, tfRootPathEnvName)
 	if rootPath == "" {
 		rootPath = filepath.Join(userPath, ".tenv")
@@ -170,30 +353,279 @@ func InitConfigFromEnv() (Config, error) {
 		return Config{}, err
 	}
 
+	githubAPIBudget, err := configutils.GetenvInt(0, tenvGithubAPIBudgetEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	githubRetry, err := configutils.GetenvInt(2, tenvGithubRetryEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	githubAssetAPI, err := configutils.GetenvBool(false, tenvGithubAssetAPIEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	http2, err := configutils.GetenvBool(true, tenvHTTP2EnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	insecure, err := configutils.GetenvBool(false, tenvInsecureEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	httpConnLimit, err := configutils.GetenvInt(0, tenvHTTPConnLimitEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	iacScanDepth, err := configutils.GetenvInt(0, tenvIaCScanDepthEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	keepVersions, err := configutils.GetenvInt(0, tenvKeepVersionsEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	umask, err := configutils.GetenvOctal(-1, tenvUmaskEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	warnUnverified, err := configutils.GetenvBool(false, tenvWarnUnverifiedEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	lockTimeout, err := configutils.GetenvDuration(0, tenvLockTimeoutEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	remoteCacheTTL, err := configutils.GetenvDuration(5*time.Minute, tenvRemoteCacheTTLEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	trashTTL, err := configutils.GetenvDuration(7*24*time.Hour, tenvTrashTTLEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	archiveAfter, err := configutils.GetenvDuration(0, tenvArchiveAfterEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	pluginTimeout, err := configutils.GetenvDuration(DefaultPluginTimeout, tenvPluginTimeoutEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	promptDefault, err := configutils.GetenvBool(false, tenvPromptDefaultEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	promptTimeout, err := configutils.GetenvDuration(0, tenvPromptTimeoutEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	pruneAfter, err := configutils.GetenvDuration(0, tenvPruneAfterEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	pinRemote, err := configutils.GetenvBool(true, tenvPinRemoteEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	tokenAuto, err := configutils.GetenvBool(true, tenvTokenAutoEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	sharedRoot, err := configutils.GetenvBool(false, tenvSharedRootEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	shellCompletion, err := configutils.GetenvBool(false, tenvShellCompletionEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	streamExtract, err := configutils.GetenvBool(false, tenvStreamEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	strictFiles, err := configutils.GetenvBool(false, tenvStrictFilesEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	telemetry, err := configutils.GetenvBool(false, tenvTelemetryEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	tgProxyTfBinary, err := configutils.GetenvBool(false, tgProxyTfBinaryEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	// tool specific variables override the global one
+	detectIaC, err := configutils.GetenvBool(true, tenvDetectIaCEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	tfDetectIaC, err := configutils.GetenvBool(detectIaC, tfDetectIaCEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	tfAmd64Fallback, err := configutils.GetenvBool(false, tfAmd64FallbackEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	tofuDetectIaC, err := configutils.GetenvBool(detectIaC, tofuDetectIaCEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	tofuReleaseManifest, err := configutils.GetenvBool(true, tofuReleaseManifestEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	checkProvenance, err := configutils.GetenvBool(false, tenvProvenanceEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	strictVerify, err := configutils.GetenvBool(false, tenvStrictVerifyEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	tofuStrictSig, err := configutils.GetenvBool(strictVerify, tofuStrictSignatureEnvName)
+	if err != nil {
+		return Config{}, err
+	}
+
+	mirrorAuth := download.Credential{
+		BearerToken: os.Getenv(tenvMirrorTokenEnvName), Header: os.Getenv(tenvMirrorHeaderEnvName),
+		Password: os.Getenv(tenvMirrorPasswordEnvName), Username: os.Getenv(tenvMirrorUsernameEnvName),
+	}
+
+	offlineSource := os.Getenv(tenvOfflineSourceEnvName)
+
 	return Config{
-		Arch:           arch,
-		Atmos:          makeRemoteConfig(AtmosRemoteURLEnvName, atmosListURLEnvName, atmosInstallModeEnvName, atmosListModeEnvName, defaultAtmosGithubURL, baseGithubURL),
-		ForceQuiet:     quiet,
-		ForceRemote:    forceRemote,
-		GithubActions:  gha,
-		GithubToken:    configutils.GetenvFallback(tenvTokenEnvName, tofuTokenEnvName),
-		NoInstall:      !autoInstall,
-		RemoteConfPath: os.Getenv(tenvRemoteConfEnvName),
-		RootPath:       rootPath,
-		Tf:             makeRemoteConfig(TfRemoteURLEnvName, tfListURLEnvName, tfInstallModeEnvName, tfListModeEnvName, defaultHashicorpURL, defaultHashicorpURL),
-		TfKeyPath:      os.Getenv(tfHashicorpPGPKeyEnvName),
-		Tg:             makeRemoteConfig(TgRemoteURLEnvName, tgListURLEnvName, tgInstallModeEnvName, tgListModeEnvName, defaultTerragruntGithubURL, baseGithubURL),
-		Tofu:           makeRemoteConfig(TofuRemoteURLEnvName, tofuListURLEnvName, tofuInstallModeEnvName, tofuListModeEnvName, defaultTofuGithubURL, baseGithubURL),
-		TofuKeyPath:    os.Getenv(tofuOpenTofuPGPKeyEnvName),
-		UserPath:       userPath,
+		AdvisoryURL:     os.Getenv(tenvAdvisoryURLEnvName),
+		AgnosticPolicy:  os.Getenv(tenvAgnosticPolicyEnvName),
+		Arch:            arch,
+		ArchiveAfter:    archiveAfter,
+		Atmos:           makeRemoteConfig(AtmosRemoteURLEnvName, atmosListURLEnvName, atmosInstallModeEnvName, atmosListModeEnvName, atmosMirrorURLEnvName, atmosBucketURLEnvName, defaultAtmosGithubURL, baseGithubURL, atmosReleasesPath).withOfflineSource(offlineSource, cmdconst.AtmosName),
+		AutoApprove:     autoApprove,
+		CABundle:        os.Getenv(tenvCABundleEnvName),
+		CheckModules:    checkModules,
+		CheckProvenance: checkProvenance,
+		ConstraintSets:  os.Getenv(tenvConstraintSetsEnvName),
+		ConstraintKey:   os.Getenv(tenvConstraintKeyEnvName),
+		Conftest:        makeRemoteConfig(ConftestRemoteURLEnvName, conftestListURLEnvName, conftestInstallModeEnvName, conftestListModeEnvName, conftestMirrorURLEnvName, conftestBucketURLEnvName, defaultConftestGithubURL, baseGithubURL, conftestReleasesPath).withOfflineSource(offlineSource, cmdconst.ConftestName),
+		DeltaURL:        os.Getenv(tenvDeltaURLEnvName),
+		DryRun:          dryRun,
+		ForceQuiet:      quiet,
+		ForceRemote:     forceRemote,
+		GithubActions:   gha,
+		GithubAPIBudget: githubAPIBudget,
+		GithubAssetAPI:  githubAssetAPI,
+		GithubRetry:     githubRetry,
+		GithubToken:     configutils.GetenvFallback(tenvTokenEnvName, tofuTokenEnvName),
+		HTTPConnLimit:   httpConnLimit,
+		IaCScanDepth:    iacScanDepth,
+		Insecure:        insecure,
+		InstallHelper:   os.Getenv(tenvInstallHelperEnvName),
+		KeepVersions:    keepVersions,
+		LockTimeout:     lockTimeout,
+		MirrorAuth:      mirrorAuth,
+		NoHTTP2:         !http2,
+		NoInstall:       !autoInstall,
+		Opa:             makeRemoteConfig(OpaRemoteURLEnvName, opaListURLEnvName, opaInstallModeEnvName, opaListModeEnvName, opaMirrorURLEnvName, opaBucketURLEnvName, defaultOpaGithubURL, baseGithubURL, opaReleasesPath).withOfflineSource(offlineSource, cmdconst.OpaName),
+		PinRemote:       pinRemote,
+		PluginTimeout:   pluginTimeout,
+		PromptDefault:   promptDefault,
+		PromptTimeout:   promptTimeout,
+		PruneAfter:      pruneAfter,
+		RegistryPath:    os.Getenv(tenvRegistryEnvName),
+		RemoteCacheTTL:  remoteCacheTTL,
+		RemoteConfPath:  os.Getenv(tenvRemoteConfEnvName),
+		RetractedPolicy: os.Getenv(tenvRetractedPolicyEnvName),
+		RetractedURL:    os.Getenv(tenvRetractedURLEnvName),
+		RootPath:        rootPath,
+		SearchBoundary:  searchBoundary,
+		SharedRoot:      sharedRoot,
+		ShellCompletion: shellCompletion,
+		StreamExtract:   streamExtract,
+		StrictFiles:     strictFiles,
+		StrictVerify:    strictVerify,
+		Telemetry:       telemetry,
+		TelemetryURL:    os.Getenv(tenvTelemetryURLEnvName),
+		Tf:              makeRemoteConfig(TfRemoteURLEnvName, tfListURLEnvName, tfInstallModeEnvName, tfListModeEnvName, tfMirrorURLEnvName, tfBucketURLEnvName, defaultHashicorpURL, defaultHashicorpURL, terraformReleasesPath).withOfflineSource(offlineSource, cmdconst.TerraformName),
+		TfAmd64Fallback: tfAmd64Fallback,
+		TfKeyPath:       os.Getenv(tfHashicorpPGPKeyEnvName),
+		TfSkipIaC:       !tfDetectIaC,
+		TokenAuto:       tokenAuto,
+		TokenSource:     os.Getenv(tenvTokenSourceEnvName),
+		TrashTTL:        trashTTL,
+		Tg:              makeRemoteConfig(TgRemoteURLEnvName, tgListURLEnvName, tgInstallModeEnvName, tgListModeEnvName, tgMirrorURLEnvName, tgBucketURLEnvName, defaultTerragruntGithubURL, baseGithubURL, terragruntReleasesPath).withOfflineSource(offlineSource, cmdconst.TerragruntName),
+		TgProxyTfBinary: tgProxyTfBinary,
+		Tofu:            makeRemoteConfig(TofuRemoteURLEnvName, tofuListURLEnvName, tofuInstallModeEnvName, tofuListModeEnvName, tofuMirrorURLEnvName, tofuBucketURLEnvName, defaultTofuGithubURL, baseGithubURL, tofuReleasesPath).withOfflineSource(offlineSource, cmdconst.TofuName),
+		TofuKeyPath:     os.Getenv(tofuOpenTofuPGPKeyEnvName),
+		TofuSkipIaC:     !tofuDetectIaC,
+		TofuNoManifest:  !tofuReleaseManifest,
+		TofuStrictSig:   tofuStrictSig,
+		Umask:           umask,
+		UseFile:         os.Getenv(tenvUseFileEnvName),
+		UserPath:        userPath,
+		WarnUnverified:  warnUnverified,
 	}, nil
 }
 
+// BoundaryMarkers returns names (like ".git") which presence in a directory stop the search of version files in parent directories.
+func (conf *Config) BoundaryMarkers() []string {
+	if conf.SearchBoundary == noSearchBoundary {
+		return nil
+	}
+
+	var markers []string
+	for _, marker := range strings.Split(conf.SearchBoundary, ",") {
+		if marker = strings.TrimSpace(marker); marker != "" {
+			markers = append(markers, marker)
+		}
+	}
+
+	return markers
+}
+
 func (conf *Config) InitDisplayer(proxyCall bool) {
 	if conf.ForceQuiet {
 		conf.Displayer = loghelper.InertDisplayer
 		conf.DisplayVerbose = false
+		download.SetProgress(nil, false)
 	} else {
+		initProgress(conf.JSONOutput)
 		logLevel := hclog.Trace
 		if !conf.DisplayVerbose {
 			logLevel = hclog.Warn
@@ -205,15 +637,30 @@ func (conf *Config) InitDisplayer(proxyCall bool) {
 			Name: cmdconst.TenvName, Level: logLevel,
 		})
 
-		if proxyCall {
+		switch {
+		case proxyCall:
 			display := loghelper.BuildDisplayFunc(os.Stderr, color.New(color.FgGreen))
 			conf.Displayer = loghelper.NewRecordingDisplayer(loghelper.MakeBasicDisplayer(appLogger, display))
-		} else {
+		case conf.JSONOutput: // keep standard output parsable
+			conf.Displayer = loghelper.MakeBasicDisplayer(appLogger, loghelper.BuildDisplayFunc(os.Stderr, color.New(color.FgGreen)))
+		default:
 			conf.Displayer = loghelper.MakeBasicDisplayer(appLogger, loghelper.StdDisplay)
 		}
 	}
 }
 
+// download progress is redrawn in place on a terminal, otherwise periodic lines are written on standard error (piped output stays clean).
+func initProgress(jsonOutput bool) {
+	switch {
+	case jsonOutput:
+		download.SetProgress(os.Stderr, isatty.IsTerminal(os.Stderr.Fd()))
+	case isatty.IsTerminal(os.Stdout.Fd()):
+		download.SetProgress(os.Stdout, true)
+	default:
+		download.SetProgress(os.Stderr, false)
+	}
+}
+
 func (conf *Config) InitInstall(forceInstall bool, forceNoInstall bool) {
 	switch {
 	case forceNoInstall: // higher priority to --no-install
@@ -223,36 +670,211 @@ func (conf *Config) InitInstall(forceInstall bool, forceNoInstall bool) {
 	}
 }
 
+// InitSearch applies per command search preference over TENV_FORCE_REMOTE (and --force-remote).
+func (conf *Config) InitSearch(preferLocal bool, preferRemote bool) {
+	switch {
+	case preferLocal: // higher priority to --prefer-local (no accidental remote lookup)
+		conf.ForceRemote = false
+	case preferRemote:
+		conf.ForceRemote = true
+	}
+}
+
 func (conf *Config) InitRemoteConf() error {
 	if conf.remoteConfLoaded {
 		return nil
 	}
 	conf.remoteConfLoaded = true
 
-	remoteConfPath := conf.RemoteConfPath
-	if remoteConfPath == "" {
-		remoteConfPath = filepath.Join(conf.RootPath, "remote.yaml")
+	remoteConf, err := conf.readRemoteConf()
+	if err != nil {
+		return err
+	}
+
+	conf.Tf.Data = remoteConf[cmdconst.TerraformName]
+	conf.Tg.Data = remoteConf[cmdconst.TerragruntName]
+	conf.Tofu.Data = remoteConf[cmdconst.TofuName]
+	conf.Atmos.Data = remoteConf[cmdconst.AtmosName]
+	conf.Conftest.Data = remoteConf[cmdconst.ConftestName]
+	conf.Opa.Data = remoteConf[cmdconst.OpaName]
+
+	if err = conf.installRemotePins(); err != nil {
+		return err
+	}
+
+	if err = conf.installClientCerts(); err != nil {
+		return err
+	}
+
+	if err = conf.installCredentials(); err != nil {
+		return err
+	}
+
+	return conf.resolveTokenSource(remoteConf[cmdconst.TenvName])
+}
+
+// credentials from remote conf file are only sent to non GitHub custom hosts.
+func (conf *Config) installCredentials() error {
+	credentials := map[string]download.Credential{}
+	for _, remoteConf := range []RemoteConfig{conf.Atmos, conf.Conftest, conf.Opa, conf.Tf, conf.Tg, conf.Tofu} {
+		credential, hosts := remoteConf.credential(conf.MirrorAuth)
+		if len(hosts) == 0 {
+			continue
+		}
+
+		if err := credential.Validate(); err != nil {
+			return err
+		}
+
+		for _, host := range hosts {
+			credentials[host] = credential
+		}
+	}
+	if len(credentials) != 0 {
+		conf.Displayer.Log(hclog.Debug, "Configured credentials for custom remote hosts", "count", len(credentials))
+	}
+	download.InstallCredentials(credentials)
+
+	return nil
+}
+
+// client certificates from remote conf file are presented to the same hosts as credentials (mutual TLS).
+func (conf *Config) installClientCerts() error {
+	certificates := map[string]tls.Certificate{}
+	for _, remoteConf := range []RemoteConfig{conf.Atmos, conf.Conftest, conf.Opa, conf.Tf, conf.Tg, conf.Tofu} {
+		certificate, hosts, err := remoteConf.clientCertificate()
+		if err != nil {
+			return err
+		}
+
+		for _, host := range hosts {
+			certificates[host] = certificate
+		}
+	}
+	if len(certificates) != 0 {
+		conf.Displayer.Log(hclog.Debug, "Configured client certificates for custom remote hosts", "count", len(certificates))
+	}
+	httpclient.SetClientCertificates(certificates)
+
+	return nil
+}
+
+// trust on first use of custom remote hosts certificate public key.
+func (conf *Config) installRemotePins() error {
+	if !conf.PinRemote {
+		return nil
+	}
+
+	var hosts []string
+	for _, remoteConf := range []RemoteConfig{conf.Atmos, conf.Conftest, conf.Opa, conf.Tf, conf.Tg, conf.Tofu} {
+		hosts = append(hosts, remoteConf.customHosts()...)
 	}
 
-	data, err := os.ReadFile(remoteConfPath)
+	return tlspin.Install(conf.UserStatePath(RemotePinsFileName), hosts, conf.Displayer)
+}
+
+func (conf *Config) readRemoteConf() (map[string]map[string]string, error) {
+	data, err := os.ReadFile(conf.remoteConfPath())
 	if err != nil {
 		if !errors.Is(err, fs.ErrNotExist) {
-			return err
+			return nil, err
 		}
 		conf.Displayer.Log(hclog.Debug, "Can not read remote configuration file", loghelper.Error, err)
 
-		return nil
+		return nil, nil
 	}
 
 	var remoteConf map[string]map[string]string
 	if err = yaml.Unmarshal(data, &remoteConf); err != nil {
-		return err
+		return nil, err
 	}
 
-	conf.Tf.Data = remoteConf[cmdconst.TerraformName]
-	conf.Tg.Data = remoteConf[cmdconst.TerragruntName]
-	conf.Tofu.Data = remoteConf[cmdconst.TofuName]
-	conf.Atmos.Data = remoteConf[cmdconst.AtmosName]
+	return remoteConf, decryptSections(remoteConf)
+}
+
+// GithubTokenFor returns the token to send to targetURL : a configured token is always used,
+// a token from GITHUB_TOKEN or GH_TOKEN only toward GitHub hosts.
+func (conf *Config) GithubTokenFor(targetURL string) string {
+	if conf.GithubToken != "" {
+		return conf.GithubToken
+	}
+
+	if conf.ambientToken == "" {
+		return ""
+	}
+
+	if parsedURL, err := url.Parse(targetURL); err == nil && isGithubHost(parsedURL.Hostname()) {
+		return conf.ambientToken
+	}
+
+	return ""
+}
+
+// ToolGithubToken returns the github_token of tool part in remote conf file when present, else GithubTokenFor(targetURL).
+func (conf *Config) ToolGithubToken(remoteConf RemoteConfig, targetURL string) string {
+	if token := remoteConf.Data["github_token"]; token != "" {
+		return token
+	}
+
+	return conf.GithubTokenFor(targetURL)
+}
+
+func (conf *Config) remoteConfPath() string {
+	if conf.RemoteConfPath != "" {
+		return conf.RemoteConfPath
+	}
+
+	return filepath.Join(conf.RootPath, "remote.yaml")
+}
+
+// an explicit token (flag or env var) has priority over remote conf file,
+// in remote conf file a (possibly encrypted) token has priority over token source (env var or remote conf file),
+// well-known variables (GITHUB_TOKEN then GH_TOKEN) are only used without any of them.
+func (conf *Config) resolveTokenSource(tenvConf map[string]string) error {
+	if conf.GithubToken != "" {
+		return nil
+	}
+
+	if token := tenvConf["github_token"]; token != "" {
+		conf.GithubToken = token
+
+		return nil
+	}
+
+	tokenSource := conf.TokenSource
+	if tokenSource == "" {
+		tokenSource = MapGetDefault(tenvConf, "token_source", "")
+	}
+
+	if tokenSource == "" {
+		conf.useAmbientToken()
+
+		return nil
+	}
+
+	token, err := secret.Resolve(tokenSource)
+	if err != nil {
+		return err
+	}
+	conf.Displayer.Log(hclog.Debug, "Resolved GitHub token from secret source")
+	conf.GithubToken = token
 
 	return nil
 }
+
+// GitHub Actions workflows and gh CLI users often already export a token (opt-out with TENV_GITHUB_TOKEN_AUTO=false),
+// it is kept apart from GithubToken to never leak it toward custom remotes.
+func (conf *Config) useAmbientToken() {
+	if !conf.TokenAuto {
+		return
+	}
+
+	for _, envName := range []string{tokenEnvName, ghTokenEnvName} {
+		if token := os.Getenv(envName); token != "" {
+			conf.Displayer.Log(hclog.Debug, "Use GitHub token from environment for GitHub hosts", "name", envName)
+			conf.ambientToken = token
+
+			return
+		}
+	}
+}
@@ -0,0 +1,172 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+)
+
+func TestInitSearch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		forceRemote  bool // from TENV_FORCE_REMOTE or --force-remote
+		preferLocal  bool
+		preferRemote bool
+		want         bool
+	}{
+		{name: "Default", want: false},
+		{name: "ForceRemoteKept", forceRemote: true, want: true},
+		{name: "PreferLocal", forceRemote: true, preferLocal: true, want: false},
+		{name: "PreferRemote", preferRemote: true, want: true},
+		{name: "PreferLocalFirst", preferLocal: true, preferRemote: true, want: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := config.Config{ForceRemote: tt.forceRemote}
+			conf.InitSearch(tt.preferLocal, tt.preferRemote)
+			if conf.ForceRemote != tt.want {
+				t.Error("Unmatching results, get :", conf.ForceRemote)
+			}
+		})
+	}
+}
+
+// modify environment, so not parallel.
+func TestGithubTokenPrecedence(t *testing.T) { //nolint
+	if runtime.GOOS == "windows" {
+		t.Skip("fake op CLI is a shell script")
+	}
+
+	// fake 1Password CLI resolving any token source
+	binPath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binPath, "op"), []byte("#!/bin/sh\nprintf source\n"), 0o755); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+	t.Setenv("PATH", binPath+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	const githubURL = "https://api.github.com/repos/"
+	const customURL = "https://artifactory.example.com/artifactory/api/github/"
+
+	tests := []struct {
+		name       string
+		flag       string
+		env        map[string]string
+		remoteConf string
+		targetURL  string
+		want       string
+	}{
+		{name: "Flag", flag: "flag", env: map[string]string{"TENV_GITHUB_TOKEN": "env", "GITHUB_TOKEN": "ambient"}, remoteConf: "tenv:\n  github_token: conf\n", targetURL: githubURL, want: "flag"},
+		{name: "Env", env: map[string]string{"TENV_GITHUB_TOKEN": "env", "TENV_GITHUB_TOKEN_SOURCE": "op:ref"}, remoteConf: "tenv:\n  github_token: conf\n", targetURL: githubURL, want: "env"},
+		{name: "TofuenvEnv", env: map[string]string{"TOFUENV_GITHUB_TOKEN": "tofuenv", "GITHUB_TOKEN": "ambient"}, targetURL: githubURL, want: "tofuenv"},
+		{name: "RemoteConf", env: map[string]string{"TENV_GITHUB_TOKEN_SOURCE": "op:ref", "GITHUB_TOKEN": "ambient"}, remoteConf: "tenv:\n  github_token: conf\n", targetURL: githubURL, want: "conf"},
+		{name: "EnvSource", env: map[string]string{"TENV_GITHUB_TOKEN_SOURCE": "op:ref", "GITHUB_TOKEN": "ambient"}, targetURL: githubURL, want: "source"},
+		{name: "RemoteConfSource", env: map[string]string{"GITHUB_TOKEN": "ambient"}, remoteConf: "tenv:\n  token_source: op:ref\n", targetURL: githubURL, want: "source"},
+		{name: "GithubToken", env: map[string]string{"GITHUB_TOKEN": "ambient", "GH_TOKEN": "gh"}, targetURL: githubURL, want: "ambient"},
+		{name: "GhToken", env: map[string]string{"GH_TOKEN": "gh"}, targetURL: "https://github.com", want: "gh"},
+		{name: "AutoDisabled", env: map[string]string{"GITHUB_TOKEN": "ambient", "TENV_GITHUB_TOKEN_AUTO": "false"}, targetURL: githubURL},
+		{name: "AmbientCustomHost", env: map[string]string{"GITHUB_TOKEN": "ambient", "GH_TOKEN": "gh"}, targetURL: customURL},
+		{name: "ConfiguredCustomHost", env: map[string]string{"TENV_GITHUB_TOKEN": "env"}, targetURL: customURL, want: "env"},
+		{name: "ToolOverride", flag: "flag", env: map[string]string{"GITHUB_TOKEN": "ambient"}, remoteConf: "tofu:\n  github_token: tool\n", targetURL: customURL, want: "tool"},
+		{name: "OtherToolOverride", env: map[string]string{"GITHUB_TOKEN": "ambient"}, remoteConf: "terragrunt:\n  github_token: tool\n", targetURL: githubURL, want: "ambient"},
+	}
+
+	envNames := []string{"GH_TOKEN", "GITHUB_TOKEN", "TENV_GITHUB_TOKEN", "TENV_GITHUB_TOKEN_AUTO", "TENV_GITHUB_TOKEN_SOURCE", "TOFUENV_GITHUB_TOKEN"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, envName := range envNames {
+				t.Setenv(envName, tt.env[envName])
+			}
+
+			remoteConfPath := filepath.Join(t.TempDir(), "remote.yaml")
+			if err := os.WriteFile(remoteConfPath, []byte(tt.remoteConf), 0o600); err != nil {
+				t.Fatal("Unexpected error :", err)
+			}
+			t.Setenv("TENV_REMOTE_CONF", remoteConfPath)
+			t.Setenv("TENV_ROOT", t.TempDir())
+
+			conf, err := config.InitConfigFromEnv()
+			if err != nil {
+				t.Fatal("Unexpected error :", err)
+			}
+			conf.Displayer = loghelper.InertDisplayer
+
+			if tt.flag != "" { // like --github-token flag parsing after initialization
+				conf.GithubToken = tt.flag
+			}
+
+			if err = conf.InitRemoteConf(); err != nil {
+				t.Fatal("Unexpected error :", err)
+			}
+
+			if token := conf.ToolGithubToken(conf.Tofu, tt.targetURL); token != tt.want {
+				t.Error("Unmatching results, get :", token)
+			}
+		})
+	}
+}
+
+// modify environment, so not parallel.
+func TestDetectIaCPrecedence(t *testing.T) { //nolint
+	tests := []struct {
+		name         string
+		global       string
+		tf           string
+		tofu         string
+		wantTfSkip   bool
+		wantTofuSkip bool
+		wantErr      bool
+	}{
+		{name: "Default"},
+		{name: "GlobalDisabled", global: "false", wantTfSkip: true, wantTofuSkip: true},
+		{name: "TofuOverride", global: "false", tofu: "true", wantTfSkip: true},
+		{name: "TfOverride", tf: "false", wantTfSkip: true},
+		{name: "BothOverride", global: "true", tf: "false", tofu: "false", wantTfSkip: true, wantTofuSkip: true},
+		{name: "InvalidGlobal", global: "maybe", wantErr: true},
+		{name: "InvalidTool", tofu: "maybe", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TENV_DETECT_IAC", tt.global)
+			t.Setenv("TFENV_DETECT_IAC", tt.tf)
+			t.Setenv("TOFUENV_DETECT_IAC", tt.tofu)
+
+			conf, err := config.InitConfigFromEnv()
+			if (err != nil) != tt.wantErr {
+				t.Fatal("Unmatching error, get :", err)
+			}
+
+			if !tt.wantErr && (conf.TfSkipIaC != tt.wantTfSkip || conf.TofuSkipIaC != tt.wantTofuSkip) {
+				t.Error("Unmatching results, get :", conf.TfSkipIaC, conf.TofuSkipIaC)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/tofuutils/tenv/v2/config/cmdconst"
+)
+
+const (
+	tenvProfileEnvName      = tenvPrefix + "PROFILE"
+	tenvProfilesConfEnvName = tenvPrefix + "PROFILES_CONF"
+)
+
+var ErrProfile = errors.New("unknown configuration profile")
+
+// ApplyProfile set environment variables defined by the selected profile (flag value or TENV_PROFILE),
+// variables already present in environment are kept. Must be called before InitConfigFromEnv.
+func ApplyProfile(profileName string) error {
+	if profileName == "" {
+		profileName = os.Getenv(tenvProfileEnvName)
+	}
+
+	if profileName == "" {
+		return nil
+	}
+
+	profiles, err := readProfiles()
+	if err != nil {
+		return err
+	}
+
+	profile, ok := profiles[profileName]
+	if !ok {
+		return ErrProfile
+	}
+
+	for key, value := range profile {
+		if _, present := os.LookupEnv(key); present {
+			continue
+		}
+
+		if err = os.Setenv(key, value); err != nil {
+			return err
+		}
+	}
+
+	// propagate selection to proxied calls
+	return os.Setenv(tenvProfileEnvName, profileName)
+}
+
+func profilesConfPath() (string, error) {
+	if profilesPath := os.Getenv(tenvProfilesConfEnvName); profilesPath != "" {
+		return profilesPath, nil
+	}
+
+	userConfigPath, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(userConfigPath, cmdconst.TenvName, "profiles.yaml"), nil
+}
+
+func readProfiles() (map[string]map[string]string, error) {
+	profilesPath, err := profilesConfPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(profilesPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrProfile
+		}
+
+		return nil, err
+	}
+
+	var profiles map[string]map[string]string
+	if err = yaml.Unmarshal(data, &profiles); err != nil {
+		return nil, err
+	}
+
+	return profiles, decryptSections(profiles)
+}
@@ -0,0 +1,79 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/config"
+)
+
+const profilesData = `ci:
+  TENV_ARCH: arm64
+  TENV_AUTO_INSTALL: "true"
+`
+
+// modify env, so not parallel.
+func TestApplyProfile(t *testing.T) { //nolint
+	profilesPath := filepath.Join(t.TempDir(), "profiles.yaml")
+	if err := os.WriteFile(profilesPath, []byte(profilesData), 0o600); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	tests := []struct {
+		name         string
+		profileName  string
+		env          map[string]string // set before call (other variables are unset)
+		profilesConf string
+		wantErr      error
+		wantEnv      map[string]string // empty value for unset variable
+	}{
+		{name: "NoProfile", profilesConf: profilesPath, wantEnv: map[string]string{"TENV_ARCH": "", "TENV_PROFILE": ""}},
+		{name: "Flag", profileName: "ci", profilesConf: profilesPath, wantEnv: map[string]string{"TENV_ARCH": "arm64", "TENV_AUTO_INSTALL": "true", "TENV_PROFILE": "ci"}},
+		{name: "EnvSelection", env: map[string]string{"TENV_PROFILE": "ci"}, profilesConf: profilesPath, wantEnv: map[string]string{"TENV_ARCH": "arm64", "TENV_PROFILE": "ci"}},
+		{name: "EnvKept", profileName: "ci", env: map[string]string{"TENV_ARCH": "amd64"}, profilesConf: profilesPath, wantEnv: map[string]string{"TENV_ARCH": "amd64", "TENV_AUTO_INSTALL": "true"}},
+		{name: "UnknownProfile", profileName: "prod", profilesConf: profilesPath, wantErr: config.ErrProfile, wantEnv: map[string]string{"TENV_ARCH": ""}},
+		{name: "MissingFile", profileName: "ci", profilesConf: filepath.Join(t.TempDir(), "missing.yaml"), wantErr: config.ErrProfile, wantEnv: map[string]string{"TENV_ARCH": ""}},
+	}
+
+	// sequential, env is shared
+	for _, tt := range tests {
+		for _, key := range []string{"TENV_ARCH", "TENV_AUTO_INSTALL", "TENV_PROFILE"} {
+			t.Setenv(key, "") // restored at test end
+			os.Unsetenv(key)
+		}
+
+		for key, value := range tt.env {
+			t.Setenv(key, value)
+		}
+		t.Setenv("TENV_PROFILES_CONF", tt.profilesConf)
+
+		if err := config.ApplyProfile(tt.profileName); err != tt.wantErr {
+			t.Error(tt.name, "unmatching error, get :", err)
+		}
+
+		for key, value := range tt.wantEnv {
+			if got := os.Getenv(key); got != value {
+				t.Error(tt.name, "unmatching value for", key, ", get :", got)
+			}
+		}
+	}
+}
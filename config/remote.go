@@ -19,9 +19,15 @@
 package config
 
 import (
+	"crypto/tls"
 	"errors"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
+
+	"github.com/tofuutils/tenv/v2/pkg/download"
+	"github.com/tofuutils/tenv/v2/pkg/secret"
 )
 
 const (
@@ -35,35 +41,66 @@ const (
 	defaultTerragruntGithubURL = defaultGithubURL + "gruntwork-io/terragrunt" + slashReleases
 	defaultTofuGithubURL       = defaultGithubURL + "opentofu/opentofu" + slashReleases
 	defaultAtmosGithubURL      = defaultGithubURL + "cloudposse/atmos" + slashReleases
+	defaultConftestGithubURL   = defaultGithubURL + "open-policy-agent/conftest" + slashReleases
+	defaultOpaGithubURL        = defaultGithubURL + "open-policy-agent/opa" + slashReleases
 	slashReleases              = "/releases"
+
+	// path of releases directory relative to remote url in direct install mode.
+	atmosReleasesPath      = "cloudposse/atmos" + slashReleasesDownload
+	conftestReleasesPath   = "open-policy-agent/conftest" + slashReleasesDownload
+	opaReleasesPath        = "open-policy-agent/opa" + slashReleasesDownload
+	slashReleasesDownload  = slashReleases + "/download"
+	terraformReleasesPath  = "terraform"
+	terragruntReleasesPath = "gruntwork-io/terragrunt" + slashReleasesDownload
+	tofuReleasesPath       = "opentofu/opentofu" + slashReleasesDownload
 )
 
 var (
+	ErrClientCert  = errors.New("client_cert and client_key must be set together")
 	ErrInstallMode = errors.New("unknown install mode")
 	ErrListMode    = errors.New("unknown list mode")
 )
 
 type RemoteConfig struct {
+	bucketURL      string            // value from env
 	Data           map[string]string // values from conf file
 	defaultBaseURL string
 	defaultURL     string
 	installMode    string // value from env
 	listMode       string // value from env
 	listURL        string // value from env
+	mirrorURL      string // value from env
+	offlinePath    string // value from env joined with tool directory
+	releasesPath   string
 	RemoteURL      string // value from flag
 	RemoteURLEnv   string // value from env
 }
 
-func makeRemoteConfig(remoteURLEnvName string, listURLEnvName string, installModeEnvName string, listModeEnvName string, defaultURL string, defaultBaseURL string) RemoteConfig {
+func makeRemoteConfig(remoteURLEnvName string, listURLEnvName string, installModeEnvName string, listModeEnvName string, mirrorURLEnvName string, bucketURLEnvName string, defaultURL string, defaultBaseURL string, releasesPath string) RemoteConfig {
 	return RemoteConfig{
-		defaultBaseURL: defaultBaseURL, defaultURL: defaultURL, installMode: os.Getenv(installModeEnvName), listMode: os.Getenv(listModeEnvName),
-		listURL: os.Getenv(listURLEnvName), RemoteURLEnv: os.Getenv(remoteURLEnvName),
+		bucketURL: os.Getenv(bucketURLEnvName), defaultBaseURL: defaultBaseURL, defaultURL: defaultURL, installMode: os.Getenv(installModeEnvName),
+		listMode: os.Getenv(listModeEnvName), listURL: os.Getenv(listURLEnvName), mirrorURL: os.Getenv(mirrorURLEnvName), releasesPath: releasesPath,
+		RemoteURLEnv: os.Getenv(remoteURLEnvName),
 	}
 }
 
+// return a copy reading releases from the tool directory of offline source (unchanged when source is empty).
+func (r RemoteConfig) withOfflineSource(offlineSource string, toolDirName string) RemoteConfig {
+	if offlineSource != "" {
+		r.offlinePath = filepath.Join(offlineSource, toolDirName)
+	}
+
+	return r
+}
+
+// GetBucketURL returns the S3 url (s3://<bucket>/<prefix>) storing releases as <version>/<asset> keys (empty when not configured).
+func (r RemoteConfig) GetBucketURL() string {
+	return strings.TrimRight(strings.TrimSpace(r.bucketURL), "/")
+}
+
 func (r RemoteConfig) GetInstallMode() string {
 	defaultInstallMode := ModeAPI
-	if r.defaultBaseURL == baseGithubURL && r.GetRemoteURL() != r.defaultURL {
+	if r.offlinePath != "" || r.GetBucketURL() != "" || r.GetMirrorURL() != "" || (r.defaultBaseURL == baseGithubURL && r.GetRemoteURL() != r.defaultURL) {
 		defaultInstallMode = InstallModeDirect
 	}
 
@@ -83,16 +120,51 @@ func (r RemoteConfig) GetListURL() string {
 	return strings.TrimRight(r.getValueForcedDefault("list_url", r.listURL, r.GetRemoteURL()), "/")
 }
 
+// GetMirrorURL returns the generic repository mirroring upstream releases (empty when not configured).
+func (r RemoteConfig) GetMirrorURL() string {
+	return strings.TrimRight(strings.TrimSpace(r.mirrorURL), "/")
+}
+
+// GetOfflinePath returns the local directory storing releases as <version>/<asset> files (empty when not configured).
+func (r RemoteConfig) GetOfflinePath() string {
+	return r.offlinePath
+}
+
+// GetReleasesPath returns the path of releases directory relative to remote url (upstream layout).
+func (r RemoteConfig) GetReleasesPath() string {
+	return r.releasesPath
+}
+
 func (r RemoteConfig) GetRemoteURL() string {
 	remoteURL := r.RemoteURL
 	if remoteURL == "" {
-		remoteURL = r.getValueForcedDefault("url", r.RemoteURLEnv, r.defaultURL)
+		forcedURL := r.RemoteURLEnv
+		if forcedURL == "" {
+			forcedURL = r.GetMirrorURL()
+		}
+		remoteURL = r.getValueForcedDefault("url", forcedURL, r.defaultURL)
 	}
 
 	return strings.TrimRight(remoteURL, "/")
 }
 
 func (r RemoteConfig) GetRewriteRule() []string {
+	if r.offlinePath != "" && r.GetInstallMode() == InstallModeDirect {
+		// releases directory of upstream layout is replaced by local directory
+		releasesURL, err := url.JoinPath(r.GetRemoteURL(), r.releasesPath)
+		if dirURL, err2 := download.FileURL(r.offlinePath); err == nil && err2 == nil {
+			return []string{releasesURL, dirURL}
+		}
+	}
+
+	if bucketURL := r.GetBucketURL(); bucketURL != "" && r.GetInstallMode() == InstallModeDirect {
+		// releases directory of upstream layout is replaced by bucket prefix
+		releasesURL, err := url.JoinPath(r.GetRemoteURL(), r.releasesPath)
+		if err == nil {
+			return []string{releasesURL, bucketURL}
+		}
+	}
+
 	oldBase := r.Data["old_base_url"]
 	newBase := r.Data["new_base_url"]
 	if oldBase != "" && newBase != "" {
@@ -120,6 +192,89 @@ func (r RemoteConfig) GetRewriteRule() []string {
 	return []string{oldBase, newBase}
 }
 
+// return credential from conf file fields (or mirror credential from env when they are empty)
+// and the hosts it applies to (custom urls, GitHub excluded).
+func (r RemoteConfig) credential(mirrorAuth download.Credential) (download.Credential, []string) {
+	credential := download.Credential{
+		BearerToken: MapGetDefault(r.Data, "bearer_token", ""),
+		Header:      MapGetDefault(r.Data, "auth_header", ""),
+		Password:    MapGetDefault(r.Data, "password", ""),
+		Username:    MapGetDefault(r.Data, "username", ""),
+	}
+	if credential.Empty() && r.GetMirrorURL() != "" {
+		credential = mirrorAuth
+	}
+	if credential.Empty() {
+		return credential, nil
+	}
+
+	return credential, r.privateHosts()
+}
+
+// return client certificate from conf file fields (file paths or secret sources),
+// and the hosts it is presented to (same as credential).
+func (r RemoteConfig) clientCertificate() (tls.Certificate, []string, error) {
+	certValue, keyValue := MapGetDefault(r.Data, "client_cert", ""), MapGetDefault(r.Data, "client_key", "")
+	if certValue == "" && keyValue == "" {
+		return tls.Certificate{}, nil, nil
+	}
+
+	if certValue == "" || keyValue == "" {
+		return tls.Certificate{}, nil, ErrClientCert
+	}
+
+	hosts := r.privateHosts()
+	if len(hosts) == 0 {
+		return tls.Certificate{}, nil, nil
+	}
+
+	certPEM, err := readPEM(certValue)
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+
+	keyPEM, err := readPEM(keyValue)
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+
+	certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+
+	return certificate, hosts, err
+}
+
+// return hosts (with port) of custom urls, GitHub excluded.
+func (r RemoteConfig) privateHosts() []string {
+	var hosts []string
+	for _, customURL := range []string{r.GetRemoteURL(), r.GetListURL(), r.Data["new_base_url"]} {
+		if customURL == "" || customURL == r.defaultURL {
+			continue
+		}
+
+		if parsedURL, err := url.Parse(customURL); err == nil && parsedURL.Host != "" && !isGithubHost(parsedURL.Hostname()) {
+			hosts = append(hosts, parsedURL.Host)
+		}
+	}
+
+	return hosts
+}
+
+// return hosts of custom https urls (different from default ones).
+func (r RemoteConfig) customHosts() []string {
+	var hosts []string
+	for _, customURL := range []string{r.GetRemoteURL(), r.GetListURL()} {
+		if customURL == r.defaultURL {
+			continue
+		}
+
+		if parsedURL, err := url.Parse(customURL); err == nil && parsedURL.Scheme == "https" && parsedURL.Hostname() != "" {
+			hosts = append(hosts, parsedURL.Hostname())
+		}
+	}
+
+	return hosts
+}
+
 func (r RemoteConfig) getValueForcedDefault(name string, forcedValue string, defaultValue string) string {
 	if forcedValue != "" {
 		return forcedValue
@@ -128,6 +283,20 @@ func (r RemoteConfig) getValueForcedDefault(name string, forcedValue string, def
 	return MapGetDefault(r.Data, name, defaultValue)
 }
 
+func readPEM(value string) ([]byte, error) {
+	if !secret.IsSource(value) {
+		return os.ReadFile(value)
+	}
+
+	data, err := secret.Resolve(value)
+
+	return []byte(data), err
+}
+
+func isGithubHost(host string) bool {
+	return host == "github.com" || host == "api.github.com"
+}
+
 func MapGetDefault(m map[string]string, key string, defaultValue string) string {
 	if value := strings.TrimSpace(m[key]); value != "" {
 		return value
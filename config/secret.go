@@ -0,0 +1,127 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/tofuutils/tenv/v2/pkg/fileperm"
+	"github.com/tofuutils/tenv/v2/pkg/secret"
+)
+
+var ErrConfFormat = errors.New("configuration file must be a mapping of sections to mapping of values")
+
+// SetProfileSecret encrypt value and write it in profiles file (TENV_PROFILES_CONF or default location).
+func SetProfileSecret(profileName string, envName string, value string) error {
+	profilesPath, err := profilesConfPath()
+	if err != nil {
+		return err
+	}
+
+	return writeSecret(profilesPath, profileName, envName, value)
+}
+
+// SetRemoteSecret encrypt value and write it in remote configuration file.
+func (conf *Config) SetRemoteSecret(section string, key string, value string) error {
+	return writeSecret(conf.remoteConfPath(), section, key, value)
+}
+
+func decryptSections(sections map[string]map[string]string) error {
+	for _, values := range sections {
+		for key, value := range values {
+			decrypted, err := secret.Decrypt(value)
+			if err != nil {
+				return err
+			}
+			values[key] = decrypted
+		}
+	}
+
+	return nil
+}
+
+// rewrite the file through a yaml node tree to keep comments and ordering of other entries.
+func setYAMLValue(data []byte, section string, key string, value string) ([]byte, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	if document.Kind == 0 {
+		document = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, ErrConfFormat
+	}
+
+	sectionNode := mappingValue(root, section)
+	if sectionNode == nil {
+		sectionNode = &yaml.Node{Kind: yaml.MappingNode}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: section}, sectionNode)
+	} else if sectionNode.Kind != yaml.MappingNode {
+		return nil, ErrConfFormat
+	}
+
+	if valueNode := mappingValue(sectionNode, key); valueNode != nil {
+		*valueNode = yaml.Node{Kind: yaml.ScalarNode, Value: value, LineComment: valueNode.LineComment}
+	} else {
+		sectionNode.Content = append(sectionNode.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, &yaml.Node{Kind: yaml.ScalarNode, Value: value})
+	}
+
+	return yaml.Marshal(&document)
+}
+
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for index := 0; index+1 < len(mapping.Content); index += 2 {
+		if mapping.Content[index].Value == key {
+			return mapping.Content[index+1]
+		}
+	}
+
+	return nil
+}
+
+func writeSecret(confPath string, section string, key string, value string) error {
+	encrypted, err := secret.Encrypt(value)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(confPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	if data, err = setYAMLValue(data, section, key, encrypted); err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(confPath), fileperm.DirMode()); err != nil {
+		return err
+	}
+
+	return os.WriteFile(confPath, data, 0o600)
+}
@@ -0,0 +1,86 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/tofuutils/tenv/v2/pkg/fileperm"
+)
+
+const (
+	RemotePinsFileName = "remote-pins.json"
+	UsersDirName       = "users"
+
+	defaultUserID = "default"
+)
+
+var ErrNotSharedRoot = errors.New("shared root layout is not enabled, set TENV_SHARED_ROOT")
+
+// MoveToUserStatePath move a file from the legacy location in root path to the per user one (missing file is ignored).
+func (conf *Config) MoveToUserStatePath(elems ...string) (bool, error) {
+	if !conf.SharedRoot {
+		return false, ErrNotSharedRoot
+	}
+
+	legacyPath := filepath.Join(append([]string{conf.RootPath}, elems...)...)
+	if _, err := os.Stat(legacyPath); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	userPath := conf.UserStatePath(elems...)
+	if err := os.MkdirAll(filepath.Dir(userPath), fileperm.DirMode()); err != nil {
+		return false, err
+	}
+
+	return true, os.Rename(legacyPath, userPath)
+}
+
+// UserStatePath returns the path of a cache or state file, in a per user sub directory when root path is shared.
+func (conf *Config) UserStatePath(elems ...string) string {
+	if !conf.SharedRoot {
+		return filepath.Join(append([]string{conf.RootPath}, elems...)...)
+	}
+
+	return filepath.Join(append([]string{conf.RootPath, UsersDirName, userID()}, elems...)...)
+}
+
+// uid on unix like system, user name elsewhere.
+func userID() string {
+	if uid := os.Getuid(); uid >= 0 {
+		return strconv.Itoa(uid)
+	}
+
+	current, err := user.Current()
+	if err != nil || current.Username == "" {
+		return defaultUserID
+	}
+
+	return strings.NewReplacer("\\", "_", "/", "_").Replace(current.Username)
+}
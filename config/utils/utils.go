@@ -21,6 +21,7 @@ package configutils
 import (
 	"os"
 	"strconv"
+	"time"
 )
 
 func GetenvBool(defaultValue bool, key string) (bool, error) {
@@ -39,6 +40,33 @@ func GetenvBoolFallback(defaultValue bool, keys ...string) (bool, error) {
 	return defaultValue, nil
 }
 
+func GetenvDuration(defaultValue time.Duration, key string) (time.Duration, error) {
+	if valueStr := os.Getenv(key); valueStr != "" {
+		return time.ParseDuration(valueStr)
+	}
+
+	return defaultValue, nil
+}
+
+func GetenvInt(defaultValue int64, key string) (int64, error) {
+	if valueStr := os.Getenv(key); valueStr != "" {
+		return strconv.ParseInt(valueStr, 10, 64)
+	}
+
+	return defaultValue, nil
+}
+
+// GetenvOctal parses a permission mask (like "022"), the value can not exceed 777.
+func GetenvOctal(defaultValue int64, key string) (int64, error) {
+	if valueStr := os.Getenv(key); valueStr != "" {
+		value, err := strconv.ParseUint(valueStr, 8, 9)
+
+		return int64(value), err
+	}
+
+	return defaultValue, nil
+}
+
 func GetenvFallback(keys ...string) string {
 	for _, key := range keys {
 		if value := os.Getenv(key); value != "" {
@@ -24,6 +24,7 @@ const (
 	AssetsName          = "assets"
 	MsgFetchAllReleases = "Fetching all releases information from "
 	MsgFetchRelease     = "Fetching release information from "
+	MsgFetchReleases    = "Fetching releases information from "
 	MsgSearch           = "Search"
 )
 
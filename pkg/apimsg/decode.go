@@ -0,0 +1,157 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package apimsg
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const snippetMaxLen = 200
+
+// DecodeError describe an API response which is not valid JSON (like an HTML error page from a proxy or a truncated body).
+type DecodeError struct {
+	ContentType string
+	Err         error
+	Snippet     string
+	StatusCode  int
+}
+
+func (e DecodeError) Error() string {
+	var builder strings.Builder
+	builder.WriteString(ErrReturn.Error())
+	builder.WriteString(" (status ")
+	builder.WriteString(strconv.Itoa(e.StatusCode))
+	builder.WriteString(", content-type ")
+	builder.WriteString(strconv.Quote(e.ContentType))
+	builder.WriteString(") : ")
+	builder.WriteString(e.Err.Error())
+	builder.WriteString(", body start : ")
+	builder.WriteString(strconv.Quote(e.Snippet))
+
+	return builder.String()
+}
+
+func (e DecodeError) Unwrap() []error {
+	return []error{ErrReturn, e.Err}
+}
+
+// Decoder is a streaming JSON decoder of a response body (never fully kept in memory),
+// it records the start of body to describe decoding errors.
+type Decoder struct {
+	*json.Decoder
+	recorder *startRecorder
+	response *http.Response
+}
+
+func NewDecoder(response *http.Response) Decoder {
+	recorder := &startRecorder{reader: response.Body}
+
+	return Decoder{Decoder: json.NewDecoder(recorder), recorder: recorder, response: response}
+}
+
+// ExpectDelim read next token, a decoding failure is a DecodeError and another token is ErrReturn.
+func (d Decoder) ExpectDelim(delim json.Delim) error {
+	token, err := d.Token()
+	if err != nil {
+		return d.Wrap(err)
+	}
+
+	if found, ok := token.(json.Delim); !ok || found != delim {
+		return ErrReturn
+	}
+
+	return nil
+}
+
+// Wrap convert a decoding error to a DecodeError.
+func (d Decoder) Wrap(err error) error {
+	return DecodeError{ContentType: d.response.Header.Get("Content-Type"), Err: err, Snippet: snippet(d.recorder.start), StatusCode: d.response.StatusCode}
+}
+
+// Decode read and decode response body, on failure the error is a DecodeError.
+func Decode[T any](response *http.Response) (T, error) {
+	decoder := NewDecoder(response)
+
+	var value T
+	if err := decoder.Decode(&value); err != nil {
+		return value, decoder.Wrap(err)
+	}
+
+	return value, nil
+}
+
+// DecodeArray decode a JSON array in response body element by element, when stop is not nil, decoding ends after
+// the first element for which it returns true (remaining body is not read). On failure the error is a DecodeError.
+func DecodeArray[T any](response *http.Response, stop func(T) bool) ([]T, error) {
+	decoder := NewDecoder(response)
+	if err := decoder.ExpectDelim('['); err != nil {
+		return nil, err
+	}
+
+	var values []T
+	for decoder.More() {
+		var value T
+		if err := decoder.Decode(&value); err != nil {
+			return nil, decoder.Wrap(err)
+		}
+		values = append(values, value)
+
+		if stop != nil && stop(value) {
+			return values, nil
+		}
+	}
+
+	// closing delimiter detects a truncated body
+	if _, err := decoder.Token(); err != nil {
+		return nil, decoder.Wrap(err)
+	}
+
+	return values, nil
+}
+
+type startRecorder struct {
+	reader io.Reader
+	start  []byte
+}
+
+func (r *startRecorder) Read(buffer []byte) (int, error) {
+	n, err := r.reader.Read(buffer)
+	// a few more bytes than snippet, to let it cut on a character boundary
+	if missing := snippetMaxLen + utf8.UTFMax - len(r.start); missing > 0 {
+		r.start = append(r.start, buffer[:min(n, missing)]...)
+	}
+
+	return n, err
+}
+
+func snippet(data []byte) string {
+	if len(data) > snippetMaxLen {
+		data = data[:snippetMaxLen]
+		for len(data) != 0 && !utf8.Valid(data) {
+			data = data[:len(data)-1] // do not cut a multi-byte character
+		}
+	}
+
+	return strings.Join(strings.Fields(string(data)), " ")
+}
@@ -0,0 +1,311 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package audit
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	AlgorithmEd25519 = "ed25519"
+
+	StatusCompliant    = "compliant"
+	StatusNonCompliant = "non-compliant"
+)
+
+var (
+	ErrAdvisory     = errors.New("pinned version affected by an advisory at or above severity threshold")
+	ErrKey          = errors.New("signing key must be an ed25519 private key in PKCS #8 PEM format")
+	ErrNonCompliant = errors.New("at least one installed version is not compliant")
+	ErrPublicKey    = errors.New("public key must be an ed25519 public key in PKIX PEM format or base64 encoded")
+	ErrSeverity     = errors.New("unknown severity, expected low, medium, high or critical")
+	ErrSignature    = errors.New("audit report signature does not match")
+)
+
+var severityRanks = map[string]int{"low": 1, "medium": 2, "moderate": 2, "high": 3, "critical": 4} //nolint
+
+// Advisory is a published security advisory whose vulnerable range includes an installed version.
+type Advisory struct {
+	ID              string `json:"id"`
+	CVE             string `json:"cve,omitempty"`
+	Severity        string `json:"severity"`
+	Summary         string `json:"summary"`
+	URL             string `json:"url"`
+	VulnerableRange string `json:"vulnerable_range"`
+	FixedVersion    string `json:"fixed_version,omitempty"`
+}
+
+type FileDigest struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+}
+
+// Entry describe one installed version.
+type Entry struct {
+	Tool         string       `json:"tool"`
+	Version      string       `json:"version"`
+	Source       string       `json:"source,omitempty"`
+	Checksum     bool         `json:"checksum"`
+	Signature    string       `json:"signature,omitempty"`
+	Provenance   string       `json:"provenance,omitempty"`
+	Unverifiable bool         `json:"unverifiable,omitempty"`
+	PostInstall  []string     `json:"post_install,omitempty"`
+	Files        []FileDigest `json:"files"`
+	Status       string       `json:"status"`
+	Reason       string       `json:"reason,omitempty"`
+	Package      string       `json:"package,omitempty"` // Go module path, set with advisories
+	Pinned       bool         `json:"pinned,omitempty"`  // version resolved for the working directory
+	Advisories   []Advisory   `json:"advisories,omitempty"`
+}
+
+type ReportSignature struct {
+	Algorithm string `json:"algorithm"`
+	PublicKey string `json:"public_key"`
+	Value     string `json:"value"`
+}
+
+type Report struct {
+	GeneratedAt time.Time        `json:"generated_at"`
+	TenvVersion string           `json:"tenv_version"`
+	Entries     []Entry          `json:"entries"`
+	Signature   *ReportSignature `json:"signature,omitempty"`
+}
+
+func MakeReport(tenvVersion string, entries []Entry) Report {
+	return Report{GeneratedAt: time.Now().UTC(), TenvVersion: tenvVersion, Entries: entries}
+}
+
+func (r Report) Compliant() bool {
+	for _, entry := range r.Entries {
+		if entry.Status != StatusCompliant {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ValidSeverity reports whether severity is known (case insensitive).
+func ValidSeverity(severity string) bool {
+	_, ok := severityRanks[strings.ToLower(severity)]
+
+	return ok
+}
+
+// CheckPinned returns an error listing advisories of pinned versions with a severity at or above threshold.
+func (r Report) CheckPinned(threshold string) error {
+	if !ValidSeverity(threshold) {
+		return ErrSeverity
+	}
+	thresholdRank := severityRanks[strings.ToLower(threshold)]
+
+	var found []string
+	for _, entry := range r.Entries {
+		if !entry.Pinned {
+			continue
+		}
+
+		for _, advisory := range entry.Advisories {
+			if severityRanks[strings.ToLower(advisory.Severity)] >= thresholdRank {
+				found = append(found, entry.Tool+" "+entry.Version+" "+advisory.ID+" ("+advisory.Severity+")")
+			}
+		}
+	}
+
+	if len(found) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%w : %s", ErrAdvisory, strings.Join(found, ", "))
+}
+
+func (r Report) Marshal() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// Markdown render the report with one table by tool (easy to convert to PDF).
+func (r Report) Markdown() string {
+	var builder strings.Builder
+	builder.WriteString("# tenv audit report\n\n")
+	builder.WriteString("- Generated at : ")
+	builder.WriteString(r.GeneratedAt.Format(time.RFC3339))
+	builder.WriteString("\n- tenv version : ")
+	builder.WriteString(r.TenvVersion)
+	builder.WriteString("\n- Installed versions : ")
+	builder.WriteString(strconv.Itoa(len(r.Entries)))
+	builder.WriteString("\n- Compliant : ")
+	builder.WriteString(strconv.FormatBool(r.Compliant()))
+	builder.WriteString("\n")
+
+	lastTool := ""
+	for _, entry := range r.Entries {
+		if entry.Tool != lastTool {
+			lastTool = entry.Tool
+			builder.WriteString("\n## ")
+			builder.WriteString(entry.Tool)
+			builder.WriteString("\n\n| Version | Status | Checksum | Signature | Source | SHA-256 |\n|---|---|---|---|---|---|\n")
+		}
+
+		status := entry.Status
+		if entry.Reason != "" {
+			status += " (" + entry.Reason + ")"
+		}
+
+		digests := make([]string, 0, len(entry.Files))
+		for _, file := range entry.Files {
+			digests = append(digests, "`"+file.Path+"` "+file.SHA256)
+		}
+
+		writeRow(&builder, entry.Version, status, strconv.FormatBool(entry.Checksum), entry.Signature, entry.Source, strings.Join(digests, "<br>"))
+	}
+
+	writeAdvisories(&builder, r.Entries)
+
+	if r.Signature != nil {
+		builder.WriteString("\n## Signature\n\n- Algorithm : ")
+		builder.WriteString(r.Signature.Algorithm)
+		builder.WriteString("\n- Public key : `")
+		builder.WriteString(r.Signature.PublicKey)
+		builder.WriteString("`\n- Value : `")
+		builder.WriteString(r.Signature.Value)
+		builder.WriteString("`\n")
+	}
+
+	return builder.String()
+}
+
+// Sign the report content (without signature) with an ed25519 private key (PKCS #8 PEM).
+func (r *Report) Sign(keyPEM []byte) error {
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return ErrKey
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return err
+	}
+
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return ErrKey
+	}
+
+	r.Signature = nil
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	publicKey, _ := privateKey.Public().(ed25519.PublicKey)
+	r.Signature = &ReportSignature{
+		Algorithm: AlgorithmEd25519,
+		PublicKey: base64.StdEncoding.EncodeToString(publicKey),
+		Value:     base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, data)),
+	}
+
+	return nil
+}
+
+// ParsePublicKey reads an ed25519 public key in PKIX PEM format (like generated by `openssl pkey -pubout`)
+// or base64 encoded (like embedded in signed reports).
+func ParsePublicKey(data []byte) (ed25519.PublicKey, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		publicKey, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return nil, ErrPublicKey
+		}
+
+		return publicKey, nil
+	}
+
+	publicKey, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return nil, ErrPublicKey
+	}
+
+	return publicKey, nil
+}
+
+// Verify check the signature against the report content with a trusted public key obtained out of band
+// (the embedded public key is informative only : anyone able to edit the report could sign it again with its own key).
+func (r Report) Verify(trustedKey ed25519.PublicKey) error {
+	if r.Signature == nil || r.Signature.Algorithm != AlgorithmEd25519 || len(trustedKey) != ed25519.PublicKeySize {
+		return ErrSignature
+	}
+
+	value, err := base64.StdEncoding.DecodeString(r.Signature.Value)
+	if err != nil {
+		return err
+	}
+
+	r.Signature = nil
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	if !ed25519.Verify(trustedKey, data, value) {
+		return ErrSignature
+	}
+
+	return nil
+}
+
+func writeAdvisories(builder *strings.Builder, entries []Entry) {
+	header := false
+	for _, entry := range entries {
+		for _, advisory := range entry.Advisories {
+			if !header {
+				header = true
+				builder.WriteString("\n## Advisories\n\n| Tool | Version | Advisory | Severity | Fixed in | Summary |\n|---|---|---|---|---|---|\n")
+			}
+
+			id := advisory.ID
+			if advisory.CVE != "" {
+				id += " (" + advisory.CVE + ")"
+			}
+			writeRow(builder, entry.Tool, entry.Version, "["+id+"]("+advisory.URL+")", advisory.Severity, advisory.FixedVersion, advisory.Summary)
+		}
+	}
+}
+
+func writeRow(builder *strings.Builder, cells ...string) {
+	builder.WriteString("|")
+	for _, cell := range cells {
+		builder.WriteString(" ")
+		builder.WriteString(strings.ReplaceAll(cell, "|", "\\|"))
+		builder.WriteString(" |")
+	}
+	builder.WriteString("\n")
+}
@@ -0,0 +1,166 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package audit_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/pkg/audit"
+)
+
+func TestSignVerify(t *testing.T) {
+	t.Parallel()
+
+	publicKey, privateKeyPEM := generateKey(t)
+	foreignPublicKey, foreignPrivateKeyPEM := generateKey(t)
+
+	report := audit.MakeReport("v1.0.0", []audit.Entry{{Tool: "tofu", Version: "1.6.2", Checksum: true, Signature: "cosign", Status: audit.StatusCompliant}})
+	if err := report.Sign(privateKeyPEM); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if err := report.Verify(publicKey); err != nil {
+		t.Error("Unexpected error :", err)
+	}
+
+	if err := report.Verify(foreignPublicKey); err != audit.ErrSignature {
+		t.Error("Should fail with another trusted key, get :", err)
+	}
+
+	report.Entries[0].Version = "1.6.3"
+	if err := report.Verify(publicKey); err != audit.ErrSignature {
+		t.Error("Should fail on altered report, get :", err)
+	}
+
+	// altered report signed again with a foreign key (embedded in report)
+	if err := report.Sign(foreignPrivateKeyPEM); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if err := report.Verify(publicKey); err != audit.ErrSignature {
+		t.Error("Should fail on report signed with a foreign key, get :", err)
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	t.Parallel()
+
+	publicKey, _ := generateKey(t)
+	keyData, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	for _, data := range [][]byte{pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: keyData}), []byte(base64.StdEncoding.EncodeToString(publicKey) + "\n")} {
+		if parsed, err := audit.ParsePublicKey(data); err != nil || !publicKey.Equal(parsed) {
+			t.Error("Unmatching result, get :", parsed, err)
+		}
+	}
+
+	if _, err = audit.ParsePublicKey([]byte("not a key")); err != audit.ErrPublicKey {
+		t.Error("Should fail on invalid key, get :", err)
+	}
+}
+
+func generateKey(t *testing.T) (ed25519.PublicKey, []byte) {
+	t.Helper()
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	keyData, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	return publicKey, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyData})
+}
+
+func TestMarkdown(t *testing.T) {
+	t.Parallel()
+
+	report := audit.MakeReport("v1.0.0", []audit.Entry{
+		{Tool: "tofu", Version: "1.6.2", Checksum: true, Signature: "cosign", Status: audit.StatusCompliant},
+		{Tool: "terraform", Version: "1.5.7", Status: audit.StatusNonCompliant, Reason: "no manifest"},
+	})
+
+	markdown := report.Markdown()
+	if !strings.Contains(markdown, "## tofu") || !strings.Contains(markdown, "| 1.5.7 | non-compliant (no manifest) |") || !strings.Contains(markdown, "- Compliant : false") {
+		t.Error("Unmatching result, get :", markdown)
+	}
+}
+
+func TestAdvisories(t *testing.T) {
+	t.Parallel()
+
+	advisory := audit.Advisory{ID: "GHSA-xxxx", CVE: "CVE-2024-0001", Severity: "high", VulnerableRange: "< 1.6.3", FixedVersion: "1.6.3"}
+	report := audit.MakeReport("v1.0.0", []audit.Entry{
+		{Tool: "OpenTofu", Version: "1.6.1", Package: "github.com/opentofu/opentofu", Advisories: []audit.Advisory{advisory}},
+		{Tool: "OpenTofu", Version: "1.6.2", Package: "github.com/opentofu/opentofu", Pinned: true, Advisories: []audit.Advisory{advisory}},
+	})
+
+	if err := report.CheckPinned("critical"); err != nil {
+		t.Error("Unexpected error :", err)
+	}
+
+	if err := report.CheckPinned("High"); !errors.Is(err, audit.ErrAdvisory) || !strings.Contains(err.Error(), "OpenTofu 1.6.2 GHSA-xxxx") {
+		t.Error("Should fail on pinned version advisory, get :", err)
+	}
+
+	if err := report.CheckPinned("urgent"); !errors.Is(err, audit.ErrSeverity) {
+		t.Error("Should fail on unknown severity, get :", err)
+	}
+
+	data, err := report.CycloneDX("v1.0.0")
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	var document struct {
+		BOMFormat       string `json:"bomFormat"`
+		Vulnerabilities []struct {
+			ID      string `json:"id"`
+			Affects []struct {
+				Ref string `json:"ref"`
+			} `json:"affects"`
+		} `json:"vulnerabilities"`
+	}
+	if err = json.Unmarshal(data, &document); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if document.BOMFormat != "CycloneDX" || len(document.Vulnerabilities) != 1 || document.Vulnerabilities[0].ID != "CVE-2024-0001" || len(document.Vulnerabilities[0].Affects) != 2 ||
+		document.Vulnerabilities[0].Affects[1].Ref != "pkg:golang/github.com/opentofu/opentofu@v1.6.2" {
+		t.Error("Unmatching result, get :", string(data))
+	}
+
+	if markdown := report.Markdown(); !strings.Contains(markdown, "## Advisories") || !strings.Contains(markdown, "| OpenTofu | 1.6.2 | [GHSA-xxxx (CVE-2024-0001)]") {
+		t.Error("Unmatching result, get :", markdown)
+	}
+}
@@ -0,0 +1,156 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package audit
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+const (
+	cycloneDXFormat  = "CycloneDX"
+	cycloneDXSpec    = "1.5"
+	advisorySource   = "GitHub Advisory Database"
+	affectedStatus   = "affected"
+	inTriageState    = "in_triage"
+	purlGolangPrefix = "pkg:golang/"
+)
+
+type cdxDocument struct {
+	BOMFormat       string             `json:"bomFormat"`
+	SpecVersion     string             `json:"specVersion"`
+	Version         int                `json:"version"`
+	Metadata        cdxMetadata        `json:"metadata"`
+	Components      []cdxComponent     `json:"components"`
+	Vulnerabilities []cdxVulnerability `json:"vulnerabilities"`
+}
+
+type cdxMetadata struct {
+	Timestamp string                    `json:"timestamp"`
+	Tools     map[string][]cdxComponent `json:"tools"`
+}
+
+type cdxComponent struct {
+	Type    string `json:"type"`
+	BOMRef  string `json:"bom-ref,omitempty"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	PURL    string `json:"purl,omitempty"`
+}
+
+type cdxSource struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+type cdxRating struct {
+	Source   cdxSource `json:"source"`
+	Severity string    `json:"severity"`
+}
+
+type cdxReference struct {
+	ID     string    `json:"id"`
+	Source cdxSource `json:"source"`
+}
+
+type cdxAnalysis struct {
+	State  string `json:"state"`
+	Detail string `json:"detail"`
+}
+
+type cdxVersion struct {
+	Version string `json:"version"`
+	Status  string `json:"status"`
+}
+
+type cdxAffect struct {
+	Ref      string       `json:"ref"`
+	Versions []cdxVersion `json:"versions"`
+}
+
+type cdxVulnerability struct {
+	ID             string         `json:"id"`
+	Source         cdxSource      `json:"source"`
+	References     []cdxReference `json:"references,omitempty"`
+	Ratings        []cdxRating    `json:"ratings"`
+	Description    string         `json:"description"`
+	Recommendation string         `json:"recommendation,omitempty"`
+	Analysis       cdxAnalysis    `json:"analysis"`
+	Affects        []cdxAffect    `json:"affects"`
+}
+
+// CycloneDX returns a CycloneDX VEX document (JSON) with installed versions as components
+// and their advisories as vulnerabilities (one by advisory, affecting every installed version in its range).
+func (r Report) CycloneDX(tenvVersion string) ([]byte, error) {
+	document := cdxDocument{
+		BOMFormat: cycloneDXFormat, SpecVersion: cycloneDXSpec, Version: 1,
+		Metadata: cdxMetadata{
+			Timestamp: r.GeneratedAt.Format(time.RFC3339),
+			Tools:     map[string][]cdxComponent{"components": {{Type: "application", Name: "tenv", Version: tenvVersion}}},
+		},
+		Components: make([]cdxComponent, 0, len(r.Entries)), Vulnerabilities: []cdxVulnerability{},
+	}
+
+	vulnIndexes := map[string]int{}
+	for _, entry := range r.Entries {
+		component := cdxComponent{Type: "application", BOMRef: entry.Tool + "@" + entry.Version, Name: entry.Tool, Version: entry.Version}
+		if entry.Package != "" {
+			component.PURL = purlGolangPrefix + entry.Package + "@v" + entry.Version
+			component.BOMRef = component.PURL
+		}
+		document.Components = append(document.Components, component)
+
+		for _, advisory := range entry.Advisories {
+			affect := cdxAffect{Ref: component.BOMRef, Versions: []cdxVersion{{Version: entry.Version, Status: affectedStatus}}}
+			if index, ok := vulnIndexes[advisory.ID]; ok {
+				document.Vulnerabilities[index].Affects = append(document.Vulnerabilities[index].Affects, affect)
+
+				continue
+			}
+
+			vulnIndexes[advisory.ID] = len(document.Vulnerabilities)
+			document.Vulnerabilities = append(document.Vulnerabilities, makeVulnerability(advisory, affect))
+		}
+	}
+
+	return json.MarshalIndent(document, "", "  ")
+}
+
+// installed versions are only known to be in vulnerable range, exploitability is left to analysis.
+func makeVulnerability(advisory Advisory, affect cdxAffect) cdxVulnerability {
+	source := cdxSource{Name: advisorySource, URL: advisory.URL}
+	vulnerability := cdxVulnerability{
+		ID: advisory.ID, Source: source, Description: advisory.Summary,
+		Ratings:  []cdxRating{{Source: source, Severity: strings.Replace(strings.ToLower(advisory.Severity), "moderate", "medium", 1)}},
+		Analysis: cdxAnalysis{State: inTriageState, Detail: "installed version in vulnerable range " + advisory.VulnerableRange},
+		Affects:  []cdxAffect{affect},
+	}
+
+	if advisory.CVE != "" {
+		vulnerability.ID = advisory.CVE
+		vulnerability.References = []cdxReference{{ID: advisory.ID, Source: source}}
+	}
+
+	if advisory.FixedVersion != "" {
+		vulnerability.Recommendation = "Upgrade to " + advisory.FixedVersion + " or later"
+	}
+
+	return vulnerability
+}
@@ -1,3 +1,5 @@
+//go:build !minimal && !no_cosign
+
 /*
  *
  * Copyright 2024 tofuutils authors.
@@ -19,13 +21,13 @@
 package cosigncheck
 
 import (
-	"errors"
 	"os"
 	"os/exec"
 	"strings"
 
 	"github.com/hashicorp/go-hclog"
 
+	"github.com/tofuutils/tenv/v2/pkg/feature"
 	"github.com/tofuutils/tenv/v2/pkg/loghelper"
 )
 
@@ -34,10 +36,9 @@ const (
 	verified       = "Verified OK"
 )
 
-var (
-	ErrCheck        = errors.New("cosign check failed")
-	ErrNotInstalled = errors.New("cosign executable not found")
-)
+func init() {
+	feature.Register("cosign")
+}
 
 func Check(data []byte, dataSig []byte, dataCert []byte, certIdentity string, certOidcIssuer string, displayer loghelper.Displayer) error {
 	_, err := exec.LookPath(cosignExecName)
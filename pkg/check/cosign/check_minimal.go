@@ -0,0 +1,28 @@
+//go:build minimal || no_cosign
+
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cosigncheck
+
+import "github.com/tofuutils/tenv/v2/pkg/loghelper"
+
+// Check behave like a missing cosign executable (signature check is then skipped with a warning).
+func Check(_ []byte, _ []byte, _ []byte, _ string, _ string, _ loghelper.Displayer) error {
+	return ErrNotInstalled
+}
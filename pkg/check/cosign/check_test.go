@@ -1,3 +1,5 @@
+//go:build !minimal && !no_cosign
+
 /*
  *
  * Copyright 2024 tofuutils authors.
@@ -18,21 +18,64 @@
 
 package pgpcheck
 
-import "github.com/ProtonMail/gopenpgp/v2/crypto"
+import (
+	"errors"
+	"fmt"
+	"strings"
 
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+)
+
+const publicKeyHeader = "-----BEGIN PGP PUBLIC KEY BLOCK-----"
+
+var (
+	ErrCheck  = errors.New("pgp check failed")
+	ErrStrict = errors.New("pgp check skipped, installation refused in strict mode")
+)
+
+// Check verify dataSig with dataPublicKey, which can be a keyring (several concatenated armored public keys).
 func Check(data []byte, dataSig []byte, dataPublicKey []byte) error {
 	pgpSignature := crypto.NewPGPSignature(dataSig)
-	publicKeyObj, err := crypto.NewKeyFromArmored(string(dataPublicKey))
+	signingKeyRing, err := readKeyRing(string(dataPublicKey))
 	if err != nil {
 		return err
 	}
 
-	signingKeyRing, err := crypto.NewKeyRing(publicKeyObj)
+	message := crypto.NewPlainMessage(data)
+
+	if err = signingKeyRing.VerifyDetached(message, pgpSignature, crypto.GetUnixTime()); err != nil {
+		return fmt.Errorf("%w : %w", ErrCheck, err)
+	}
+
+	return nil
+}
+
+func readKeyRing(armored string) (*crypto.KeyRing, error) {
+	keyRing, err := crypto.NewKeyRing(nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	message := crypto.NewPlainMessage(data)
+	blocks := strings.Split(armored, publicKeyHeader)
+	if len(blocks) < 3 { // zero or one key, parsed as is (armor decoding skips leading text)
+		publicKeyObj, err := crypto.NewKeyFromArmored(armored)
+		if err != nil {
+			return nil, err
+		}
+
+		return keyRing, keyRing.AddKey(publicKeyObj)
+	}
+
+	for _, block := range blocks[1:] {
+		publicKeyObj, err := crypto.NewKeyFromArmored(publicKeyHeader + block)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = keyRing.AddKey(publicKeyObj); err != nil {
+			return nil, err
+		}
+	}
 
-	return signingKeyRing.VerifyDetached(message, pgpSignature, crypto.GetUnixTime())
+	return keyRing, nil
 }
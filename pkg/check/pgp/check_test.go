@@ -22,6 +22,8 @@ import (
 	_ "embed"
 	"testing"
 
+	"github.com/ProtonMail/gopenpgp/v2/crypto"
+
 	pgpcheck "github.com/tofuutils/tenv/v2/pkg/check/pgp"
 )
 
@@ -57,3 +59,57 @@ func TestPgpCheckErrorSig(t *testing.T) {
 		t.Error("Should fail on erroneous signature")
 	}
 }
+
+func TestPgpCheckKeyRing(t *testing.T) {
+	t.Parallel()
+
+	signerArmored, signature := signWithNewKey(t)
+
+	// signing key is not the first one of the keyring
+	keyRing := append(append([]byte{}, dataKey...), []byte("\n"+signerArmored)...)
+	if err := pgpcheck.Check(data, signature, keyRing); err != nil {
+		t.Error("Unexpected error : ", err)
+	}
+
+	if pgpcheck.Check(data, signature, dataKey) == nil {
+		t.Error("Should fail without signing key")
+	}
+}
+
+func TestPgpCheckLeadingText(t *testing.T) {
+	t.Parallel()
+
+	signerArmored, signature := signWithNewKey(t)
+
+	// like a key file downloaded from a web page, with text before armor
+	keyFile := []byte("Public key of release signer, fingerprint below.\n\n" + signerArmored)
+	if err := pgpcheck.Check(data, signature, keyFile); err != nil {
+		t.Error("Unexpected error : ", err)
+	}
+}
+
+func signWithNewKey(t *testing.T) (string, []byte) {
+	t.Helper()
+
+	signingKey, err := crypto.GenerateKey("signer", "signer@example.com", "x25519", 0)
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	signingKeyRing, err := crypto.NewKeyRing(signingKey)
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	signature, err := signingKeyRing.SignDetached(crypto.NewPlainMessage(data))
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	signerArmored, err := signingKey.GetArmoredPublicKey()
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	return signerArmored, signature.GetBinary()
+}
@@ -32,13 +32,19 @@ var (
 )
 
 func Check(data []byte, dataSums []byte, fileName string) error {
+	hashed := sha256.Sum256(data)
+
+	return CheckSum(hashed[:], dataSums, fileName)
+}
+
+// CheckSum compare an already computed sha256 (like one from a streamed download).
+func CheckSum(hashed []byte, dataSums []byte, fileName string) error {
 	dataSum, err := extract(dataSums, fileName)
 	if err != nil {
 		return err
 	}
 
-	hashed := sha256.Sum256(data)
-	if !bytes.Equal(dataSum, hashed[:]) {
+	if !bytes.Equal(dataSum, hashed) {
 		return ErrCheck
 	}
 
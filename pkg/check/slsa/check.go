@@ -0,0 +1,100 @@
+//go:build !minimal && !no_slsa
+
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package slsacheck
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
+
+	"github.com/tofuutils/tenv/v2/pkg/feature"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+)
+
+const (
+	slsaExecName = "slsa-verifier"
+	verified     = "PASSED: Verified SLSA provenance"
+)
+
+func init() {
+	feature.Register("slsa")
+}
+
+// Check that provenance attests data has been built by builderID from sourceURI (like "github.com/opentofu/opentofu") at sourceTag.
+func Check(data []byte, provenance []byte, sourceURI string, sourceTag string, builderID string, displayer loghelper.Displayer) error {
+	_, err := exec.LookPath(slsaExecName)
+	if err != nil {
+		return ErrNotInstalled
+	}
+
+	dataFileName, remove, err := tempFile("data", data)
+	if err != nil {
+		return err
+	}
+	defer remove()
+
+	provenanceFileName, remove, err := tempFile("provenance.intoto.jsonl", provenance)
+	if err != nil {
+		return err
+	}
+	defer remove()
+
+	cmdArgs := []string{
+		"verify-artifact", "--provenance-path", provenanceFileName, "--source-uri", sourceURI, "--source-tag", sourceTag,
+		"--builder-id", builderID, dataFileName,
+	}
+
+	var outBuffer, errBuffer strings.Builder
+	cmd := exec.Command(slsaExecName, cmdArgs...)
+	cmd.Stdout = &outBuffer
+	cmd.Stderr = &errBuffer
+
+	cmd.Run() //nolint
+
+	stdOutContent, stdErrContent := outBuffer.String(), errBuffer.String()
+
+	displayer.Log(hclog.Debug, "slsa-verifier output", "stdOut", stdOutContent, "stdErr", stdErrContent)
+
+	if !strings.Contains(stdOutContent, verified) && !strings.Contains(stdErrContent, verified) {
+		return ErrCheck
+	}
+
+	return nil
+}
+
+func tempFile(name string, data []byte) (string, func(), error) {
+	tmpFile, err := os.CreateTemp("", name)
+	if err != nil {
+		return "", nil, err
+	}
+
+	tmpFileName := tmpFile.Name()
+	tmpFile.Close()
+	if err = os.WriteFile(tmpFileName, data, 0o600); err != nil {
+		return "", nil, err
+	}
+
+	return tmpFileName, func() {
+		os.Remove(tmpFileName)
+	}, nil
+}
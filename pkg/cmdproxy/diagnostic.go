@@ -0,0 +1,149 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmdproxy
+
+import (
+	"bytes"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+)
+
+const headerSize = 256
+
+var (
+	elfArchs = map[elf.Machine]string{ //nolint
+		elf.EM_386: "386", elf.EM_X86_64: "amd64", elf.EM_ARM: "arm", elf.EM_AARCH64: "arm64",
+		elf.EM_PPC64: "ppc64", elf.EM_RISCV: "riscv64", elf.EM_S390: "s390x",
+	}
+	machoArchs = map[macho.Cpu]string{ //nolint
+		macho.Cpu386: "386", macho.CpuAmd64: "amd64", macho.CpuArm: "arm", macho.CpuArm64: "arm64",
+	}
+	peArchs = map[uint16]string{ //nolint
+		pe.IMAGE_FILE_MACHINE_I386: "386", pe.IMAGE_FILE_MACHINE_AMD64: "amd64",
+		pe.IMAGE_FILE_MACHINE_ARMNT: "arm", pe.IMAGE_FILE_MACHINE_ARM64: "arm64",
+	}
+)
+
+// Diagnose describes the state of a file which can not be executed
+// (existence, permissions, binary format and architecture, noexec mount).
+func Diagnose(filePath string) []string {
+	lines := []string{"path : " + filePath}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return append(lines, "state : "+err.Error())
+	}
+
+	if info.IsDir() {
+		return append(lines, "file type : directory")
+	}
+
+	permissions := "permissions : " + info.Mode().String()
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o111 == 0 {
+		permissions += " (not executable)"
+	}
+	lines = append(lines, permissions, "file type : "+describeFile(filePath, info.Size()))
+
+	if mountPoint := noexecMountPoint(filePath); mountPoint != "" {
+		lines = append(lines, "mount : "+mountPoint+" (noexec)")
+	}
+
+	return lines
+}
+
+func describeFile(filePath string, size int64) string {
+	if size == 0 {
+		return "empty file (interrupted installation ?)"
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "unreadable (" + err.Error() + ")"
+	}
+	defer file.Close()
+
+	header := make([]byte, headerSize)
+	n, _ := io.ReadFull(file, header)
+	header = header[:n]
+
+	switch {
+	case bytes.HasPrefix(header, []byte("#!")):
+		return describeScript(header)
+	case bytes.HasPrefix(header, []byte(elf.ELFMAG)):
+		if elfFile, err := elf.NewFile(file); err == nil {
+			return describeBinary("ELF", archName(elfArchs, elfFile.Machine, elfFile.Machine.String()), "linux")
+		}
+	case bytes.HasPrefix(header, []byte("MZ")):
+		if peFile, err := pe.NewFile(file); err == nil {
+			return describeBinary("PE", archName(peArchs, peFile.Machine, "unknown"), "windows")
+		}
+	default:
+		if fatFile, err := macho.NewFatFile(file); err == nil {
+			archs := make([]string, 0, len(fatFile.Arches))
+			for _, arch := range fatFile.Arches {
+				archs = append(archs, archName(machoArchs, arch.Cpu, arch.Cpu.String()))
+			}
+
+			return describeBinary("Mach-O universal", strings.Join(archs, "/"), "darwin")
+		}
+
+		if machoFile, err := macho.NewFile(file); err == nil {
+			return describeBinary("Mach-O", archName(machoArchs, machoFile.Cpu, machoFile.Cpu.String()), "darwin")
+		}
+	}
+
+	return "unknown format (not an executable for " + runtime.GOOS + "/" + runtime.GOARCH + ")"
+}
+
+func describeScript(header []byte) string {
+	line, _, _ := bytes.Cut(header[2:], []byte("\n"))
+	fields := strings.Fields(string(line))
+	if len(fields) == 0 {
+		return "script without interpreter"
+	}
+
+	interpreter := fields[0]
+	if _, err := os.Stat(interpreter); err != nil {
+		return "script, interpreter " + interpreter + " not found"
+	}
+
+	return "script, interpreter " + interpreter
+}
+
+// ELF binaries are reported as linux ones (the most common case for tenv users).
+func describeBinary(format string, arch string, goos string) string {
+	description := format + " " + arch
+	if goos != runtime.GOOS || !strings.Contains(arch, runtime.GOARCH) {
+		description += " (mismatch with current platform " + runtime.GOOS + "/" + runtime.GOARCH + ")"
+	}
+
+	return description
+}
+
+func archName[K comparable](archs map[K]string, key K, defaultName string) string {
+	if name, ok := archs[key]; ok {
+		return name
+	}
+
+	return defaultName
+}
@@ -0,0 +1,80 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmdproxy_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/pkg/cmdproxy"
+)
+
+func TestDiagnose(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("permissions and scripts are not diagnosed on windows")
+	}
+
+	dirPath := t.TempDir()
+	emptyPath := filepath.Join(dirPath, "empty")
+	scriptPath := filepath.Join(dirPath, "script")
+	if err := os.WriteFile(emptyPath, nil, 0o644); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+	if err := os.WriteFile(scriptPath, []byte("#!/missing/interpreter\necho\n"), 0o755); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	selfPath, err := os.Executable()
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	tests := []struct {
+		name     string
+		filePath string
+		want     []string
+	}{
+		{name: "missing", filePath: filepath.Join(dirPath, "missing"), want: []string{"state : "}},
+		{name: "empty", filePath: emptyPath, want: []string{"(not executable)", "empty file"}},
+		{name: "script", filePath: scriptPath, want: []string{"interpreter /missing/interpreter not found"}},
+		{name: "native", filePath: selfPath, want: []string{"file type : "}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			diagnostic := strings.Join(cmdproxy.Diagnose(tt.filePath), "\n")
+			for _, want := range tt.want {
+				if !strings.Contains(diagnostic, want) {
+					t.Errorf("Diagnostic should contain %q, get : %s", want, diagnostic)
+				}
+			}
+			if tt.name == "native" && strings.Contains(diagnostic, "mismatch") {
+				t.Error("Current executable should match current platform, get :", diagnostic)
+			}
+		})
+	}
+}
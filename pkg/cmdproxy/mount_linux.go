@@ -0,0 +1,75 @@
+//go:build linux
+
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmdproxy
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const mountInfoPath = "/proc/self/mountinfo"
+
+var mountEscaper = strings.NewReplacer(`\040`, " ", `\011`, "\t", `\012`, "\n", `\134`, `\`) //nolint
+
+// return the mount point containing filePath when it is mounted with noexec option (empty otherwise).
+func noexecMountPoint(filePath string) string {
+	resolvedPath, err := filepath.EvalSymlinks(filePath)
+	if err != nil {
+		return ""
+	}
+
+	file, err := os.Open(mountInfoPath)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	// fields : id, parent id, major:minor, root, mount point, mount options, ...
+	bestMountPoint, noexec := "", false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 6 {
+			continue
+		}
+
+		mountPoint := mountEscaper.Replace(fields[4])
+		if len(mountPoint) <= len(bestMountPoint) || !inMountPoint(resolvedPath, mountPoint) {
+			continue
+		}
+
+		bestMountPoint = mountPoint
+		noexec = slices.Contains(strings.Split(fields[5], ","), "noexec")
+	}
+
+	if !noexec {
+		return ""
+	}
+
+	return bestMountPoint
+}
+
+func inMountPoint(filePath string, mountPoint string) bool {
+	return mountPoint == "/" || filePath == mountPoint || strings.HasPrefix(filePath, mountPoint+"/")
+}
@@ -26,13 +26,17 @@ import (
 	"os"
 	"os/exec"
 	"os/signal"
+	"path/filepath"
 	"strconv"
 	"strings"
+
+	"github.com/tofuutils/tenv/v2/pkg/fileperm"
 )
 
 var errDelimiter = errors.New("key and value should not contains delimiter")
 
-func Run(execPath string, cmdArgs []string, gha bool) {
+// Run call execPath with cmdArgs, env entries (KEY=value) are added to the current environment.
+func Run(execPath string, cmdArgs []string, env []string, gha bool) {
 	exitCode := 0
 	defer func() {
 		os.Exit(exitCode)
@@ -40,6 +44,9 @@ func Run(execPath string, cmdArgs []string, gha bool) {
 
 	// proxy to selected version
 	cmd := exec.Command(execPath, cmdArgs...)
+	if len(env) != 0 {
+		cmd.Env = append(os.Environ(), env...) // last value of a duplicated key is used
+	}
 	done, err := initIO(cmd, execPath, &exitCode, gha)
 	if err != nil {
 		exitWithErrorMsg(execPath, err, &exitCode)
@@ -50,6 +57,7 @@ func Run(execPath string, cmdArgs []string, gha bool) {
 
 	if err = cmd.Start(); err != nil {
 		exitWithErrorMsg(execPath, err, &exitCode)
+		displayDiagnostic(cmd.Path)
 
 		return
 	}
@@ -76,6 +84,18 @@ func exitWithErrorMsg(execName string, err error, pExitCode *int) {
 	}
 }
 
+// only resolved path are diagnosed (a command not found in PATH is already explicit).
+func displayDiagnostic(execPath string) {
+	if !filepath.IsAbs(execPath) {
+		return
+	}
+
+	fmt.Println("Diagnostic :") //nolint
+	for _, line := range Diagnose(execPath) {
+		fmt.Println(" ", line) //nolint
+	}
+}
+
 func initIO(cmd *exec.Cmd, execName string, pExitCode *int, gha bool) (func(), error) {
 	cmd.Stdin = os.Stdin
 	if !gha {
@@ -86,7 +106,7 @@ func initIO(cmd *exec.Cmd, execName string, pExitCode *int, gha bool) (func(), e
 	}
 
 	outputPath := os.Getenv("GITHUB_OUTPUT")
-	outputFile, err := os.OpenFile(outputPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644) //nolint
+	outputFile, err := os.OpenFile(outputPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, fileperm.FileMode()) //nolint
 	if err != nil {
 		return nil, err
 	}
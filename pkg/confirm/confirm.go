@@ -0,0 +1,72 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package confirm
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+)
+
+// Ask displays question and reads the answer (a line like "y" or "no") from reader.
+// An empty or unreadable answer selects defaultYes, like the timeout expiration (disabled when 0),
+// a defaulted decision is logged.
+func Ask(reader io.Reader, displayer loghelper.Displayer, question string, timeout time.Duration, defaultYes bool) bool {
+	hint := " [y/N]"
+	if defaultYes {
+		hint = " [Y/n]"
+	}
+	displayer.Display(question + hint)
+
+	answerChan := make(chan string, 1)
+	go func() {
+		line, _ := bufio.NewReader(reader).ReadString('\n')
+		answerChan <- strings.ToLower(strings.TrimSpace(line))
+	}()
+
+	var timeoutChan <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutChan = timer.C
+	}
+
+	select {
+	case answer := <-answerChan:
+		switch answer {
+		case "y", "yes":
+			return true
+		case "n", "no":
+			return false
+		case "":
+			displayer.Log(hclog.Info, "No answer, use default decision", "question", question, "approved", defaultYes)
+		default:
+			displayer.Log(hclog.Warn, "Unrecognized answer, use default decision", "question", question, "answer", answer, "approved", defaultYes)
+		}
+	case <-timeoutChan:
+		displayer.Log(hclog.Warn, "No answer before timeout, use default decision", "question", question, "timeout", timeout.String(), "approved", defaultYes)
+	}
+
+	return defaultYes
+}
@@ -0,0 +1,68 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package confirm_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tofuutils/tenv/v2/pkg/confirm"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+)
+
+func TestAsk(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		input      string
+		defaultYes bool
+		want       bool
+	}{
+		{name: "yes", input: "y\n", want: true},
+		{name: "no", input: "No\n", defaultYes: true, want: false},
+		{name: "empty", input: "\n", defaultYes: true, want: true},
+		{name: "closed", input: "", want: false},
+		{name: "unrecognized", input: "maybe\n", want: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if result := confirm.Ask(strings.NewReader(tt.input), loghelper.InertDisplayer, "Continue ?", 0, tt.defaultYes); result != tt.want {
+				t.Error("Unmatching result, get :", result)
+			}
+		})
+	}
+}
+
+func TestAskTimeout(t *testing.T) {
+	t.Parallel()
+
+	reader, writer := io.Pipe() // never written
+	defer writer.Close()
+
+	if !confirm.Ask(reader, loghelper.InertDisplayer, "Continue ?", 10*time.Millisecond, true) {
+		t.Error("Default decision should be used after timeout")
+	}
+}
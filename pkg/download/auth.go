@@ -0,0 +1,99 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package download
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/tofuutils/tenv/v2/pkg/httpclient"
+)
+
+var ErrHeaderFormat = errors.New("auth header must have the form <name>: <value>")
+
+// Credential is applied to requests toward a host (only when they do not already have an Authorization header).
+type Credential struct {
+	BearerToken string
+	Header      string // "<name>: <value>"
+	Password    string
+	Username    string
+}
+
+func (c Credential) Empty() bool {
+	return c.BearerToken == "" && c.Header == "" && c.Username == ""
+}
+
+func (c Credential) Validate() error {
+	if c.Header != "" {
+		if name, _, found := strings.Cut(c.Header, ":"); !found || strings.TrimSpace(name) == "" {
+			return ErrHeaderFormat
+		}
+	}
+
+	return nil
+}
+
+func (c Credential) apply(request *http.Request) {
+	if c.Header != "" {
+		name, value, _ := strings.Cut(c.Header, ":")
+		request.Header.Set(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+
+	if request.Header.Get("Authorization") != "" {
+		return
+	}
+
+	switch {
+	case c.BearerToken != "":
+		request.Header.Set("Authorization", "Bearer "+c.BearerToken)
+	case c.Username != "":
+		request.SetBasicAuth(c.Username, c.Password)
+	}
+}
+
+type authTransport struct {
+	base        http.RoundTripper
+	credentials map[string]Credential
+}
+
+func (t authTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	credential, ok := t.credentials[request.URL.Host]
+	if !ok {
+		return t.base.RoundTrip(request)
+	}
+
+	authRequest := request.Clone(request.Context())
+	credential.apply(authRequest)
+
+	return t.base.RoundTrip(authRequest)
+}
+
+// InstallCredentials add authentication to requests of shared http client by host (no effect when credentials is empty).
+//
+// The host is matched with port (as in url.URL.Host), credentials are not forwarded on redirect to another host.
+func InstallCredentials(credentials map[string]Credential) {
+	if len(credentials) == 0 {
+		return
+	}
+
+	httpclient.WrapTransport(func(base http.RoundTripper) http.RoundTripper {
+		return authTransport{base: base, credentials: credentials}
+	})
+}
@@ -19,11 +19,22 @@
 package download
 
 import (
+	"bytes"
+	"errors"
 	"io"
 	"net/http"
 	"net/url"
+
+	"github.com/tofuutils/tenv/v2/pkg/feature"
+	"github.com/tofuutils/tenv/v2/pkg/httpclient"
 )
 
+var ErrNotFound = errors.New("remote file not found")
+
+func init() {
+	feature.Register("http")
+}
+
 func ApplyUrlTranformer(urlTransformer func(string) (string, error), baseURLs ...string) ([]string, error) {
 	transformedURLs := make([]string, 0, len(baseURLs))
 	for _, baseURL := range baseURLs {
@@ -38,16 +49,112 @@ func ApplyUrlTranformer(urlTransformer func(string) (string, error), baseURLs ..
 	return transformedURLs, nil
 }
 
-func Bytes(url string, display func(string)) ([]byte, error) {
+type RequestOption = func(*http.Request)
+
+func Bytes(url string, display func(string), requestOptions ...RequestOption) ([]byte, error) {
+	body, err := Stream(url, display, requestOptions...)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return io.ReadAll(body)
+}
+
+// Stream returns the response body without reading it, caller must close it.
+func Stream(url string, display func(string), requestOptions ...RequestOption) (io.ReadCloser, error) {
 	display("Downloading " + url)
 
-	response, err := http.Get(url) //nolint
+	fetch, ok, err := schemeFetcher(url)
+	if err != nil {
+		return nil, err
+	}
+	if ok { // optional backends only support full fetch
+		data, err := fetch(url)
+		if err != nil {
+			return nil, err
+		}
+
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, option := range requestOptions {
+		option(request)
+	}
+
+	response, err := httpclient.Client().Do(request)
 	if err != nil {
 		return nil, err
 	}
-	defer response.Body.Close()
 
-	return io.ReadAll(response.Body)
+	if response.StatusCode == http.StatusNotFound {
+		response.Body.Close()
+
+		return nil, ErrNotFound
+	}
+
+	return progressReadCloser{Reader: withProgress(response.Body, url, 0, response.ContentLength), Closer: response.Body}, nil
+}
+
+// Exists check a remote file presence without downloading it (HEAD request).
+func Exists(url string, requestOptions ...RequestOption) (bool, error) {
+	fetch, ok, err := schemeFetcher(url)
+	if err != nil {
+		return false, err
+	}
+	if ok { // optional backends only support full fetch
+		_, err = fetch(url)
+
+		return err == nil, nil
+	}
+
+	request, err := http.NewRequest(http.MethodHead, url, nil)
+	if err != nil {
+		return false, err
+	}
+
+	for _, option := range requestOptions {
+		option(request)
+	}
+
+	response, err := httpclient.Client().Do(request)
+	if err != nil {
+		return false, err
+	}
+	response.Body.Close()
+
+	switch {
+	case response.StatusCode == http.StatusNotFound:
+		return false, nil
+	case response.StatusCode >= http.StatusBadRequest:
+		return false, errors.New(response.Status)
+	}
+
+	return true, nil
+}
+
+func ExistAll(urls []string, requestOptions ...RequestOption) ([]bool, error) {
+	founds := make([]bool, 0, len(urls))
+	for _, url := range urls {
+		found, err := Exists(url, requestOptions...)
+		if err != nil {
+			return nil, err
+		}
+		founds = append(founds, found)
+	}
+
+	return founds, nil
+}
+
+func WithHeader(key string, value string) RequestOption {
+	return func(request *http.Request) {
+		request.Header.Set(key, value)
+	}
 }
 
 func UrlTranformer(rewriteRule []string) func(string) (string, error) {
@@ -19,11 +19,96 @@
 package download_test
 
 import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/tofuutils/tenv/v2/pkg/download"
 )
 
+func TestExists(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodHead || request.URL.Path != "/SHA256SUMS" {
+			writer.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	exists, err := download.Exists(server.URL + "/SHA256SUMS")
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if !exists {
+		t.Error("File should exist")
+	}
+
+	if exists, err = download.Exists(server.URL + "/SHA256SUMS.sig"); err != nil || exists {
+		t.Error("File should not exist, get :", exists, err)
+	}
+}
+
+func TestFileScheme(t *testing.T) {
+	t.Parallel()
+
+	dirPath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dirPath, "SHA256SUMS"), []byte("checksums"), 0o644); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	dirURL, err := download.FileURL(dirPath)
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	data, err := download.Bytes(dirURL+"/SHA256SUMS", func(string) {})
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if string(data) != "checksums" {
+		t.Error("Unmatching results, get :", string(data))
+	}
+
+	if _, err = download.Bytes(dirURL+"/SHA256SUMS.sig", func(string) {}); !errors.Is(err, download.ErrNotFound) {
+		t.Error("Should fail on missing file, get :", err)
+	}
+}
+
+func TestInstallCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		username, password, _ := request.BasicAuth()
+		_, _ = writer.Write([]byte(username + ":" + password + ":" + request.Header.Get("X-Mirror-Key")))
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	download.InstallCredentials(map[string]download.Credential{
+		serverURL.Host: {Header: "X-Mirror-Key: secret", Password: "pass", Username: "user"},
+	})
+
+	data, err := download.Bytes(server.URL, func(string) {})
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if string(data) != "user:pass:secret" {
+		t.Error("Unexpected result, get :", string(data))
+	}
+}
+
 func TestUrlTransformer(t *testing.T) {
 	urlTransformer := download.UrlTranformer([]string{"https://releases.hashicorp.com", "http://localhost:8080"})
 
@@ -76,3 +161,91 @@ func TestUrlTransformerSlash(t *testing.T) {
 		t.Error("Unexpected result, get :", value)
 	}
 }
+
+func TestResumable(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	content := []byte(strings.Repeat("terraform archive content ", 1024))
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		ranges = append(ranges, request.Header.Get("Range"))
+		if len(ranges) == 1 { // interrupted transfer
+			writer.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			_, _ = writer.Write(content[:len(content)/2])
+			writer.(http.Flusher).Flush()
+			conn, _, _ := writer.(http.Hijacker).Hijack()
+			conn.Close()
+
+			return
+		}
+
+		http.ServeContent(writer, request, "terraform.zip", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	data, cleanup, err := download.Resumable(server.URL+"/terraform.zip", func(string) {})
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+	defer cleanup()
+
+	if !bytes.Equal(data, content) {
+		t.Error("Unmatching results, get length :", len(data))
+	}
+
+	if len(ranges) != 2 || ranges[0] != "" || ranges[1] != "bytes="+strconv.Itoa(len(content)/2)+"-" {
+		t.Error("Unmatching range requests, get :", ranges)
+	}
+}
+
+func TestBytesAll(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte(request.URL.Path))
+	}))
+	defer server.Close()
+
+	names := []string{"/tofu.zip", "/SHA256SUMS", "/SHA256SUMS.pem", "/SHA256SUMS.sig", "/SHA256SUMS.gpgsig", "/key.asc"}
+	urls := make([]string, 0, len(names))
+	for _, name := range names {
+		urls = append(urls, server.URL+name)
+	}
+
+	datas, err := download.BytesAll(urls, func(string) {})
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	for index, data := range datas {
+		if string(data) != names[index] {
+			t.Error("Unmatching results, get :", string(data))
+		}
+	}
+}
+
+func TestProgress(t *testing.T) {
+	data := bytes.Repeat([]byte{'a'}, 2<<20)
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		writer.Write(data)
+	}))
+	defer server.Close()
+
+	var buffer bytes.Buffer
+	download.SetProgress(&buffer, false)
+	defer download.SetProgress(nil, false)
+
+	result, err := download.Bytes(server.URL+"/tofu_1.6.2_linux_amd64.zip", func(string) {})
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if len(result) != len(data) {
+		t.Error("Unmatching result size, get :", len(result))
+	}
+
+	if output := buffer.String(); output != "Downloaded tofu_1.6.2_linux_amd64.zip 2.0 MB in 0s\n" {
+		t.Error("Unmatching output, get :", output)
+	}
+}
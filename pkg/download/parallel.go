@@ -0,0 +1,71 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package download
+
+import (
+	"sync"
+)
+
+// MaxParallel is the maximum number of concurrent downloads started by BytesAll.
+const MaxParallel = 4
+
+// BytesAll download urls content concurrently (bounded by MaxParallel), results are in urls order.
+//
+// The first error in urls order is returned once all downloads have ended.
+func BytesAll(urls []string, display func(string), requestOptions ...RequestOption) ([][]byte, error) {
+	display = SyncDisplay(display)
+
+	results := make([][]byte, len(urls))
+	errs := make([]error, len(urls))
+	semaphore := make(chan struct{}, MaxParallel)
+	var waitGroup sync.WaitGroup
+	for index, url := range urls {
+		index, url := index, url
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			results[index], errs[index] = Bytes(url, display, requestOptions...)
+		}()
+	}
+	waitGroup.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return results, nil
+}
+
+// SyncDisplay returns a display function safe for concurrent calls (displayers are not).
+func SyncDisplay(display func(string)) func(string) {
+	var lock sync.Mutex
+
+	return func(msg string) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		display(msg)
+	}
+}
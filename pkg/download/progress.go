@@ -0,0 +1,174 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package download
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// smaller downloads (checksum and signature files) are not reported.
+	progressMinSize = 1 << 20
+
+	redrawInterval = 200 * time.Millisecond
+	logInterval    = 5 * time.Second
+)
+
+type progressOutput struct {
+	writer   io.Writer
+	terminal bool
+}
+
+var (
+	progressConf atomic.Pointer[progressOutput] //nolint
+	progressLock sync.Mutex                     //nolint
+)
+
+// SetProgress enables progress report of large downloads on writer (disabled when nil) :
+// a line redrawn in place when terminal is true, periodic lines otherwise.
+func SetProgress(writer io.Writer, terminal bool) {
+	if writer == nil {
+		progressConf.Store(nil)
+
+		return
+	}
+
+	progressConf.Store(&progressOutput{writer: writer, terminal: terminal})
+}
+
+type progressReader struct {
+	reader      io.Reader
+	output      *progressOutput
+	name        string
+	total       int64
+	initial     int64 // already downloaded part (resumed download)
+	done        int64
+	start       time.Time
+	lastReport  time.Time
+	interval    time.Duration
+	endReported bool
+}
+
+// wrap reader to report its progress, remaining is the size to read after offset (reader is returned unchanged when unknown or small).
+func withProgress(reader io.Reader, rawURL string, offset int64, remaining int64) io.Reader {
+	output := progressConf.Load()
+	total := offset + remaining
+	if output == nil || remaining < 0 || total < progressMinSize {
+		return reader
+	}
+
+	interval := logInterval
+	if output.terminal {
+		interval = redrawInterval
+	}
+
+	now := time.Now()
+
+	return &progressReader{
+		reader: reader, output: output, name: fileName(rawURL), total: total, initial: offset,
+		done: offset, start: now, lastReport: now, interval: interval,
+	}
+}
+
+func (pr *progressReader) Read(buffer []byte) (int, error) {
+	n, err := pr.reader.Read(buffer)
+	pr.done += int64(n)
+
+	switch now := time.Now(); {
+	case err != nil || pr.done >= pr.total:
+		pr.end(now)
+	case now.Sub(pr.lastReport) >= pr.interval:
+		pr.lastReport = now
+		pr.report(now)
+	}
+
+	return n, err
+}
+
+func (pr *progressReader) report(now time.Time) {
+	percent := pr.done * 100 / pr.total
+	elapsed := now.Sub(pr.start)
+	line := pr.name + " " + strconv.FormatInt(percent, 10) + "% " + formatSize(pr.done) + " / " + formatSize(pr.total)
+	if speed := float64(pr.done-pr.initial) / elapsed.Seconds(); speed > 0 {
+		eta := time.Duration(float64(pr.total-pr.done)/speed) * time.Second
+		line += " " + formatSize(int64(speed)) + "/s ETA " + eta.String()
+	}
+
+	progressLock.Lock()
+	defer progressLock.Unlock()
+
+	if pr.output.terminal {
+		fmt.Fprint(pr.output.writer, "\r\033[K", line) //nolint
+	} else {
+		fmt.Fprintln(pr.output.writer, "Downloaded", line) //nolint
+	}
+}
+
+// the redrawn line is cleared, periodic lines end with a summary.
+func (pr *progressReader) end(now time.Time) {
+	if pr.endReported {
+		return
+	}
+	pr.endReported = true
+
+	progressLock.Lock()
+	defer progressLock.Unlock()
+
+	if pr.output.terminal {
+		fmt.Fprint(pr.output.writer, "\r\033[K") //nolint
+	} else {
+		elapsed := now.Sub(pr.start).Round(time.Second)
+		fmt.Fprintln(pr.output.writer, "Downloaded", pr.name, formatSize(pr.done), "in", elapsed.String()) //nolint
+	}
+}
+
+type progressReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func fileName(rawURL string) string {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	return path.Base(parsedURL.Path)
+}
+
+func formatSize(size int64) string {
+	const unit = 1 << 10
+	if size < unit {
+		return strconv.FormatInt(size, 10) + " B"
+	}
+
+	value, prefix := float64(size)/unit, 0
+	for ; value >= unit && prefix < 3; prefix++ {
+		value /= unit
+	}
+
+	return strconv.FormatFloat(value, 'f', 1, 64) + " " + "KMGT"[prefix:prefix+1] + "B"
+}
@@ -0,0 +1,200 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package download
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/tofuutils/tenv/v2/pkg/httpclient"
+)
+
+const (
+	partialDirName = "partial"
+	resumeAttempts = 3
+)
+
+var errRangeMismatch = errors.New("range response does not start at partial file end")
+
+// Resumable download url content like Bytes, received data is kept in a partial file (in user cache directory)
+// and completed with a Range request on retry, or on next call after an interruption.
+//
+// Caller must call the returned cleanup once the content has been verified (or rejected),
+// a partial file is only kept when the download does not complete.
+func Resumable(url string, display func(string), requestOptions ...RequestOption) ([]byte, func(), error) {
+	return ResumableContext(context.Background(), url, display, requestOptions...)
+}
+
+// ResumableContext is Resumable with a context allowing to interrupt the download (the partial file is kept).
+func ResumableContext(ctx context.Context, url string, display func(string), requestOptions ...RequestOption) ([]byte, func(), error) {
+	if _, ok, err := schemeFetcher(url); err != nil || ok { // optional backends only support full fetch
+		data, err := Bytes(url, display, requestOptions...)
+
+		return data, noCleanup, err
+	}
+
+	partialPath, err := partialFilePath(url)
+	if err != nil {
+		data, err := Bytes(url, display, requestOptions...)
+
+		return data, noCleanup, err
+	}
+
+	cleanup := func() {
+		os.Remove(partialPath)
+	}
+
+	for attempt := 1; ; attempt++ {
+		retry, err := downloadPart(ctx, url, partialPath, display, requestOptions)
+		if err == nil {
+			break
+		}
+		if !retry || attempt == resumeAttempts || ctx.Err() != nil {
+			if !retry {
+				cleanup()
+			}
+
+			return nil, noCleanup, err
+		}
+	}
+
+	data, err := os.ReadFile(partialPath)
+	if err != nil {
+		cleanup()
+
+		return nil, noCleanup, err
+	}
+
+	return data, cleanup, nil
+}
+
+// PartialDir returns the directory where incomplete downloads are kept.
+func PartialDir() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(cacheDir, "tenv", partialDirName), nil
+}
+
+// append missing content to partial file, returned boolean indicate if the error is transient (the partial file is kept).
+func downloadPart(ctx context.Context, url string, partialPath string, display func(string), requestOptions []RequestOption) (bool, error) {
+	var offset int64
+	if info, err := os.Stat(partialPath); err == nil {
+		offset = info.Size()
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, err
+	}
+
+	for _, option := range requestOptions {
+		option(request)
+	}
+
+	if offset == 0 {
+		display("Downloading " + url)
+	} else {
+		display("Resuming download of " + url + " from byte " + strconv.FormatInt(offset, 10))
+		request.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+	}
+
+	response, err := httpclient.Client().Do(request)
+	if err != nil {
+		return true, err
+	}
+	defer response.Body.Close()
+
+	flag := os.O_CREATE | os.O_WRONLY
+	switch {
+	case response.StatusCode == http.StatusPartialContent:
+		if !rangeStartAt(response.Header.Get("Content-Range"), offset) {
+			os.Remove(partialPath)
+
+			return true, errRangeMismatch
+		}
+		flag |= os.O_APPEND
+	case response.StatusCode == http.StatusRequestedRangeNotSatisfiable: // remote file changed, restart from zero
+		os.Remove(partialPath)
+
+		return true, errors.New(response.Status)
+	case response.StatusCode == http.StatusNotFound:
+		return false, ErrNotFound
+	case response.StatusCode >= http.StatusInternalServerError:
+		return true, errors.New(response.Status)
+	case response.StatusCode >= http.StatusBadRequest:
+		return false, errors.New(response.Status)
+	default: // Range not supported, full content
+		flag |= os.O_TRUNC
+		offset = 0
+	}
+
+	file, err := os.OpenFile(partialPath, flag, 0o600)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	if _, err = io.Copy(file, withProgress(response.Body, url, offset, response.ContentLength)); err != nil {
+		return true, err
+	}
+
+	return false, nil
+}
+
+func noCleanup() {}
+
+// partial file name is derived from url, so a later call resume the same download.
+func partialFilePath(url string) (string, error) {
+	dirPath, err := PartialDir()
+	if err != nil {
+		return "", err
+	}
+
+	if err = os.MkdirAll(dirPath, 0o700); err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256([]byte(url))
+
+	return filepath.Join(dirPath, hex.EncodeToString(hash[:])+".part"), nil
+}
+
+// check a "bytes <start>-<end>/<size>" header value.
+func rangeStartAt(contentRange string, offset int64) bool {
+	rangeValue, found := strings.CutPrefix(contentRange, "bytes ")
+	if !found {
+		return false
+	}
+
+	start, _, _ := strings.Cut(rangeValue, "-")
+	parsedStart, err := strconv.ParseInt(start, 10, 64)
+
+	return err == nil && parsedStart == offset
+}
@@ -0,0 +1,66 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package download
+
+import (
+	"errors"
+	"net/url"
+	"sync"
+
+	"github.com/tofuutils/tenv/v2/pkg/feature"
+)
+
+type FetchFunc = func(string) ([]byte, error)
+
+var ErrScheme = errors.New("unsupported url scheme (not compiled in this tenv build)")
+
+var (
+	fetchers     = map[string]FetchFunc{} //nolint
+	fetchersLock sync.RWMutex
+)
+
+// RegisterScheme allows an optional backend to handle download for its url scheme.
+func RegisterScheme(scheme string, fetch FetchFunc) {
+	fetchersLock.Lock()
+	defer fetchersLock.Unlock()
+
+	fetchers[scheme] = fetch
+	feature.Register(scheme)
+}
+
+func schemeFetcher(rawURL string) (FetchFunc, bool, error) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, false, err
+	}
+
+	switch parsedURL.Scheme {
+	case "", "http", "https":
+		return nil, false, nil
+	}
+
+	fetchersLock.RLock()
+	fetch, ok := fetchers[parsedURL.Scheme]
+	fetchersLock.RUnlock()
+	if !ok {
+		return nil, false, ErrScheme
+	}
+
+	return fetch, true, nil
+}
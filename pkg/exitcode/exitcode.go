@@ -0,0 +1,100 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package exitcode
+
+import (
+	"errors"
+	"net"
+	"net/url"
+
+	"github.com/tofuutils/tenv/v2/pkg/apimsg"
+	"github.com/tofuutils/tenv/v2/pkg/audit"
+	cosigncheck "github.com/tofuutils/tenv/v2/pkg/check/cosign"
+	pgpcheck "github.com/tofuutils/tenv/v2/pkg/check/pgp"
+	sha256check "github.com/tofuutils/tenv/v2/pkg/check/sha256"
+	slsacheck "github.com/tofuutils/tenv/v2/pkg/check/slsa"
+	"github.com/tofuutils/tenv/v2/pkg/download"
+	"github.com/tofuutils/tenv/v2/pkg/github"
+	"github.com/tofuutils/tenv/v2/pkg/lockfile"
+)
+
+// stable values, documented in README (shell scripts can rely on them).
+const (
+	Success      = 0
+	Generic      = 1
+	Usage        = 2
+	NoCompatible = 3
+	Network      = 4
+	Verification = 5
+	LockTimeout  = 6
+)
+
+// errors mapped to NoCompatible, registered by domain packages at init (keeps this package free of their import).
+var noCompatibleErrs []error //nolint
+
+// RegisterNoCompatible declares errors meaning no compatible version has been found, must be called at init.
+func RegisterNoCompatible(errs ...error) {
+	noCompatibleErrs = append(noCompatibleErrs, errs...)
+}
+
+func FromError(err error) int {
+	if err == nil {
+		return Success
+	}
+
+	for _, noCompatibleErr := range noCompatibleErrs {
+		if errors.Is(err, noCompatibleErr) {
+			return NoCompatible
+		}
+	}
+
+	var netErr net.Error
+	var rateLimitErr github.RateLimitError
+	var urlErr *url.Error
+	switch {
+	case errors.Is(err, lockfile.ErrTimeout):
+		return LockTimeout
+	case errors.Is(err, sha256check.ErrCheck), errors.Is(err, sha256check.ErrNoSum), errors.Is(err, cosigncheck.ErrCheck), errors.Is(err, cosigncheck.ErrStrict), errors.Is(err, pgpcheck.ErrCheck), errors.Is(err, pgpcheck.ErrStrict), errors.Is(err, slsacheck.ErrCheck), errors.Is(err, audit.ErrNonCompliant), errors.Is(err, audit.ErrSignature), errors.Is(err, audit.ErrAdvisory):
+		return Verification
+	case errors.Is(err, apimsg.ErrReturn), errors.Is(err, download.ErrNotFound), errors.Is(err, github.ErrBudget), errors.As(err, &rateLimitErr), errors.As(err, &urlErr), errors.As(err, &netErr):
+		return Network
+	}
+
+	return Generic
+}
+
+// Name return a stable label for code (used in usage statistics).
+func Name(code int) string {
+	switch code {
+	case Success:
+		return "success"
+	case Usage:
+		return "usage"
+	case NoCompatible:
+		return "no_compatible"
+	case Network:
+		return "network"
+	case Verification:
+		return "verification"
+	case LockTimeout:
+		return "lock_timeout"
+	}
+
+	return "generic"
+}
@@ -0,0 +1,60 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package exitcode_test
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+
+	sha256check "github.com/tofuutils/tenv/v2/pkg/check/sha256"
+	slsacheck "github.com/tofuutils/tenv/v2/pkg/check/slsa"
+	"github.com/tofuutils/tenv/v2/pkg/exitcode"
+	"github.com/tofuutils/tenv/v2/pkg/lockfile"
+)
+
+var errNoCompatible = errors.New("no compatible version found")
+
+func init() {
+	exitcode.RegisterNoCompatible(errNoCompatible)
+}
+
+func TestFromError(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		err  error
+		code int
+	}{
+		{err: nil, code: exitcode.Success},
+		{err: errors.New("unknown"), code: exitcode.Generic},
+		{err: fmt.Errorf("wrapped : %w", errNoCompatible), code: exitcode.NoCompatible},
+		{err: &url.Error{Op: "Get", URL: "https://example.com", Err: errors.New("refused")}, code: exitcode.Network},
+		{err: fmt.Errorf("wrapped : %w", sha256check.ErrCheck), code: exitcode.Verification},
+		{err: fmt.Errorf("wrapped : %w", slsacheck.ErrCheck), code: exitcode.Verification},
+		{err: lockfile.ErrTimeout, code: exitcode.LockTimeout},
+	}
+
+	for _, testCase := range testCases {
+		if code := exitcode.FromError(testCase.err); code != testCase.code {
+			t.Error("Unexpected code for", testCase.err, ": get", code, "instead of", testCase.code)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package extract
+
+import (
+	"context"
+	"crypto/sha256"
+	"io"
+	"os"
+	"path/filepath"
+
+	sha256check "github.com/tofuutils/tenv/v2/pkg/check/sha256"
+	"github.com/tofuutils/tenv/v2/pkg/download"
+	"github.com/tofuutils/tenv/v2/pkg/fileperm"
+	"github.com/tofuutils/tenv/v2/pkg/targz"
+	"github.com/tofuutils/tenv/v2/pkg/zip"
+)
+
+// Format extract an archive in a directory, from memory or while downloading it.
+type Format struct {
+	FromBytes  func(data []byte, dirPath string, filter func(string) bool) error
+	FromStream func(reader io.Reader, dirPath string, filter func(string) bool) error
+}
+
+var (
+	TarGz = Format{FromBytes: targz.UntarToDir, FromStream: targz.UntarStream} //nolint
+	Zip   = Format{FromBytes: zip.UnzipToDir, FromStream: zip.UnzipStream}     //nolint
+)
+
+// Raw is a downloaded executable written as fileName (filters are ignored).
+func Raw(fileName string) Format {
+	return Format{
+		FromBytes: func(data []byte, dirPath string, _ func(string) bool) error {
+			if err := os.MkdirAll(dirPath, fileperm.DirMode()); err != nil {
+				return err
+			}
+
+			return os.WriteFile(filepath.Join(dirPath, fileName), data, fileperm.ExecMode())
+		},
+		FromStream: func(reader io.Reader, dirPath string, _ func(string) bool) error {
+			if err := os.MkdirAll(dirPath, fileperm.DirMode()); err != nil {
+				return err
+			}
+
+			file, err := os.OpenFile(filepath.Join(dirPath, fileName), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, fileperm.ExecMode())
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+
+			_, err = io.Copy(file, reader)
+
+			return err
+		},
+	}
+}
+
+// Pending is an archive download started before its checksum file is verified.
+type Pending struct {
+	cancel         context.CancelFunc
+	cleanup        func()
+	data           []byte
+	display        func(string)
+	done           chan struct{}
+	err            error
+	format         Format
+	messages       []string
+	requestOptions []download.RequestOption
+	url            string
+}
+
+// Start download url content in background, so it is concurrent with checksum and signature files downloads
+// (with stream, nothing is started : the archive is read during extraction, once dataSums is known).
+//
+// Caller must call Install or Discard on the returned Pending.
+func (f Format) Start(url string, stream bool, display func(string), requestOptions ...download.RequestOption) *Pending {
+	pending := &Pending{display: display, format: f, requestOptions: requestOptions, url: url}
+	if stream {
+		return pending
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pending.cancel, pending.done = cancel, make(chan struct{})
+	go func() {
+		defer close(pending.done)
+
+		// messages are displayed by the caller goroutine (displayers are not safe for concurrent use)
+		record := func(msg string) {
+			pending.messages = append(pending.messages, msg)
+		}
+		pending.data, pending.cleanup, pending.err = download.ResumableContext(ctx, url, record, requestOptions...)
+	}()
+
+	return pending
+}
+
+// Discard interrupts the download (its partial file is kept to be resumed later).
+func (p *Pending) Discard() {
+	if p.done == nil {
+		return
+	}
+
+	p.cancel()
+	<-p.done
+	if p.err == nil {
+		p.cleanup()
+	}
+}
+
+// Install waits the download end and extract it in dirPath, checking its sha256 against the assetName line of dataSums (skipped when dataSums is nil).
+//
+// Without stream, an interrupted download is resumed (see download.Resumable) and its partial file is removed once checked.
+//
+// With stream, the archive is never fully kept in memory or on disk : it is extracted while downloading
+// and hashed on the fly in a temporary directory, renamed to dirPath only when the checksum matches.
+func (p *Pending) Install(dataSums []byte, assetName string, dirPath string, filter func(string) bool) error {
+	if p.done == nil {
+		return p.format.installStream(p.url, dataSums, assetName, dirPath, filter, p.display, p.requestOptions)
+	}
+
+	<-p.done
+	p.cancel()
+	for _, msg := range p.messages {
+		p.display(msg)
+	}
+
+	if p.err != nil {
+		return p.err
+	}
+	defer p.cleanup()
+
+	if dataSums != nil {
+		if err := sha256check.Check(p.data, dataSums, assetName); err != nil {
+			return err
+		}
+	}
+
+	return p.format.FromBytes(p.data, dirPath, filter)
+}
+
+// Install download url content in dirPath (see Pending.Install).
+func (f Format) Install(url string, stream bool, dataSums []byte, assetName string, dirPath string, filter func(string) bool, display func(string), requestOptions ...download.RequestOption) error {
+	return f.Start(url, stream, display, requestOptions...).Install(dataSums, assetName, dirPath, filter)
+}
+
+func (f Format) installStream(url string, dataSums []byte, assetName string, dirPath string, filter func(string) bool, display func(string), requestOptions []download.RequestOption) error {
+	body, err := download.Stream(url, display, requestOptions...)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	// extracted in a sibling temporary directory (not a version name, so ignored when listing installed versions),
+	// an interrupted or unverified extraction never appears as installed
+	parentPath := filepath.Dir(dirPath)
+	if err = os.MkdirAll(parentPath, fileperm.DirMode()); err != nil {
+		return err
+	}
+
+	tempPath, err := os.MkdirTemp(parentPath, ".stream-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempPath)
+
+	if err = f.streamAndCheck(body, dataSums, assetName, tempPath, filter); err != nil {
+		return err
+	}
+
+	if err = os.Chmod(tempPath, fileperm.DirMode()); err != nil { // MkdirTemp creates it with 0700
+		return err
+	}
+
+	return os.Rename(tempPath, dirPath)
+}
+
+func (f Format) streamAndCheck(reader io.Reader, dataSums []byte, assetName string, dirPath string, filter func(string) bool) error {
+	hasher := sha256.New()
+	hashedReader := io.TeeReader(reader, hasher)
+	if err := f.FromStream(hashedReader, dirPath, filter); err != nil {
+		return err
+	}
+
+	// trailing data (like zip central directory end) is part of the checksum
+	if _, err := io.Copy(io.Discard, hashedReader); err != nil {
+		return err
+	}
+
+	if dataSums == nil {
+		return nil
+	}
+
+	return sha256check.CheckSum(hasher.Sum(nil), dataSums, assetName)
+}
@@ -0,0 +1,87 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package extract_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	sha256check "github.com/tofuutils/tenv/v2/pkg/check/sha256"
+	"github.com/tofuutils/tenv/v2/pkg/extract"
+)
+
+func TestInstallStream(t *testing.T) {
+	t.Parallel()
+
+	content := []byte("terragrunt binary")
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write(content)
+	}))
+	defer server.Close()
+
+	hashed := sha256.Sum256(content)
+	dataSums := []byte(hex.EncodeToString(hashed[:]) + "  terragrunt_linux_amd64\n")
+
+	dirPath := filepath.Join(t.TempDir(), "0.55.0")
+	format := extract.Raw("terragrunt")
+	if err := format.Install(server.URL, true, dataSums, "terragrunt_linux_amd64", dirPath, nil, func(string) {}); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dirPath, "terragrunt")); err != nil || string(data) != string(content) {
+		t.Error("Unmatching results, get :", string(data), err)
+	}
+
+	parentPath := t.TempDir()
+	otherPath := filepath.Join(parentPath, "0.55.1")
+	wrongSums := []byte(hex.EncodeToString(make([]byte, sha256.Size)) + "  terragrunt_linux_amd64\n")
+	if err := format.Install(server.URL, true, wrongSums, "terragrunt_linux_amd64", otherPath, nil, func(string) {}); !errors.Is(err, sha256check.ErrCheck) {
+		t.Error("Should fail on invalid checksum, get :", err)
+	}
+
+	if entries, err := os.ReadDir(parentPath); err != nil || len(entries) != 0 {
+		t.Error("Nothing should be left after invalid checksum, get :", entries, err)
+	}
+}
+
+func TestInstallStreamInterrupted(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Content-Length", "1024")
+		_, _ = writer.Write([]byte("partial"))
+	}))
+	defer server.Close()
+
+	parentPath := t.TempDir()
+	dirPath := filepath.Join(parentPath, "0.55.0")
+	if err := extract.Raw("terragrunt").Install(server.URL, true, nil, "terragrunt_linux_amd64", dirPath, nil, func(string) {}); err == nil {
+		t.Fatal("Should fail on interrupted download")
+	}
+
+	if entries, err := os.ReadDir(parentPath); err != nil || len(entries) != 0 {
+		t.Error("Nothing should be left after interrupted download, get :", entries, err)
+	}
+}
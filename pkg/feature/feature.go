@@ -0,0 +1,50 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package feature
+
+import (
+	"slices"
+	"sync"
+)
+
+var (
+	features     []string //nolint
+	featuresLock sync.Mutex
+)
+
+// Register is meant to be called from init function of optional backends (selected with build tags).
+func Register(name string) {
+	featuresLock.Lock()
+	defer featuresLock.Unlock()
+
+	if !slices.Contains(features, name) {
+		features = append(features, name)
+	}
+}
+
+// List returns a sorted copy of compiled-in features names.
+func List() []string {
+	featuresLock.Lock()
+	defer featuresLock.Unlock()
+
+	result := slices.Clone(features)
+	slices.Sort(result)
+
+	return result
+}
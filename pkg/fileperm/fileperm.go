@@ -0,0 +1,55 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package fileperm
+
+import (
+	"os"
+	"sync/atomic"
+)
+
+const defaultUmask = 0o022
+
+var umask atomic.Uint32 //nolint
+
+func init() {
+	umask.Store(defaultUmask)
+}
+
+// SetUmask changes the mask removed from modes of created files and directories (default 022, giving 0755 and 0644),
+// it is also applied to the process (on unix like systems), so permissions not granted by the user one are kept.
+func SetUmask(mask os.FileMode) {
+	mask &= os.ModePerm
+	umask.Store(uint32(mask))
+	setProcessUmask(mask)
+}
+
+// DirMode returns the mode of created directories.
+func DirMode() os.FileMode {
+	return os.ModePerm &^ os.FileMode(umask.Load())
+}
+
+// ExecMode returns the mode of created executable files.
+func ExecMode() os.FileMode {
+	return DirMode()
+}
+
+// FileMode returns the mode of created regular files.
+func FileMode() os.FileMode {
+	return 0o666 &^ os.FileMode(umask.Load())
+}
@@ -0,0 +1,38 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package fileperm_test
+
+import (
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/pkg/fileperm"
+)
+
+func TestSetUmask(t *testing.T) {
+	if dirMode, fileMode := fileperm.DirMode(), fileperm.FileMode(); dirMode != 0o755 || fileMode != 0o644 {
+		t.Error("Unmatching default results, get :", dirMode, fileMode)
+	}
+
+	fileperm.SetUmask(0o002)
+	defer fileperm.SetUmask(0o022)
+
+	if dirMode, execMode, fileMode := fileperm.DirMode(), fileperm.ExecMode(), fileperm.FileMode(); dirMode != 0o775 || execMode != 0o775 || fileMode != 0o664 {
+		t.Error("Unmatching results, get :", dirMode, execMode, fileMode)
+	}
+}
@@ -0,0 +1,50 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package github
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+var ErrBudget = errors.New("GitHub API call budget exhausted for this invocation (see TENV_GITHUB_API_BUDGET)")
+
+var (
+	apiBudget atomic.Int64 //nolint
+	apiCalls  atomic.Int64 //nolint
+)
+
+// APICalls return the number of GitHub API calls made by current process.
+func APICalls() int64 {
+	return apiCalls.Load()
+}
+
+// SetAPIBudget limit the number of GitHub API calls by process (zero or negative means unlimited).
+func SetAPIBudget(budget int64) {
+	apiBudget.Store(budget)
+}
+
+func consumeAPICall() error {
+	calls := apiCalls.Add(1)
+	if budget := apiBudget.Load(); budget > 0 && calls > budget {
+		return ErrBudget
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package github
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// modify process wide budget, so not parallel (parallel tests start after it).
+func TestAPIBudget(t *testing.T) { //nolint
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write([]byte(`{"tag_name": "v1.6.0"}`))
+	}))
+	defer server.Close()
+	defer SetAPIBudget(0)
+
+	tests := []struct {
+		name       string
+		budget     int64 // relative to calls already made, unlimited when zero
+		requests   int
+		wantFailed int
+	}{
+		{name: "Unlimited", requests: 3},
+		{name: "WithinBudget", budget: 3, requests: 3},
+		{name: "Exhausted", budget: 2, requests: 4, wantFailed: 2},
+	}
+
+	// sequential, budget is process wide
+	for _, tt := range tests {
+		before := APICalls()
+		budget := tt.budget
+		if budget != 0 {
+			budget += before
+		}
+		SetAPIBudget(budget)
+
+		failed := 0
+		for i := 0; i < tt.requests; i++ {
+			if _, err := apiGetRequest[releaseEntry](server.URL, ""); errors.Is(err, ErrBudget) {
+				failed++
+			} else if err != nil {
+				t.Fatal("Unexpected error :", err)
+			}
+		}
+
+		if calls := APICalls() - before; failed != tt.wantFailed || calls != int64(tt.requests) {
+			t.Error(tt.name, "unmatching results, get :", failed, calls)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package github
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"github.com/tofuutils/tenv/v2/pkg/fileperm"
+)
+
+var cacheDir atomic.Value //nolint
+
+// validator of a cached response, the body is stored beside it (see bodyPath).
+type etagEntry struct {
+	ETag string `json:"etag"`
+	Link string `json:"link,omitempty"` // pagination links of a page response
+}
+
+// SetCacheDir enable conditional API requests (If-None-Match) with responses stored in dirPath (disabled when empty).
+//
+// A 304 response is answered from the stored body, GitHub does not count it against the rate limit.
+func SetCacheDir(dirPath string) {
+	cacheDir.Store(dirPath)
+}
+
+// the authorization header is part of the key (responses differ with private repositories access).
+func etagFilePath(callURL string, authorizationHeader string) string {
+	dirPath, _ := cacheDir.Load().(string)
+	if dirPath == "" {
+		return ""
+	}
+
+	hash := sha256.Sum256([]byte(authorizationHeader + " " + callURL))
+
+	return filepath.Join(dirPath, hex.EncodeToString(hash[:])+".json")
+}
+
+func bodyPath(filePath string) string {
+	return strings.TrimSuffix(filePath, ".json") + ".body"
+}
+
+// an entry without stored body is ignored.
+func readETagEntry(filePath string) (etagEntry, bool) {
+	var entry etagEntry
+	if filePath == "" {
+		return entry, false
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return entry, false
+	}
+
+	if err = json.Unmarshal(data, &entry); err != nil {
+		return entry, false
+	}
+
+	info, err := os.Stat(bodyPath(filePath))
+
+	return entry, entry.ETag != "" && err == nil && info.Size() != 0
+}
+
+// createETagBody returns a temporary file to store a response body while it is decoded.
+func createETagBody(filePath string) (*os.File, error) {
+	dirPath := filepath.Dir(filePath)
+	if err := os.MkdirAll(dirPath, fileperm.DirMode()); err != nil {
+		return nil, err
+	}
+
+	return os.CreateTemp(dirPath, ".body-")
+}
+
+// best effort, the next call is simply not conditional on failure.
+func writeETagEntry(filePath string, entry etagEntry, tmpBodyPath string) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	if err = os.Rename(tmpBodyPath, bodyPath(filePath)); err == nil {
+		_ = os.WriteFile(filePath, data, 0o600)
+	}
+}
+
+func removeETagEntry(filePath string) {
+	os.Remove(filePath)
+	os.Remove(bodyPath(filePath))
+}
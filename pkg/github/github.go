@@ -19,27 +19,56 @@
 package github
 
 import (
-	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
 	"net/url"
-	"strconv"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-version"
 
 	"github.com/tofuutils/tenv/v2/pkg/apimsg"
+	"github.com/tofuutils/tenv/v2/pkg/download"
+	"github.com/tofuutils/tenv/v2/pkg/feature"
 	versionfinder "github.com/tofuutils/tenv/v2/versionmanager/semantic/finder"
 )
 
 const (
+	BaseURL  = "https://github.com"
 	Download = "download"
 	Releases = "releases"
-
-	pageQuery = "?page="
 )
 
+func init() {
+	feature.Register("github")
+}
+
 var errContinue = errors.New("continue")
 
-func AssetDownloadURL(tag string, searchedAssetNames []string, githubReleaseURL string, githubToken string, display func(string)) ([]string, error) {
+// SplitTag returns the release tag (with 'v' prefix) and the version (without it).
+func SplitTag(versionStr string) (string, string) {
+	versionStr = strings.TrimPrefix(versionStr, "v")
+
+	return "v" + versionStr, versionStr
+}
+
+type assetEntry struct {
+	APIURL      string `json:"url"`
+	DownloadURL string `json:"browser_download_url"`
+	Name        string `json:"name"`
+}
+
+type releaseEntry struct {
+	AssetsURL   string `json:"assets_url"`
+	PublishedAt string `json:"published_at"`
+	TagName     string `json:"tag_name"`
+}
+
+// with assetAPI and a token, returned urls target the asset API endpoint (working with private repositories),
+// they must be downloaded with AssetRequestOptions.
+func AssetDownloadURL(tag string, searchedAssetNames []string, githubReleaseURL string, githubToken string, assetAPI bool, display func(string)) ([]string, error) {
 	releaseUrl, err := url.JoinPath(githubReleaseURL, "tags", tag) //nolint
 	if err != nil {
 		return nil, err
@@ -48,14 +77,13 @@ func AssetDownloadURL(tag string, searchedAssetNames []string, githubReleaseURL
 	display(apimsg.MsgFetchRelease + releaseUrl)
 
 	authorizationHeader := buildAuthorizationHeader(githubToken)
-	value, err := apiGetRequest(releaseUrl, authorizationHeader)
+	release, err := apiGetRequest[releaseEntry](releaseUrl, authorizationHeader)
 	if err != nil {
 		return nil, err
 	}
 
-	object, _ := value.(map[string]any)
-	baseAssetsURL, ok := object["assets_url"].(string)
-	if !ok {
+	baseAssetsURL := release.AssetsURL
+	if baseAssetsURL == "" {
 		return nil, apimsg.ErrReturn
 	}
 
@@ -65,17 +93,29 @@ func AssetDownloadURL(tag string, searchedAssetNames []string, githubReleaseURL
 		searchedAssetNameSet[searchAssetName] = struct{}{}
 	}
 
-	page := 1
+	apiURL := assetAPI && githubToken != ""
 	assets := make(map[string]string, waited)
-	baseAssetsURL += pageQuery
-	for {
-		assetsURL := baseAssetsURL + strconv.Itoa(page)
-		value, err = apiGetRequest(assetsURL, authorizationHeader)
+	assetsURL := firstPageURL(baseAssetsURL)
+	for page := 1; ; page++ {
+		// stop decoding the page once all searched assets are found
+		var pageAssets []assetEntry
+		linkHeader, err := apiGetPage(assetsURL, authorizationHeader, func(response *http.Response) (err error) {
+			pending := waited - len(assets)
+			pageAssets, err = apimsg.DecodeArray(response, func(asset assetEntry) bool {
+				if _, ok := searchedAssetNameSet[asset.Name]; ok {
+					pending--
+				}
+
+				return pending == 0
+			})
+
+			return err
+		})
 		if err != nil {
 			return nil, err
 		}
 
-		if err = extractAssets(assets, searchedAssetNameSet, waited, value); err == nil {
+		if err = extractAssets(assets, searchedAssetNameSet, waited, apiURL, pageAssets); err == nil {
 			assetURLs := make([]string, 0, waited)
 			for _, searchAssetName := range searchedAssetNames {
 				assetURLs = append(assetURLs, assets[searchAssetName])
@@ -85,37 +125,131 @@ func AssetDownloadURL(tag string, searchedAssetNames []string, githubReleaseURL
 		} else if err != errContinue {
 			return nil, err
 		}
-		page++
+
+		if assetsURL = nextPageURL(linkHeader, baseAssetsURL, page, len(pageAssets)); assetsURL == "" {
+			return nil, apimsg.ErrAsset
+		}
+	}
+}
+
+func AssetRequestOptions(githubToken string, assetAPI bool) []download.RequestOption {
+	if !assetAPI || githubToken == "" {
+		return nil
+	}
+
+	return []download.RequestOption{
+		download.WithHeader("Accept", "application/octet-stream"),
+		download.WithHeader("Authorization", buildAuthorizationHeader(githubToken)),
+		download.WithHeader("X-GitHub-Api-Version", "2022-11-28"),
 	}
 }
 
 func ListReleases(githubReleaseURL string, githubToken string) ([]string, error) {
-	basePageURL := githubReleaseURL + pageQuery
+	releases, _, err := ListDatedReleases(githubReleaseURL, githubToken)
+
+	return releases, err
+}
+
+// ListDatedReleases is like ListReleases and returns publish dates too (by version).
+func ListDatedReleases(githubReleaseURL string, githubToken string) ([]string, map[string]time.Time, error) {
 	authorizationHeader := buildAuthorizationHeader(githubToken)
 
-	page := 1
 	var releases []string
-	for {
-		pageURL := basePageURL + strconv.Itoa(page)
-		value, err := apiGetRequest(pageURL, authorizationHeader)
+	dates := map[string]time.Time{}
+	pageURL := firstPageURL(githubReleaseURL)
+	for page := 1; ; page++ {
+		var pageReleases []releaseEntry
+		linkHeader, err := apiGetPage(pageURL, authorizationHeader, func(response *http.Response) (err error) {
+			pageReleases, err = apimsg.DecodeArray[releaseEntry](response, nil)
+
+			return err
+		})
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
-		releases, err = extractReleases(releases, value)
+		extractDates(dates, pageReleases)
+		releases, err = extractReleases(releases, pageReleases)
 		if err == nil {
-			return releases, nil
+			return releases, dates, nil
 		} else if err != errContinue {
-			return nil, err
+			return nil, nil, err
+		}
+
+		if pageURL = nextPageURL(linkHeader, githubReleaseURL, page, len(pageReleases)); pageURL == "" {
+			return releases, dates, nil
+		}
+	}
+}
+
+// FindRelease returns the highest version matching in the first page containing a match (releases are listed newest first,
+// so remaining pages are not fetched), or an empty string when no version match.
+func FindRelease(githubReleaseURL string, githubToken string, match func(string) bool) (string, error) {
+	authorizationHeader := buildAuthorizationHeader(githubToken)
+
+	pageURL := firstPageURL(githubReleaseURL)
+	for page := 1; ; page++ {
+		var pageReleases []releaseEntry
+		linkHeader, err := apiGetPage(pageURL, authorizationHeader, func(response *http.Response) (err error) {
+			pageReleases, err = apimsg.DecodeArray[releaseEntry](response, nil)
+
+			return err
+		})
+		if err != nil {
+			return "", err
+		}
+
+		versions, err := extractReleases(nil, pageReleases)
+		if err == nil {
+			return "", nil
+		} else if err != errContinue {
+			return "", err
+		}
+
+		found := ""
+		for _, version := range versions {
+			if match(version) && (found == "" || greaterVersion(version, found)) {
+				found = version
+			}
+		}
+
+		if found != "" {
+			return found, nil
+		}
+
+		if pageURL = nextPageURL(linkHeader, githubReleaseURL, page, len(pageReleases)); pageURL == "" {
+			return "", nil
 		}
-		page++
 	}
 }
 
-func apiGetRequest(callURL string, authorizationHeader string) (any, error) {
+func apiGetRequest[T any](callURL string, authorizationHeader string) (T, error) {
+	var value T
+	_, err := apiGetPage(callURL, authorizationHeader, func(response *http.Response) (err error) {
+		value, err = apimsg.Decode[T](response)
+
+		return err
+	})
+
+	return value, err
+}
+
+// retry once on invalid JSON (transient proxy error page or truncated body), decode is called
+// with each response (it must replace previously decoded content), the Link header of response is returned.
+func apiGetPage(callURL string, authorizationHeader string, decode func(*http.Response) error) (string, error) {
+	linkHeader, err := innerAPIGetRequest(callURL, authorizationHeader, decode)
+	var decodeErr apimsg.DecodeError
+	if errors.As(err, &decodeErr) {
+		linkHeader, err = innerAPIGetRequest(callURL, authorizationHeader, decode)
+	}
+
+	return linkHeader, err
+}
+
+func innerAPIGetRequest(callURL string, authorizationHeader string, decode func(*http.Response) error) (string, error) {
 	request, err := http.NewRequest(http.MethodGet, callURL, nil)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
 	request.Header.Set("Accept", "application/vnd.github+json")
@@ -124,21 +258,76 @@ func apiGetRequest(callURL string, authorizationHeader string) (any, error) {
 	}
 	request.Header.Set("X-GitHub-Api-Version", "2022-11-28")
 
-	response, err := http.DefaultClient.Do(request)
+	etagPath := etagFilePath(callURL, authorizationHeader)
+	entry, cached := readETagEntry(etagPath)
+	if cached {
+		request.Header.Set("If-None-Match", entry.ETag)
+	}
+
+	response, err := doAPIRequest(request)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 	defer response.Body.Close()
 
-	data, err := io.ReadAll(response.Body)
+	if cached && response.StatusCode == http.StatusNotModified {
+		if err = decodeCached(etagPath, response, decode); err == nil {
+			return entry.Link, nil
+		}
+
+		// stored body is unusable (truncated or corrupted), drop it and ask a full response
+		response.Body.Close()
+		removeETagEntry(etagPath)
+
+		return innerAPIGetRequest(callURL, authorizationHeader, decode)
+	}
+
+	etag, linkHeader := response.Header.Get("ETag"), response.Header.Get("Link")
+	if etagPath == "" || etag == "" || response.StatusCode != http.StatusOK {
+		return linkHeader, decode(response)
+	}
+
+	// body is stored while it is decoded (never fully kept in memory)
+	bodyFile, err := createETagBody(etagPath)
 	if err != nil {
-		return nil, err
+		return linkHeader, decode(response)
 	}
+	defer os.Remove(bodyFile.Name()) // no-op once renamed
+	defer bodyFile.Close()
 
-	var value any
-	err = json.Unmarshal(data, &value)
+	teeReader := io.TeeReader(response.Body, bodyFile)
+	response.Body = io.NopCloser(teeReader)
+	if err = decode(response); err != nil {
+		return linkHeader, err
+	}
 
-	return value, err
+	// decoding can stop before the end of body, the stored body must be complete
+	if _, err = io.Copy(io.Discard, teeReader); err == nil && bodyFile.Close() == nil {
+		writeETagEntry(etagPath, etagEntry{ETag: etag, Link: linkHeader}, bodyFile.Name())
+	}
+
+	return linkHeader, nil
+}
+
+func decodeCached(etagPath string, response *http.Response, decode func(*http.Response) error) error {
+	bodyFile, err := os.Open(bodyPath(etagPath))
+	if err != nil {
+		return err
+	}
+	defer bodyFile.Close()
+
+	cachedResponse := *response
+	cachedResponse.Body = bodyFile
+
+	return decode(&cachedResponse)
+}
+
+// versions come from versionfinder, an unparsable one is never greater.
+func greaterVersion(v1Str string, v2Str string) bool {
+	v1, err1 := version.NewVersion(v1Str)
+	v2, err2 := version.NewVersion(v2Str)
+
+	return err1 == nil && (err2 != nil || v1.GreaterThan(v2))
 }
 
 func buildAuthorizationHeader(token string) string {
@@ -149,32 +338,29 @@ func buildAuthorizationHeader(token string) string {
 	return "Bearer " + token
 }
 
-func extractAssets(assets map[string]string, searchedAssetNameSet map[string]struct{}, waited int, value any) error {
-	values, ok := value.([]any)
-	if !ok {
-		return apimsg.ErrReturn
-	}
-
-	if len(values) == 0 {
+func extractAssets(assets map[string]string, searchedAssetNameSet map[string]struct{}, waited int, apiURL bool, pageAssets []assetEntry) error {
+	if len(pageAssets) == 0 {
 		return apimsg.ErrAsset
 	}
 
-	for _, value := range values {
-		object, _ := value.(map[string]any)
-		assetName, ok := object["name"].(string) //nolint
-		if !ok {
+	for _, asset := range pageAssets {
+		if asset.Name == "" {
 			return apimsg.ErrReturn
 		}
 
-		if _, ok := searchedAssetNameSet[assetName]; !ok {
+		if _, ok := searchedAssetNameSet[asset.Name]; !ok {
 			continue
 		}
 
-		downloadURL, ok := object["browser_download_url"].(string)
-		if !ok {
+		downloadURL := asset.DownloadURL
+		if apiURL {
+			downloadURL = asset.APIURL
+		}
+
+		if downloadURL == "" {
 			return apimsg.ErrReturn
 		}
-		assets[assetName] = downloadURL
+		assets[asset.Name] = downloadURL
 
 		if len(assets) == waited {
 			return nil
@@ -184,18 +370,13 @@ func extractAssets(assets map[string]string, searchedAssetNameSet map[string]str
 	return errContinue
 }
 
-func extractReleases(releases []string, value any) ([]string, error) {
-	values, ok := value.([]any)
-	if !ok {
-		return nil, apimsg.ErrReturn
-	}
-
-	if len(values) == 0 {
+func extractReleases(releases []string, pageReleases []releaseEntry) ([]string, error) {
+	if len(pageReleases) == 0 {
 		return releases, nil
 	}
 
-	for _, value := range values {
-		version := extractVersion(value)
+	for _, release := range pageReleases {
+		version := versionfinder.Find(release.TagName)
 		if version == "" {
 			return nil, apimsg.ErrReturn
 		}
@@ -205,9 +386,13 @@ func extractReleases(releases []string, value any) ([]string, error) {
 	return releases, errContinue
 }
 
-func extractVersion(value any) string {
-	object, _ := value.(map[string]any)
-	version, _ := object["tag_name"].(string)
-
-	return versionfinder.Find(version)
+// releases without a valid published_at are skipped.
+func extractDates(dates map[string]time.Time, pageReleases []releaseEntry) {
+	for _, release := range pageReleases {
+		if date, err := time.Parse(time.RFC3339, release.PublishedAt); err == nil {
+			if version := versionfinder.Find(release.TagName); version != "" {
+				dates[version] = date
+			}
+		}
+	}
 }
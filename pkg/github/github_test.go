@@ -21,11 +21,20 @@ package github
 import (
 	_ "embed"
 	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/tofuutils/tenv/v2/pkg/apimsg"
 	"github.com/tofuutils/tenv/v2/versionmanager/semantic"
+	versionfinder "github.com/tofuutils/tenv/v2/versionmanager/semantic/finder"
 )
 
 // empty marker.
@@ -35,7 +44,7 @@ var e = struct{}{}
 var assetsData []byte
 
 var (
-	assetsValue any
+	assetsValue []assetEntry
 	assetsErr   error
 )
 
@@ -43,7 +52,7 @@ var (
 var releaseData []byte
 
 var (
-	releaseValue any
+	releaseValue releaseEntry
 	releaseErr   error
 )
 
@@ -51,7 +60,7 @@ var (
 var releasesData []byte
 
 var (
-	releasesValue any
+	releasesValue []releaseEntry
 	releasesErr   error
 )
 
@@ -61,12 +70,58 @@ func init() {
 	releasesErr = json.Unmarshal(releasesData, &releasesValue)
 }
 
+func TestAPIGetRequestRetry(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) == 1 {
+			writer.Header().Set("Content-Type", "text/html")
+			writer.WriteHeader(http.StatusBadGateway)
+			_, _ = writer.Write([]byte("<html><body>Bad Gateway</body></html>"))
+
+			return
+		}
+		_, _ = writer.Write([]byte(`{"tag_name": "v1.6.0"}`))
+	}))
+	defer server.Close()
+
+	value, err := apiGetRequest[releaseEntry](server.URL, "")
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if value.TagName != "v1.6.0" || calls.Load() != 2 {
+		t.Error("Unmatching result, get :", value, calls.Load())
+	}
+}
+
+func TestAPIGetRequestDiagnostic(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.Header().Set("Content-Type", "text/html")
+		writer.WriteHeader(http.StatusBadGateway)
+		_, _ = writer.Write([]byte("<html><body>Bad Gateway</body></html>"))
+	}))
+	defer server.Close()
+
+	_, err := apiGetRequest[releaseEntry](server.URL, "")
+	if !errors.Is(err, apimsg.ErrReturn) {
+		t.Fatal("Should fail with ErrReturn, get :", err)
+	}
+
+	if msg := err.Error(); !strings.Contains(msg, "status 502") || !strings.Contains(msg, `"text/html"`) || !strings.Contains(msg, "Bad Gateway") {
+		t.Error("Missing diagnostics in error :", msg)
+	}
+}
+
 func TestExtractAssetsEmpty(t *testing.T) {
 	t.Parallel()
 
 	assets := map[string]string{}
 	searchedAssetNames := map[string]struct{}{"tofu_1.6.0_386.deb": e, "tofu_1.6.0_amd64.apk.gpgsig": e}
-	err := extractAssets(assets, searchedAssetNames, 2, []any{})
+	err := extractAssets(assets, searchedAssetNames, 2, false, nil)
 	if err == nil {
 		t.Error("Should fail on empty data")
 	} else if err != apimsg.ErrAsset {
@@ -83,7 +138,7 @@ func TestExtractAssetsMissing(t *testing.T) {
 
 	assets := map[string]string{}
 	searchedAssetNames := map[string]struct{}{"tofu_1.6.0_386.deb": e, "any_name.zip": e}
-	err := extractAssets(assets, searchedAssetNames, 2, assetsValue)
+	err := extractAssets(assets, searchedAssetNames, 2, false, assetsValue)
 	if err == nil {
 		t.Error("Should fail on non exiting fileName")
 	} else if err != errContinue {
@@ -100,7 +155,7 @@ func TestExtractAssetsPresent(t *testing.T) {
 
 	assets := map[string]string{}
 	searchedAssetNames := map[string]struct{}{"tofu_1.6.0_386.deb": e, "tofu_1.6.0_amd64.apk.gpgsig": e}
-	err := extractAssets(assets, searchedAssetNames, 2, assetsValue)
+	err := extractAssets(assets, searchedAssetNames, 2, false, assetsValue)
 	if err != nil {
 		t.Fatal("Unexpected extract error : ", err)
 	}
@@ -113,10 +168,29 @@ func TestExtractAssetsPresent(t *testing.T) {
 	}
 }
 
+func TestExtractAssetsAPIEndpoint(t *testing.T) {
+	t.Parallel()
+
+	if assetsErr != nil {
+		t.Fatal("Unexpected parsing error : ", assetsErr)
+	}
+
+	assets := map[string]string{}
+	searchedAssetNames := map[string]struct{}{"tofu_1.6.0_386.deb": e}
+	err := extractAssets(assets, searchedAssetNames, 1, true, assetsValue)
+	if err != nil {
+		t.Fatal("Unexpected extract error : ", err)
+	}
+
+	if res := assets["tofu_1.6.0_386.deb"]; res != "https://api.github.com/repos/opentofu/opentofu/releases/assets/144823141" {
+		t.Error("Unmatching result, get :", res)
+	}
+}
+
 func TestExtractReleasesEmpty(t *testing.T) {
 	t.Parallel()
 
-	releases, err := extractReleases([]string{"value"}, []any{})
+	releases, err := extractReleases([]string{"value"}, nil)
 	if err != nil {
 		t.Fatal("Unexpected extract error : ", err)
 	}
@@ -152,6 +226,20 @@ func TestExtractReleasesPresent(t *testing.T) {
 	}
 }
 
+func TestExtractDates(t *testing.T) {
+	t.Parallel()
+
+	if releasesErr != nil {
+		t.Fatal("Unexpected parsing error : ", releasesErr)
+	}
+
+	dates := map[string]time.Time{}
+	extractDates(dates, releasesValue)
+	if len(dates) != 4 || !dates["1.6.0"].Equal(time.Date(2024, 1, 10, 14, 13, 28, 0, time.UTC)) {
+		t.Error("Unmatching results, get :", dates)
+	}
+}
+
 func TestExtractVersion(t *testing.T) {
 	t.Parallel()
 
@@ -159,7 +247,7 @@ func TestExtractVersion(t *testing.T) {
 		t.Fatal("Unexpected parsing error : ", releaseErr)
 	}
 
-	version := extractVersion(releaseValue)
+	version := versionfinder.Find(releaseValue.TagName)
 	if version == "" {
 		t.Fatal("Unexpected empty result")
 	}
@@ -167,3 +255,305 @@ func TestExtractVersion(t *testing.T) {
 		t.Error("Unmatching result, get :", version)
 	}
 }
+
+func TestSplitTag(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		versionStr  string
+		wantTag     string
+		wantVersion string
+	}{
+		{name: "Prefixed", versionStr: "v1.6.0", wantTag: "v1.6.0", wantVersion: "1.6.0"},
+		{name: "Bare", versionStr: "1.6.0", wantTag: "v1.6.0", wantVersion: "1.6.0"},
+		{name: "Prerelease", versionStr: "1.7.0-rc1", wantTag: "v1.7.0-rc1", wantVersion: "1.7.0-rc1"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if tag, version := SplitTag(tt.versionStr); tag != tt.wantTag || version != tt.wantVersion {
+				t.Error("Unmatching results, get :", tag, version)
+			}
+		})
+	}
+}
+
+func TestAPIGetRequestETag(t *testing.T) {
+	SetCacheDir(t.TempDir())
+	defer SetCacheDir("")
+
+	var notModified atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			writer.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+		writer.Header().Set("ETag", `"v1"`)
+		_, _ = writer.Write([]byte(`{"tag_name": "v1.6.0"}`))
+	}))
+	defer server.Close()
+
+	for i := 0; i < 2; i++ {
+		value, err := apiGetRequest[releaseEntry](server.URL, "")
+		if err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+
+		if value.TagName != "v1.6.0" {
+			t.Error("Unmatching result, get :", value)
+		}
+	}
+
+	if notModified.Load() != 1 {
+		t.Error("Second call should be conditional, get :", notModified.Load())
+	}
+}
+
+func TestAPIGetRequestETagStaleBody(t *testing.T) {
+	SetCacheDir(t.TempDir())
+	defer SetCacheDir("")
+
+	var conditional, full atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Header.Get("If-None-Match") == `"v1"` {
+			conditional.Add(1)
+			writer.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+		full.Add(1)
+		writer.Header().Set("ETag", `"v1"`)
+		_, _ = writer.Write([]byte(`{"tag_name": "v1.6.0"}`))
+	}))
+	defer server.Close()
+
+	if _, err := apiGetRequest[releaseEntry](server.URL, ""); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	// truncated stored body
+	if err := os.WriteFile(bodyPath(etagFilePath(server.URL, "")), []byte(`{"tag_na`), 0o600); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		value, err := apiGetRequest[releaseEntry](server.URL, "")
+		if err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+
+		if value.TagName != "v1.6.0" {
+			t.Error("Unmatching result, get :", value)
+		}
+	}
+
+	// stale body dropped then refreshed, last call served from renewed cache
+	if conditional.Load() != 2 || full.Load() != 2 {
+		t.Error("Unmatching calls, get :", conditional.Load(), full.Load())
+	}
+}
+
+func TestAPIGetRequestRateLimit(t *testing.T) {
+	SetRateLimitRetries(2)
+	defer SetRateLimitRetries(0)
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path == "/far" {
+			writer.Header().Set("X-RateLimit-Remaining", "0")
+			writer.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+			writer.WriteHeader(http.StatusForbidden)
+
+			return
+		}
+
+		if calls.Add(1) == 1 {
+			writer.Header().Set("Retry-After", "0")
+			writer.WriteHeader(http.StatusTooManyRequests)
+
+			return
+		}
+		_, _ = writer.Write([]byte(`{"tag_name": "v1.6.0"}`))
+	}))
+	defer server.Close()
+
+	value, err := apiGetRequest[releaseEntry](server.URL, "")
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if value.TagName != "v1.6.0" || calls.Load() != 2 {
+		t.Error("Unmatching result, get :", value, calls.Load())
+	}
+
+	var rateLimitErr RateLimitError
+	if _, err = apiGetRequest[releaseEntry](server.URL+"/far", ""); !errors.As(err, &rateLimitErr) || rateLimitErr.Reset.IsZero() {
+		t.Error("Should fail with a rate limit reset, get :", err)
+	}
+}
+
+func TestNextLink(t *testing.T) {
+	t.Parallel()
+
+	linkHeader := `<https://api.github.com/repositories/1/releases?per_page=100&page=2>; rel="next", <https://api.github.com/repositories/1/releases?per_page=100&page=5>; rel="last"`
+	if next := nextLink(linkHeader); next != "https://api.github.com/repositories/1/releases?per_page=100&page=2" {
+		t.Error("Unmatching result, get :", next)
+	}
+
+	if next := nextLink(`<https://api.github.com/repositories/1/releases?per_page=100&page=1>; rel="prev"`); next != "" {
+		t.Error("Should not find next link, get :", next)
+	}
+}
+
+func TestListReleasesLinkPagination(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		calls.Add(1)
+		if request.URL.Query().Get("per_page") != "100" {
+			t.Error("Unmatching page size, get :", request.URL.RawQuery)
+		}
+
+		if request.URL.Query().Get("cursor") == "" {
+			writer.Header().Set("Link", "<"+server.URL+`/releases?per_page=100&cursor=b>; rel="next"`)
+			_, _ = writer.Write([]byte(`[{"tag_name": "v1.7.0"}, {"tag_name": "v1.6.2"}]`))
+
+			return
+		}
+		writer.Header().Set("Link", "<"+server.URL+`/releases?per_page=100>; rel="first"`)
+		_, _ = writer.Write([]byte(`[{"tag_name": "v1.6.1"}]`))
+	}))
+	defer server.Close()
+
+	releases, err := ListReleases(server.URL+"/releases", "")
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if !slices.Equal(releases, []string{"1.7.0", "1.6.2", "1.6.1"}) || calls.Load() != 2 {
+		t.Error("Unmatching results, get :", releases, calls.Load())
+	}
+}
+
+func TestAssetDownloadURLEarlyStop(t *testing.T) {
+	t.Parallel()
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path == "/releases/tags/v1.6.0" {
+			_, _ = writer.Write([]byte(`{"assets_url": "` + server.URL + `/assets"}`))
+
+			return
+		}
+		// content after searched assets is never decoded
+		_, _ = writer.Write([]byte(`[{"name": "a.zip", "browser_download_url": "https://a"}, {"name": "b.zip", "browser_download_url": "https://b"}, {"name": `))
+	}))
+	defer server.Close()
+
+	assetURLs, err := AssetDownloadURL("v1.6.0", []string{"b.zip", "a.zip"}, server.URL+"/releases", "", false, func(string) {})
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if !slices.Equal(assetURLs, []string{"https://b", "https://a"}) {
+		t.Error("Unmatching results, get :", assetURLs)
+	}
+}
+
+func TestFindRelease(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		calls.Add(1)
+		if request.URL.Query().Get("cursor") == "" {
+			writer.Header().Set("Link", "<"+server.URL+`/releases?per_page=100&cursor=b>; rel="next"`)
+			_, _ = writer.Write([]byte(`[{"tag_name": "v1.7.0"}, {"tag_name": "v1.6.2"}, {"tag_name": "v1.6.3"}]`))
+
+			return
+		}
+		_, _ = writer.Write([]byte(`[{"tag_name": "v1.6.4"}]`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		prefix    string
+		want      string
+		wantCalls int32
+	}{
+		{name: "FirstPage", prefix: "1.6.", want: "1.6.3", wantCalls: 1},
+		{name: "NoMatch", prefix: "1.5.", want: "", wantCalls: 2},
+	}
+
+	for _, tt := range tests { // sequential, calls are counted
+		calls.Store(0)
+		found, err := FindRelease(server.URL+"/releases", "", func(version string) bool {
+			return strings.HasPrefix(version, tt.prefix)
+		})
+		if err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+
+		if found != tt.want || calls.Load() != tt.wantCalls {
+			t.Error(tt.name, "unmatching results, get :", found, calls.Load())
+		}
+	}
+}
+
+func TestAssetDownloadURLETagEarlyStop(t *testing.T) {
+	cacheDirPath := t.TempDir()
+	SetCacheDir(cacheDirPath)
+	defer SetCacheDir("")
+
+	assetsPage := `[{"name": "a.zip", "browser_download_url": "https://a"}, {"name": "b.zip", "browser_download_url": "https://b"}]`
+	var notModified atomic.Int32
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path == "/releases/tags/v1.6.0" {
+			_, _ = writer.Write([]byte(`{"assets_url": "` + server.URL + `/assets"}`))
+
+			return
+		}
+
+		if request.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			writer.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+		writer.Header().Set("ETag", `"v1"`)
+		_, _ = writer.Write([]byte(assetsPage))
+	}))
+	defer server.Close()
+
+	for i := 0; i < 2; i++ {
+		// decoding stops after a.zip, stored body must stay complete
+		assetURLs, err := AssetDownloadURL("v1.6.0", []string{"a.zip"}, server.URL+"/releases", "", false, func(string) {})
+		if err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+
+		if !slices.Equal(assetURLs, []string{"https://a"}) {
+			t.Error("Unmatching results, get :", assetURLs)
+		}
+	}
+
+	if notModified.Load() != 1 {
+		t.Error("Second call should be conditional, get :", notModified.Load())
+	}
+
+	data, err := os.ReadFile(bodyPath(etagFilePath(server.URL+"/assets?per_page=100", "")))
+	if err != nil || string(data) != assetsPage {
+		t.Error("Unmatching stored body, get :", string(data), err)
+	}
+}
@@ -0,0 +1,83 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package github
+
+import (
+	"strconv"
+	"strings"
+)
+
+// maximum page size allowed by GitHub API (default is 30).
+const perPage = 100
+
+func firstPageURL(baseURL string) string {
+	return baseURL + querySeparator(baseURL) + "per_page=" + strconv.Itoa(perPage)
+}
+
+// follow the RFC 5988 Link header when present, without it (GitHub omit it when there is a single page)
+// a full page is the only reason to ask the next page number.
+func nextPageURL(linkHeader string, baseURL string, page int, itemCount int) string {
+	if linkHeader != "" {
+		return nextLink(linkHeader)
+	}
+
+	if itemCount < perPage {
+		return ""
+	}
+
+	return firstPageURL(baseURL) + "&page=" + strconv.Itoa(page+1)
+}
+
+// extract target of link with "next" relation (like `<https://api.github.com/...?page=2>; rel="next", <...>; rel="last"`).
+func nextLink(linkHeader string) string {
+	for _, link := range strings.Split(linkHeader, ",") {
+		target, params, found := strings.Cut(link, ";")
+		if !found {
+			continue
+		}
+
+		target = strings.TrimSpace(target)
+		if len(target) < 2 || target[0] != '<' || target[len(target)-1] != '>' {
+			continue
+		}
+
+		for _, param := range strings.Split(params, ";") {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if name != "rel" {
+				continue
+			}
+
+			for _, rel := range strings.Fields(strings.Trim(value, `"`)) {
+				if rel == "next" {
+					return target[1 : len(target)-1]
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+func querySeparator(callURL string) string {
+	if strings.Contains(callURL, "?") {
+		return "&"
+	}
+
+	return "?"
+}
@@ -0,0 +1,114 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package github
+
+import (
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/tofuutils/tenv/v2/pkg/httpclient"
+)
+
+// waits longer than maxRateLimitWait are not worth blocking a command, tenv fails immediately in that case.
+const maxRateLimitWait = time.Minute
+
+var rateLimitRetries atomic.Int64 //nolint
+
+// RateLimitError is returned when GitHub API rate limit is reached and retries are exhausted (or reset is too far).
+type RateLimitError struct {
+	Reset time.Time
+}
+
+func (e RateLimitError) Error() string {
+	if e.Reset.IsZero() {
+		return "GitHub API rate limited, set TENV_GITHUB_TOKEN to increase the limit"
+	}
+
+	return "GitHub API rate limited until " + e.Reset.Local().Format(time.DateTime) + ", set TENV_GITHUB_TOKEN to increase the limit"
+}
+
+// SetRateLimitRetries set the number of retries when GitHub API rate limit is reached (zero or negative disable them).
+func SetRateLimitRetries(retries int64) {
+	rateLimitRetries.Store(retries)
+}
+
+// send request, waiting and retrying while rate limited (each attempt consumes API budget).
+func doAPIRequest(request *http.Request) (*http.Response, error) {
+	for attempt := int64(0); ; attempt++ {
+		if err := consumeAPICall(); err != nil {
+			return nil, err
+		}
+
+		response, err := httpclient.Client().Do(request)
+		if err != nil {
+			return nil, err
+		}
+
+		limited, wait := rateLimitWait(response, attempt)
+		if !limited {
+			return response, nil
+		}
+		response.Body.Close()
+
+		if attempt >= rateLimitRetries.Load() || wait > maxRateLimitWait {
+			var reset time.Time
+			if wait > 0 {
+				reset = time.Now().Add(wait).Truncate(time.Second)
+			}
+
+			return nil, RateLimitError{Reset: reset}
+		}
+
+		time.Sleep(wait)
+	}
+}
+
+// Retry-After has priority over X-RateLimit-Reset,
+// without them (secondary rate limit) an exponential backoff is used.
+func rateLimitWait(response *http.Response, attempt int64) (bool, time.Duration) {
+	switch response.StatusCode {
+	case http.StatusTooManyRequests:
+	case http.StatusForbidden:
+		if response.Header.Get("Retry-After") == "" && response.Header.Get("X-RateLimit-Remaining") != "0" {
+			return false, 0
+		}
+	default:
+		return false, 0
+	}
+
+	if retryAfter := response.Header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			return true, time.Duration(seconds) * time.Second
+		}
+
+		if date, err := http.ParseTime(retryAfter); err == nil {
+			return true, max(time.Until(date), 0)
+		}
+	}
+
+	if response.Header.Get("X-RateLimit-Remaining") == "0" {
+		if epoch, err := strconv.ParseInt(response.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return true, max(time.Until(time.Unix(epoch, 0)), 0)
+		}
+	}
+
+	return true, time.Second << attempt
+}
@@ -20,14 +20,15 @@ package htmlquery
 
 import (
 	"io"
-	"net/http"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
+
+	"github.com/tofuutils/tenv/v2/pkg/httpclient"
 )
 
 func Request(callURL string, selector string, extractor func(*goquery.Selection) string) ([]string, error) {
-	response, err := http.Get(callURL)
+	response, err := httpclient.Get(callURL)
 	if err != nil {
 		return nil, err
 	}
@@ -36,6 +37,11 @@ func Request(callURL string, selector string, extractor func(*goquery.Selection)
 	return extractList(response.Body, selector, extractor)
 }
 
+// Extract works like Request on an already fetched document.
+func Extract(reader io.Reader, selector string, extractor func(*goquery.Selection) string) ([]string, error) {
+	return extractList(reader, selector, extractor)
+}
+
 func SelectionExtractor(part string) func(*goquery.Selection) string {
 	if part == "#text" {
 		return selectionTextExtractor
@@ -0,0 +1,185 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"os"
+)
+
+const (
+	defaultUserAgent    = "tenv"
+	maxIdleConnsPerHost = 8 // assets of a release are downloaded from the same host
+)
+
+var ErrCABundle = errors.New("no PEM certificate found in CA bundle")
+
+// Options tune the shared client, zero values keep defaults.
+type Options struct {
+	CABundlePath       string // PEM file of certificates trusted in addition to system ones (like a corporate proxy CA)
+	DisableHTTP2       bool
+	InsecureSkipVerify bool
+	MaxConnsPerHost    int // cap on concurrent connections toward a host (unlimited when 0)
+	UserAgent          string
+}
+
+var (
+	transport = newTransport()                                            //nolint
+	router    = &hostTransport{}                                          //nolint
+	client    = &http.Client{Transport: userAgentTransport{base: router}} //nolint
+	userAgent = defaultUserAgent                                          //nolint
+)
+
+// Client returns the client shared by github, download and retrievers calls.
+func Client() *http.Client {
+	return client
+}
+
+// Configure the shared client, must be called before any request.
+//
+// Proxies are always read from HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables (or their lower case versions).
+func Configure(options Options) error {
+	transport.MaxConnsPerHost = options.MaxConnsPerHost
+	if options.DisableHTTP2 {
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{} // non nil empty map disable HTTP/2
+	}
+
+	if options.UserAgent != "" {
+		userAgent = options.UserAgent
+	}
+
+	if options.CABundlePath == "" && !options.InsecureSkipVerify {
+		return nil
+	}
+
+	tlsConfig := &tls.Config{} //nolint
+	if transport.TLSClientConfig != nil {
+		tlsConfig = transport.TLSClientConfig.Clone()
+	}
+	tlsConfig.InsecureSkipVerify = options.InsecureSkipVerify //nolint
+
+	if options.CABundlePath != "" {
+		rootCAs, err := loadCABundle(options.CABundlePath)
+		if err != nil {
+			return err
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	return nil
+}
+
+func Get(url string) (*http.Response, error) {
+	return client.Get(url)
+}
+
+// Transport returns the underlying connection pool (to adjust its TLS configuration).
+func Transport() *http.Transport {
+	return transport
+}
+
+// SetClientCertificates present a client certificate to hosts (mutual TLS), no effect when certificates is empty.
+//
+// The host is matched with port (as in url.URL.Host), each one get its own connection pool cloned from Transport(),
+// so this must be called after other TLS adjustments.
+func SetClientCertificates(certificates map[string]tls.Certificate) {
+	if len(certificates) == 0 {
+		return
+	}
+
+	transports := make(map[string]*http.Transport, len(certificates))
+	for host, certificate := range certificates {
+		certTransport := transport.Clone()
+		if certTransport.TLSClientConfig == nil {
+			certTransport.TLSClientConfig = &tls.Config{} //nolint
+		}
+		certTransport.TLSClientConfig.Certificates = []tls.Certificate{certificate}
+		transports[host] = certTransport
+	}
+	router.transports = transports
+}
+
+// WrapTransport add a round tripper layer (like authentication) over the current one.
+func WrapTransport(wrap func(http.RoundTripper) http.RoundTripper) {
+	client.Transport = wrap(client.Transport)
+}
+
+// certificates are added to system pool (when available), so public hosts stay reachable.
+func loadCABundle(filePath string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	rootCAs, err := x509.SystemCertPool()
+	if err != nil {
+		rootCAs = x509.NewCertPool()
+	}
+
+	if !rootCAs.AppendCertsFromPEM(data) {
+		return nil, ErrCABundle
+	}
+
+	return rootCAs, nil
+}
+
+func newTransport() *http.Transport {
+	baseTransport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return &http.Transport{Proxy: http.ProxyFromEnvironment, MaxIdleConnsPerHost: maxIdleConnsPerHost} //nolint
+	}
+
+	cloned := baseTransport.Clone()
+	cloned.MaxIdleConnsPerHost = maxIdleConnsPerHost
+
+	return cloned
+}
+
+// route requests toward hosts with a client certificate to their dedicated connection pool.
+type hostTransport struct {
+	transports map[string]*http.Transport
+}
+
+func (t *hostTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if certTransport, ok := t.transports[request.URL.Host]; ok {
+		return certTransport.RoundTrip(request)
+	}
+
+	return transport.RoundTrip(request)
+}
+
+type userAgentTransport struct {
+	base http.RoundTripper
+}
+
+func (t userAgentTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if request.Header.Get("User-Agent") != "" {
+		return t.base.RoundTrip(request)
+	}
+
+	uaRequest := request.Clone(request.Context())
+	uaRequest.Header.Set("User-Agent", userAgent)
+
+	return t.base.RoundTrip(uaRequest)
+}
@@ -0,0 +1,131 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package httpclient_test
+
+import (
+	"crypto/tls"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/pkg/httpclient"
+)
+
+func TestUserAgent(t *testing.T) {
+	t.Parallel()
+
+	var userAgents []string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		userAgents = append(userAgents, request.Header.Get("User-Agent"))
+	}))
+	defer server.Close()
+
+	response, err := httpclient.Get(server.URL)
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+	response.Body.Close()
+
+	request, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+	request.Header.Set("User-Agent", "custom")
+
+	if response, err = httpclient.Client().Do(request); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+	response.Body.Close()
+
+	if len(userAgents) != 2 || userAgents[0] != "tenv" || userAgents[1] != "custom" {
+		t.Error("Unmatching results, get :", userAgents)
+	}
+}
+
+func TestCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+
+	dirPath := t.TempDir()
+	bundlePath := filepath.Join(dirPath, "ca.pem")
+	certData := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(bundlePath, certData, 0o644); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	invalidPath := filepath.Join(dirPath, "invalid.pem")
+	if err := os.WriteFile(invalidPath, []byte("not a certificate"), 0o644); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if err := httpclient.Configure(httpclient.Options{CABundlePath: invalidPath}); !errors.Is(err, httpclient.ErrCABundle) {
+		t.Error("Should fail on invalid CA bundle, get :", err)
+	}
+
+	if err := httpclient.Configure(httpclient.Options{CABundlePath: bundlePath}); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+	defer func() { httpclient.Transport().TLSClientConfig = nil }()
+
+	response, err := httpclient.Get(server.URL)
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+	response.Body.Close()
+}
+
+func TestClientCertificates(t *testing.T) {
+	var peerCount int
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(_ http.ResponseWriter, request *http.Request) {
+		peerCount = len(request.TLS.PeerCertificates)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert} //nolint
+	server.StartTLS()
+	defer server.Close()
+
+	httpclient.Transport().TLSClientConfig = &tls.Config{RootCAs: server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs} //nolint
+	defer func() { httpclient.Transport().TLSClientConfig = nil }()
+
+	if _, err := httpclient.Get(server.URL); err == nil {
+		t.Error("Should fail without client certificate")
+	}
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	// reuse server key pair as client certificate
+	httpclient.SetClientCertificates(map[string]tls.Certificate{serverURL.Host: server.TLS.Certificates[0]})
+
+	response, err := httpclient.Get(server.URL)
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+	response.Body.Close()
+
+	if peerCount != 1 {
+		t.Error("Unmatching results, get :", peerCount)
+	}
+}
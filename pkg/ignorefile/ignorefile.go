@@ -0,0 +1,205 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package ignorefile
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"regexp"
+	"strings"
+)
+
+type rule struct {
+	dirOnly bool
+	negate  bool
+	regex   *regexp.Regexp
+	whole   bool // match against whole relative path instead of last name
+}
+
+// Matcher follow gitignore syntax : '#' comment, '!' negation, trailing '/' for directory only,
+// leading or inner '/' anchor pattern to the ignore file directory, '*', '?', '[...]' and '**' wildcards.
+type Matcher struct {
+	rules []rule
+}
+
+func Parse(reader io.Reader) (Matcher, error) {
+	var rules []rule
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		if parsed, ok := parseLine(scanner.Text()); ok {
+			rules = append(rules, parsed)
+		}
+	}
+
+	return Matcher{rules: rules}, scanner.Err()
+}
+
+// Read return an empty Matcher when the file does not exist.
+func Read(filePath string) (Matcher, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return Matcher{}, nil
+		}
+
+		return Matcher{}, err
+	}
+	defer file.Close()
+
+	return Parse(file)
+}
+
+// Merge returns a Matcher applying rules of matchers in order (so the last one has the final say).
+func Merge(matchers ...Matcher) Matcher {
+	var rules []rule
+	for _, matcher := range matchers {
+		rules = append(rules, matcher.rules...)
+	}
+
+	return Matcher{rules: rules}
+}
+
+func (m Matcher) Empty() bool {
+	return len(m.rules) == 0
+}
+
+// Match expect a '/' separated path relative to the ignore file directory.
+// A path within an ignored directory is ignored.
+func (m Matcher) Match(relPath string, isDir bool) bool {
+	if len(m.rules) == 0 {
+		return false
+	}
+
+	parts := strings.Split(strings.Trim(relPath, "/"), "/")
+	for index := range parts {
+		last := index == len(parts)-1
+		if m.matchOne(strings.Join(parts[:index+1], "/"), parts[index], !last || isDir) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// last matching rule win.
+func (m Matcher) matchOne(relPath string, name string, isDir bool) bool {
+	ignored := false
+	for _, r := range m.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+
+		target := name
+		if r.whole {
+			target = relPath
+		}
+
+		if r.regex.MatchString(target) {
+			ignored = !r.negate
+		}
+	}
+
+	return ignored
+}
+
+func parseLine(line string) (rule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || line[0] == '#' {
+		return rule{}, false
+	}
+
+	var parsed rule
+	if line[0] == '!' {
+		parsed.negate = true
+		line = line[1:]
+	} else if line[0] == '\\' {
+		line = line[1:] // escaped '#' or '!'
+	}
+
+	if cleaned, found := strings.CutSuffix(line, "/"); found {
+		parsed.dirOnly = true
+		line = cleaned
+	}
+
+	if cleaned, found := strings.CutPrefix(line, "/"); found {
+		parsed.whole = true
+		line = cleaned
+	} else {
+		parsed.whole = strings.Contains(line, "/")
+	}
+
+	if line == "" {
+		return rule{}, false
+	}
+
+	regex, err := regexp.Compile(globToRegexp(line))
+	if err != nil {
+		return rule{}, false
+	}
+	parsed.regex = regex
+
+	return parsed, true
+}
+
+func globToRegexp(pattern string) string {
+	var builder strings.Builder
+	builder.WriteByte('^')
+	for index := 0; index < len(pattern); index++ {
+		switch char := pattern[index]; char {
+		case '*':
+			if index+1 < len(pattern) && pattern[index+1] == '*' {
+				index++
+				if index+1 < len(pattern) && pattern[index+1] == '/' {
+					index++
+					builder.WriteString("(?:.*/)?")
+				} else {
+					builder.WriteString(".*")
+				}
+			} else {
+				builder.WriteString("[^/]*")
+			}
+		case '?':
+			builder.WriteString("[^/]")
+		case '[':
+			if end := strings.IndexByte(pattern[index:], ']'); end > 0 {
+				class := pattern[index+1 : index+end]
+				if cleaned, found := strings.CutPrefix(class, "!"); found {
+					class = "^" + cleaned
+				}
+				builder.WriteString("[" + class + "]")
+				index += end
+			} else {
+				builder.WriteString(`\[`)
+			}
+		case '\\':
+			if index+1 < len(pattern) {
+				index++
+				builder.WriteString(regexp.QuoteMeta(pattern[index : index+1]))
+			}
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(char)))
+		}
+	}
+	builder.WriteByte('$')
+
+	return builder.String()
+}
@@ -0,0 +1,97 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package ignorefile_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/pkg/ignorefile"
+)
+
+const ignoreContent = `# test fixtures
+examples/
+/legacy.tf
+**/testdata/**
+*_test.tf
+!keep_test.tf
+`
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+
+	matcher, err := ignorefile.Parse(strings.NewReader(ignoreContent))
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	cases := map[string]bool{
+		"main.tf":                false,
+		"legacy.tf":              true,
+		"sub/legacy.tf":          false,
+		"examples/basic/main.tf": true,
+		"modules/examples/a.tf":  true,
+		"modules/testdata/b.tf":  true,
+		"versions_test.tf":       true,
+		"keep_test.tf":           false,
+	}
+	for relPath, expected := range cases {
+		if matcher.Match(relPath, false) != expected {
+			t.Error("Unexpected result for", relPath, ", expected :", expected)
+		}
+	}
+}
+
+func TestMatchDirOnly(t *testing.T) {
+	t.Parallel()
+
+	matcher, err := ignorefile.Parse(strings.NewReader("examples/"))
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if matcher.Match("examples", false) {
+		t.Error("File should not match a directory only pattern")
+	}
+	if !matcher.Match("examples", true) {
+		t.Error("Directory should match")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	t.Parallel()
+
+	gitMatcher, err := ignorefile.Parse(strings.NewReader("vendor/\n*.bak\n"))
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	tenvMatcher, err := ignorefile.Parse(strings.NewReader("!keep.bak\n"))
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	matcher := ignorefile.Merge(gitMatcher, tenvMatcher)
+	cases := map[string]bool{"vendor/main.tf": true, "old.bak": true, "keep.bak": false, "main.tf": false}
+	for relPath, expected := range cases {
+		if matcher.Match(relPath, false) != expected {
+			t.Error("Unexpected result for", relPath, ", expected :", expected)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package jobqueue
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	JobsPath = "/jobs"
+
+	// TokenFileName is written by the daemon in tenv root path (readable only by its owner),
+	// clients must send its content as bearer token.
+	TokenFileName = "jobs-token"
+)
+
+var ErrToken = errors.New("missing or invalid jobs token")
+
+type Request struct {
+	Priority string `json:"priority"`
+	Tool     string `json:"tool"`
+	Version  string `json:"version"`
+}
+
+// ServeHTTP handle GET and POST on /jobs, GET (with optional wait=true query) and DELETE on /jobs/<id>.
+func (q *Queue) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	idStr := strings.Trim(strings.TrimPrefix(request.URL.Path, JobsPath), "/")
+	if idStr == "" {
+		switch request.Method {
+		case http.MethodGet:
+			writeJSON(writer, http.StatusOK, q.List())
+		case http.MethodPost:
+			var jobRequest Request
+			if err := json.NewDecoder(request.Body).Decode(&jobRequest); err != nil || jobRequest.Tool == "" || jobRequest.Version == "" {
+				http.Error(writer, "invalid job request", http.StatusBadRequest)
+
+				return
+			}
+
+			if jobRequest.Priority == "" {
+				jobRequest.Priority = PriorityInteractive
+			}
+
+			job, err := q.Enqueue(jobRequest.Tool, jobRequest.Version, jobRequest.Priority)
+			if err != nil {
+				http.Error(writer, err.Error(), http.StatusBadRequest)
+
+				return
+			}
+			writeJSON(writer, http.StatusAccepted, job)
+		default:
+			http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+		}
+
+		return
+	}
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		http.Error(writer, ErrNotFound.Error(), http.StatusNotFound)
+
+		return
+	}
+
+	var job Job
+	switch request.Method {
+	case http.MethodGet:
+		if request.URL.Query().Get("wait") == "true" {
+			job, err = q.Wait(id)
+		} else {
+			job, err = q.Get(id)
+		}
+	case http.MethodDelete:
+		job, err = q.Cancel(id)
+	default:
+		http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	switch {
+	case errors.Is(err, ErrNotFound):
+		http.Error(writer, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrNotPending):
+		http.Error(writer, err.Error(), http.StatusConflict)
+	default:
+		writeJSON(writer, http.StatusOK, job)
+	}
+}
+
+// Authorize reject requests without the bearer token (401).
+func Authorize(handler http.Handler, token string) http.Handler {
+	expected := []byte("Bearer " + token)
+
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(request.Header.Get("Authorization")), expected) != 1 {
+			http.Error(writer, ErrToken.Error(), http.StatusUnauthorized)
+
+			return
+		}
+		handler.ServeHTTP(writer, request)
+	})
+}
+
+// WriteToken generate a random token and write it in filePath (replaced, mode 0600).
+func WriteToken(filePath string) (string, error) {
+	data := make([]byte, 32)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(data)
+
+	// a previous file would keep its permissions
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if _, err = file.WriteString(token); err != nil {
+		return "", err
+	}
+
+	return token, file.Close()
+}
+
+func ReadToken(filePath string) (string, error) {
+	data, err := os.ReadFile(filePath)
+
+	return strings.TrimSpace(string(data)), err
+}
+
+func writeJSON(writer http.ResponseWriter, status int, value any) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	_ = json.NewEncoder(writer).Encode(value)
+}
@@ -0,0 +1,164 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package jobqueue_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/pkg/jobqueue"
+)
+
+func TestAuthorize(t *testing.T) {
+	t.Parallel()
+
+	queue := jobqueue.Make(1, func(string, string) error { return nil })
+	server := httptest.NewServer(jobqueue.Authorize(queue, "secret"))
+	t.Cleanup(server.Close) // after parallel subtests
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+	}{
+		{name: "Missing", wantStatus: http.StatusUnauthorized},
+		{name: "Invalid", authorization: "Bearer other", wantStatus: http.StatusUnauthorized},
+		{name: "Valid", authorization: "Bearer secret", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			request, err := http.NewRequest(http.MethodGet, server.URL+jobqueue.JobsPath, nil)
+			if err != nil {
+				t.Fatal("Unexpected error :", err)
+			}
+			if tt.authorization != "" {
+				request.Header.Set("Authorization", tt.authorization)
+			}
+
+			response, err := http.DefaultClient.Do(request)
+			if err != nil {
+				t.Fatal("Unexpected error :", err)
+			}
+			response.Body.Close()
+
+			if response.StatusCode != tt.wantStatus {
+				t.Error("Unmatching status, get :", response.StatusCode)
+			}
+		})
+	}
+}
+
+func TestWriteToken(t *testing.T) {
+	t.Parallel()
+
+	filePath := filepath.Join(t.TempDir(), jobqueue.TokenFileName)
+	if err := os.WriteFile(filePath, []byte("previous"), 0o644); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	token, err := jobqueue.WriteToken(filePath)
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if read, err := jobqueue.ReadToken(filePath); err != nil || read != token || len(token) != 64 {
+		t.Error("Unmatching results, get :", read, token, err)
+	}
+
+	if info, err := os.Stat(filePath); runtime.GOOS != "windows" && (err != nil || info.Mode().Perm() != 0o600) {
+		t.Error("Token file should be private, get :", info.Mode(), err)
+	}
+}
+
+// meant to be run with -race : concurrent clients and workers.
+func TestQueueConcurrentRequests(t *testing.T) {
+	t.Parallel()
+
+	var mutex sync.Mutex
+	runs := map[string]int{}
+	queue := jobqueue.Make(4, func(tool string, version string) error {
+		mutex.Lock()
+		runs[tool+" "+version]++
+		mutex.Unlock()
+
+		return nil
+	})
+	server := httptest.NewServer(jobqueue.Authorize(queue, "secret"))
+	defer server.Close()
+
+	var group sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		group.Add(1)
+		go func(index int) {
+			defer group.Done()
+
+			body, _ := json.Marshal(jobqueue.Request{Tool: "tofu", Version: "1.6." + strconv.Itoa(index%5)})
+			var job jobqueue.Job
+			if err := call(server.URL, http.MethodPost, "", body, &job); err != nil {
+				t.Error("Unexpected error :", err)
+
+				return
+			}
+
+			var jobs []jobqueue.Job
+			if err := call(server.URL, http.MethodGet, "", nil, &jobs); err != nil {
+				t.Error("Unexpected error :", err)
+			}
+
+			if err := call(server.URL, http.MethodGet, "/"+strconv.FormatInt(job.ID, 10)+"?wait=true", nil, &job); err != nil || !job.Ended() {
+				t.Error("Unmatching results, get :", job, err)
+			}
+		}(i)
+	}
+	group.Wait()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	if len(runs) != 5 {
+		t.Error("Unmatching results, get :", runs)
+	}
+}
+
+func call(serverURL string, method string, subPath string, body []byte, result any) error {
+	request, err := http.NewRequest(method, serverURL+jobqueue.JobsPath+subPath, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", "Bearer secret")
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return json.NewDecoder(response.Body).Decode(result)
+}
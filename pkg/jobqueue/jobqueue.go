@@ -0,0 +1,258 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package jobqueue
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	PriorityInteractive = "interactive"
+	PriorityPrefetch    = "prefetch"
+
+	StatusCanceled = "canceled"
+	StatusDone     = "done"
+	StatusFailed   = "failed"
+	StatusPending  = "pending"
+	StatusRunning  = "running"
+
+	maxFinished = 100
+)
+
+var (
+	ErrNotFound   = errors.New("job not found")
+	ErrNotPending = errors.New("only pending job can be canceled")
+	ErrPriority   = errors.New("unknown priority, expected interactive or prefetch")
+)
+
+type Job struct {
+	Created  time.Time  `json:"created"`
+	Error    string     `json:"error,omitempty"`
+	Finished *time.Time `json:"finished,omitempty"`
+	ID       int64      `json:"id"`
+	Priority string     `json:"priority"`
+	Started  *time.Time `json:"started,omitempty"`
+	Status   string     `json:"status"`
+	Tool     string     `json:"tool"`
+	Version  string     `json:"version"`
+}
+
+func (j Job) Ended() bool {
+	return j.Status == StatusCanceled || j.Status == StatusDone || j.Status == StatusFailed
+}
+
+type RunFunc func(tool string, version string) error
+
+// Queue execute install jobs with bounded concurrency, interactive jobs before prefetch ones,
+// a job already pending or running for the same tool and version is reused.
+type Queue struct {
+	changed *sync.Cond
+	jobs    []*Job // creation order
+	mutex   sync.Mutex
+	nextID  int64
+	run     RunFunc
+}
+
+// Make start workers goroutines (at least one).
+func Make(workers int, run RunFunc) *Queue {
+	q := &Queue{run: run}
+	q.changed = sync.NewCond(&q.mutex)
+	for i := 0; i < max(workers, 1); i++ {
+		go q.work()
+	}
+
+	return q
+}
+
+func (q *Queue) Cancel(id int64) (Job, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	job := q.find(id)
+	if job == nil {
+		return Job{}, ErrNotFound
+	}
+
+	if job.Status != StatusPending {
+		return *job, ErrNotPending
+	}
+
+	now := time.Now()
+	job.Finished, job.Status = &now, StatusCanceled
+	q.changed.Broadcast()
+
+	return *job, nil
+}
+
+func (q *Queue) Enqueue(tool string, version string, priority string) (Job, error) {
+	if priority != PriorityInteractive && priority != PriorityPrefetch {
+		return Job{}, ErrPriority
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, job := range q.jobs {
+		if job.Tool != tool || job.Version != version || job.Ended() {
+			continue
+		}
+
+		if priority == PriorityInteractive {
+			job.Priority = priority
+		}
+
+		return *job, nil
+	}
+
+	q.nextID++
+	job := &Job{Created: time.Now(), ID: q.nextID, Priority: priority, Status: StatusPending, Tool: tool, Version: version}
+	q.jobs = append(q.jobs, job)
+	q.trim()
+	q.changed.Broadcast()
+
+	return *job, nil
+}
+
+func (q *Queue) Get(id int64) (Job, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if job := q.find(id); job != nil {
+		return *job, nil
+	}
+
+	return Job{}, ErrNotFound
+}
+
+func (q *Queue) List() []Job {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	jobs := make([]Job, 0, len(q.jobs))
+	for _, job := range q.jobs {
+		jobs = append(jobs, *job)
+	}
+
+	return jobs
+}
+
+// Wait until the job is ended.
+func (q *Queue) Wait(id int64) (Job, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for {
+		job := q.find(id)
+		if job == nil {
+			return Job{}, ErrNotFound
+		}
+
+		if job.Ended() {
+			return *job, nil
+		}
+		q.changed.Wait()
+	}
+}
+
+func (q *Queue) find(id int64) *Job {
+	for _, job := range q.jobs {
+		if job.ID == id {
+			return job
+		}
+	}
+
+	return nil
+}
+
+func (q *Queue) next() *Job {
+	var prefetch *Job
+	for _, job := range q.jobs {
+		if job.Status != StatusPending {
+			continue
+		}
+
+		if job.Priority == PriorityInteractive {
+			return job
+		}
+
+		if prefetch == nil {
+			prefetch = job
+		}
+	}
+
+	return prefetch
+}
+
+// keep only the most recent ended jobs.
+func (q *Queue) trim() {
+	endedCount := 0
+	for _, job := range q.jobs {
+		if job.Ended() {
+			endedCount++
+		}
+	}
+
+	toRemove := endedCount - maxFinished
+	if toRemove <= 0 {
+		return
+	}
+
+	q.jobs = slices.DeleteFunc(q.jobs, func(job *Job) bool {
+		if toRemove > 0 && job.Ended() {
+			toRemove--
+
+			return true
+		}
+
+		return false
+	})
+}
+
+func (q *Queue) work() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for {
+		job := q.next()
+		if job == nil {
+			q.changed.Wait()
+
+			continue
+		}
+
+		started := time.Now()
+		job.Started, job.Status = &started, StatusRunning
+		q.changed.Broadcast()
+
+		q.mutex.Unlock()
+		err := q.run(job.Tool, job.Version)
+		q.mutex.Lock()
+
+		finished := time.Now()
+		job.Finished, job.Status = &finished, StatusDone
+		if err != nil {
+			job.Error, job.Status = err.Error(), StatusFailed
+		}
+		q.trim()
+		q.changed.Broadcast()
+	}
+}
@@ -0,0 +1,112 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package jobqueue_test
+
+import (
+	"runtime"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/pkg/jobqueue"
+)
+
+type recorder struct {
+	mutex   sync.Mutex
+	release chan struct{}
+	runs    []string
+}
+
+func (r *recorder) run(tool string, version string) error {
+	<-r.release
+	r.mutex.Lock()
+	r.runs = append(r.runs, tool+" "+version)
+	r.mutex.Unlock()
+
+	return nil
+}
+
+func TestQueueDeduplicate(t *testing.T) {
+	t.Parallel()
+
+	rec := &recorder{release: make(chan struct{})}
+	queue := jobqueue.Make(1, rec.run)
+
+	first, err := queue.Enqueue("tofu", "1.6.2", jobqueue.PriorityPrefetch)
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	second, err := queue.Enqueue("tofu", "1.6.2", jobqueue.PriorityInteractive)
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if first.ID != second.ID || second.Priority != jobqueue.PriorityInteractive {
+		t.Error("Job should be reused with upgraded priority, get :", first, second)
+	}
+
+	close(rec.release)
+	job, err := queue.Wait(first.ID)
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if job.Status != jobqueue.StatusDone || len(queue.List()) != 1 {
+		t.Error("Unmatching results, get :", job, queue.List())
+	}
+}
+
+func TestQueuePriorityAndCancel(t *testing.T) {
+	t.Parallel()
+
+	rec := &recorder{release: make(chan struct{})}
+	queue := jobqueue.Make(1, rec.run)
+
+	blocking, _ := queue.Enqueue("terraform", "1.5.7", jobqueue.PriorityPrefetch)
+	for job, _ := queue.Get(blocking.ID); job.Status != jobqueue.StatusRunning; job, _ = queue.Get(blocking.ID) {
+		runtime.Gosched() // wait for worker to block on first job
+	}
+	prefetch, _ := queue.Enqueue("tofu", "1.6.0", jobqueue.PriorityPrefetch)
+	canceled, _ := queue.Enqueue("tofu", "1.7.0", jobqueue.PriorityPrefetch)
+	interactive, _ := queue.Enqueue("tofu", "1.8.0", jobqueue.PriorityInteractive)
+
+	if _, err := queue.Cancel(canceled.ID); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if _, err := queue.Enqueue("atmos", "1.0.0", "urgent"); err != jobqueue.ErrPriority {
+		t.Error("Incorrect error reported, get :", err)
+	}
+
+	close(rec.release)
+	for _, job := range []jobqueue.Job{blocking, prefetch, interactive} {
+		if _, err := queue.Wait(job.ID); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+	}
+
+	if !slices.Equal(rec.runs, []string{"terraform 1.5.7", "tofu 1.8.0", "tofu 1.6.0"}) {
+		t.Error("Unmatching results, get :", rec.runs)
+	}
+
+	if _, err := queue.Cancel(prefetch.ID); err != jobqueue.ErrNotPending {
+		t.Error("Incorrect error reported, get :", err)
+	}
+}
@@ -0,0 +1,87 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package junit
+
+import (
+	"encoding/xml"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/tofuutils/tenv/v2/pkg/fileperm"
+)
+
+type Failure struct {
+	Message string `xml:"message,attr"`
+}
+
+type TestCase struct {
+	Name      string   `xml:"name,attr"`
+	ClassName string   `xml:"classname,attr"`
+	Time      string   `xml:"time,attr"`
+	Failure   *Failure `xml:"failure,omitempty"`
+}
+
+type TestSuite struct {
+	XMLName   xml.Name   `xml:"testsuite"`
+	Name      string     `xml:"name,attr"`
+	Tests     int        `xml:"tests,attr"`
+	Failures  int        `xml:"failures,attr"`
+	Time      string     `xml:"time,attr"`
+	TestCases []TestCase `xml:"testcase"`
+}
+
+func MakeSuite(name string) TestSuite {
+	return TestSuite{Name: name}
+}
+
+// Add a test case, an empty failure message means success.
+func (s *TestSuite) Add(name string, duration time.Duration, failureMessage string) {
+	testCase := TestCase{Name: name, ClassName: s.Name, Time: formatDuration(duration)}
+	if failureMessage != "" {
+		testCase.Failure = &Failure{Message: failureMessage}
+		s.Failures++
+	}
+
+	s.Tests++
+	s.TestCases = append(s.TestCases, testCase)
+}
+
+func (s *TestSuite) Marshal(duration time.Duration) ([]byte, error) {
+	s.Time = formatDuration(duration)
+	data, err := xml.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), data...), nil
+}
+
+func (s *TestSuite) Write(filePath string, duration time.Duration) error {
+	data, err := s.Marshal(duration)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filePath, data, fileperm.FileMode())
+}
+
+func formatDuration(duration time.Duration) string {
+	return strconv.FormatFloat(duration.Seconds(), 'f', 3, 64)
+}
@@ -0,0 +1,48 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package junit_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tofuutils/tenv/v2/pkg/junit"
+)
+
+func TestMarshal(t *testing.T) {
+	t.Parallel()
+
+	suite := junit.MakeSuite("tenv exec")
+	suite.Add("1.6.0", time.Second, "")
+	suite.Add("1.7.0", 2*time.Second, "exit status 1")
+
+	data, err := suite.Marshal(3 * time.Second)
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	result := string(data)
+	if !strings.Contains(result, `<testsuite name="tenv exec" tests="2" failures="1" time="3.000">`) {
+		t.Error("Unexpected suite header, get :", result)
+	}
+	if !strings.Contains(result, `<failure message="exit status 1"></failure>`) {
+		t.Error("Missing failure, get :", result)
+	}
+}
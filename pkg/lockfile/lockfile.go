@@ -19,6 +19,7 @@
 package lockfile
 
 import (
+	"errors"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -27,6 +28,7 @@ import (
 
 	"github.com/hashicorp/go-hclog"
 
+	"github.com/tofuutils/tenv/v2/pkg/fileperm"
 	"github.com/tofuutils/tenv/v2/pkg/loghelper"
 )
 
@@ -35,17 +37,29 @@ const (
 	msgDelete = "can not remove .lock file"
 )
 
+var ErrTimeout = errors.New("timeout while waiting for .lock file")
+
 // ! dirPath must already exist (no mkdir here).
 // the returned function must be used to delete the lock.
-func Write(dirPath string, displayer loghelper.Displayer) func() {
+// a zero (or negative) timeout wait without limit.
+func Write(dirPath string, timeout time.Duration, displayer loghelper.Displayer) (func(), error) {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+
 	lockPath := filepath.Join(dirPath, ".lock")
 	for logLevel := hclog.Warn; true; logLevel = hclog.Info {
-		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL, 0o644) //nolint
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL, fileperm.FileMode()) //nolint
 		if err == nil {
 			f.Close()
 			break
 		}
 
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return nil, ErrTimeout
+		}
+
 		displayer.Log(logLevel, msgWrite, loghelper.Error, err)
 		time.Sleep(time.Second)
 	}
@@ -54,26 +68,22 @@ func Write(dirPath string, displayer loghelper.Displayer) func() {
 		if err := os.RemoveAll(lockPath); err != nil {
 			displayer.Log(hclog.Warn, msgDelete, loghelper.Error, err)
 		}
-	})
+	}), nil
 }
 
 // the returned function may be used to avoid goroutine leak
 // (also avoid conflicting behavior with versionmanager/proxy.transmitIncreasingSignal).
 func CleanAndExitOnInterrupt(clean func()) func() {
-	signalChan := make(chan os.Signal)
+	signalChan := make(chan os.Signal, 1) // signal package does not block on send, an unbuffered channel could miss the interrupt
 	endChan := make(chan struct{})
 	signal.Notify(signalChan, os.Interrupt)
 	go func() {
-		for {
-			select {
-			case <-signalChan:
-				clean()
-				os.Exit(1)
-			case <-endChan:
-				signal.Stop(signalChan)
-
-				break
-			}
+		select {
+		case <-signalChan:
+			clean()
+			os.Exit(1)
+		case <-endChan:
+			signal.Stop(signalChan)
 		}
 	}()
 
@@ -91,8 +91,27 @@ func TestParallelWriteRead(t *testing.T) {
 	}
 }
 
+func TestWriteTimeout(t *testing.T) {
+	t.Parallel()
+
+	timeoutDirPath := t.TempDir()
+
+	deleteLock, err := lockfile.Write(timeoutDirPath, 0, loghelper.InertDisplayer)
+	if err != nil {
+		t.Fatal("Unexpected error during test init :", err)
+	}
+	defer deleteLock()
+
+	if _, err = lockfile.Write(timeoutDirPath, time.Millisecond, loghelper.InertDisplayer); err != lockfile.ErrTimeout {
+		t.Error("Should fail on timeout, get :", err)
+	}
+}
+
 func writeReadFile(dirPath string, filePath string, data []byte, displayer loghelper.Displayer) ([]byte, error) {
-	deleteLock := lockfile.Write(dirPath, displayer)
+	deleteLock, err := lockfile.Write(dirPath, 0, displayer)
+	if err != nil {
+		return nil, err
+	}
 	defer deleteLock()
 
 	if err := os.WriteFile(filePath, data, 0o644); err != nil {
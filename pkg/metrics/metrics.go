@@ -0,0 +1,184 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Registry expose metrics with Prometheus text format (only used features are implemented : counters,
+// histograms and gauges computed at scrape time, with a single label).
+type Registry struct {
+	mutex      sync.Mutex
+	counters   []*Counter
+	gauges     []*GaugeFunc
+	histograms []*Histogram
+}
+
+type Counter struct {
+	help   string
+	label  string
+	name   string
+	values map[string]float64
+}
+
+// GaugeFunc compute values by label value on each scrape.
+type GaugeFunc struct {
+	compute func() map[string]float64
+	help    string
+	label   string
+	name    string
+}
+
+type Histogram struct {
+	buckets []float64
+	help    string
+	label   string
+	name    string
+	series  map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	counts []uint64 // cumulative by bucket
+	count  uint64
+	sum    float64
+}
+
+func (r *Registry) NewCounter(name string, help string, label string) *Counter {
+	counter := &Counter{help: help, label: label, name: name, values: map[string]float64{}}
+	r.mutex.Lock()
+	r.counters = append(r.counters, counter)
+	r.mutex.Unlock()
+
+	return counter
+}
+
+func (r *Registry) NewGaugeFunc(name string, help string, label string, compute func() map[string]float64) {
+	r.mutex.Lock()
+	r.gauges = append(r.gauges, &GaugeFunc{compute: compute, help: help, label: label, name: name})
+	r.mutex.Unlock()
+}
+
+// NewHistogram expect sorted buckets.
+func (r *Registry) NewHistogram(name string, help string, label string, buckets []float64) *Histogram {
+	histogram := &Histogram{buckets: buckets, help: help, label: label, name: name, series: map[string]*histogramSeries{}}
+	r.mutex.Lock()
+	r.histograms = append(r.histograms, histogram)
+	r.mutex.Unlock()
+
+	return histogram
+}
+
+func (r *Registry) Inc(counter *Counter, labelValue string) {
+	r.mutex.Lock()
+	counter.values[labelValue]++
+	r.mutex.Unlock()
+}
+
+func (r *Registry) Observe(histogram *Histogram, labelValue string, value float64) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	series, ok := histogram.series[labelValue]
+	if !ok {
+		series = &histogramSeries{counts: make([]uint64, len(histogram.buckets))}
+		histogram.series[labelValue] = series
+	}
+
+	for index, bound := range histogram.buckets {
+		if value <= bound {
+			series.counts[index]++
+		}
+	}
+	series.count++
+	series.sum += value
+}
+
+func (r *Registry) ServeHTTP(writer http.ResponseWriter, _ *http.Request) {
+	writer.Header().Set("Content-Type", contentType)
+	_ = r.Write(writer)
+}
+
+func (r *Registry) Write(writer io.Writer) error {
+	var builder strings.Builder
+
+	r.mutex.Lock()
+	for _, counter := range r.counters {
+		writeHeader(&builder, counter.name, counter.help, "counter")
+		writeValues(&builder, counter.name, counter.label, counter.values)
+	}
+
+	for _, histogram := range r.histograms {
+		writeHeader(&builder, histogram.name, histogram.help, "histogram")
+		for _, labelValue := range sortedKeys(histogram.series) {
+			series := histogram.series[labelValue]
+			prefix := histogram.name + "_bucket{" + histogram.label + "=" + strconv.Quote(labelValue) + ",le="
+			for index, bound := range histogram.buckets {
+				builder.WriteString(prefix + strconv.Quote(formatFloat(bound)) + "} " + strconv.FormatUint(series.counts[index], 10) + "\n")
+			}
+			builder.WriteString(prefix + "\"+Inf\"} " + strconv.FormatUint(series.count, 10) + "\n")
+			labels := "{" + histogram.label + "=" + strconv.Quote(labelValue) + "} "
+			builder.WriteString(histogram.name + "_sum" + labels + formatFloat(series.sum) + "\n")
+			builder.WriteString(histogram.name + "_count" + labels + strconv.FormatUint(series.count, 10) + "\n")
+		}
+	}
+	gauges := slices.Clone(r.gauges)
+	r.mutex.Unlock()
+
+	for _, gauge := range gauges { // computed without lock
+		writeHeader(&builder, gauge.name, gauge.help, "gauge")
+		writeValues(&builder, gauge.name, gauge.label, gauge.compute())
+	}
+
+	_, err := io.WriteString(writer, builder.String())
+
+	return err
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	return keys
+}
+
+func writeHeader(builder *strings.Builder, name string, help string, metricType string) {
+	builder.WriteString("# HELP " + name + " " + help + "\n")
+	builder.WriteString("# TYPE " + name + " " + metricType + "\n")
+}
+
+func writeValues(builder *strings.Builder, name string, label string, values map[string]float64) {
+	for _, labelValue := range sortedKeys(values) {
+		builder.WriteString(name + "{" + label + "=" + strconv.Quote(labelValue) + "} " + formatFloat(values[labelValue]) + "\n")
+	}
+}
@@ -0,0 +1,66 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package metrics_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/pkg/metrics"
+)
+
+const expectedOutput = `# HELP tenv_installs_total Number of installations.
+# TYPE tenv_installs_total counter
+tenv_installs_total{tool="tofu"} 2
+# HELP tenv_resolution_duration_seconds Resolution latency.
+# TYPE tenv_resolution_duration_seconds histogram
+tenv_resolution_duration_seconds_bucket{tool="tofu",le="0.1"} 1
+tenv_resolution_duration_seconds_bucket{tool="tofu",le="1"} 1
+tenv_resolution_duration_seconds_bucket{tool="tofu",le="+Inf"} 2
+tenv_resolution_duration_seconds_sum{tool="tofu"} 2.05
+tenv_resolution_duration_seconds_count{tool="tofu"} 2
+# HELP tenv_disk_usage_bytes Disk usage.
+# TYPE tenv_disk_usage_bytes gauge
+tenv_disk_usage_bytes{tool="tofu"} 1024
+`
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	var registry metrics.Registry
+	installs := registry.NewCounter("tenv_installs_total", "Number of installations.", "tool")
+	latency := registry.NewHistogram("tenv_resolution_duration_seconds", "Resolution latency.", "tool", []float64{0.1, 1})
+	registry.NewGaugeFunc("tenv_disk_usage_bytes", "Disk usage.", "tool", func() map[string]float64 {
+		return map[string]float64{"tofu": 1024}
+	})
+
+	registry.Inc(installs, "tofu")
+	registry.Inc(installs, "tofu")
+	registry.Observe(latency, "tofu", 0.05)
+	registry.Observe(latency, "tofu", 2)
+
+	var builder strings.Builder
+	if err := registry.Write(&builder); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if output := builder.String(); output != expectedOutput {
+		t.Error("Unmatching results, get :", output)
+	}
+}
@@ -0,0 +1,284 @@
+//go:build !minimal && !no_s3
+
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package s3
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	containerHost   = "http://169.254.170.2"
+	defaultProfile  = "default"
+	metadataTimeout = time.Second
+	metadataURL     = "http://169.254.169.254/latest/"
+	refreshMargin   = time.Minute
+)
+
+var errNoMetadata = errors.New("no credentials from instance metadata")
+
+type credentials struct {
+	AccessKeyID     string
+	Expiration      time.Time // zero when credentials do not expire
+	SecretAccessKey string
+	SessionToken    string
+}
+
+type credentialsCache struct {
+	cached  credentials
+	lock    sync.Mutex
+	loaded  bool
+	resolve func() (credentials, error)
+}
+
+// format returned by container and instance metadata endpoints.
+type remoteCredentials struct {
+	AccessKeyID     string    `json:"AccessKeyId"` //nolint
+	Expiration      time.Time `json:"Expiration"`
+	SecretAccessKey string    `json:"SecretAccessKey"`
+	Token           string    `json:"Token"`
+}
+
+func (c *credentialsCache) get() (credentials, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.loaded && (c.cached.Expiration.IsZero() || time.Until(c.cached.Expiration) > refreshMargin) {
+		return c.cached, nil
+	}
+
+	creds, err := c.resolve()
+	if err != nil {
+		return credentials{}, err
+	}
+	c.cached, c.loaded = creds, true
+
+	return creds, nil
+}
+
+// standard AWS chain : environment, shared credentials file, container endpoint then instance metadata (anonymous when none found).
+func resolveCredentials(httpClient *http.Client) (credentials, error) {
+	if accessKeyID, secretAccessKey := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); accessKeyID != "" && secretAccessKey != "" {
+		return credentials{AccessKeyID: accessKeyID, SecretAccessKey: secretAccessKey, SessionToken: os.Getenv("AWS_SESSION_TOKEN")}, nil
+	}
+
+	profileValues, err := readProfile(sharedFilePath("AWS_SHARED_CREDENTIALS_FILE", "credentials"), profileName())
+	if err != nil {
+		return credentials{}, err
+	}
+
+	if accessKeyID, secretAccessKey := profileValues["aws_access_key_id"], profileValues["aws_secret_access_key"]; accessKeyID != "" && secretAccessKey != "" {
+		return credentials{AccessKeyID: accessKeyID, SecretAccessKey: secretAccessKey, SessionToken: profileValues["aws_session_token"]}, nil
+	}
+
+	metadataClient := &http.Client{Transport: httpClient.Transport, Timeout: metadataTimeout}
+	if containerURL := containerCredentialsURL(); containerURL != "" {
+		request, err := http.NewRequest(http.MethodGet, containerURL, nil)
+		if err != nil {
+			return credentials{}, err
+		}
+
+		if token := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN"); token != "" {
+			request.Header.Set("Authorization", token)
+		}
+
+		return fetchRemoteCredentials(metadataClient, request)
+	}
+
+	if strings.EqualFold(os.Getenv("AWS_EC2_METADATA_DISABLED"), "true") {
+		return credentials{}, nil
+	}
+
+	creds, err := instanceCredentials(metadataClient)
+	if err != nil { // not on an EC2 instance (or no role attached)
+		return credentials{}, nil //nolint
+	}
+
+	return creds, nil
+}
+
+func containerCredentialsURL() string {
+	if fullURI := os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI"); fullURI != "" {
+		return fullURI
+	}
+
+	if relativeURI := os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); relativeURI != "" {
+		return containerHost + relativeURI
+	}
+
+	return ""
+}
+
+func fetchRemoteCredentials(metadataClient *http.Client, request *http.Request) (credentials, error) {
+	response, err := metadataClient.Do(request)
+	if err != nil {
+		return credentials{}, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return credentials{}, errors.New("credentials endpoint returned status " + response.Status)
+	}
+
+	var remoteCreds remoteCredentials
+	if err = json.NewDecoder(response.Body).Decode(&remoteCreds); err != nil {
+		return credentials{}, err
+	}
+
+	return credentials{AccessKeyID: remoteCreds.AccessKeyID, Expiration: remoteCreds.Expiration, SecretAccessKey: remoteCreds.SecretAccessKey, SessionToken: remoteCreds.Token}, nil
+}
+
+// IMDSv2 : session token, then role name, then role credentials.
+func instanceCredentials(metadataClient *http.Client) (credentials, error) {
+	request, err := http.NewRequest(http.MethodPut, metadataURL+"api/token", nil)
+	if err != nil {
+		return credentials{}, err
+	}
+	request.Header.Set("X-Aws-Ec2-Metadata-Token-Ttl-Seconds", "21600")
+
+	token, err := readMetadata(metadataClient, request)
+	if err != nil {
+		return credentials{}, err
+	}
+
+	rolesURL := metadataURL + "meta-data/iam/security-credentials/"
+	if request, err = http.NewRequest(http.MethodGet, rolesURL, nil); err != nil {
+		return credentials{}, err
+	}
+	request.Header.Set("X-Aws-Ec2-Metadata-Token", token)
+
+	roles, err := readMetadata(metadataClient, request)
+	if err != nil {
+		return credentials{}, err
+	}
+
+	role, _, _ := strings.Cut(strings.TrimSpace(roles), "\n")
+	if role == "" {
+		return credentials{}, errNoMetadata
+	}
+
+	if request, err = http.NewRequest(http.MethodGet, rolesURL+role, nil); err != nil {
+		return credentials{}, err
+	}
+	request.Header.Set("X-Aws-Ec2-Metadata-Token", token)
+
+	return fetchRemoteCredentials(metadataClient, request)
+}
+
+func readMetadata(metadataClient *http.Client, request *http.Request) (string, error) {
+	response, err := metadataClient.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return "", errNoMetadata
+	}
+
+	var builder strings.Builder
+	scanner := bufio.NewScanner(response.Body)
+	for scanner.Scan() {
+		builder.WriteString(scanner.Text())
+		builder.WriteByte('\n')
+	}
+
+	return strings.TrimSpace(builder.String()), scanner.Err()
+}
+
+func profileName() string {
+	if name := os.Getenv("AWS_PROFILE"); name != "" {
+		return name
+	}
+
+	return defaultProfile
+}
+
+// read values of a section in an AWS ini file (nil without error when the file does not exist).
+func readProfile(filePath string, section string) (map[string]string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+	defer file.Close()
+
+	values := map[string]string{}
+	inSection := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || line[0] == '#' || line[0] == ';':
+		case line[0] == '[':
+			inSection = strings.TrimSpace(strings.Trim(line, "[]")) == section
+		case inSection:
+			if key, value, found := strings.Cut(line, "="); found {
+				values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+			}
+		}
+	}
+
+	return values, scanner.Err()
+}
+
+func resolveRegion() string {
+	for _, envName := range []string{"AWS_REGION", "AWS_DEFAULT_REGION"} {
+		if region := os.Getenv(envName); region != "" {
+			return region
+		}
+	}
+
+	section := profileName()
+	if section != defaultProfile {
+		section = "profile " + section
+	}
+
+	if profileValues, err := readProfile(sharedFilePath("AWS_CONFIG_FILE", "config"), section); err == nil && profileValues["region"] != "" {
+		return profileValues["region"]
+	}
+
+	return defaultRegion
+}
+
+func sharedFilePath(envName string, fileName string) string {
+	if filePath := os.Getenv(envName); filePath != "" {
+		return filePath
+	}
+
+	userPath, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(userPath, ".aws", fileName)
+}
@@ -0,0 +1,283 @@
+//go:build !minimal && !no_s3
+
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package s3
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tofuutils/tenv/v2/pkg/download"
+	"github.com/tofuutils/tenv/v2/pkg/httpclient"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+)
+
+const (
+	bucketRegionHeader = "X-Amz-Bucket-Region"
+	defaultRegion      = "us-east-1"
+)
+
+var ErrURL = errors.New("s3 url must have the form s3://<bucket>/<key>")
+
+var (
+	sharedClient     *client //nolint
+	sharedClientOnce sync.Once
+)
+
+type client struct {
+	credentials *credentialsCache
+	endpoint    string // custom endpoint (like MinIO) called with path style, empty for AWS
+	httpClient  *http.Client
+	region      string
+}
+
+type listResult struct {
+	CommonPrefixes []struct {
+		Prefix string `xml:"Prefix"`
+	} `xml:"CommonPrefixes"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+type responseError struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+func init() {
+	download.RegisterScheme(Scheme, Fetch)
+}
+
+// Fetch download an object from its s3://<bucket>/<key> url (with standard AWS credential chain).
+func Fetch(rawURL string) ([]byte, error) {
+	bucket, key, err := parseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return defaultClient().get(bucket, key, nil)
+}
+
+// List returns names of sub directories (common prefixes without trailing slash) of an s3://<bucket>/<prefix> url.
+func List(rawURL string) ([]string, error) {
+	bucket, prefix, err := parseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return defaultClient().list(bucket, prefix)
+}
+
+func defaultClient() *client {
+	sharedClientOnce.Do(func() {
+		endpoint := os.Getenv("AWS_ENDPOINT_URL_S3")
+		if endpoint == "" {
+			endpoint = os.Getenv("AWS_ENDPOINT_URL")
+		}
+
+		httpClient := httpclient.Client()
+		sharedClient = &client{
+			credentials: &credentialsCache{resolve: func() (credentials, error) {
+				return resolveCredentials(httpClient)
+			}},
+			endpoint: strings.TrimRight(endpoint, "/"), httpClient: httpClient, region: resolveRegion(),
+		}
+	})
+
+	return sharedClient
+}
+
+func (c *client) get(bucket string, key string, query url.Values) ([]byte, error) {
+	data, header, err := c.call(c.region, bucket, key, query)
+	if err == nil || header == nil {
+		return data, err
+	}
+
+	// bucket in another region, AWS tells which one
+	if region := header.Get(bucketRegionHeader); region != "" && region != c.region {
+		data, _, err = c.call(region, bucket, key, query)
+	}
+
+	return data, err
+}
+
+func (c *client) call(region string, bucket string, key string, query url.Values) ([]byte, http.Header, error) {
+	creds, err := c.credentials.get()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	canonicalURI, canonicalQuery := encodePath(bucket, key, c.endpoint != "" || strings.Contains(bucket, ".")), encodeQuery(query)
+	host := loghelper.Concat(bucket, ".s3.", region, ".amazonaws.com")
+	switch {
+	case c.endpoint != "":
+		host = c.endpoint
+	case strings.Contains(bucket, "."): // virtual host style would not match certificate
+		host = loghelper.Concat("s3.", region, ".amazonaws.com")
+	}
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+
+	callURL := host + canonicalURI
+	if canonicalQuery != "" {
+		callURL = loghelper.Concat(callURL, "?", canonicalQuery)
+	}
+
+	request, err := http.NewRequest(http.MethodGet, callURL, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	sign(request, canonicalURI, canonicalQuery, creds, region, time.Now())
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch {
+	case response.StatusCode == http.StatusNotFound:
+		return nil, response.Header, download.ErrNotFound
+	case response.StatusCode >= http.StatusMultipleChoices:
+		var s3Err responseError
+		if xml.Unmarshal(data, &s3Err) == nil && s3Err.Code != "" {
+			return nil, response.Header, errors.New(loghelper.Concat("s3 ", s3Err.Code, " : ", s3Err.Message))
+		}
+
+		return nil, response.Header, errors.New("s3 call failed with status " + response.Status)
+	}
+
+	return data, nil, nil
+}
+
+func (c *client) list(bucket string, prefix string) ([]string, error) {
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	var names []string
+	query := url.Values{"delimiter": {"/"}, "list-type": {"2"}, "prefix": {prefix}}
+	for {
+		data, err := c.get(bucket, "", query)
+		if err != nil {
+			return nil, err
+		}
+
+		var result listResult
+		if err = xml.Unmarshal(data, &result); err != nil {
+			return nil, err
+		}
+
+		for _, commonPrefix := range result.CommonPrefixes {
+			if name := strings.TrimSuffix(strings.TrimPrefix(commonPrefix.Prefix, prefix), "/"); name != "" {
+				names = append(names, name)
+			}
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return names, nil
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+// path style includes bucket name in path.
+func encodePath(bucket string, key string, pathStyle bool) string {
+	var pathBuilder strings.Builder
+	if pathStyle {
+		pathBuilder.WriteByte('/')
+		pathBuilder.WriteString(uriEncode(bucket))
+	}
+
+	pathBuilder.WriteByte('/')
+	for index, part := range strings.Split(key, "/") {
+		if index != 0 {
+			pathBuilder.WriteByte('/')
+		}
+		pathBuilder.WriteString(uriEncode(part))
+	}
+
+	return pathBuilder.String()
+}
+
+// sorted by key, as required for signature.
+func encodeQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		for _, value := range query[key] {
+			parts = append(parts, loghelper.Concat(uriEncode(key), "=", uriEncode(value)))
+		}
+	}
+
+	return strings.Join(parts, "&")
+}
+
+func parseURL(rawURL string) (string, string, error) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", err
+	}
+
+	if parsedURL.Scheme != Scheme || parsedURL.Host == "" {
+		return "", "", ErrURL
+	}
+
+	return parsedURL.Host, strings.Trim(parsedURL.Path, "/"), nil
+}
+
+// RFC 3986 unreserved characters are kept, everything else is percent encoded.
+func uriEncode(value string) string {
+	const hexDigits = "0123456789ABCDEF"
+
+	var encoded strings.Builder
+	for index := 0; index < len(value); index++ {
+		char := value[index]
+		if ('A' <= char && char <= 'Z') || ('a' <= char && char <= 'z') || ('0' <= char && char <= '9') || char == '-' || char == '.' || char == '_' || char == '~' {
+			encoded.WriteByte(char)
+
+			continue
+		}
+		encoded.WriteByte('%')
+		encoded.WriteByte(hexDigits[char>>4])
+		encoded.WriteByte(hexDigits[char&0xF])
+	}
+
+	return encoded.String()
+}
@@ -0,0 +1,99 @@
+//go:build !minimal && !no_s3
+
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package s3
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+)
+
+const (
+	algorithm        = "AWS4-HMAC-SHA256"
+	amzDateFormat    = "20060102T150405Z"
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	service          = "s3"
+)
+
+// sign add AWS Signature Version 4 headers to a request without body (no effect with anonymous credentials).
+func sign(request *http.Request, canonicalURI string, canonicalQuery string, creds credentials, region string, now time.Time) {
+	if creds.AccessKeyID == "" {
+		return
+	}
+
+	amzDate := now.UTC().Format(amzDateFormat)
+	shortDate := amzDate[:8]
+	request.Header.Set("X-Amz-Content-Sha256", emptyPayloadHash)
+	request.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		request.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers := map[string]string{"host": request.URL.Host}
+	for name, values := range request.Header {
+		if lowerName := strings.ToLower(name); lowerName == "range" || strings.HasPrefix(lowerName, "x-amz-") {
+			headers[lowerName] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(loghelper.Concat(name, ":", headers[name], "\n"))
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{request.Method, canonicalURI, canonicalQuery, canonicalHeaders.String(), signedHeaders, emptyPayloadHash}, "\n")
+	scope := strings.Join([]string{shortDate, region, service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{algorithm, amzDate, scope, hashHex(canonicalRequest)}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), shortDate)
+	for _, part := range []string{region, service, "aws4_request"} {
+		signingKey = hmacSHA256(signingKey, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	request.Header.Set("Authorization", loghelper.Concat(algorithm, " Credential=", creds.AccessKeyID, "/", scope, ",SignedHeaders=", signedHeaders, ",Signature=", signature))
+}
+
+func hashHex(value string) string {
+	hashed := sha256.Sum256([]byte(value))
+
+	return hex.EncodeToString(hashed[:])
+}
+
+func hmacSHA256(key []byte, value string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+
+	return mac.Sum(nil)
+}
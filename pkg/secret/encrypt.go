@@ -0,0 +1,208 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+const (
+	EncryptedPrefix = "enc:"
+
+	keyAccount = "encryption-key"
+	keyLabel   = "tenv encryption key"
+	keyService = "tenv"
+	keySize    = 32
+)
+
+var (
+	ErrCipher = errors.New("invalid encrypted value")
+	ErrKey    = errors.New("invalid encryption key stored in keychain")
+)
+
+var (
+	cachedKey []byte //nolint
+	keyMutex  sync.Mutex
+)
+
+// Decrypt a value produced by Encrypt, other values are returned unchanged.
+func Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+
+	key, err := loadKey(false)
+	if err != nil {
+		return "", err
+	}
+
+	return DecryptWithKey(key, value)
+}
+
+func DecryptWithKey(key []byte, value string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, EncryptedPrefix))
+	if err != nil {
+		return "", ErrCipher
+	}
+
+	gcm, err := makeGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonceSize := gcm.NonceSize()
+	if len(data) < nonceSize {
+		return "", ErrCipher
+	}
+
+	plaintext, err := gcm.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return "", ErrCipher
+	}
+
+	return string(plaintext), nil
+}
+
+// Encrypt a value with AES-256-GCM, the key is stored in OS keychain (generated on first use).
+func Encrypt(plaintext string) (string, error) {
+	key, err := loadKey(true)
+	if err != nil {
+		return "", err
+	}
+
+	return EncryptWithKey(key, plaintext)
+}
+
+func EncryptWithKey(key []byte, plaintext string) (string, error) {
+	gcm, err := makeGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	return EncryptedPrefix + base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(plaintext), nil)), nil
+}
+
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, EncryptedPrefix)
+}
+
+func loadKey(create bool) ([]byte, error) {
+	keyMutex.Lock()
+	defer keyMutex.Unlock()
+
+	if cachedKey != nil {
+		return cachedKey, nil
+	}
+
+	key, err := resolveOrCreateKey(create, Resolve, storeKey)
+	if err != nil {
+		return nil, err
+	}
+	cachedKey = key
+
+	return key, nil
+}
+
+// a new key is generated only when the keychain explicitly reports a missing key,
+// any other error (locked keychain, missing command, ...) is returned to not overwrite the existing key.
+func resolveOrCreateKey(create bool, resolve func(string) (string, error), store func(string) error) ([]byte, error) {
+	encodedKey, err := resolve(KeychainPrefix + keyService + ":" + keyAccount)
+	switch {
+	case err == nil:
+		key, err := base64.StdEncoding.DecodeString(encodedKey)
+		if err != nil || len(key) != keySize {
+			return nil, ErrKey
+		}
+
+		return key, nil
+	case !create || !errors.Is(err, ErrNotFound):
+		return nil, err
+	}
+
+	key := make([]byte, keySize)
+	if _, err = rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	if err = store(base64.StdEncoding.EncodeToString(key)); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func makeGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func storeKey(encodedKey string) error {
+	cmd, err := storeCommand(runtime.GOOS, encodedKey)
+	if err != nil {
+		return err
+	}
+
+	var errBuffer strings.Builder
+	cmd.Stderr = &errBuffer
+
+	if err := cmd.Run(); err != nil {
+		if errMsg := strings.TrimSpace(errBuffer.String()); errMsg != "" {
+			return errors.New(errMsg)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// the key is always written on standard input, never in arguments (visible to other users in process list) :
+// security reads its command in interactive mode (base64 key does not need quoting).
+func storeCommand(goos string, encodedKey string) (*exec.Cmd, error) {
+	var cmd *exec.Cmd
+	switch goos {
+	case "darwin":
+		cmd = exec.Command("security", "-i")
+		cmd.Stdin = strings.NewReader("add-generic-password -U -s " + keyService + " -a " + keyAccount + " -l \"" + keyLabel + "\" -w " + encodedKey + "\n")
+	case "linux", "freebsd", "openbsd", "netbsd":
+		cmd = exec.Command("secret-tool", "store", "--label", keyLabel, "service", keyService, "account", keyAccount)
+		cmd.Stdin = strings.NewReader(encodedKey)
+	default:
+		return nil, ErrUnsupported
+	}
+
+	return cmd, nil
+}
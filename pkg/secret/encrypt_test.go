@@ -0,0 +1,163 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package secret
+
+import (
+	"encoding/base64"
+	"errors"
+	"io"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var testKey = []byte("0123456789abcdef0123456789abcdef")
+
+func TestDecryptPlainValue(t *testing.T) {
+	t.Parallel()
+
+	value, err := Decrypt("plain-token")
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if value != "plain-token" {
+		t.Error("Unmatching results, get :", value)
+	}
+}
+
+func TestEncryptRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	encrypted, err := EncryptWithKey(testKey, "ghp_secret")
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if !IsEncrypted(encrypted) {
+		t.Fatal("Missing prefix, get :", encrypted)
+	}
+
+	value, err := DecryptWithKey(testKey, encrypted)
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if value != "ghp_secret" {
+		t.Error("Unmatching results, get :", value)
+	}
+}
+
+func TestDecryptTampered(t *testing.T) {
+	t.Parallel()
+
+	encrypted, err := EncryptWithKey(testKey, "ghp_secret")
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	replacement := "A"
+	if encrypted[20] == 'A' {
+		replacement = "B"
+	}
+	tampered := encrypted[:20] + replacement + encrypted[21:]
+
+	if _, err = DecryptWithKey(testKey, tampered); err != ErrCipher {
+		t.Error("Incorrect error reported, get :", err)
+	}
+}
+
+func TestResolveOrCreateKey(t *testing.T) {
+	t.Parallel()
+
+	errLocked := errors.New("keychain locked")
+	encodedKey := base64.StdEncoding.EncodeToString(testKey)
+	tests := []struct {
+		name        string
+		create      bool
+		resolveErr  error
+		wantErr     error
+		wantCreated bool
+	}{
+		{name: "Stored", create: true},
+		{name: "NotFoundCreate", create: true, resolveErr: ErrNotFound, wantCreated: true},
+		{name: "NotFoundNoCreate", resolveErr: ErrNotFound, wantErr: ErrNotFound},
+		{name: "Locked", create: true, resolveErr: errLocked, wantErr: errLocked},
+		{name: "MissingCommand", create: true, resolveErr: exec.ErrNotFound, wantErr: exec.ErrNotFound},
+		{name: "Unsupported", create: true, resolveErr: ErrUnsupported, wantErr: ErrUnsupported},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			stored := ""
+			resolve := func(string) (string, error) {
+				if tt.resolveErr != nil {
+					return "", tt.resolveErr
+				}
+
+				return encodedKey, nil
+			}
+			store := func(value string) error {
+				stored = value
+
+				return nil
+			}
+
+			key, err := resolveOrCreateKey(tt.create, resolve, store)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatal("Unmatching error, get :", err)
+			}
+
+			if created := stored != ""; created != tt.wantCreated {
+				t.Error("Unmatching key creation, get :", created)
+			}
+
+			if tt.wantErr == nil && !tt.wantCreated && string(key) != string(testKey) {
+				t.Error("Unmatching key, get :", key)
+			}
+		})
+	}
+}
+
+func TestStoreCommand(t *testing.T) {
+	t.Parallel()
+
+	for _, goos := range []string{"darwin", "linux"} {
+		cmd, err := storeCommand(goos, "c2VjcmV0LWtleQ==")
+		if err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+
+		if slices.ContainsFunc(cmd.Args, func(arg string) bool { return strings.Contains(arg, "c2VjcmV0LWtleQ==") }) {
+			t.Error("Key should not be in arguments, get :", cmd.Args)
+		}
+
+		if input, err := io.ReadAll(cmd.Stdin); err != nil || !strings.Contains(string(input), "c2VjcmV0LWtleQ==") {
+			t.Error("Key should be on standard input, get :", string(input), err)
+		}
+	}
+
+	if _, err := storeCommand("windows", "c2VjcmV0LWtleQ=="); err != ErrUnsupported {
+		t.Error("Should fail on unsupported platform, get :", err)
+	}
+}
@@ -0,0 +1,145 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package secret
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+const (
+	KeychainPrefix = "keychain:"
+	OnePassPrefix  = "op:"
+	VaultPrefix    = "vault:"
+
+	onePassRefPrefix = "op://"
+)
+
+var (
+	ErrEmpty       = errors.New("secret source returned an empty value")
+	ErrNotFound    = errors.New("secret not found in keychain")
+	ErrSource      = errors.New("unknown secret source, expected keychain:, op: or vault: prefix")
+	ErrUnsupported = errors.New("keychain secret source not supported on this platform")
+	ErrVaultField  = errors.New("vault secret source must have the form vault:<path>#<field>")
+)
+
+// Resolve a secret from an external source, supported format :
+//   - keychain:<service>[:<account>] (macOS security or libsecret secret-tool)
+//   - op:<reference> or op://<vault>/<item>/<field> (1Password CLI)
+//   - vault:<path>#<field> (Vault KV via vault CLI).
+func Resolve(source string) (string, error) {
+	name, args, err := command(source)
+	if err != nil {
+		return "", err
+	}
+
+	var errBuffer strings.Builder
+	cmd := exec.Command(name, args...)
+	cmd.Stderr = &errBuffer
+
+	output, err := cmd.Output()
+	if err != nil {
+		errMsg := strings.TrimSpace(errBuffer.String())
+		if strings.HasPrefix(source, KeychainPrefix) && keychainNotFound(runtime.GOOS, err, errMsg) {
+			return "", ErrNotFound
+		}
+
+		if errMsg != "" {
+			return "", errors.New(errMsg)
+		}
+
+		return "", err
+	}
+
+	value := string(bytes.TrimSpace(output))
+	if value == "" {
+		return "", ErrEmpty
+	}
+
+	return value, nil
+}
+
+// IsSource returns true when value has a secret source prefix (keychain:, op: or vault:).
+func IsSource(value string) bool {
+	return strings.HasPrefix(value, KeychainPrefix) || strings.HasPrefix(value, OnePassPrefix) || strings.HasPrefix(value, VaultPrefix)
+}
+
+func command(source string) (string, []string, error) {
+	switch {
+	case strings.HasPrefix(source, KeychainPrefix):
+		service, account, _ := strings.Cut(source[len(KeychainPrefix):], ":")
+
+		return keychainCommand(service, account)
+	case strings.HasPrefix(source, onePassRefPrefix):
+		return "op", []string{"read", "--no-newline", source}, nil
+	case strings.HasPrefix(source, OnePassPrefix):
+		return "op", []string{"read", "--no-newline", source[len(OnePassPrefix):]}, nil
+	case strings.HasPrefix(source, VaultPrefix):
+		path, field, found := strings.Cut(source[len(VaultPrefix):], "#")
+		if !found || path == "" || field == "" {
+			return "", nil, ErrVaultField
+		}
+
+		return "vault", []string{"kv", "get", "-field=" + field, path}, nil
+	default:
+		return "", nil, ErrSource
+	}
+}
+
+func keychainCommand(service string, account string) (string, []string, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		args := []string{"find-generic-password", "-w", "-s", service}
+		if account != "" {
+			args = append(args, "-a", account)
+		}
+
+		return "security", args, nil
+	case "linux", "freebsd", "openbsd", "netbsd":
+		args := []string{"lookup", "service", service}
+		if account != "" {
+			args = append(args, "account", account)
+		}
+
+		return "secret-tool", args, nil
+	default:
+		return "", nil, ErrUnsupported
+	}
+}
+
+// only an explicit missing item is reported as not found (a locked keychain or a missing command are other errors) :
+// security exits with code 44 (errSecItemNotFound), secret-tool lookup exits with code 1 without message.
+func keychainNotFound(goos string, err error, errMsg string) bool {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return false
+	}
+
+	switch goos {
+	case "darwin":
+		return exitErr.ExitCode() == 44
+	case "linux", "freebsd", "openbsd", "netbsd":
+		return exitErr.ExitCode() == 1 && errMsg == ""
+	default:
+		return false
+	}
+}
@@ -0,0 +1,100 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package secret
+
+import (
+	"os/exec"
+	"slices"
+	"testing"
+)
+
+func TestCommandOnePass(t *testing.T) {
+	t.Parallel()
+
+	name, args, err := command("op://dev/github/token")
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if name != "op" || !slices.Equal(args, []string{"read", "--no-newline", "op://dev/github/token"}) {
+		t.Error("Unmatching results, get :", name, args)
+	}
+}
+
+func TestCommandUnknown(t *testing.T) {
+	t.Parallel()
+
+	if _, _, err := command("plain-token"); err != ErrSource {
+		t.Error("Incorrect error reported, get :", err)
+	}
+}
+
+func TestCommandVault(t *testing.T) {
+	t.Parallel()
+
+	name, args, err := command("vault:secret/tenv#github_token")
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if name != "vault" || !slices.Equal(args, []string{"kv", "get", "-field=github_token", "secret/tenv"}) {
+		t.Error("Unmatching results, get :", name, args)
+	}
+}
+
+func TestCommandVaultNoField(t *testing.T) {
+	t.Parallel()
+
+	if _, _, err := command("vault:secret/tenv"); err != ErrVaultField {
+		t.Error("Incorrect error reported, get :", err)
+	}
+}
+
+func TestKeychainNotFound(t *testing.T) {
+	t.Parallel()
+
+	exitErr := func(code string) error {
+		return exec.Command("sh", "-c", "exit "+code).Run()
+	}
+
+	tests := []struct {
+		name   string
+		goos   string
+		err    error
+		errMsg string
+		want   bool
+	}{
+		{name: "DarwinMissingItem", goos: "darwin", err: exitErr("44"), want: true},
+		{name: "DarwinLocked", goos: "darwin", err: exitErr("51"), errMsg: "User interaction is not allowed."},
+		{name: "LinuxMissingItem", goos: "linux", err: exitErr("1"), want: true},
+		{name: "LinuxDBusError", goos: "linux", err: exitErr("1"), errMsg: "Cannot autolaunch D-Bus without X11 $DISPLAY"},
+		{name: "MissingCommand", goos: "linux", err: exec.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := keychainNotFound(tt.goos, tt.err, tt.errMsg); got != tt.want {
+				t.Error("Unmatching result, get :", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package targz
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tofuutils/tenv/v2/pkg/fileperm"
+)
+
+// TarDir write a gzipped tar archive of regular files in dirPath accepted by filter (called with paths relative to dirPath),
+// returns the relative paths of archived files.
+func TarDir(dirPath string, writer io.Writer, filter func(string) bool) ([]string, error) {
+	gzipWriter, err := gzip.NewWriterLevel(writer, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	tarWriter := tar.NewWriter(gzipWriter)
+	err = filepath.WalkDir(dirPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.Type().IsRegular() {
+			return err
+		}
+
+		name, err := filepath.Rel(dirPath, path)
+		if err != nil || !filter(name) {
+			return err
+		}
+
+		if err = copyFileToTar(tarWriter, path, filepath.ToSlash(name)); err != nil {
+			return err
+		}
+		names = append(names, name)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tarWriter.Close(); err != nil {
+		return nil, err
+	}
+
+	return names, gzipWriter.Close()
+}
+
+// UntarToDir extract a gzipped tar archive held in memory.
+func UntarToDir(dataTarGz []byte, dirPath string, filter func(string) bool) error {
+	return UntarStream(bytes.NewReader(dataTarGz), dirPath, filter)
+}
+
+// UntarStream extract entries while reading (tar archives are sequential), ensure the directory exists with a MkdirAll call.
+func UntarStream(reader io.Reader, dirPath string, filter func(string) bool) error {
+	err := os.MkdirAll(dirPath, fileperm.DirMode())
+	if err != nil {
+		return err
+	}
+
+	gzipReader, err := gzip.NewReader(reader)
+	if err != nil {
+		return err
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err = copyTarEntryToDir(header, tarReader, dirPath, filter); err != nil {
+			return err
+		}
+	}
+}
+
+// WalkStream call fn with the name and content of each regular file entry (without extracting them).
+func WalkStream(reader io.Reader, fn func(name string, content io.Reader) error) error {
+	gzipReader, err := gzip.NewReader(reader)
+	if err != nil {
+		return err
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if err = fn(header.Name, tarReader); err != nil {
+			return err
+		}
+	}
+}
+
+// links and other special entries are ignored.
+func copyTarEntryToDir(header *tar.Header, reader io.Reader, dirPath string, filter func(string) bool) error {
+	destPath, err := sanitizeArchivePath(dirPath, header.Name)
+	if err != nil {
+		return err
+	}
+
+	switch header.Typeflag {
+	case tar.TypeDir:
+		return os.MkdirAll(destPath, fileperm.DirMode())
+	case tar.TypeReg:
+	default:
+		return nil
+	}
+
+	if !filter(destPath) {
+		return nil
+	}
+
+	if err = os.MkdirAll(filepath.Dir(destPath), fileperm.DirMode()); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(destPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, header.FileInfo().Mode().Perm())
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(file, reader) //nolint
+
+	return err
+}
+
+func copyFileToTar(tarWriter *tar.Writer, filePath string, name string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = name
+
+	if err = tarWriter.WriteHeader(header); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(tarWriter, file) //nolint
+
+	return err
+}
+
+// Sanitize archive file pathing from "G305" (file traversal).
+func sanitizeArchivePath(dirPath string, fileName string) (string, error) {
+	destPath := filepath.Join(dirPath, fileName)
+	if strings.HasPrefix(destPath, filepath.Clean(dirPath)) {
+		return destPath, nil
+	}
+
+	return "", fmt.Errorf("content filepath is tainted: %s", fileName)
+}
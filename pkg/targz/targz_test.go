@@ -0,0 +1,86 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package targz_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/pkg/targz"
+)
+
+func TestUntarToDir(t *testing.T) {
+	t.Parallel()
+
+	var buffer bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buffer)
+	tarWriter := tar.NewWriter(gzipWriter)
+	entries := []tar.Header{
+		{Name: "conftest", Mode: 0o755, Typeflag: tar.TypeReg},
+		{Name: "LICENSE", Mode: 0o644, Typeflag: tar.TypeReg},
+		{Name: "doc/", Mode: 0o755, Typeflag: tar.TypeDir},
+		{Name: "doc/README.md", Mode: 0o644, Typeflag: tar.TypeReg},
+	}
+	for _, header := range entries {
+		header := header
+		content := []byte("content of " + header.Name)
+		if header.Typeflag == tar.TypeReg {
+			header.Size = int64(len(content))
+		}
+		if err := tarWriter.WriteHeader(&header); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if _, err := tarWriter.Write(content); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	dirPath := t.TempDir()
+	if err := targz.UntarToDir(buffer.Bytes(), dirPath, func(path string) bool { return filepath.Base(path) != "LICENSE" }); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	for _, name := range []string{"conftest", "doc/README.md"} {
+		data, err := os.ReadFile(filepath.Join(dirPath, name))
+		if err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+
+		if string(data) != "content of "+name {
+			t.Error("Unmatching results, get :", string(data))
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dirPath, "LICENSE")); !os.IsNotExist(err) {
+		t.Error("Filtered entry should not be extracted, get :", err)
+	}
+}
@@ -0,0 +1,118 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package telemetry
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+	"runtime"
+
+	"github.com/tofuutils/tenv/v2/pkg/apimsg"
+	"github.com/tofuutils/tenv/v2/pkg/fileperm"
+	"github.com/tofuutils/tenv/v2/pkg/httpclient"
+)
+
+// Report only contains aggregated counters, no path, argument, version constraint or identifier.
+type Report struct {
+	Arch     string         `json:"arch"`
+	Commands map[string]int `json:"commands"`
+	Errors   map[string]int `json:"errors"`
+	OS       string         `json:"os"`
+	Tools    map[string]int `json:"tools"`
+	Version  string         `json:"version"`
+}
+
+func MakeReport(tenvVersion string) Report {
+	return Report{
+		Arch: runtime.GOARCH, Commands: map[string]int{}, Errors: map[string]int{},
+		OS: runtime.GOOS, Tools: map[string]int{}, Version: tenvVersion,
+	}
+}
+
+// Read return an empty report when the file does not exist.
+func Read(filePath string, tenvVersion string) (Report, error) {
+	report := MakeReport(tenvVersion)
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return report, nil
+		}
+
+		return report, err
+	}
+
+	if err = json.Unmarshal(data, &report); err != nil {
+		return MakeReport(tenvVersion), err
+	}
+	report.Version = tenvVersion // keep only current version
+
+	return report, nil
+}
+
+// Record increment counters in the local file (empty values are not counted).
+func Record(filePath string, tenvVersion string, command string, tool string, errorCategory string) error {
+	report, err := Read(filePath, tenvVersion)
+	if err != nil {
+		report = MakeReport(tenvVersion) // reset corrupted file
+	}
+
+	increment(report.Commands, command)
+	increment(report.Tools, tool)
+	increment(report.Errors, errorCategory)
+
+	data, err := report.Marshal()
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filePath, data, fileperm.FileMode())
+}
+
+func (r Report) Marshal() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+func Send(url string, report Report) error {
+	data, err := report.Marshal()
+	if err != nil {
+		return err
+	}
+
+	response, err := httpclient.Client().Post(url, "application/json", bytes.NewReader(data)) //nolint
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= http.StatusBadRequest {
+		return apimsg.ErrReturn
+	}
+
+	return nil
+}
+
+func increment(counters map[string]int, key string) {
+	if key != "" {
+		counters[key]++
+	}
+}
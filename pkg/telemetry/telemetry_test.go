@@ -0,0 +1,47 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package telemetry_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/pkg/telemetry"
+)
+
+func TestRecord(t *testing.T) {
+	t.Parallel()
+
+	filePath := filepath.Join(t.TempDir(), "telemetry.json")
+	if err := telemetry.Record(filePath, "v1.0.0", "install", "tofu", ""); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+	if err := telemetry.Record(filePath, "v1.1.0", "install", "tf", "network"); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	report, err := telemetry.Read(filePath, "v1.1.0")
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if report.Commands["install"] != 2 || report.Tools["tofu"] != 1 || report.Tools["tf"] != 1 || report.Errors["network"] != 1 || len(report.Errors) != 1 {
+		t.Error("Unmatching results, get :", report)
+	}
+}
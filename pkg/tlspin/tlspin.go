@@ -0,0 +1,134 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package tlspin
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"sync"
+
+	"github.com/hashicorp/go-hclog"
+
+	"github.com/tofuutils/tenv/v2/pkg/fileperm"
+	"github.com/tofuutils/tenv/v2/pkg/httpclient"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+)
+
+// Pinner record on first use the public key fingerprint of selected hosts and warn when it changes later.
+type Pinner struct {
+	displayer loghelper.Displayer
+	filePath  string
+	hosts     map[string]struct{}
+	mutex     sync.Mutex
+	pins      map[string]string
+	warned    map[string]struct{}
+}
+
+// Install add pin verification to shared http client transport for hosts (no effect when hosts is empty).
+func Install(filePath string, hosts []string, displayer loghelper.Displayer) error {
+	if len(hosts) == 0 {
+		return nil
+	}
+
+	transport := httpclient.Transport()
+	pinner, err := makePinner(filePath, hosts, displayer)
+	if err != nil {
+		return err
+	}
+
+	tlsConfig := &tls.Config{} //nolint
+	if transport.TLSClientConfig != nil {
+		tlsConfig = transport.TLSClientConfig.Clone()
+	}
+	tlsConfig.VerifyConnection = pinner.verifyConnection
+	transport.TLSClientConfig = tlsConfig
+
+	return nil
+}
+
+func makePinner(filePath string, hosts []string, displayer loghelper.Displayer) (*Pinner, error) {
+	pins := map[string]string{}
+	data, err := os.ReadFile(filePath)
+	if err == nil {
+		err = json.Unmarshal(data, &pins)
+	} else if errors.Is(err, fs.ErrNotExist) {
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	hostSet := make(map[string]struct{}, len(hosts))
+	for _, host := range hosts {
+		hostSet[host] = struct{}{}
+	}
+
+	return &Pinner{displayer: displayer, filePath: filePath, hosts: hostSet, pins: pins, warned: map[string]struct{}{}}, nil
+}
+
+// check return false when fingerprint differ from the recorded one.
+func (p *Pinner) check(host string, fingerprint string) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	pinned, ok := p.pins[host]
+	if ok {
+		return pinned == fingerprint
+	}
+
+	p.pins[host] = fingerprint
+	data, err := json.MarshalIndent(p.pins, "", "  ")
+	if err == nil {
+		err = os.WriteFile(p.filePath, data, fileperm.FileMode())
+	}
+
+	if err == nil {
+		p.displayer.Log(hclog.Debug, "Recorded remote public key fingerprint", "host", host, "fingerprint", fingerprint)
+	} else {
+		p.displayer.Log(hclog.Warn, "Failed to record remote public key fingerprint", loghelper.Error, err)
+	}
+
+	return true
+}
+
+// never fail the connection, certificate chain is still verified by standard library.
+func (p *Pinner) verifyConnection(state tls.ConnectionState) error {
+	if _, ok := p.hosts[state.ServerName]; !ok || len(state.PeerCertificates) == 0 {
+		return nil
+	}
+
+	sum := sha256.Sum256(state.PeerCertificates[0].RawSubjectPublicKeyInfo)
+	if fingerprint := hex.EncodeToString(sum[:]); !p.check(state.ServerName, fingerprint) {
+		p.mutex.Lock()
+		_, alreadyWarned := p.warned[state.ServerName]
+		p.warned[state.ServerName] = struct{}{}
+		p.mutex.Unlock()
+
+		if !alreadyWarned {
+			p.displayer.Log(hclog.Error, loghelper.Concat("WARNING : public key of ", state.ServerName, " changed since first use (possible DNS hijacking), if the change is legitimate remove its entry from ", p.filePath), "fingerprint", fingerprint)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package tlspin
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+)
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	filePath := filepath.Join(t.TempDir(), "pins.json")
+	pinner, err := makePinner(filePath, []string{"mirror.example.com"}, loghelper.InertDisplayer)
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if !pinner.check("mirror.example.com", "aaaa") {
+		t.Error("First use should be accepted")
+	}
+
+	// reload recorded pins
+	pinner, err = makePinner(filePath, []string{"mirror.example.com"}, loghelper.InertDisplayer)
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if !pinner.check("mirror.example.com", "aaaa") {
+		t.Error("Same fingerprint should be accepted")
+	}
+
+	if pinner.check("mirror.example.com", "bbbb") {
+		t.Error("Changed fingerprint should be reported")
+	}
+}
@@ -0,0 +1,146 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package updatebot
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	renovateFileNames   = []string{"renovate.json", ".renovaterc", ".renovaterc.json", ".github/renovate.json", ".gitlab/renovate.json"}
+	dependabotFileNames = []string{".github/dependabot.yml", ".github/dependabot.yaml"}
+)
+
+// Rule is a version constraint read from an update bot configuration.
+type Rule struct {
+	Source     string // configuration file path, relative to the scanned directory
+	Constraint string
+	Exclude    bool // true when matching versions are ignored by the bot
+}
+
+type renovateConfig struct {
+	PackageRules []struct {
+		AllowedVersions   string   `json:"allowedVersions"`
+		MatchDepNames     []string `json:"matchDepNames"`
+		MatchPackageNames []string `json:"matchPackageNames"`
+	} `json:"packageRules"`
+}
+
+type dependabotConfig struct {
+	Updates []struct {
+		Ignore []struct {
+			DependencyName string   `yaml:"dependency-name"`
+			Versions       []string `yaml:"versions"`
+		} `yaml:"ignore"`
+	} `yaml:"updates"`
+}
+
+// Read returns rules concerning one of depNames from Renovate (packageRules allowedVersions)
+// and Dependabot (ignore versions) configuration files in dirPath.
+//
+// Renovate regex or glob allowedVersions are skipped and returned in separate slice.
+func Read(dirPath string, depNames []string) ([]Rule, []Rule, error) {
+	var rules, skipped []Rule
+	for _, fileName := range renovateFileNames {
+		data, err := readFile(dirPath, fileName)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(data) == 0 {
+			continue
+		}
+
+		var parsed renovateConfig
+		if err = json.Unmarshal(data, &parsed); err != nil {
+			return nil, nil, err
+		}
+
+		for _, packageRule := range parsed.PackageRules {
+			if packageRule.AllowedVersions == "" || !matchAny(depNames, packageRule.MatchDepNames, packageRule.MatchPackageNames) {
+				continue
+			}
+
+			rule := Rule{Source: fileName, Constraint: packageRule.AllowedVersions}
+			if strings.HasPrefix(rule.Constraint, "/") || strings.HasPrefix(rule.Constraint, "!/") {
+				skipped = append(skipped, rule)
+			} else {
+				rules = append(rules, rule)
+			}
+		}
+	}
+
+	for _, fileName := range dependabotFileNames {
+		data, err := readFile(dirPath, fileName)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(data) == 0 {
+			continue
+		}
+
+		var parsed dependabotConfig
+		if err = yaml.Unmarshal(data, &parsed); err != nil {
+			return nil, nil, err
+		}
+
+		for _, update := range parsed.Updates {
+			for _, ignore := range update.Ignore {
+				if !slices.Contains(depNames, ignore.DependencyName) {
+					continue
+				}
+
+				for _, versions := range ignore.Versions {
+					rules = append(rules, Rule{Source: fileName, Constraint: versions, Exclude: true})
+				}
+			}
+		}
+	}
+
+	return rules, skipped, nil
+}
+
+func matchAny(depNames []string, nameLists ...[]string) bool {
+	for _, names := range nameLists {
+		for _, name := range names {
+			if slices.Contains(depNames, name) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// return nil when the file does not exist.
+func readFile(dirPath string, fileName string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(dirPath, filepath.FromSlash(fileName)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	return data, err
+}
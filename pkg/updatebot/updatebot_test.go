@@ -0,0 +1,72 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package updatebot_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/pkg/updatebot"
+)
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+
+	dirPath := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dirPath, ".github"), 0o755); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	renovateData := `{"packageRules": [
+		{"matchDepNames": ["hashicorp/terraform"], "allowedVersions": "< 1.8"},
+		{"matchPackageNames": ["hashicorp/terraform"], "allowedVersions": "/^1\\./"},
+		{"matchDepNames": ["opentofu/opentofu"], "allowedVersions": "< 1.7"}
+	]}`
+	if err := os.WriteFile(filepath.Join(dirPath, "renovate.json"), []byte(renovateData), 0o644); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	dependabotData := `version: 2
+updates:
+  - package-ecosystem: terraform
+    directory: /
+    ignore:
+      - dependency-name: hashicorp/terraform
+        versions: [">= 1.7.5"]
+`
+	if err := os.WriteFile(filepath.Join(dirPath, ".github", "dependabot.yml"), []byte(dependabotData), 0o644); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	rules, skipped, err := updatebot.Read(dirPath, []string{"hashicorp/terraform"})
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	expected := []updatebot.Rule{{Source: "renovate.json", Constraint: "< 1.8"}, {Source: ".github/dependabot.yml", Constraint: ">= 1.7.5", Exclude: true}}
+	if !slices.Equal(rules, expected) {
+		t.Error("Unmatching results, get :", rules)
+	}
+
+	if len(skipped) != 1 || skipped[0].Constraint != "/^1\\./" {
+		t.Error("Unmatching skipped results, get :", skipped)
+	}
+}
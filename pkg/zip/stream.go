@@ -0,0 +1,225 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package zip
+
+import (
+	"bufio"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tofuutils/tenv/v2/pkg/fileperm"
+)
+
+const (
+	centralHeaderSignature = 0x02014b50
+	descriptorSignature    = 0x08074b50
+	localHeaderSignature   = 0x04034b50
+
+	descriptorFlag = 0x8
+	methodDeflate  = 8
+	methodStore    = 0
+	unixCreator    = 3
+)
+
+var (
+	ErrCRC        = errors.New("zip entry crc32 mismatch")
+	ErrStreamable = errors.New("zip entry not readable as stream")
+)
+
+// UnzipStream extract entries while reading (no random access), file modes are applied
+// once the central directory is reached. Reading stops at the central directory end,
+// so caller must drain reader when trailing data is needed (like hashing).
+func UnzipStream(reader io.Reader, dirPath string, filter func(string) bool) error {
+	err := os.MkdirAll(dirPath, fileperm.DirMode())
+	if err != nil {
+		return err
+	}
+
+	// a flate reader does not read ahead of compressed data with an io.ByteReader
+	bufReader := bufio.NewReader(reader)
+	written := map[string]string{} // entry name to written path
+	for {
+		var signature uint32
+		if err = binary.Read(bufReader, binary.LittleEndian, &signature); err != nil {
+			return err
+		}
+
+		switch signature {
+		case localHeaderSignature:
+			if err = streamEntry(bufReader, dirPath, filter, written); err != nil {
+				return err
+			}
+		case centralHeaderSignature:
+			return applyModes(bufReader, written)
+		default:
+			return ErrStreamable
+		}
+	}
+}
+
+func streamEntry(reader *bufio.Reader, dirPath string, filter func(string) bool, written map[string]string) error {
+	var header struct {
+		Version, Flags, Method, ModTime, ModDate uint16
+		CRC, CompressedSize, Size                uint32
+		NameLen, ExtraLen                        uint16
+	}
+	if err := binary.Read(reader, binary.LittleEndian, &header); err != nil {
+		return err
+	}
+
+	nameAndExtra := make([]byte, int(header.NameLen)+int(header.ExtraLen))
+	if _, err := io.ReadFull(reader, nameAndExtra); err != nil {
+		return err
+	}
+	name := string(nameAndExtra[:header.NameLen])
+
+	hasDescriptor := header.Flags&descriptorFlag != 0
+	var content io.Reader
+	switch {
+	case header.Method == methodDeflate:
+		content = flate.NewReader(reader)
+	case header.Method == methodStore && !hasDescriptor:
+		content = io.LimitReader(reader, int64(header.CompressedSize))
+	default:
+		return ErrStreamable
+	}
+
+	destPath, err := sanitizeArchivePath(dirPath, name)
+	if err != nil {
+		return err
+	}
+
+	hasher := crc32.NewIEEE()
+	content = io.TeeReader(content, hasher)
+	switch {
+	case strings.HasSuffix(name, "/"): // filepath.Join removes the trailing separator
+		err = os.MkdirAll(destPath, fileperm.DirMode())
+	case filter(destPath):
+		err = writeStream(destPath, content)
+		written[name] = destPath
+	}
+	if err != nil {
+		return err
+	}
+
+	// consume remaining entry data (directory or filtered entry)
+	if _, err = io.Copy(io.Discard, content); err != nil {
+		return err
+	}
+
+	expectedCRC := header.CRC
+	if hasDescriptor {
+		if expectedCRC, err = readDescriptorCRC(reader); err != nil {
+			return err
+		}
+	}
+
+	if hasher.Sum32() != expectedCRC {
+		return ErrCRC
+	}
+
+	return nil
+}
+
+// data descriptor signature is optional, 32 bits sizes are assumed (no zip64 entry).
+func readDescriptorCRC(reader io.Reader) (uint32, error) {
+	var values [3]uint32
+	if err := binary.Read(reader, binary.LittleEndian, &values); err != nil {
+		return 0, err
+	}
+
+	if values[0] != descriptorSignature {
+		return values[0], nil
+	}
+
+	var size uint32
+
+	return values[1], binary.Read(reader, binary.LittleEndian, &size)
+}
+
+func writeStream(destPath string, content io.Reader) error {
+	// entries are not required to follow their directory entry
+	if err := os.MkdirAll(filepath.Dir(destPath), fileperm.DirMode()); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(destPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(file, content)
+
+	return err
+}
+
+// central directory entries follow each other, the first signature is already read.
+func applyModes(reader io.Reader, written map[string]string) error {
+	for {
+		var header struct {
+			CreatorVersion, ReaderVersion, Flags, Method, ModTime, ModDate uint16
+			CRC, CompressedSize, Size                                      uint32
+			NameLen, ExtraLen, CommentLen, Disk, InternalAttrs             uint16
+			ExternalAttrs, Offset                                          uint32
+		}
+		if err := binary.Read(reader, binary.LittleEndian, &header); err != nil {
+			return err
+		}
+
+		nameExtraComment := make([]byte, int(header.NameLen)+int(header.ExtraLen)+int(header.CommentLen))
+		if _, err := io.ReadFull(reader, nameExtraComment); err != nil {
+			return err
+		}
+
+		if destPath, ok := written[string(nameExtraComment[:header.NameLen])]; ok {
+			if err := os.Chmod(destPath, entryMode(header.CreatorVersion, header.ExternalAttrs)); err != nil {
+				return err
+			}
+		}
+
+		var signature uint32
+		if err := binary.Read(reader, binary.LittleEndian, &signature); err != nil {
+			return err
+		}
+
+		if signature != centralHeaderSignature {
+			return nil
+		}
+	}
+}
+
+// same permission bits as archive/zip FileHeader.Mode, restricted by configured umask (chmod ignores it).
+func entryMode(creatorVersion uint16, externalAttrs uint32) os.FileMode {
+	if creatorVersion>>8 == unixCreator {
+		return os.FileMode(externalAttrs>>16) & fileperm.DirMode()
+	}
+
+	if externalAttrs&0x01 != 0 { // msdos read only
+		return 0o444
+	}
+
+	return fileperm.FileMode()
+}
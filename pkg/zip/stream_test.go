@@ -0,0 +1,122 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package zip_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/pkg/pathfilter"
+	tenvzip "github.com/tofuutils/tenv/v2/pkg/zip"
+)
+
+func TestUnzipStream(t *testing.T) {
+	t.Parallel()
+
+	var buffer bytes.Buffer
+	zipWriter := zip.NewWriter(&buffer)
+	entries := []struct {
+		name   string
+		method uint16
+		mode   os.FileMode
+	}{{"LICENSE.txt", zip.Store, 0o644}, {"tofu", zip.Deflate, 0o755}}
+	for _, entry := range entries {
+		content := []byte("content of " + entry.name)
+		header := &zip.FileHeader{Name: entry.name, Method: entry.method}
+		header.SetMode(entry.mode)
+
+		// stored entry with sizes in local header (no data descriptor)
+		create := zipWriter.CreateHeader
+		if entry.method == zip.Store {
+			header.CRC32 = crc32.ChecksumIEEE(content)
+			header.CompressedSize64, header.UncompressedSize64 = uint64(len(content)), uint64(len(content))
+			create = zipWriter.CreateRaw
+		}
+
+		writer, err := create(header)
+		if err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+		if _, err = writer.Write(content); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	dirPath := t.TempDir()
+	if err := tenvzip.UnzipStream(&buffer, dirPath, pathfilter.NameEqual("tofu")); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dirPath, "tofu"))
+	if err != nil || string(data) != "content of tofu" {
+		t.Error("Unmatching results, get :", string(data), err)
+	}
+
+	if info, err := os.Stat(filepath.Join(dirPath, "tofu")); err != nil || info.Mode().Perm() != 0o755 {
+		t.Error("Unmatching mode, get :", info, err)
+	}
+
+	if _, err = os.Stat(filepath.Join(dirPath, "LICENSE.txt")); !os.IsNotExist(err) {
+		t.Error("Filtered entry should not be written, get :", err)
+	}
+}
+
+func TestUnzipStreamDirectories(t *testing.T) {
+	t.Parallel()
+
+	var buffer bytes.Buffer
+	zipWriter := zip.NewWriter(&buffer)
+	for _, name := range []string{"docs/", "docs/README.md", "bin/tofu"} { // bin has no directory entry
+		writer, err := zipWriter.Create(name)
+		if err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+		if name[len(name)-1] == '/' {
+			continue
+		}
+		if _, err = writer.Write([]byte(name)); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	dirPath := t.TempDir()
+	if err := tenvzip.UnzipStream(&buffer, dirPath, func(string) bool { return true }); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if info, err := os.Stat(filepath.Join(dirPath, "docs")); err != nil || !info.IsDir() {
+		t.Error("Directory entry should be a directory, get :", info, err)
+	}
+
+	for _, name := range []string{"docs/README.md", "bin/tofu"} {
+		if data, err := os.ReadFile(filepath.Join(dirPath, name)); err != nil || string(data) != name {
+			t.Error("Unmatching results, get :", string(data), err)
+		}
+	}
+}
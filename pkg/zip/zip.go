@@ -25,12 +25,22 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
+
+	"github.com/tofuutils/tenv/v2/pkg/fileperm"
 )
 
+// maximum number of entries extracted concurrently.
+const maxWorkers = 8
+
 // ensure the directory exists with a MkdirAll call.
+//
+// Entries are independent with random access, so they are extracted concurrently (bounded worker pool),
+// UnzipStream is the ordered fallback when the archive is read as a stream.
 func UnzipToDir(dataZip []byte, dirPath string, filter func(string) bool) error {
-	err := os.MkdirAll(dirPath, 0o755)
+	err := os.MkdirAll(dirPath, fileperm.DirMode())
 	if err != nil {
 		return err
 	}
@@ -41,8 +51,42 @@ func UnzipToDir(dataZip []byte, dirPath string, filter func(string) bool) error
 		return err
 	}
 
+	workers := min(runtime.NumCPU(), maxWorkers, len(zipReader.File))
+	if workers < 2 {
+		for _, file := range zipReader.File {
+			if err = copyZipFileToDir(file, dirPath, filter); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	files := make(chan *zip.File)
+	errs := make([]error, workers)
+	var waitGroup sync.WaitGroup
+	for index := 0; index < workers; index++ {
+		index := index
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+
+			for file := range files {
+				if errs[index] == nil { // keep consuming after an error to not block the producer
+					errs[index] = copyZipFileToDir(file, dirPath, filter)
+				}
+			}
+		}()
+	}
+
 	for _, file := range zipReader.File {
-		if err = copyZipFileToDir(file, dirPath, filter); err != nil {
+		files <- file
+	}
+	close(files)
+	waitGroup.Wait()
+
+	for _, err := range errs {
+		if err != nil {
 			return err
 		}
 	}
@@ -57,9 +101,18 @@ func copyZipFileToDir(zipFile *zip.File, dirPath string, filter func(string) boo
 		return err
 	}
 
-	if destPath[len(destPath)-1] == '/' {
+	if strings.HasSuffix(zipFile.Name, "/") {
 		// trailing slash indicates a directory
-		return os.MkdirAll(destPath, 0o755)
+		return os.MkdirAll(destPath, fileperm.DirMode())
+	}
+
+	if !filter(destPath) {
+		return nil
+	}
+
+	// directory entries can be listed after their files or be handled by another worker
+	if err = os.MkdirAll(filepath.Dir(destPath), fileperm.DirMode()); err != nil {
+		return err
 	}
 
 	reader, err := zipFile.Open()
@@ -73,10 +126,6 @@ func copyZipFileToDir(zipFile *zip.File, dirPath string, filter func(string) boo
 		return err
 	}
 
-	if !filter(destPath) {
-		return nil
-	}
-
 	return os.WriteFile(destPath, data, zipFile.Mode())
 }
 
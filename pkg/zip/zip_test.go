@@ -0,0 +1,76 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package zip_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	tenvzip "github.com/tofuutils/tenv/v2/pkg/zip"
+)
+
+func TestUnzipToDir(t *testing.T) {
+	t.Parallel()
+
+	var buffer bytes.Buffer
+	zipWriter := zip.NewWriter(&buffer)
+	names := []string{"tofu", "LICENSE", "doc/"}
+	for index := 0; index < 20; index++ {
+		names = append(names, "doc/page"+strconv.Itoa(index)+".md")
+	}
+	for _, name := range names {
+		writer, err := zipWriter.Create(name)
+		if err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+		if name[len(name)-1] == '/' {
+			continue
+		}
+		if _, err = writer.Write([]byte("content of " + name)); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+	}
+	if err := zipWriter.Close(); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	dirPath := t.TempDir()
+	if err := tenvzip.UnzipToDir(buffer.Bytes(), dirPath, func(path string) bool { return filepath.Base(path) != "LICENSE" }); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	for _, name := range names[3:] {
+		data, err := os.ReadFile(filepath.Join(dirPath, name))
+		if err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+
+		if string(data) != "content of "+name {
+			t.Error("Unmatching results, get :", string(data))
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dirPath, "LICENSE")); !os.IsNotExist(err) {
+		t.Error("Filtered entry should not be extracted, get :", err)
+	}
+}
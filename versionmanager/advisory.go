@@ -0,0 +1,187 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-version"
+
+	"github.com/tofuutils/tenv/v2/config/cmdconst"
+	"github.com/tofuutils/tenv/v2/pkg/audit"
+	"github.com/tofuutils/tenv/v2/pkg/download"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+)
+
+const (
+	// DefaultAdvisoryURL is the GitHub global security advisories API,
+	// TENV_ADVISORY_URL can target a mirror or a local JSON file with the same format.
+	DefaultAdvisoryURL = "https://api.github.com/advisories"
+
+	advisoryCacheDirName = "advisories"
+	advisoryEcosystem    = "go"
+	advisoryPageSize     = "100" // a single page covers advisories of one tool
+)
+
+// Go module path of each tool in advisory feeds.
+var advisoryPackages = map[string]string{ //nolint
+	cmdconst.AtmosName:      "github.com/cloudposse/atmos",
+	cmdconst.ConftestName:   "github.com/open-policy-agent/conftest",
+	cmdconst.OpaName:        "github.com/open-policy-agent/opa",
+	cmdconst.TerraformName:  "github.com/hashicorp/terraform",
+	cmdconst.TerragruntName: "github.com/gruntwork-io/terragrunt",
+	cmdconst.TofuName:       "github.com/opentofu/opentofu",
+}
+
+type advisoryEntry struct {
+	GHSAID          string  `json:"ghsa_id"`
+	CVEID           string  `json:"cve_id"`
+	HTMLURL         string  `json:"html_url"`
+	Summary         string  `json:"summary"`
+	Severity        string  `json:"severity"`
+	WithdrawnAt     *string `json:"withdrawn_at"`
+	Vulnerabilities []struct {
+		Package struct {
+			Ecosystem string `json:"ecosystem"`
+			Name      string `json:"name"`
+		} `json:"package"`
+		VulnerableVersionRange string `json:"vulnerable_version_range"`
+		FirstPatchedVersion    string `json:"first_patched_version"`
+	} `json:"vulnerabilities"`
+}
+
+// WithAdvisories completes audit entries with published advisories affecting their version
+// (remote feed is cached like other remote documents), and marks the version resolved for working directory as pinned.
+func (m VersionManager) WithAdvisories(entries []audit.Entry) ([]audit.Entry, error) {
+	packageName, ok := advisoryPackages[m.execName]
+	if !ok || len(entries) == 0 {
+		return entries, nil
+	}
+
+	advisories, err := m.readAdvisories(packageName)
+	if err != nil {
+		return nil, err
+	}
+
+	pinned, _, err := m.ResolveLocal()
+	if err != nil {
+		m.conf.Displayer.Log(hclog.Warn, "Unable to resolve pinned version", "tool", m.FolderName, loghelper.Error, err)
+	}
+
+	for index := range entries {
+		entry := &entries[index]
+		entry.Package = packageName
+		entry.Pinned = entry.Version == pinned
+		entry.Advisories = matchAdvisories(advisories, packageName, entry.Version)
+	}
+
+	return entries, nil
+}
+
+func (m VersionManager) readAdvisories(packageName string) ([]advisoryEntry, error) {
+	location := m.conf.AdvisoryURL
+	if location == "" {
+		location = DefaultAdvisoryURL
+	}
+
+	var data []byte
+	var err error
+	if isRemoteLocation(location) {
+		location, err = advisoryQueryURL(location, packageName)
+		if err != nil {
+			return nil, err
+		}
+		data, err = m.fetchCached(location, advisoryCacheDirName, m.downloadAdvisories)
+	} else {
+		data, err = os.ReadFile(location)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var advisories []advisoryEntry
+	if err = json.Unmarshal(data, &advisories); err != nil {
+		return nil, err
+	}
+
+	return advisories, nil
+}
+
+func (m VersionManager) downloadAdvisories(location string) ([]byte, error) {
+	requestOptions := []download.RequestOption{download.WithHeader("Accept", "application/vnd.github+json")}
+	if githubToken := m.conf.GithubTokenFor(location); githubToken != "" {
+		requestOptions = append(requestOptions, download.WithHeader("Authorization", "Bearer "+githubToken))
+	}
+
+	return download.Bytes(location, loghelper.InertDisplayer.Display, requestOptions...)
+}
+
+func advisoryQueryURL(baseURL string, packageName string) (string, error) {
+	parsedURL, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+
+	query := parsedURL.Query()
+	query.Set("affects", packageName)
+	query.Set("ecosystem", advisoryEcosystem)
+	query.Set("per_page", advisoryPageSize)
+	parsedURL.RawQuery = query.Encode()
+
+	return parsedURL.String(), nil
+}
+
+// withdrawn advisories and unparsable ranges are ignored.
+func matchAdvisories(advisories []advisoryEntry, packageName string, versionStr string) []audit.Advisory {
+	installedVersion, err := version.NewVersion(versionStr)
+	if err != nil {
+		return nil
+	}
+
+	var matched []audit.Advisory
+	for _, advisory := range advisories {
+		if advisory.WithdrawnAt != nil {
+			continue
+		}
+
+		for _, vulnerability := range advisory.Vulnerabilities {
+			if vulnerability.Package.Name != packageName || !strings.EqualFold(vulnerability.Package.Ecosystem, advisoryEcosystem) {
+				continue
+			}
+
+			constraint, err := version.NewConstraint(vulnerability.VulnerableVersionRange)
+			if err != nil || !constraint.Check(installedVersion) {
+				continue
+			}
+
+			matched = append(matched, audit.Advisory{
+				ID: advisory.GHSAID, CVE: advisory.CVEID, Severity: advisory.Severity, Summary: advisory.Summary, URL: advisory.HTMLURL,
+				VulnerableRange: vulnerability.VulnerableVersionRange, FixedVersion: vulnerability.FirstPatchedVersion,
+			})
+
+			break
+		}
+	}
+
+	return matched
+}
@@ -0,0 +1,136 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager/archive"
+	"github.com/tofuutils/tenv/v2/versionmanager/lastuse"
+)
+
+var (
+	ErrArchiveDisabled = errors.New("no archival policy, set TENV_ARCHIVE_AFTER")
+	ErrArchiveLinked   = errors.New("installed versions are linked with link-all, archival would break links")
+)
+
+// Archive compress an installed version in place, it is expanded again by the next proxy call.
+func (m VersionManager) Archive(version string) error {
+	if version == "" {
+		return errEmptyVersion
+	}
+
+	versionPath := filepath.Join(m.installDir(), version)
+	if _, err := os.Stat(versionPath); err != nil {
+		return ErrNotInstalled
+	}
+
+	if archive.IsArchived(versionPath) {
+		m.conf.Displayer.Display(loghelper.Concat(m.FolderName, " ", version, " already archived"))
+
+		return nil
+	}
+
+	if m.hasLinkDirs() {
+		return ErrArchiveLinked
+	}
+
+	if err := archive.Compress(versionPath, m.conf); err != nil {
+		return err
+	}
+	m.conf.Displayer.Display(loghelper.Concat("Archival of ", m.FolderName, " ", version, " successful"))
+
+	return nil
+}
+
+// ArchiveUnused compress installed versions not used since TENV_ARCHIVE_AFTER.
+func (m VersionManager) ArchiveUnused() error {
+	if m.conf.ArchiveAfter <= 0 {
+		return ErrArchiveDisabled
+	}
+
+	if m.hasLinkDirs() {
+		m.conf.Displayer.Log(hclog.Info, "Skip archival of unused versions", "reason", "versions linked with link-all")
+
+		return nil
+	}
+
+	installPath := m.installDir()
+	versions, err := m.innerListLocal(installPath, false)
+	if err != nil {
+		return err
+	}
+
+	limit := time.Now().Add(-m.conf.ArchiveAfter)
+	for _, version := range versions {
+		versionPath := filepath.Join(installPath, version)
+		if archive.IsArchived(versionPath) || !m.unusedSince(versionPath, limit) {
+			continue
+		}
+
+		if err = archive.Compress(versionPath, m.conf); err != nil {
+			m.conf.Displayer.Log(hclog.Warn, "Failed to archive unused version", "version", version, loghelper.Error, err)
+
+			continue
+		}
+		m.conf.Displayer.Display(loghelper.Concat("Archival of unused ", m.FolderName, " ", version, " successful"))
+	}
+
+	return nil
+}
+
+func (m VersionManager) unusedSince(versionPath string, limit time.Time) bool {
+	useDate := m.useDate(versionPath)
+
+	return !useDate.IsZero() && useDate.Before(limit)
+}
+
+// never used versions are dated by their installation (directory modification time).
+func (m VersionManager) useDate(versionPath string) time.Time {
+	useDate := lastuse.Read(versionPath, m.conf)
+	if useDate.IsZero() {
+		if info, err := os.Stat(versionPath); err == nil {
+			useDate = info.ModTime()
+		}
+	}
+
+	return useDate
+}
+
+// IsArchived returns true when an installed version is compressed.
+func (m VersionManager) IsArchived(version string) bool {
+	return archive.IsArchived(filepath.Join(m.installDir(), version))
+}
+
+// apply archival policy after installations (like trash purge after uninstallations).
+func (m VersionManager) archiveAfterInstall() {
+	if m.conf.ArchiveAfter <= 0 {
+		return
+	}
+
+	if err := m.ArchiveUnused(); err != nil {
+		m.conf.Displayer.Log(hclog.Warn, "Failed to apply archival policy", loghelper.Error, err)
+	}
+}
@@ -0,0 +1,198 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager"
+)
+
+var binaryData = []byte("#!/bin/sh\necho 1.6.1\n")
+
+func TestArchiveDigests(t *testing.T) {
+	t.Parallel()
+
+	conf, manager := makeArchivalManager(t)
+
+	expected, err := manager.Audit()
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if err = manager.Archive("1.6.1"); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if !manager.IsArchived("1.6.1") {
+		t.Fatal("Version should be archived")
+	}
+
+	entries, err := manager.Audit()
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if len(entries) != 1 || len(entries[0].Files) != 2 || !reflect.DeepEqual(entries[0].Files, expected[0].Files) {
+		t.Error("Unmatching results, get :", entries, ", want :", expected)
+	}
+
+	if _, err = os.Stat(filepath.Join(conf.RootPath, "OpenTofu", "1.6.1", "tofu")); !errors.Is(err, os.ErrNotExist) {
+		t.Error("Digests should not expand archived version, get :", err)
+	}
+}
+
+func TestArchiveLinked(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require privileges on Windows")
+	}
+
+	conf, manager := makeArchivalManager(t)
+	conf.ArchiveAfter = time.Hour
+
+	if err := manager.LinkAll(t.TempDir()); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if err := manager.ArchiveUnused(); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if manager.IsArchived("1.6.1") {
+		t.Error("Linked version should not be archived")
+	}
+
+	if err := manager.Archive("1.6.1"); !errors.Is(err, versionmanager.ErrArchiveLinked) {
+		t.Error("Unmatching error, get :", err)
+	}
+}
+
+func TestLinkAllExpand(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require privileges on Windows")
+	}
+
+	_, manager := makeArchivalManager(t)
+	if err := manager.Archive("1.6.1"); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	linkDir := t.TempDir()
+	if err := manager.LinkAll(linkDir); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if manager.IsArchived("1.6.1") {
+		t.Error("Linked version should be expanded")
+	}
+
+	if data, err := os.ReadFile(filepath.Join(linkDir, "tofu-1.6.1")); err != nil || string(data) != string(binaryData) {
+		t.Error("Unmatching results, get :", string(data), err)
+	}
+}
+
+func TestDeltaArchivedBase(t *testing.T) { //nolint:paralleltest // modify PATH
+	if runtime.GOOS == "windows" {
+		t.Skip("fake bspatch is a shell script")
+	}
+
+	// fake bspatch copying base binary (patched binary match the served checksum)
+	binPath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(binPath, "bspatch"), []byte("#!/bin/sh\ncp \"$1\" \"$2\"\n"), 0o755); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+	t.Setenv("PATH", binPath+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	sum := sha256.Sum256(binaryData)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if path.Dir(r.URL.Path) != "/tofu" {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		if path.Ext(r.URL.Path) == ".sha256" {
+			w.Write([]byte(hex.EncodeToString(sum[:]) + "  tofu\n"))
+
+			return
+		}
+		w.Write([]byte("patch"))
+	}))
+	defer server.Close()
+
+	conf, manager := makeArchivalManager(t)
+	conf.Arch = runtime.GOARCH
+	conf.DeltaURL = server.URL
+	if err := manager.Archive("1.6.1"); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if err := manager.Install("1.6.2"); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(conf.RootPath, "OpenTofu", "1.6.2", "tofu")); err != nil || string(data) != string(binaryData) {
+		t.Error("Unmatching results, get :", string(data), err)
+	}
+}
+
+// an OpenTofu 1.6.1 installed long ago, with a nested file.
+func makeArchivalManager(t *testing.T) (*config.Config, versionmanager.VersionManager) {
+	t.Helper()
+
+	conf := &config.Config{Displayer: loghelper.InertDisplayer, RootPath: t.TempDir(), LockTimeout: time.Second}
+	manager := versionmanager.Make(conf, "", "tofu", "OpenTofu", nil, nil, fakeRetriever{}, "", "", nil)
+
+	versionPath := filepath.Join(conf.RootPath, "OpenTofu", "1.6.1")
+	if err := os.MkdirAll(filepath.Join(versionPath, "docs"), 0o755); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(versionPath, "tofu"), binaryData, 0o755); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(versionPath, "docs", "README.md"), []byte("readme"), 0o644); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	old := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(versionPath, old, old); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	return conf, manager
+}
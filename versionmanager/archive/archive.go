@@ -0,0 +1,168 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package archive
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/go-hclog"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/lockfile"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/pkg/targz"
+	"github.com/tofuutils/tenv/v2/versionmanager/lastuse"
+	"github.com/tofuutils/tenv/v2/versionmanager/manifest"
+)
+
+// FileName is the archive replacing the files of a version directory (tenv metadata stay uncompressed).
+const FileName = "archive.tar.gz"
+
+const lockName = ".lock"
+
+var ErrEmpty = errors.New("no file to archive")
+
+// IsArchived returns true when the version installed in dirPath has been compressed.
+func IsArchived(dirPath string) bool {
+	_, err := os.Stat(filepath.Join(dirPath, FileName))
+
+	return err == nil
+}
+
+// Compress the files of the version installed in dirPath in place.
+func Compress(dirPath string, conf *config.Config) error {
+	deleteLock, err := lockfile.Write(dirPath, conf.LockTimeout, conf.Displayer)
+	if err != nil {
+		return err
+	}
+	disableExit := lockfile.CleanAndExitOnInterrupt(deleteLock)
+	defer disableExit()
+	defer deleteLock()
+
+	if IsArchived(dirPath) {
+		return nil
+	}
+
+	// written under a temporary name to never leave a partial archive
+	archivePath := filepath.Join(dirPath, FileName)
+	tmpPath := archivePath + ".tmp"
+	names, err := writeArchive(dirPath, tmpPath)
+	if err == nil && len(names) == 0 {
+		err = ErrEmpty
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, archivePath)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+
+		return err
+	}
+
+	for _, name := range names {
+		if err = os.Remove(filepath.Join(dirPath, name)); err != nil {
+			conf.Displayer.Log(hclog.Warn, "Failed to remove archived file", "file", name, loghelper.Error, err)
+		}
+	}
+
+	return nil
+}
+
+// Expand decompress the version installed in dirPath when it has been archived (no-op otherwise).
+func Expand(dirPath string, conf *config.Config) error {
+	if !IsArchived(dirPath) {
+		return nil
+	}
+
+	deleteLock, err := lockfile.Write(dirPath, conf.LockTimeout, conf.Displayer)
+	if err != nil {
+		return err
+	}
+	disableExit := lockfile.CleanAndExitOnInterrupt(deleteLock)
+	defer disableExit()
+	defer deleteLock()
+
+	// second check with lock, a concurrent call could have already expanded it
+	archivePath := filepath.Join(dirPath, FileName)
+	file, err := os.Open(archivePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+	defer file.Close()
+
+	conf.Displayer.Log(hclog.Debug, "Expand archived version", "path", dirPath)
+	if err = targz.UntarStream(file, dirPath, func(string) bool { return true }); err != nil {
+		return err
+	}
+	file.Close() // before removal (required on Windows)
+
+	return os.Remove(archivePath)
+}
+
+// Walk call fn with the relative path (slash separated) and content of each archived file of the version installed in dirPath,
+// so archived files can be read without expanding them (no-op when not archived).
+func Walk(dirPath string, fn func(name string, content io.Reader) error) error {
+	file, err := os.Open(filepath.Join(dirPath, FileName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+	defer file.Close()
+
+	return targz.WalkStream(file, fn)
+}
+
+// IsArchiveFile returns true for the archive (or its temporary file) in a version directory.
+func IsArchiveFile(name string) bool {
+	return name == FileName || name == FileName+".tmp"
+}
+
+func writeArchive(dirPath string, archivePath string) ([]string, error) {
+	file, err := os.Create(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	names, err := targz.TarDir(dirPath, file, toArchive)
+	if err != nil {
+		return nil, err
+	}
+
+	return names, file.Close()
+}
+
+// files written by tenv at the root of the version directory stay uncompressed.
+func toArchive(name string) bool {
+	if filepath.Dir(name) != "." {
+		return true
+	}
+
+	return !manifest.IsMetadata(name) && !lastuse.IsUsageFile(name) && name != lockName && name != FileName+".tmp"
+}
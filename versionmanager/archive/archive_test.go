@@ -0,0 +1,88 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package archive_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager/archive"
+	"github.com/tofuutils/tenv/v2/versionmanager/lastuse"
+)
+
+func TestCompressExpand(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.Config{Displayer: loghelper.InertDisplayer, RootPath: t.TempDir()}
+	dirPath := filepath.Join(conf.RootPath, "OpenTofu", "1.6.2")
+	if err := os.MkdirAll(filepath.Join(dirPath, "doc"), 0o755); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	contents := map[string]string{"tofu": "binary content", "doc/README.md": "readme content"}
+	for name, content := range contents {
+		if err := os.WriteFile(filepath.Join(dirPath, name), []byte(content), 0o755); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+	}
+	lastuse.WriteNow(dirPath, conf)
+
+	if err := archive.Compress(dirPath, conf); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if !archive.IsArchived(dirPath) {
+		t.Fatal("Version should be archived")
+	}
+
+	if _, err := os.Stat(filepath.Join(dirPath, "tofu")); !os.IsNotExist(err) {
+		t.Error("Archived file should be removed, get :", err)
+	}
+
+	if usage := lastuse.ReadUsage(dirPath, conf); usage.Count != 1 {
+		t.Error("Last use file should stay uncompressed, get :", usage)
+	}
+
+	if err := archive.Expand(dirPath, conf); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if archive.IsArchived(dirPath) {
+		t.Error("Version should not be archived after expansion")
+	}
+
+	for name, content := range contents {
+		filePath := filepath.Join(dirPath, name)
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+
+		if string(data) != content {
+			t.Error("Unmatching results, get :", string(data))
+		}
+
+		if info, err := os.Stat(filePath); err != nil || info.Mode().Perm()&0o100 == 0 {
+			t.Error("Executable permission should be kept, get :", info, err)
+		}
+	}
+}
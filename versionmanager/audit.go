@@ -0,0 +1,144 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/tofuutils/tenv/v2/pkg/audit"
+	"github.com/tofuutils/tenv/v2/versionmanager/archive"
+	"github.com/tofuutils/tenv/v2/versionmanager/lastuse"
+	"github.com/tofuutils/tenv/v2/versionmanager/manifest"
+)
+
+// Audit returns an entry by installed version with digests of its files and verification details.
+func (m VersionManager) Audit() ([]audit.Entry, error) {
+	installPath := m.installDir()
+	versions, err := m.innerListLocal(installPath, false)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]audit.Entry, 0, len(versions))
+	for _, version := range versions {
+		entry, err := m.auditEntry(installPath, version)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func (m VersionManager) auditEntry(installPath string, version string) (audit.Entry, error) {
+	versionPath := filepath.Join(installPath, version)
+	files, err := digestFiles(versionPath)
+	if err != nil {
+		return audit.Entry{}, err
+	}
+
+	entry := audit.Entry{Tool: m.FolderName, Version: version, Files: files, Status: audit.StatusNonCompliant}
+	installManifest, found := manifest.Read(versionPath, m.conf.Displayer)
+	switch {
+	case !found:
+		entry.Reason = "no manifest, installed by an older tenv"
+	case installManifest.Unverifiable:
+		entry.Reason = "no checksum published upstream"
+	case !installManifest.Checksum:
+		entry.Reason = "checksum not verified"
+	case installManifest.ChecksumOnly():
+		entry.Reason = "no signature published upstream"
+	case !installManifest.Verified():
+		entry.Reason = "signature not verified"
+	default:
+		entry.Status = audit.StatusCompliant
+	}
+
+	entry.Checksum = installManifest.Checksum
+	entry.PostInstall = installManifest.PostInstall
+	entry.Provenance = installManifest.Provenance
+	entry.Signature = installManifest.Signature
+	entry.Source = installManifest.Source
+	entry.Unverifiable = installManifest.Unverifiable
+
+	return entry, nil
+}
+
+// ReadManifest returns false when the installed version has no readable manifest.
+func (m VersionManager) ReadManifest(version string) (manifest.Manifest, bool) {
+	return manifest.Read(filepath.Join(m.installDir(), version), m.conf.Displayer)
+}
+
+// files of an archived version are read in its archive (same digests as expanded files), sorted by path.
+func digestFiles(versionPath string) ([]audit.FileDigest, error) {
+	var files []audit.FileDigest
+	err := filepath.WalkDir(versionPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		name := entry.Name()
+		if manifest.IsMetadata(name) || lastuse.IsUsageFile(name) || archive.IsArchiveFile(name) || !entry.Type().IsRegular() {
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		relPath, err := filepath.Rel(versionPath, path)
+		if err != nil {
+			return err
+		}
+
+		return appendDigest(&files, filepath.ToSlash(relPath), file)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = archive.Walk(versionPath, func(name string, content io.Reader) error {
+		return appendDigest(&files, name, content)
+	})
+	slices.SortFunc(files, func(a audit.FileDigest, b audit.FileDigest) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return files, err
+}
+
+func appendDigest(files *[]audit.FileDigest, relPath string, content io.Reader) error {
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, content); err != nil {
+		return err
+	}
+	*files = append(*files, audit.FileDigest{Path: relPath, SHA256: hex.EncodeToString(hasher.Sum(nil))})
+
+	return nil
+}
@@ -22,13 +22,23 @@ import (
 	"github.com/hashicorp/hcl/v2/hclparse"
 
 	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/config/cmdconst"
 	"github.com/tofuutils/tenv/v2/versionmanager"
+	"github.com/tofuutils/tenv/v2/versionmanager/postinstall"
 	atmosretriever "github.com/tofuutils/tenv/v2/versionmanager/retriever/atmos"
+	cacheretriever "github.com/tofuutils/tenv/v2/versionmanager/retriever/cache"
+	conftestretriever "github.com/tofuutils/tenv/v2/versionmanager/retriever/conftest"
+	mirrorretriever "github.com/tofuutils/tenv/v2/versionmanager/retriever/mirror"
+	offlineretriever "github.com/tofuutils/tenv/v2/versionmanager/retriever/offline"
+	oparetriever "github.com/tofuutils/tenv/v2/versionmanager/retriever/opa"
+	s3retriever "github.com/tofuutils/tenv/v2/versionmanager/retriever/s3"
 	terraformretriever "github.com/tofuutils/tenv/v2/versionmanager/retriever/terraform"
 	terragruntretriever "github.com/tofuutils/tenv/v2/versionmanager/retriever/terragrunt"
 	tofuretriever "github.com/tofuutils/tenv/v2/versionmanager/retriever/tofu"
+	asdfparser "github.com/tofuutils/tenv/v2/versionmanager/semantic/parser/asdf"
 	flatparser "github.com/tofuutils/tenv/v2/versionmanager/semantic/parser/flat"
 	iacparser "github.com/tofuutils/tenv/v2/versionmanager/semantic/parser/iac"
+	miseparser "github.com/tofuutils/tenv/v2/versionmanager/semantic/parser/mise"
 	terragruntparser "github.com/tofuutils/tenv/v2/versionmanager/semantic/parser/terragrunt"
 	tomlparser "github.com/tofuutils/tenv/v2/versionmanager/semantic/parser/toml"
 	"github.com/tofuutils/tenv/v2/versionmanager/semantic/types"
@@ -37,61 +47,111 @@ import (
 type BuilderFunc = func(*config.Config, *hclparse.Parser) versionmanager.VersionManager
 
 func BuildAtmosManager(conf *config.Config, hclParser *hclparse.Parser) versionmanager.VersionManager {
-	atmosRetriever := atmosretriever.Make(conf)
-	versionFiles := []types.VersionFile{
+	atmosRetriever := cacheretriever.Make(conf, &conf.Atmos, withRemoteStore(conf, &conf.Atmos, atmosretriever.Make(conf)), "Atmos")
+	versionFiles := append([]types.VersionFile{
 		{Name: ".atmos-version", Parser: flatparser.RetrieveVersion},
-	}
+	}, sharedVersionFiles(cmdconst.AtmosName)...)
 
-	return versionmanager.Make(conf, config.AtmosDefaultConstraintEnvName, "Atmos", nil, atmosRetriever, config.AtmosVersionEnvName, config.AtmosDefaultVersionEnvName, versionFiles)
+	return versionmanager.Make(conf, config.AtmosDefaultConstraintEnvName, cmdconst.AtmosName, "Atmos", nil, nil, atmosRetriever, config.AtmosVersionEnvName, config.AtmosDefaultVersionEnvName, versionFiles)
+}
+
+func BuildConftestManager(conf *config.Config, hclParser *hclparse.Parser) versionmanager.VersionManager {
+	conftestRetriever := cacheretriever.Make(conf, &conf.Conftest, withRemoteStore(conf, &conf.Conftest, conftestretriever.Make(conf)), "Conftest")
+	versionFiles := append([]types.VersionFile{
+		{Name: ".conftest-version", Parser: flatparser.RetrieveVersion},
+	}, sharedVersionFiles(cmdconst.ConftestName)...)
+
+	return versionmanager.Make(conf, config.ConftestDefaultConstraintEnvName, cmdconst.ConftestName, "Conftest", nil, nil, conftestRetriever, config.ConftestVersionEnvName, config.ConftestDefaultVersionEnvName, versionFiles)
+}
+
+func BuildOpaManager(conf *config.Config, hclParser *hclparse.Parser) versionmanager.VersionManager {
+	opaRetriever := cacheretriever.Make(conf, &conf.Opa, withRemoteStore(conf, &conf.Opa, oparetriever.Make(conf)), "OPA")
+	versionFiles := append([]types.VersionFile{
+		{Name: ".opa-version", Parser: flatparser.RetrieveVersion},
+	}, sharedVersionFiles(cmdconst.OpaName)...)
+
+	return versionmanager.Make(conf, config.OpaDefaultConstraintEnvName, cmdconst.OpaName, "OPA", nil, nil, opaRetriever, config.OpaVersionEnvName, config.OpaDefaultVersionEnvName, versionFiles)
 }
 
 func BuildTfManager(conf *config.Config, hclParser *hclparse.Parser) versionmanager.VersionManager {
-	tfRetriever := terraformretriever.Make(conf)
+	tfRetriever := cacheretriever.Make(conf, &conf.Tf, withRemoteStore(conf, &conf.Tf, terraformretriever.Make(conf)), "Terraform")
 	gruntParser := terragruntparser.Make(hclParser)
-	versionFiles := []types.VersionFile{
+	versionFiles := append([]types.VersionFile{
 		{Name: ".terraform-version", Parser: flatparser.RetrieveVersion},
 		{Name: ".tfswitchrc", Parser: flatparser.RetrieveVersion},
 		{Name: terragruntparser.HCLName, Parser: gruntParser.RetrieveTerraformVersionConstraintFromHCL},
 		{Name: terragruntparser.JSONName, Parser: gruntParser.RetrieveTerraformVersionConstraintFromJSON},
-	}
+	}, sharedVersionFiles(cmdconst.TerraformName)...)
+	// users migrating between tools often keep both files
+	versionFiles = append(versionFiles, types.VersionFile{ConflictOnly: true, Name: ".opentofu-version", Parser: flatparser.RetrieveVersion})
 
-	iacExts := []iacparser.ExtDescription{
-		{Value: ".tf", Parser: hclParser.ParseHCLFile},
-		{Value: ".tf.json", Parser: hclParser.ParseJSONFile},
+	var iacExts []iacparser.ExtDescription
+	if !conf.TfSkipIaC {
+		iacExts = iacparser.TfExts(hclParser)
 	}
 
-	return versionmanager.Make(conf, config.TfDefaultConstraintEnvName, "Terraform", iacExts, tfRetriever, config.TfVersionEnvName, config.TfDefaultVersionEnvName, versionFiles)
+	tfSteps := []postinstall.Step{postinstall.EnsureExecutable(cmdconst.TerraformName)}
+
+	return versionmanager.Make(conf, config.TfDefaultConstraintEnvName, cmdconst.TerraformName, "Terraform", iacExts, tfSteps, tfRetriever, config.TfVersionEnvName, config.TfDefaultVersionEnvName, versionFiles)
 }
 
 func BuildTgManager(conf *config.Config, hclParser *hclparse.Parser) versionmanager.VersionManager {
-	tgRetriever := terragruntretriever.Make(conf)
+	tgRetriever := cacheretriever.Make(conf, &conf.Tg, withRemoteStore(conf, &conf.Tg, terragruntretriever.Make(conf)), "Terragrunt")
 	gruntParser := terragruntparser.Make(hclParser)
-	versionFiles := []types.VersionFile{
+	versionFiles := append([]types.VersionFile{
 		{Name: ".terragrunt-version", Parser: flatparser.RetrieveVersion},
 		{Name: ".tgswitchrc", Parser: flatparser.RetrieveVersion},
 		{Name: ".tgswitch.toml", Parser: tomlparser.RetrieveVersion},
 		{Name: terragruntparser.HCLName, Parser: gruntParser.RetrieveTerragruntVersionConstraintFromHCL},
 		{Name: terragruntparser.JSONName, Parser: gruntParser.RetrieveTerragruntVersionConstraintFromJSON},
-	}
+	}, sharedVersionFiles(cmdconst.TerragruntName)...)
 
-	return versionmanager.Make(conf, config.TgDefaultConstraintEnvName, "Terragrunt", nil, tgRetriever, config.TgVersionEnvName, config.TgDefaultVersionEnvName, versionFiles)
+	return versionmanager.Make(conf, config.TgDefaultConstraintEnvName, cmdconst.TerragruntName, "Terragrunt", nil, nil, tgRetriever, config.TgVersionEnvName, config.TgDefaultVersionEnvName, versionFiles)
 }
 
 func BuildTofuManager(conf *config.Config, hclParser *hclparse.Parser) versionmanager.VersionManager {
-	tofuRetriever := tofuretriever.Make(conf)
+	tofuRetriever := cacheretriever.Make(conf, &conf.Tofu, withRemoteStore(conf, &conf.Tofu, tofuretriever.Make(conf)), "OpenTofu")
 	gruntParser := terragruntparser.Make(hclParser)
-	versionFiles := []types.VersionFile{
+	versionFiles := append([]types.VersionFile{
 		{Name: ".opentofu-version", Parser: flatparser.RetrieveVersion},
 		{Name: terragruntparser.HCLName, Parser: gruntParser.RetrieveTerraformVersionConstraintFromHCL},
 		{Name: terragruntparser.JSONName, Parser: gruntParser.RetrieveTerraformVersionConstraintFromJSON},
+	}, sharedVersionFiles(cmdconst.TofuName)...)
+	versionFiles = append(versionFiles, types.VersionFile{ConflictOnly: true, Name: ".terraform-version", Parser: flatparser.RetrieveVersion})
+
+	var iacExts []iacparser.ExtDescription
+	if !conf.TofuSkipIaC {
+		iacExts = iacparser.TofuExts(hclParser)
 	}
 
-	iacExts := []iacparser.ExtDescription{
-		{Value: ".tofu", Parser: hclParser.ParseHCLFile},
-		{Value: ".tofu.json", Parser: hclParser.ParseJSONFile},
-		{Value: ".tf", Parser: hclParser.ParseHCLFile},
-		{Value: ".tf.json", Parser: hclParser.ParseJSONFile},
+	tofuSteps := []postinstall.Step{postinstall.EnsureExecutable(cmdconst.TofuName)}
+
+	return versionmanager.Make(conf, config.TofuDefaultConstraintEnvName, cmdconst.TofuName, "OpenTofu", iacExts, tofuSteps, tofuRetriever, config.TofuVersionEnvName, config.TofuDefaultVersionEnvName, versionFiles)
+}
+
+// asdf and mise files are read after tool specific files.
+func sharedVersionFiles(execName string) []types.VersionFile {
+	toolName := asdfparser.ToolName(execName)
+	miseRetriever := miseparser.MakeRetriever(toolName)
+
+	return []types.VersionFile{
+		{Name: asdfparser.FileName, Parser: asdfparser.MakeRetriever(toolName)},
+		{Name: miseparser.HiddenFileName, Parser: miseRetriever},
+		{Name: miseparser.FileName, Parser: miseRetriever},
+		{Name: miseparser.ConfigFileName, Parser: miseRetriever},
 	}
+}
 
-	return versionmanager.Make(conf, config.TofuDefaultConstraintEnvName, "OpenTofu", iacExts, tofuRetriever, config.TofuVersionEnvName, config.TofuDefaultVersionEnvName, versionFiles)
+// offline source has priority over S3 bucket, which has priority over HTTPS mirror.
+func withRemoteStore(conf *config.Config, remoteConf *config.RemoteConfig, retriever versionmanager.ReleaseInfoRetriever) versionmanager.ReleaseInfoRetriever {
+	switch {
+	case remoteConf.GetOfflinePath() != "":
+		return offlineretriever.Make(conf, remoteConf, retriever)
+	case remoteConf.GetBucketURL() != "":
+		return s3retriever.Make(conf, remoteConf, retriever)
+	case remoteConf.GetMirrorURL() != "":
+		return mirrorretriever.Make(conf, remoteConf, retriever, remoteConf.GetReleasesPath())
+	default:
+		return retriever
+	}
 }
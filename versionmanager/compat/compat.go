@@ -0,0 +1,93 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package compat
+
+import (
+	"strconv"
+
+	"github.com/hashicorp/go-version"
+
+	"github.com/tofuutils/tenv/v2/config/cmdconst"
+)
+
+type tgRange struct {
+	max string // empty when supported by every later Terragrunt release
+	min string
+}
+
+// bundled from Terragrunt supported versions documentation, keys are IaC major.minor.
+var matrix = map[string]map[string]tgRange{ //nolint
+	cmdconst.TerraformName: {
+		"0.11": {min: "0.14.0", max: "0.18.7"},
+		"0.12": {min: "0.19.0"},
+		"0.13": {min: "0.25.0"},
+		"0.14": {min: "0.27.0"},
+		"0.15": {min: "0.29.0"},
+		"1.0":  {min: "0.31.0"},
+		"1.1":  {min: "0.36.0"},
+		"1.2":  {min: "0.38.0"},
+		"1.3":  {min: "0.40.0"},
+		"1.4":  {min: "0.45.0"},
+		"1.5":  {min: "0.48.0"},
+		"1.6":  {min: "0.53.0"},
+		"1.7":  {min: "0.56.0"},
+		"1.8":  {min: "0.57.0"},
+		"1.9":  {min: "0.60.0"},
+	},
+	cmdconst.TofuName: {
+		"1.6": {min: "0.52.0"},
+		"1.7": {min: "0.58.0"},
+		"1.8": {min: "0.66.0"},
+	},
+}
+
+// Compatible reports if the IaC tool version (OpenTofu or Terraform) is supported by the Terragrunt version,
+// the second result is false when the pairing is not in the bundled matrix (then considered compatible).
+func Compatible(execName string, iacVersion string, tgVersion string) (bool, bool) {
+	parsedIaC, err := version.NewVersion(iacVersion)
+	if err != nil {
+		return true, false
+	}
+
+	parsedTg, err := version.NewVersion(tgVersion)
+	if err != nil {
+		return true, false
+	}
+
+	segments := parsedIaC.Segments()
+	supported, ok := matrix[execName][strconv.Itoa(segments[0])+"."+strconv.Itoa(segments[1])]
+	if !ok {
+		return true, false
+	}
+
+	if parsedTg.LessThan(version.Must(version.NewVersion(supported.min))) {
+		return false, true
+	}
+
+	return supported.max == "" || !parsedTg.GreaterThan(version.Must(version.NewVersion(supported.max))), true
+}
+
+// Predicate filter IaC versions supported by the Terragrunt version.
+func Predicate(execName string, tgVersion string) func(string) bool {
+	return func(iacVersion string) bool {
+		compatible, _ := Compatible(execName, iacVersion, tgVersion)
+
+		return compatible
+	}
+}
@@ -0,0 +1,49 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package compat_test
+
+import (
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/versionmanager/compat"
+)
+
+func TestCompatible(t *testing.T) {
+	t.Parallel()
+
+	for _, testCase := range []struct {
+		execName   string
+		iacVersion string
+		tgVersion  string
+		compatible bool
+		known      bool
+	}{
+		{execName: "terraform", iacVersion: "1.5.7", tgVersion: "0.48.0", compatible: true, known: true},
+		{execName: "terraform", iacVersion: "1.5.7", tgVersion: "0.47.0", compatible: false, known: true},
+		{execName: "terraform", iacVersion: "0.11.15", tgVersion: "0.20.0", compatible: false, known: true},
+		{execName: "tofu", iacVersion: "1.6.2", tgVersion: "0.55.1", compatible: true, known: true},
+		{execName: "tofu", iacVersion: "1.8.0", tgVersion: "0.55.1", compatible: false, known: true},
+		{execName: "tofu", iacVersion: "2.0.0", tgVersion: "0.55.1", compatible: true, known: false},
+	} {
+		compatible, known := compat.Compatible(testCase.execName, testCase.iacVersion, testCase.tgVersion)
+		if compatible != testCase.compatible || known != testCase.known {
+			t.Error("Unmatching results for", testCase.execName, testCase.iacVersion, testCase.tgVersion, ", get :", compatible, known)
+		}
+	}
+}
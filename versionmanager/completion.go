@@ -0,0 +1,134 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
+
+	"github.com/tofuutils/tenv/v2/config/cmdconst"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/pkg/winbin"
+)
+
+const bashCompInit = "autoload -U +X bashcompinit && bashcompinit"
+
+// tools completing themselves when called by the shell with COMP_LINE (like with their -install-autocomplete flag).
+var selfCompletingNames = []string{cmdconst.TerraformName, cmdconst.TerragruntName, cmdconst.TofuName} //nolint
+
+// register the proxy (instead of the installed binary) as completion command in existing shell rc files,
+// so completions always come from the version resolved at completion time.
+func (m VersionManager) syncCompletion() {
+	if !m.conf.ShellCompletion || runtime.GOOS == winbin.OsName || !slices.Contains(selfCompletingNames, m.execName) {
+		return
+	}
+
+	exePath, err := os.Executable()
+	if err != nil {
+		m.conf.Displayer.Log(hclog.Warn, "Failed to locate proxy for shell completion", loghelper.Error, err)
+
+		return
+	}
+
+	proxyPath := filepath.Join(filepath.Dir(exePath), m.execName)
+	if _, err = os.Stat(proxyPath); err != nil {
+		m.conf.Displayer.Log(hclog.Warn, "Failed to locate proxy for shell completion", loghelper.Error, err)
+
+		return
+	}
+
+	m.registerCompletion(proxyPath)
+}
+
+// add completion commands calling proxyPath in existing rc files of user directory.
+func (m VersionManager) registerCompletion(proxyPath string) {
+	for rcName, lines := range completionLines(proxyPath, m.execName) {
+		rcPath := filepath.Join(m.conf.UserPath, rcName)
+		added, err := appendMissingLines(rcPath, lines)
+		if err != nil {
+			m.conf.Displayer.Log(hclog.Warn, "Failed to register shell completion", "filePath", rcPath, loghelper.Error, err)
+
+			continue
+		}
+
+		if added {
+			m.conf.Displayer.Display(loghelper.Concat("Registered shell completion of ", m.execName, " in ", rcPath))
+		}
+	}
+}
+
+// lines to add in each shell rc file (zsh needs bash completion emulation for complete builtin).
+func completionLines(proxyPath string, execName string) map[string][]string {
+	return map[string][]string{
+		".bashrc": {loghelper.Concat("complete -C ", proxyPath, " ", execName)},
+		".zshrc":  {bashCompInit, loghelper.Concat("complete -o nospace -C ", proxyPath, " ", execName)},
+	}
+}
+
+// a missing file is not created (the shell is not used).
+func appendMissingLines(filePath string, lines []string) (bool, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	existingLines := strings.Split(string(data), "\n")
+	for index, line := range existingLines {
+		existingLines[index] = strings.TrimSpace(line)
+	}
+
+	var toAdd strings.Builder
+	if len(data) != 0 && data[len(data)-1] != '\n' {
+		toAdd.WriteByte('\n')
+	}
+
+	added := false
+	for _, line := range lines {
+		if !slices.Contains(existingLines, line) {
+			toAdd.WriteString(line)
+			toAdd.WriteByte('\n')
+			added = true
+		}
+	}
+
+	if !added {
+		return false, nil
+	}
+
+	file, err := os.OpenFile(filePath, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	_, err = file.WriteString(toAdd.String())
+
+	return err == nil, err
+}
@@ -0,0 +1,121 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+)
+
+func TestRegisterCompletion(t *testing.T) {
+	t.Parallel()
+
+	const proxyPath = "/home/user/.tenv/bin/tofu"
+	const bashLine = "complete -C " + proxyPath + " tofu\n"
+	const zshLines = bashCompInit + "\ncomplete -o nospace -C " + proxyPath + " tofu\n"
+
+	tests := []struct {
+		name     string
+		bashrc   string // file not created when "-"
+		zshrc    string // file not created when "-"
+		wantBash string // "-" when file must not exist
+		wantZsh  string // "-" when file must not exist
+	}{
+		{name: "NoShellFile", bashrc: "-", zshrc: "-", wantBash: "-", wantZsh: "-"},
+		{name: "EmptyFiles", wantBash: bashLine, wantZsh: zshLines},
+		{name: "MissingTrailingNewline", bashrc: "export PATH", zshrc: "-", wantBash: "export PATH\n" + bashLine, wantZsh: "-"},
+		{name: "AlreadyRegistered", bashrc: "alias ll='ls -l'\n" + bashLine, zshrc: "-", wantBash: "alias ll='ls -l'\n" + bashLine, wantZsh: "-"},
+		{name: "ZshCompInitPresent", bashrc: "-", zshrc: "  " + bashCompInit + "  \n", wantBash: "-", wantZsh: "  " + bashCompInit + "  \ncomplete -o nospace -C " + proxyPath + " tofu\n"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			userPath := t.TempDir()
+			for rcName, content := range map[string]string{".bashrc": tt.bashrc, ".zshrc": tt.zshrc} {
+				if content == "-" {
+					continue
+				}
+
+				if err := os.WriteFile(filepath.Join(userPath, rcName), []byte(content), 0o600); err != nil {
+					t.Fatal("Unexpected error :", err)
+				}
+			}
+
+			manager := VersionManager{conf: &config.Config{Displayer: loghelper.InertDisplayer, UserPath: userPath}, execName: "tofu"}
+			// second call must not duplicate lines
+			manager.registerCompletion(proxyPath)
+			manager.registerCompletion(proxyPath)
+
+			for rcName, want := range map[string]string{".bashrc": tt.wantBash, ".zshrc": tt.wantZsh} {
+				data, err := os.ReadFile(filepath.Join(userPath, rcName))
+				if want == "-" {
+					if !os.IsNotExist(err) {
+						t.Error("Should not be created :", rcName, err)
+					}
+
+					continue
+				}
+
+				if err != nil || string(data) != want {
+					t.Error("Unmatching", rcName, "content, get :", string(data), err)
+				}
+			}
+		})
+	}
+}
+
+func TestSyncCompletionDisabled(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		enabled  bool
+		execName string
+	}{
+		{name: "Disabled", execName: "tofu"},
+		{name: "NotSelfCompleting", enabled: true, execName: "atmos"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			userPath := t.TempDir()
+			rcPath := filepath.Join(userPath, ".bashrc")
+			if err := os.WriteFile(rcPath, nil, 0o600); err != nil {
+				t.Fatal("Unexpected error :", err)
+			}
+
+			manager := VersionManager{conf: &config.Config{Displayer: loghelper.InertDisplayer, ShellCompletion: tt.enabled, UserPath: userPath}, execName: tt.execName}
+			manager.syncCompletion()
+
+			if data, err := os.ReadFile(rcPath); err != nil || len(data) != 0 {
+				t.Error("Should be unchanged, get :", string(data), err)
+			}
+		})
+	}
+}
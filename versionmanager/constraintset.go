@@ -0,0 +1,150 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
+	"gopkg.in/yaml.v3"
+
+	pgpcheck "github.com/tofuutils/tenv/v2/pkg/check/pgp"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+)
+
+const (
+	// ConstraintSetPrefix marks a reference to a shared constraint set, by name (searched in TENV_CONSTRAINT_SETS)
+	// or by location (URL, absolute or relative path).
+	ConstraintSetPrefix = "@"
+
+	constraintSetDirName = "constraint-sets"
+	signatureExt         = ".sig"
+)
+
+var errNoSetConstraint = errors.New("no constraint for this tool in shared constraint set")
+
+// expand shared constraint set references, on failure the reference is kept (so the constraint is rejected instead of ignored).
+func (m VersionManager) expandConstraint(constraint string, source string) string {
+	if !strings.Contains(constraint, ConstraintSetPrefix) {
+		return constraint
+	}
+
+	softConstraint, soft := strings.CutPrefix(constraint, SoftConstraintPrefix)
+	parts := strings.Split(softConstraint, ",")
+	for index, part := range parts {
+		parts[index] = strings.TrimSpace(part)
+		ref, found := strings.CutPrefix(parts[index], ConstraintSetPrefix)
+		if !found {
+			continue
+		}
+
+		expanded, err := m.readConstraintSet(ref)
+		if err != nil {
+			m.conf.Displayer.Log(hclog.Warn, "Unable to read shared constraint set", "reference", ref, "source", source, loghelper.Error, err)
+
+			return constraint
+		}
+		parts[index] = expanded
+	}
+
+	expanded := strings.Join(parts, ", ")
+	if soft {
+		return SoftConstraintPrefix + expanded
+	}
+
+	return expanded
+}
+
+// a set is a YAML map of constraints by tool (lower case folder name, like "opentofu"), or a single constraint for all tools.
+func (m VersionManager) readConstraintSet(ref string) (string, error) {
+	data, err := m.fetchConstraintSet(m.constraintSetLocation(ref))
+	if err != nil {
+		return "", err
+	}
+
+	var constraints map[string]string
+	if err = yaml.Unmarshal(data, &constraints); err != nil || len(constraints) == 0 {
+		return strings.TrimSpace(string(data)), nil
+	}
+
+	constraint, ok := constraints[strings.ToLower(m.FolderName)]
+	if !ok {
+		return "", errNoSetConstraint
+	}
+
+	return constraint, nil
+}
+
+func (m VersionManager) constraintSetLocation(ref string) string {
+	if isRemoteLocation(ref) || filepath.IsAbs(ref) || strings.HasPrefix(ref, ".") {
+		return ref
+	}
+
+	baseLocation := m.conf.ConstraintSets
+	if baseLocation == "" {
+		return filepath.Join(m.conf.RootPath, constraintSetDirName, ref)
+	}
+
+	if isRemoteLocation(baseLocation) {
+		if location, err := url.JoinPath(baseLocation, ref); err == nil {
+			return location
+		}
+	}
+
+	return filepath.Join(baseLocation, ref)
+}
+
+// remote sets are cached (see fetchCached).
+func (m VersionManager) fetchConstraintSet(location string) ([]byte, error) {
+	if !isRemoteLocation(location) {
+		return m.checkConstraintSet(location, os.ReadFile)
+	}
+
+	return m.fetchCached(location, constraintSetDirName, func(location string) ([]byte, error) {
+		return m.checkConstraintSet(location, downloadDocument)
+	})
+}
+
+// with TENV_CONSTRAINT_SETS_KEY, the detached signature (location with .sig suffix) is mandatory.
+func (m VersionManager) checkConstraintSet(location string, read func(string) ([]byte, error)) ([]byte, error) {
+	data, err := read(location)
+	if err != nil || m.conf.ConstraintKey == "" {
+		return data, err
+	}
+
+	dataSig, err := read(location + signatureExt)
+	if err != nil {
+		return nil, err
+	}
+
+	dataPublicKey, err := os.ReadFile(m.conf.ConstraintKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = pgpcheck.Check(data, dataSig, dataPublicKey); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
@@ -0,0 +1,97 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
+
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+)
+
+var errEmptyHelper = errors.New("install helper command is empty")
+
+// needDelegation returns true when an install helper is configured and the installation directory
+// (or its nearest existing parent) is not writable by current user.
+func (m VersionManager) needDelegation() bool {
+	if m.conf.InstallHelper == "" {
+		return false
+	}
+
+	dirPath := filepath.Join(m.conf.RootPath, m.FolderName)
+	for {
+		if _, err := os.Stat(dirPath); err == nil {
+			break
+		}
+
+		parentPath := filepath.Dir(dirPath)
+		if parentPath == dirPath {
+			break
+		}
+		dirPath = parentPath
+	}
+
+	f, err := os.CreateTemp(dirPath, ".write-check")
+	if err != nil {
+		return errors.Is(err, fs.ErrPermission)
+	}
+	f.Close()
+	os.Remove(f.Name())
+
+	return false
+}
+
+// delegateInstall runs "<helper> <tenv> <tool> install <version> --root-path <root>" (resolution stays unprivileged).
+func (m VersionManager) delegateInstall(version string) error {
+	helperName, cmdArgs, err := m.delegateCommand(version)
+	if err != nil {
+		return err
+	}
+
+	m.conf.Displayer.Display(loghelper.Concat("Delegate installation of ", m.FolderName, " ", version, " to ", helperName))
+	m.conf.Displayer.Log(hclog.Debug, "Install helper call", "args", cmdArgs)
+
+	cmd := exec.Command(helperName, cmdArgs...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stderr // keep standard output clean for proxy calls
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
+}
+
+// return helper executable name and its arguments (remaining helper words then tenv install call).
+func (m VersionManager) delegateCommand(version string) (string, []string, error) {
+	helperParts := strings.Fields(m.conf.InstallHelper)
+	if len(helperParts) == 0 {
+		return "", nil, errEmptyHelper
+	}
+
+	execPath, err := os.Executable()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return helperParts[0], append(helperParts[1:], execPath, m.execName, "install", version, "--root-path", m.conf.RootPath, "--arch", m.conf.Arch), nil //nolint
+}
@@ -0,0 +1,137 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+)
+
+func TestNeedDelegation(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("directory permissions are not enforced on Windows")
+	}
+
+	tests := []struct {
+		name      string
+		helper    string
+		readOnly  bool   // applied to the created base directory
+		rootPath  string // relative to base directory
+		createDir bool   // create installation directory before the check
+		want      bool
+	}{
+		{name: "NoHelper", readOnly: true, rootPath: "tenv"},
+		{name: "Writable", helper: "sudo -n", rootPath: "tenv", createDir: true},
+		{name: "MissingParents", helper: "sudo -n", rootPath: "usr/local/tenv"},
+		{name: "ReadOnlyParent", helper: "sudo -n", readOnly: true, rootPath: "usr/local/tenv", want: true},
+		{name: "ReadOnlyInstallDir", helper: "sudo -n", readOnly: true, rootPath: "tenv", createDir: true, want: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if tt.want && os.Geteuid() == 0 {
+				t.Skip("root ignores directory permissions")
+			}
+
+			basePath := t.TempDir()
+			rootPath := filepath.Join(basePath, tt.rootPath)
+			if tt.createDir {
+				if err := os.MkdirAll(filepath.Join(rootPath, "OpenTofu"), 0o755); err != nil {
+					t.Fatal("Unexpected error :", err)
+				}
+			}
+
+			if tt.readOnly {
+				probedPath := basePath
+				if tt.createDir {
+					probedPath = filepath.Join(rootPath, "OpenTofu")
+				}
+
+				if err := os.Chmod(probedPath, 0o555); err != nil {
+					t.Fatal("Unexpected error :", err)
+				}
+				t.Cleanup(func() { os.Chmod(probedPath, 0o755) }) //nolint
+			}
+
+			manager := VersionManager{conf: &config.Config{InstallHelper: tt.helper, RootPath: rootPath}, FolderName: "OpenTofu"}
+			if res := manager.needDelegation(); res != tt.want {
+				t.Error("Unmatching results, get :", res)
+			}
+
+			// write check must not leave anything behind
+			if entries, err := os.ReadDir(basePath); err != nil || len(entries) > 1 {
+				t.Error("Unexpected content after check, get :", entries, err)
+			}
+		})
+	}
+}
+
+func TestDelegateCommand(t *testing.T) {
+	t.Parallel()
+
+	execPath, err := os.Executable()
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	installArgs := []string{execPath, "tofu", "install", "1.6.2", "--root-path", "/usr/local/tenv", "--arch", "arm64"}
+
+	tests := []struct {
+		name       string
+		helper     string
+		wantHelper string
+		wantArgs   []string
+		wantErr    error
+	}{
+		{name: "SingleWord", helper: "pkexec", wantHelper: "pkexec", wantArgs: installArgs},
+		{name: "WithOptions", helper: "sudo -n --preserve-env", wantHelper: "sudo", wantArgs: append([]string{"-n", "--preserve-env"}, installArgs...)},
+		{name: "ExtraSpaces", helper: "  sudo \t -n  ", wantHelper: "sudo", wantArgs: append([]string{"-n"}, installArgs...)},
+		{name: "Empty", wantErr: errEmptyHelper},
+		{name: "Whitespace", helper: " \t ", wantErr: errEmptyHelper},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := &config.Config{Arch: "arm64", Displayer: loghelper.InertDisplayer, InstallHelper: tt.helper, RootPath: "/usr/local/tenv"}
+			manager := VersionManager{conf: conf, execName: "tofu", FolderName: "OpenTofu"}
+			helperName, cmdArgs, err := manager.delegateCommand("1.6.2")
+			if err != tt.wantErr {
+				t.Fatal("Unmatching error, get :", err)
+			}
+
+			if helperName != tt.wantHelper || !slices.Equal(cmdArgs, tt.wantArgs) {
+				t.Error("Unmatching results, get :", helperName, cmdArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-version"
+
+	"github.com/tofuutils/tenv/v2/config/cmdconst"
+	sha256check "github.com/tofuutils/tenv/v2/pkg/check/sha256"
+	"github.com/tofuutils/tenv/v2/pkg/download"
+	"github.com/tofuutils/tenv/v2/pkg/fileperm"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/pkg/winbin"
+	"github.com/tofuutils/tenv/v2/versionmanager/archive"
+	"github.com/tofuutils/tenv/v2/versionmanager/manifest"
+)
+
+const bspatchExecName = "bspatch"
+
+var errNoDeltaBase = errors.New("no installed version to patch from")
+
+// installDelta try to build targeted version from the nearest lower installed version with a binary delta,
+// return false when caller should fallback to a full download.
+func (m VersionManager) installDelta(installPath string, targetVersion string) bool {
+	if m.conf.DeltaURL == "" {
+		return false
+	}
+
+	// patched binaries are not covered by upstream signature
+	if m.strictSignature() {
+		m.conf.Displayer.Log(hclog.Debug, "Strict signature mode, skip delta update")
+
+		return false
+	}
+
+	if _, err := exec.LookPath(bspatchExecName); err != nil {
+		m.conf.Displayer.Log(hclog.Debug, "bspatch executable not found, skip delta update")
+
+		return false
+	}
+
+	targetPath := filepath.Join(installPath, targetVersion)
+	if err := m.applyDelta(installPath, targetPath, targetVersion); err != nil {
+		m.conf.Displayer.Log(hclog.Info, "Delta update not available, fallback to full download", loghelper.Error, err)
+		os.RemoveAll(targetPath)
+
+		return false
+	}
+
+	return true
+}
+
+func (m VersionManager) strictSignature() bool {
+	switch m.execName {
+	case cmdconst.TerraformName:
+		return m.conf.StrictVerify
+	case cmdconst.TofuName:
+		return m.conf.TofuStrictSig
+	}
+
+	return false
+}
+
+func (m VersionManager) applyDelta(installPath string, targetPath string, targetVersion string) error {
+	baseVersion, err := m.deltaBaseVersion(installPath, targetVersion)
+	if err != nil {
+		return err
+	}
+
+	binaryName := winbin.GetBinaryName(m.execName)
+	patchName := loghelper.Concat(baseVersion, "_", targetVersion, "_", runtime.GOOS, "_", m.conf.Arch, ".bsdiff")
+	patchURL, err := url.JoinPath(m.conf.DeltaURL, m.execName, patchName)
+	if err != nil {
+		return err
+	}
+
+	patchData, err := download.Bytes(patchURL, m.conf.Displayer.Display)
+	if err != nil {
+		return err
+	}
+
+	// sha256 of patched binary, in SHA256SUMS format
+	dataSums, err := download.Bytes(patchURL+".sha256", m.conf.Displayer.Display)
+	if err != nil {
+		return err
+	}
+
+	patchFile, err := os.CreateTemp("", patchName)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(patchFile.Name())
+
+	_, err = patchFile.Write(patchData)
+	patchFile.Close()
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(targetPath, fileperm.DirMode()); err != nil {
+		return err
+	}
+
+	// an archived base version is expanded on demand (like proxy calls do)
+	if err = archive.Expand(filepath.Join(installPath, baseVersion), m.conf); err != nil {
+		return err
+	}
+
+	targetBinaryPath := filepath.Join(targetPath, binaryName)
+	cmd := exec.Command(bspatchExecName, filepath.Join(installPath, baseVersion, binaryName), targetBinaryPath, patchFile.Name())
+	if output, err := cmd.CombinedOutput(); err != nil {
+		m.conf.Displayer.Log(hclog.Debug, "bspatch output", "output", string(output))
+
+		return err
+	}
+
+	data, err := os.ReadFile(targetBinaryPath)
+	if err != nil {
+		return err
+	}
+
+	if err = sha256check.Check(data, dataSums, binaryName); err != nil {
+		return err
+	}
+
+	if err = os.Chmod(targetBinaryPath, fileperm.ExecMode()); err != nil {
+		return err
+	}
+
+	// the patched binary is checked against delta server checksum, not upstream signature
+	manifest.Write(targetPath, manifest.Manifest{Checksum: true, Signature: manifest.SignatureSkipped}, m.conf.Displayer)
+	m.conf.Displayer.Display(loghelper.Concat("Patched ", m.FolderName, " ", baseVersion, " to ", targetVersion))
+
+	return nil
+}
+
+// highest installed version lower than target.
+func (m VersionManager) deltaBaseVersion(installPath string, targetVersion string) (string, error) {
+	parsedTarget, err := version.NewVersion(targetVersion)
+	if err != nil {
+		return "", err
+	}
+
+	versions, err := m.innerListLocal(installPath, true)
+	if err != nil {
+		return "", err
+	}
+
+	for _, installed := range versions {
+		if parsedInstalled, err := version.NewVersion(installed); err == nil && parsedInstalled.LessThan(parsedTarget) {
+			return installed, nil
+		}
+	}
+
+	return "", errNoDeltaBase
+}
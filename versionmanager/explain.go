@@ -0,0 +1,110 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager
+
+import (
+	"strings"
+
+	"github.com/hashicorp/go-version"
+
+	"github.com/tofuutils/tenv/v2/versionmanager/semantic"
+	iacparser "github.com/tofuutils/tenv/v2/versionmanager/semantic/parser/iac"
+	"github.com/tofuutils/tenv/v2/versionmanager/semantic/types"
+)
+
+const SourceKindEvaluation = "evaluation"
+
+// Explain is like DetectResult, it also returns the resolution chain : the source of the requested version,
+// the constraints combined to evaluate it (when it is not an exact version), then the evaluation decision.
+func (m VersionManager) Explain() (Evaluation, []PrecedenceStep, error) {
+	recorder := &types.SourceRecorder{Displayer: m.conf.Displayer}
+	m.conf.Displayer = recorder
+	configVersion, err := m.Resolve(semantic.LatestAllowedKey)
+	m.conf.Displayer = recorder.Displayer
+	if err != nil {
+		m.conf.Displayer.Flush(false)
+
+		return Evaluation{}, nil, err
+	}
+
+	var steps []PrecedenceStep
+	if recorder.Source == "" {
+		steps = append(steps, PrecedenceStep{Kind: SourceKindStrategy, Name: configVersion, Detail: "no version found in sources, fallback strategy"})
+	} else {
+		steps = append(steps, PrecedenceStep{Kind: m.sourceKind(recorder.Source), Name: recorder.Source, Detail: "requested " + configVersion})
+	}
+
+	if _, err = version.NewVersion(configVersion); err != nil {
+		if steps, err = m.appendConstraintSteps(steps, configVersion); err != nil {
+			return Evaluation{}, steps, err
+		}
+	}
+
+	evaluation, err := m.evaluateDetected(configVersion, false)
+	if err == nil {
+		steps = append(steps, PrecedenceStep{Kind: SourceKindEvaluation, Name: evaluation.Source, Detail: evaluation.Version})
+	}
+
+	return evaluation, steps, err
+}
+
+// follow semantic.ParsePredicate rules : only these strategies and version constraints are combined with constraint sources.
+func (m VersionManager) appendConstraintSteps(steps []PrecedenceStep, requested string) ([]PrecedenceStep, error) {
+	switch {
+	case requested == semantic.LatestAllowedKey, requested == semantic.MinRequiredKey:
+		fileRequirements, err := iacparser.GatherFileRequirements(m.conf, m.iacExts)
+		if err != nil {
+			return steps, err
+		}
+
+		for _, fileRequirement := range fileRequirements {
+			steps = append(steps, PrecedenceStep{Kind: SourceKindIaC, Name: fileRequirement.FilePath, Detail: "required_version " + fileRequirement.Required})
+		}
+		if len(fileRequirements) == 0 {
+			steps = append(steps, PrecedenceStep{Kind: SourceKindStrategy, Name: semantic.LatestKey, Detail: "no required_version found in project files"})
+		}
+	case requested == semantic.LatestKey, requested == semantic.LatestStableKey, requested == semantic.LatestPreKey,
+		strings.HasPrefix(requested, semantic.LatestPrefix), strings.HasPrefix(requested, semantic.MinPrefix):
+		return steps, nil
+	}
+
+	constraint, source := m.ReadDefaultConstraintWithSource()
+	if constraint == "" {
+		return steps, nil
+	}
+
+	if softConstraint, found := strings.CutPrefix(constraint, SoftConstraintPrefix); found {
+		steps = append(steps, PrecedenceStep{Kind: m.sourceKind(source), Name: source, Detail: "soft constraint " + strings.TrimSpace(softConstraint) + " (only warn)"})
+	} else {
+		steps = append(steps, PrecedenceStep{Kind: m.sourceKind(source), Name: source, Detail: "constraint " + constraint})
+	}
+
+	return steps, nil
+}
+
+func (m VersionManager) sourceKind(source string) string {
+	switch source {
+	case m.VersionEnvName, m.defaultVersionEnvName, m.constraintEnvName:
+		return SourceKindEnv
+	case m.pluginEnvName():
+		return SourceKindPlugin
+	default:
+		return SourceKindFile
+	}
+}
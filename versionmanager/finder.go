@@ -0,0 +1,63 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager
+
+import (
+	"github.com/tofuutils/tenv/v2/versionmanager/semantic"
+	"github.com/tofuutils/tenv/v2/versionmanager/semantic/types"
+)
+
+// ReleaseFinder is implemented by retrievers able to search the highest matching release without listing all of them.
+type ReleaseFinder interface {
+	FindRelease(match func(string) bool) (string, error)
+}
+
+// HighestMatching returns the highest version matching in versions (empty when none match),
+// a fallback for ReleaseFinder implementations.
+func HighestMatching(versions []string, match func(string) bool) string {
+	found := ""
+	for _, version := range versions {
+		if match(version) && (found == "" || semantic.CmpVersion(version, found) > 0) {
+			found = version
+		}
+	}
+
+	return found
+}
+
+// findRemote returns the first remote version matching predicate (empty when none match),
+// paging stops early when the highest matching version is searched and retriever supports it.
+func (m VersionManager) findRemote(predicateInfo types.PredicateInfo) (string, error) {
+	if finder, ok := m.retriever.(ReleaseFinder); ok && predicateInfo.ReverseOrder {
+		return finder.FindRelease(predicateInfo.Predicate)
+	}
+
+	versions, err := m.ListRemote(predicateInfo.ReverseOrder)
+	if err != nil {
+		return "", err
+	}
+
+	for _, version := range versions {
+		if predicateInfo.Predicate(version) {
+			return version, nil
+		}
+	}
+
+	return "", nil
+}
@@ -0,0 +1,86 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager"
+)
+
+var errFullListing = errors.New("full listing")
+
+type fakeFinderRetriever struct {
+	fakeRetriever
+}
+
+func (fakeFinderRetriever) ListReleases() ([]string, error) {
+	return nil, errFullListing
+}
+
+func (r fakeFinderRetriever) FindRelease(match func(string) bool) (string, error) {
+	return versionmanager.HighestMatching(r.fakeRetriever, match), nil
+}
+
+func TestHighestMatching(t *testing.T) {
+	t.Parallel()
+
+	versions := []string{"1.7.0", "1.6.2", "1.6.10", "1.6.3"}
+	tests := []struct {
+		name   string
+		prefix string
+		want   string
+	}{
+		{name: "Highest", prefix: "1.", want: "1.7.0"},
+		{name: "NotLexical", prefix: "1.6.", want: "1.6.10"},
+		{name: "NoMatch", prefix: "1.5.", want: ""},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if found := versionmanager.HighestMatching(versions, func(version string) bool { return strings.HasPrefix(version, tt.prefix) }); found != tt.want {
+				t.Error("Unmatching result, get :", found)
+			}
+		})
+	}
+}
+
+func TestEvaluateReleaseFinder(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.Config{Displayer: loghelper.InertDisplayer, NoInstall: true, RootPath: t.TempDir(), SearchBoundary: "none"}
+	manager := versionmanager.Make(conf, "", "tofu", "OpenTofu", nil, nil, fakeFinderRetriever{fakeRetriever{"1.6.2", "1.7.0", "1.6.3"}}, "", "", nil)
+
+	// highest version search use finder (no full listing)
+	if evaluation, err := manager.EvaluateResult("~> 1.6.0", false); err != nil || evaluation.Version != "1.6.3" {
+		t.Error("Unmatching results, get :", evaluation, err)
+	}
+
+	// lowest version search needs full listing
+	if _, err := manager.EvaluateResult("min:1.6.0", false); !errors.Is(err, errFullListing) {
+		t.Error("Unmatching error, get :", err)
+	}
+}
@@ -0,0 +1,71 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-version"
+
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+)
+
+type initState struct {
+	TerraformVersion string `json:"terraform_version"`
+}
+
+// checkInitVersion warns when the working directory has been initialized with another major/minor version
+// (avoid confusing "state was written by a newer version" failures).
+func (m VersionManager) checkInitVersion(detectedVersion string) {
+	data, err := os.ReadFile(filepath.Join(".terraform", "terraform.tfstate"))
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			m.conf.Displayer.Log(hclog.Debug, "Unable to read .terraform state", loghelper.Error, err)
+		}
+
+		return
+	}
+
+	var state initState
+	if err = json.Unmarshal(data, &state); err != nil || state.TerraformVersion == "" {
+		m.conf.Displayer.Log(hclog.Debug, "No version recorded in .terraform state", loghelper.Error, err)
+
+		return
+	}
+
+	initVersion, err := version.NewVersion(state.TerraformVersion)
+	if err != nil {
+		return
+	}
+
+	parsedVersion, err := version.NewVersion(detectedVersion)
+	if err != nil {
+		return
+	}
+
+	initSegments, segments := initVersion.Segments(), parsedVersion.Segments()
+	if initSegments[0] != segments[0] || initSegments[1] != segments[1] {
+		m.conf.Displayer.Log(hclog.Warn, loghelper.Concat("Working directory has been initialized with version ", state.TerraformVersion, ", which differs from ", m.FolderName, " ", detectedVersion, " (run init again if needed)"))
+	}
+}
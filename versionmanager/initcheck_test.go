@@ -0,0 +1,93 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/hcl/v2/hclparse"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager"
+	iacparser "github.com/tofuutils/tenv/v2/versionmanager/semantic/parser/iac"
+)
+
+// change working directory and env, so not parallel.
+func TestDetectInitVersion(t *testing.T) { //nolint
+	previousPath, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+	defer os.Chdir(previousPath) //nolint
+
+	t.Setenv("TENV_TEST_INIT_VERSION", "1.6.2")
+
+	tests := []struct {
+		name     string
+		state    string // .terraform/terraform.tfstate content, not written when empty
+		wantWarn bool
+	}{
+		{name: "NotInitialized"},
+		{name: "SameMinor", state: `{"terraform_version": "1.6.0"}`},
+		{name: "OtherMinor", state: `{"terraform_version": "1.5.7"}`, wantWarn: true},
+		{name: "OtherMajor", state: `{"terraform_version": "0.6.2"}`, wantWarn: true},
+		{name: "NoRecordedVersion", state: `{"version": 3}`},
+		{name: "InvalidState", state: `not json`},
+	}
+
+	// sequential, each case change working directory
+	for _, tt := range tests {
+		workPath := t.TempDir()
+		if tt.state != "" {
+			if err = os.MkdirAll(filepath.Join(workPath, ".terraform"), 0o755); err != nil {
+				t.Fatal("Unexpected error :", err)
+			}
+
+			if err = os.WriteFile(filepath.Join(workPath, ".terraform", "terraform.tfstate"), []byte(tt.state), 0o600); err != nil {
+				t.Fatal("Unexpected error :", err)
+			}
+		}
+
+		if err = os.Chdir(workPath); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+
+		var buffer bytes.Buffer
+		logger := hclog.New(&hclog.LoggerOptions{Level: hclog.Warn, Output: &buffer})
+		conf := &config.Config{Displayer: loghelper.MakeBasicDisplayer(logger, func(string) {}), NoInstall: true, RootPath: t.TempDir(), SearchBoundary: "none"}
+		if err = os.MkdirAll(filepath.Join(conf.RootPath, "OpenTofu", "1.6.2"), 0o755); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+
+		manager := versionmanager.Make(conf, "", "tofu", "OpenTofu", iacparser.TfExts(hclparse.NewParser()), nil, fakeRetriever{}, "TENV_TEST_INIT_VERSION", "", nil)
+		if evaluation, err := manager.DetectResult(false); err != nil || evaluation.Version != "1.6.2" {
+			t.Fatal(tt.name, "unexpected results :", evaluation, err)
+		}
+
+		if warned := strings.Contains(buffer.String(), "Working directory has been initialized with version"); warned != tt.wantWarn {
+			t.Error(tt.name, "unmatching results, get :", buffer.String())
+		}
+	}
+}
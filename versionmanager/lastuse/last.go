@@ -19,42 +19,229 @@
 package lastuse
 
 import (
+	"encoding/json"
 	"errors"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/go-hclog"
 
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/fileperm"
 	"github.com/tofuutils/tenv/v2/pkg/loghelper"
 )
 
-const fileName = "last-use.txt"
+const (
+	FileName = "last-use.json"
+	// LegacyFileName only contains a date (like "2024-06-01"), it is read as a usage without count
+	// and replaced by FileName on next write.
+	LegacyFileName = "last-use.txt"
 
-func Read(dirPath string, displayer loghelper.Displayer) time.Time {
-	data, err := os.ReadFile(filepath.Join(dirPath, fileName))
+	lockFileName   = FileName + ".lock"
+	lockRetryDelay = 10 * time.Millisecond
+	lockStaleAfter = 10 * time.Second // lock left by an interrupted process
+	lockTimeout    = 2 * time.Second
+)
+
+var errLockTimeout = errors.New("timeout while waiting for usage file lock")
+
+// Usage is stored as a JSON object, like {"count":3,"date":"2024-06-01T10:02:03Z"},
+// count is the number of proxy calls and date the last one (or the last touch).
+type Usage struct {
+	Count int       `json:"count"`
+	Date  time.Time `json:"date"`
+}
+
+// IsUsageFile returns true for files managed by this package in a version directory (usage, legacy, lock or temporary file).
+func IsUsageFile(name string) bool {
+	return name == FileName || name == LegacyFileName || strings.HasPrefix(name, FileName+".")
+}
+
+// Read the last use date of the version installed in dirPath,
+// with a shared root it is the most recent date among all users.
+func Read(dirPath string, conf *config.Config) time.Time {
+	return ReadUsage(dirPath, conf).Date
+}
+
+// ReadUsage read the usage of the version installed in dirPath,
+// with a shared root date is the most recent among all users and count is their sum.
+func ReadUsage(dirPath string, conf *config.Config) Usage {
+	usage := readDir(dirPath, conf.Displayer)
+	if !conf.SharedRoot {
+		return usage
+	}
+
+	relPath, err := filepath.Rel(conf.RootPath, dirPath)
 	if err != nil {
-		displayer.Log(loghelper.LevelWarnOrDebug(errors.Is(err, fs.ErrNotExist)), "Unable to read date in file", loghelper.Error, err)
+		return usage
+	}
+
+	userDirPaths, _ := filepath.Glob(filepath.Join(conf.RootPath, config.UsersDirName, "*", relPath))
+	for _, userDirPath := range userDirPaths {
+		userUsage := readDir(userDirPath, conf.Displayer)
+		if userUsage.Date.After(usage.Date) {
+			usage.Date = userUsage.Date
+		}
+		usage.Count += userUsage.Count
+	}
+
+	return usage
+}
+
+// Touch record date as last use of the version installed in dirPath (count is unchanged).
+func Touch(dirPath string, date time.Time, conf *config.Config) error {
+	userDirPath, err := userDir(dirPath, conf)
+	if err != nil {
+		return err
+	}
+
+	return update(userDirPath, conf.Displayer, func(usage *Usage) {
+		usage.Date = date
+	})
+}
+
+// WriteNow record the current date as last use of the version installed in dirPath and increment its count
+// (in a per user sub directory when root path is shared).
+func WriteNow(dirPath string, conf *config.Config) {
+	userDirPath, err := userDir(dirPath, conf)
+	if err == nil {
+		err = update(userDirPath, conf.Displayer, func(usage *Usage) {
+			usage.Count++
+			usage.Date = time.Now().UTC().Truncate(time.Second)
+		})
+	}
+
+	if err != nil {
+		conf.Displayer.Log(hclog.Warn, "Unable to write usage in file", loghelper.Error, err)
+	}
+}
+
+// read FileName in dirPath, with a fallback on LegacyFileName.
+func readDir(dirPath string, displayer loghelper.Displayer) Usage {
+	var usage Usage
+	data, err := os.ReadFile(filepath.Join(dirPath, FileName))
+	if err == nil {
+		if err = json.Unmarshal(data, &usage); err != nil {
+			displayer.Log(hclog.Warn, "Unable to parse usage in file", loghelper.Error, err)
+		}
+
+		return usage
+	}
+
+	if !errors.Is(err, fs.ErrNotExist) {
+		displayer.Log(hclog.Warn, "Unable to read usage in file", loghelper.Error, err)
 
-		return time.Time{}
+		return usage
 	}
 
-	parsed, err := time.Parse(time.DateOnly, string(data)) //nolint
+	data, err = os.ReadFile(filepath.Join(dirPath, LegacyFileName))
 	if err != nil {
+		displayer.Log(loghelper.LevelWarnOrDebug(errors.Is(err, fs.ErrNotExist)), "Unable to read date in file", loghelper.Error, err)
+
+		return usage
+	}
+
+	if usage.Date, err = time.Parse(time.DateOnly, strings.TrimSpace(string(data))); err != nil { //nolint
 		displayer.Log(hclog.Warn, "Unable to parse date in file", loghelper.Error, err)
+	}
+
+	return usage
+}
+
+func userDir(dirPath string, conf *config.Config) (string, error) {
+	if !conf.SharedRoot {
+		return dirPath, nil
+	}
+
+	relPath, err := filepath.Rel(conf.RootPath, dirPath)
+	if err != nil {
+		return "", err
+	}
+
+	userDirPath := conf.UserStatePath(relPath)
 
-		return time.Time{}
+	return userDirPath, os.MkdirAll(userDirPath, fileperm.DirMode())
+}
+
+// update apply change to the usage stored in dirPath under a lock (concurrent proxy calls are the common case).
+func update(dirPath string, displayer loghelper.Displayer, change func(*Usage)) error {
+	unlock, err := lock(filepath.Join(dirPath, lockFileName))
+	if err != nil {
+		return err
 	}
+	defer unlock()
 
-	return parsed
+	usage := readDir(dirPath, displayer)
+	change(&usage)
+
+	return write(dirPath, usage)
 }
 
-func WriteNow(dirPath string, displayer loghelper.Displayer) {
-	lastUsePath := filepath.Join(dirPath, fileName)
-	nowData := time.Now().AppendFormat(nil, time.DateOnly) //nolint
+// lock is not shared with installation lock : proxy calls must not wait installations.
+func lock(lockPath string) (func(), error) {
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL, fileperm.FileMode())
+		if err == nil {
+			file.Close()
+
+			return func() {
+				os.Remove(lockPath)
+			}, nil
+		}
 
-	if err := os.WriteFile(lastUsePath, nowData, 0o644); err != nil {
-		displayer.Log(hclog.Warn, "Unable to write date in file", loghelper.Error, err)
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, err
+		}
+
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > lockStaleAfter {
+			os.Remove(lockPath)
+
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, errLockTimeout
+		}
+		time.Sleep(lockRetryDelay)
 	}
 }
+
+// the file is replaced atomically (readers never see a partial content), the legacy file is removed once migrated.
+func write(dirPath string, usage Usage) error {
+	data, err := json.Marshal(usage)
+	if err != nil {
+		return err
+	}
+
+	tempFile, err := os.CreateTemp(dirPath, FileName+".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile.Name()) // no effect once renamed
+
+	_, err = tempFile.Write(data)
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = os.Chmod(tempFile.Name(), fileperm.FileMode()); err != nil { // CreateTemp creates it with 0600
+		return err
+	}
+
+	if err = os.Rename(tempFile.Name(), filepath.Join(dirPath, FileName)); err != nil {
+		return err
+	}
+
+	if err = os.Remove(filepath.Join(dirPath, LegacyFileName)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package lastuse_test
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager/lastuse"
+)
+
+func TestLegacyMigration(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.Config{Displayer: loghelper.InertDisplayer, RootPath: t.TempDir()}
+	dirPath := filepath.Join(conf.RootPath, "OpenTofu", "1.6.2")
+	if err := os.MkdirAll(dirPath, 0o755); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dirPath, lastuse.LegacyFileName), []byte("2024-06-01"), 0o644); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if usage := lastuse.ReadUsage(dirPath, conf); usage.Count != 0 || usage.Date.Format(time.DateOnly) != "2024-06-01" {
+		t.Error("Unmatching results, get :", usage)
+	}
+
+	lastuse.WriteNow(dirPath, conf)
+	lastuse.WriteNow(dirPath, conf)
+	if _, err := os.Stat(filepath.Join(dirPath, lastuse.LegacyFileName)); !os.IsNotExist(err) {
+		t.Error("Legacy file should be removed, get :", err)
+	}
+
+	if usage := lastuse.ReadUsage(dirPath, conf); usage.Count != 2 || time.Since(usage.Date) > time.Minute {
+		t.Error("Unmatching results, get :", usage)
+	}
+
+	touchDate := time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC)
+	if err := lastuse.Touch(dirPath, touchDate, conf); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if usage := lastuse.ReadUsage(dirPath, conf); usage.Count != 2 || !usage.Date.Equal(touchDate) {
+		t.Error("Unmatching results, get :", usage)
+	}
+}
+
+func TestConcurrentWrites(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.Config{Displayer: loghelper.InertDisplayer, RootPath: t.TempDir()}
+	dirPath := filepath.Join(conf.RootPath, "OpenTofu", "1.6.2")
+	if err := os.MkdirAll(dirPath, 0o755); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	const calls = 50
+	var group sync.WaitGroup
+	for i := 0; i < calls; i++ {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			lastuse.WriteNow(dirPath, conf)
+		}()
+	}
+	group.Wait()
+
+	if usage := lastuse.ReadUsage(dirPath, conf); usage.Count != calls {
+		t.Error("Lost updates, get :", usage)
+	}
+
+	// only the usage file is left (no lock or temporary file)
+	if entries, err := os.ReadDir(dirPath); err != nil || len(entries) != 1 || entries[0].Name() != lastuse.FileName {
+		t.Error("Unmatching directory content, get :", entries, err)
+	}
+}
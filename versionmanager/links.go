@@ -0,0 +1,161 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
+
+	"github.com/tofuutils/tenv/v2/pkg/fileperm"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/pkg/winbin"
+	"github.com/tofuutils/tenv/v2/versionmanager/archive"
+)
+
+const linkDirsFileName = "link-dirs"
+
+// LinkAll (re)generate in dirPath a symlink by installed version (named like "tofu-1.7.4"),
+// and register dirPath to keep it in sync after installs and uninstalls.
+func (m VersionManager) LinkAll(dirPath string) error {
+	absPath, err := filepath.Abs(dirPath)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(absPath, fileperm.DirMode()); err != nil {
+		return err
+	}
+
+	if err = m.linkInDir(absPath); err != nil {
+		return err
+	}
+	m.conf.Displayer.Display(loghelper.Concat("Linked ", m.FolderName, " versions in ", absPath))
+
+	return registerLinkDir(filepath.Join(m.conf.RootPath, linkDirsFileName), absPath)
+}
+
+func (m VersionManager) linkInDir(dirPath string) error {
+	installPath := m.installDir()
+	versions, err := m.innerListLocal(installPath, false)
+	if err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return err
+	}
+
+	// remove previous links of this tool (only symlinks targeting its installation directory)
+	prefix := m.execName + "-"
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, prefix) || entry.Type()&fs.ModeSymlink == 0 {
+			continue
+		}
+
+		linkPath := filepath.Join(dirPath, name)
+		if target, err := os.Readlink(linkPath); err == nil && strings.HasPrefix(target, installPath+string(filepath.Separator)) {
+			if err = os.Remove(linkPath); err != nil {
+				return err
+			}
+		}
+	}
+
+	binaryName := winbin.GetBinaryName(m.execName)
+	for _, version := range versions {
+		// symlinks can not expand on demand, so linked versions must be expanded
+		if err = archive.Expand(filepath.Join(installPath, version), m.conf); err != nil {
+			return err
+		}
+
+		linkName := winbin.GetBinaryName(prefix + version)
+		if err = os.Symlink(filepath.Join(installPath, version, binaryName), filepath.Join(dirPath, linkName)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// hasLinkDirs returns true when installed versions are linked (see LinkAll).
+func (m VersionManager) hasLinkDirs() bool {
+	dirPaths, err := readLinkDirs(filepath.Join(m.conf.RootPath, linkDirsFileName))
+	if err != nil {
+		m.conf.Displayer.Log(hclog.Warn, "Failed to read registered link directories", loghelper.Error, err)
+
+		return true // conservative, archival would break existing links
+	}
+
+	return len(dirPaths) != 0
+}
+
+// best effort, called after installs and uninstalls.
+func (m VersionManager) syncLinks() {
+	dirPaths, err := readLinkDirs(filepath.Join(m.conf.RootPath, linkDirsFileName))
+	if err != nil {
+		m.conf.Displayer.Log(hclog.Warn, "Failed to read registered link directories", loghelper.Error, err)
+
+		return
+	}
+
+	for _, dirPath := range dirPaths {
+		if err = m.linkInDir(dirPath); err != nil {
+			m.conf.Displayer.Log(hclog.Warn, "Failed to update links", "dirPath", dirPath, loghelper.Error, err)
+		}
+	}
+}
+
+func readLinkDirs(filePath string) ([]string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+	defer file.Close()
+
+	var dirPaths []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if dirPath := strings.TrimSpace(scanner.Text()); dirPath != "" {
+			dirPaths = append(dirPaths, dirPath)
+		}
+	}
+
+	return dirPaths, scanner.Err()
+}
+
+func registerLinkDir(filePath string, dirPath string) error {
+	dirPaths, err := readLinkDirs(filePath)
+	if err != nil || slices.Contains(dirPaths, dirPath) {
+		return err
+	}
+
+	return os.WriteFile(filePath, []byte(strings.Join(append(dirPaths, dirPath), "\n")+"\n"), fileperm.FileMode())
+}
@@ -0,0 +1,117 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager"
+)
+
+func TestLinkAll(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require privileges on Windows")
+	}
+
+	conf := &config.Config{Displayer: loghelper.InertDisplayer, RootPath: t.TempDir()}
+	manager := versionmanager.Make(conf, "", "tofu", "OpenTofu", nil, nil, fakeRetriever{}, "", "", nil)
+	installPath := filepath.Join(conf.RootPath, "OpenTofu")
+	for _, version := range []string{"1.6.2", "1.7.0"} {
+		if err := os.MkdirAll(filepath.Join(installPath, version), 0o755); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+
+		if err := os.WriteFile(filepath.Join(installPath, version, "tofu"), []byte(version), 0o755); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+	}
+
+	// previous content of link directory
+	linkDir := t.TempDir()
+	foreignTarget := filepath.Join(t.TempDir(), "tofu")
+	if err := os.Symlink(foreignTarget, filepath.Join(linkDir, "tofu-0.1.0")); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if err := os.Symlink(filepath.Join(installPath, "1.5.0", "tofu"), filepath.Join(linkDir, "tofu-1.5.0")); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(linkDir, "tofu-notes"), nil, 0o600); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	// second call must not duplicate registration
+	for i := 0; i < 2; i++ {
+		if err := manager.LinkAll(linkDir); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		wantTarget string // empty when entry should not be a symlink, "-" when entry should not exist
+	}{
+		{name: "tofu-0.1.0", wantTarget: foreignTarget},
+		{name: "tofu-1.5.0", wantTarget: "-"},
+		{name: "tofu-1.6.2", wantTarget: filepath.Join(installPath, "1.6.2", "tofu")},
+		{name: "tofu-1.7.0", wantTarget: filepath.Join(installPath, "1.7.0", "tofu")},
+		{name: "tofu-notes"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			target, err := os.Readlink(filepath.Join(linkDir, tt.name))
+			switch tt.wantTarget {
+			case "-":
+				if !os.IsNotExist(err) {
+					t.Error("Should be removed, get :", target, err)
+				}
+			case "":
+				if err == nil {
+					t.Error("Should be kept as a regular file, get :", target)
+				}
+			default:
+				if err != nil || target != tt.wantTarget {
+					t.Error("Unmatching results, get :", target, err)
+				}
+			}
+		})
+	}
+
+	data, err := os.ReadFile(filepath.Join(conf.RootPath, "link-dirs"))
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if strings.TrimSpace(string(data)) != linkDir {
+		t.Error("Unmatching registered directories, get :", string(data))
+	}
+}
@@ -25,17 +25,25 @@ import (
 	"path/filepath"
 	"slices"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-version"
 
 	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/confirm"
+	"github.com/tofuutils/tenv/v2/pkg/exitcode"
+	"github.com/tofuutils/tenv/v2/pkg/fileperm"
 	"github.com/tofuutils/tenv/v2/pkg/lockfile"
 	"github.com/tofuutils/tenv/v2/pkg/loghelper"
 	"github.com/tofuutils/tenv/v2/pkg/reversecmp"
+	"github.com/tofuutils/tenv/v2/versionmanager/archive"
 	"github.com/tofuutils/tenv/v2/versionmanager/lastuse"
+	"github.com/tofuutils/tenv/v2/versionmanager/manifest"
+	"github.com/tofuutils/tenv/v2/versionmanager/postinstall"
 	"github.com/tofuutils/tenv/v2/versionmanager/semantic"
+	asdfparser "github.com/tofuutils/tenv/v2/versionmanager/semantic/parser/asdf"
 	flatparser "github.com/tofuutils/tenv/v2/versionmanager/semantic/parser/flat"
 	iacparser "github.com/tofuutils/tenv/v2/versionmanager/semantic/parser/iac"
 	"github.com/tofuutils/tenv/v2/versionmanager/semantic/types"
@@ -43,104 +51,220 @@ import (
 
 var (
 	errEmptyVersion        = errors.New("empty version")
-	errNoCompatible        = errors.New("no compatible version found")
+	ErrMaxCount            = errors.New("maximum number of versions must be positive")
+	ErrNoCompatible        = errors.New("no compatible version found")
 	ErrNoCompatibleLocally = errors.New("no compatible version found locally")
+	ErrNotInstalled        = errors.New("version not installed")
 )
 
+func init() {
+	exitcode.RegisterNoCompatible(ErrNoCompatible, ErrNoCompatibleLocally)
+}
+
+const (
+	reasonHidden     = "hidden directory"
+	reasonNotDir     = "not a directory"
+	reasonNotVersion = "not a version name"
+)
+
+var internalFileNames = map[string]struct{}{".lock": {}, "constraint": {}, notifySeenFileName: {}, releaseDatesFileName: {}, RemoteCacheFileName: {}, "version": {}} //nolint
+
 type ReleaseInfoRetriever interface {
 	InstallRelease(version string, targetPath string) error
 	ListReleases() ([]string, error)
 }
 
+// Sources of an evaluated version.
+const (
+	SourceExact  = "exact"  // requested version was an exact version
+	SourceLocal  = "local"  // an installed version matched the requested constraint or strategy
+	SourceRemote = "remote" // a remote version matched the requested constraint or strategy
+)
+
+// Evaluation is the result of a version evaluation, Installed is false when auto install is disabled and Version is missing.
+type Evaluation struct {
+	Installed bool
+	Source    string
+	Version   string
+}
+
 type DatedVersion struct {
-	UseDate time.Time
-	Version string
+	Archived bool
+	UseCount int
+	UseDate  time.Time
+	Version  string
+}
+
+type IgnoredEntry struct {
+	Name   string
+	Reason string
 }
 
 type VersionManager struct {
 	conf                  *config.Config
 	constraintEnvName     string
+	createdInstallDir     *atomic.Value
+	execName              string
 	FolderName            string
 	iacExts               []iacparser.ExtDescription
+	postInstallSteps      []postinstall.Step
 	retriever             ReleaseInfoRetriever
 	VersionEnvName        string
 	defaultVersionEnvName string
 	VersionFiles          []types.VersionFile
 }
 
-func Make(conf *config.Config, constraintEnvName string, folderName string, iacExts []iacparser.ExtDescription, retriever ReleaseInfoRetriever, versionEnvName string, defaultVersionEnvName string, versionFiles []types.VersionFile) VersionManager {
-	return VersionManager{conf: conf, constraintEnvName: constraintEnvName, FolderName: folderName, iacExts: iacExts, retriever: retriever, VersionEnvName: versionEnvName, defaultVersionEnvName: defaultVersionEnvName, VersionFiles: versionFiles}
+func Make(conf *config.Config, constraintEnvName string, execName string, folderName string, iacExts []iacparser.ExtDescription, postInstallSteps []postinstall.Step, retriever ReleaseInfoRetriever, versionEnvName string, defaultVersionEnvName string, versionFiles []types.VersionFile) VersionManager {
+	return VersionManager{conf: conf, constraintEnvName: constraintEnvName, createdInstallDir: &atomic.Value{}, execName: execName, FolderName: folderName, iacExts: iacExts, postInstallSteps: postInstallSteps, retriever: retriever, VersionEnvName: versionEnvName, defaultVersionEnvName: defaultVersionEnvName, VersionFiles: versionFiles}
+}
+
+// BinaryPath returns the executable path of version (installed or not).
+func (m VersionManager) BinaryPath(version string) (string, error) {
+	return filepath.Join(m.installDir(), version, m.execName), nil
 }
 
 // Detect version (resolve and evaluate, can install depending on auto install env var).
+//
+// A version missing while auto install is disabled is returned with ErrNoCompatibleLocally (see DetectResult).
 func (m VersionManager) Detect(proxyCall bool) (string, error) {
+	evaluation, err := m.DetectResult(proxyCall)
+
+	return evaluation.Version, evaluationError(evaluation, err)
+}
+
+// DetectResult is like Detect, without auto install a missing version is reported in result instead of an error.
+func (m VersionManager) DetectResult(proxyCall bool) (Evaluation, error) {
 	configVersion, err := m.Resolve(semantic.LatestAllowedKey)
 	if err != nil {
 		m.conf.Displayer.Flush(proxyCall)
 
-		return "", err
+		return Evaluation{}, err
+	}
+
+	return m.evaluateDetected(configVersion, proxyCall)
+}
+
+func (m VersionManager) evaluateDetected(configVersion string, proxyCall bool) (Evaluation, error) {
+	evaluation, err := m.EvaluateResult(configVersion, proxyCall)
+	if evaluation.Version != "" && len(m.iacExts) != 0 {
+		m.checkInitVersion(evaluation.Version)
+		if m.conf.CheckModules {
+			m.checkModulesRequirement(evaluation.Version)
+		}
 	}
 
-	return m.Evaluate(configVersion, proxyCall)
+	return evaluation, err
 }
 
 // Evaluate version resolution strategy or version constraint (can install depending on auto install env var).
+//
+// A version missing while auto install is disabled is returned with ErrNoCompatibleLocally (see EvaluateResult).
 func (m VersionManager) Evaluate(requestedVersion string, proxyCall bool) (string, error) {
+	evaluation, err := m.EvaluateResult(requestedVersion, proxyCall)
+
+	return evaluation.Version, evaluationError(evaluation, err)
+}
+
+// EvaluateResult is like Evaluate, without auto install a missing version is reported in result instead of an error,
+// so callers decide what to do with it.
+func (m VersionManager) EvaluateResult(requestedVersion string, proxyCall bool) (Evaluation, error) {
+	evaluation, err := m.innerEvaluate(requestedVersion, proxyCall)
+	if err == nil && evaluation.Installed {
+		m.warnSoftConstraint(evaluation.Version)
+	}
+
+	return evaluation, err
+}
+
+func (m VersionManager) innerEvaluate(requestedVersion string, proxyCall bool) (Evaluation, error) {
 	parsedVersion, err := version.NewVersion(requestedVersion)
 	if err == nil {
-		cleanedVersion := parsedVersion.String() // use a parsable version
+		evaluation := Evaluation{Source: SourceExact, Version: parsedVersion.String()} // use a parsable version
+		if err = m.checkRetractedPin(evaluation.Version); err != nil {
+			m.conf.Displayer.Flush(proxyCall)
+
+			return Evaluation{}, err
+		}
+		m.warnTerragruntCompatibility(evaluation.Version)
 		if m.conf.NoInstall {
-			_, installed, err := m.checkVersionInstallation("", cleanedVersion)
+			_, installed, err := m.checkVersionInstallation("", evaluation.Version)
 			if err != nil {
-				return "", err
+				return Evaluation{}, err
 			}
 
 			if !installed {
-				return cleanedVersion, m.autoInstallDisabledMsg(cleanedVersion)
+				m.autoInstallDisabledMsg(evaluation.Version)
+
+				return evaluation, nil
 			}
 			m.conf.Displayer.Flush(proxyCall)
+			evaluation.Installed = true
 
-			return cleanedVersion, nil
+			return evaluation, nil
 		}
 
-		return cleanedVersion, m.installSpecificVersion(cleanedVersion, proxyCall)
+		evaluation.Installed = true
+
+		return evaluation, m.installSpecificVersion(evaluation.Version, proxyCall)
 	}
 
 	predicateInfo, err := semantic.ParsePredicate(requestedVersion, m.FolderName, m, m.iacExts, m.conf)
+	if err == nil {
+		predicateInfo, err = m.skipRetracted(predicateInfo)
+	}
 	if err != nil {
 		m.conf.Displayer.Flush(proxyCall)
 
-		return "", err
+		return Evaluation{}, err
 	}
 
-	installPath, err := m.InstallPath()
-	if err != nil {
-		m.conf.Displayer.Flush(proxyCall)
-
-		return "", err
+	if compatibleInfo, ok := m.terragruntCompatiblePredicate(predicateInfo); ok {
+		// without auto install, an installed unsupported pairing is preferred to an error
+		evaluation, err := m.evaluatePredicate(compatibleInfo, proxyCall, m.conf.NoInstall)
+		if !errors.Is(err, ErrNoCompatible) {
+			return evaluation, err
+		}
+		m.conf.Displayer.Log(hclog.Warn, loghelper.Concat("No ", m.FolderName, " version compatible with calling Terragrunt, fallback to unsupported pairing"))
 	}
 
-	if !m.conf.ForceRemote {
-		versions, err := m.innerListLocal(installPath, predicateInfo.ReverseOrder)
+	return m.evaluatePredicate(predicateInfo, proxyCall, false)
+}
+
+// with localOnly, return ErrNoCompatible instead of searching a remote version.
+func (m VersionManager) evaluatePredicate(predicateInfo types.PredicateInfo, proxyCall bool, localOnly bool) (Evaluation, error) {
+	if m.conf.ForceRemote {
+		m.conf.Displayer.Log(hclog.Debug, "Skip local search", "reason", "remote search forced")
+	} else {
+		versions, err := m.localCandidates(predicateInfo)
 		if err != nil {
 			m.conf.Displayer.Flush(proxyCall)
 
-			return "", err
+			return Evaluation{}, err
 		}
 
 		for _, version := range versions {
 			if predicateInfo.Predicate(version) {
+				m.conf.Displayer.Log(hclog.Debug, "Version decision", "search", "local", "version", version)
 				m.conf.Displayer.Display("Found compatible version installed locally : " + version)
 				m.conf.Displayer.Flush(proxyCall)
 
-				return version, nil
+				return Evaluation{Installed: true, Source: SourceLocal, Version: version}, nil
 			}
 		}
 
+		if localOnly {
+			return Evaluation{}, ErrNoCompatible
+		}
+
 		m.conf.Displayer.Display("No compatible version found locally, search a remote one...")
 	}
 
-	return m.searchInstallRemote(predicateInfo, m.conf.NoInstall, proxyCall)
+	evaluation, err := m.searchInstallRemote(predicateInfo, m.conf.NoInstall, proxyCall)
+	if err == nil {
+		m.conf.Displayer.Log(hclog.Debug, "Version decision", "search", "remote", "version", evaluation.Version)
+	}
+
+	return evaluation, err
 }
 
 func (m VersionManager) Install(requestedVersion string) error {
@@ -160,20 +284,53 @@ func (m VersionManager) Install(requestedVersion string) error {
 	return err
 }
 
+// EnsureInstalled install version when missing (unless auto install is disabled).
+func (m VersionManager) EnsureInstalled(version string) error {
+	_, installed, err := m.checkVersionInstallation("", version)
+	if err != nil || installed {
+		return err
+	}
+
+	if m.conf.NoInstall {
+		m.autoInstallDisabledMsg(version)
+
+		return ErrNoCompatibleLocally
+	}
+
+	return m.installSpecificVersion(version, true)
+}
+
 // try to ensure the directory exists with a MkdirAll call.
 // (made lazy method : not always useful and allows flag override for root path).
 func (m VersionManager) InstallPath() (string, error) {
-	dirPath := filepath.Join(m.conf.RootPath, m.FolderName)
+	return m.ensureInstallDir()
+}
 
-	return dirPath, os.MkdirAll(dirPath, 0o755)
+// installDir has no side effect, callers reading the directory must handle its absence.
+func (m VersionManager) installDir() string {
+	return filepath.Join(m.conf.RootPath, m.FolderName)
 }
 
-func (m VersionManager) ListLocal(reverseOrder bool) ([]DatedVersion, error) {
-	installPath, err := m.InstallPath()
-	if err != nil {
-		return nil, err
+// the MkdirAll call is done once by VersionManager and root path (memoized state is shared by copies).
+func (m VersionManager) ensureInstallDir() (string, error) {
+	dirPath := m.installDir()
+	if m.createdInstallDir != nil && m.createdInstallDir.Load() == dirPath {
+		return dirPath, nil
+	}
+
+	if err := os.MkdirAll(dirPath, fileperm.DirMode()); err != nil {
+		return "", err
+	}
+
+	if m.createdInstallDir != nil {
+		m.createdInstallDir.Store(dirPath)
 	}
 
+	return dirPath, nil
+}
+
+func (m VersionManager) ListLocal(reverseOrder bool) ([]DatedVersion, error) {
+	installPath := m.installDir()
 	versions, err := m.innerListLocal(installPath, reverseOrder)
 	if err != nil {
 		return nil, err
@@ -181,17 +338,98 @@ func (m VersionManager) ListLocal(reverseOrder bool) ([]DatedVersion, error) {
 
 	datedVersions := make([]DatedVersion, 0, len(versions))
 	for _, version := range versions {
+		versionPath := filepath.Join(installPath, version)
+		usage := lastuse.ReadUsage(versionPath, m.conf)
 		datedVersions = append(datedVersions, DatedVersion{
-			UseDate: lastuse.Read(filepath.Join(installPath, version), m.conf.Displayer),
-			Version: version,
+			Archived: archive.IsArchived(versionPath),
+			UseCount: usage.Count,
+			UseDate:  usage.Date,
+			Version:  version,
 		})
 	}
 
 	return datedVersions, nil
 }
 
+// ListMatching returns the highest remote versions matching constraint (at most maxCount).
+func (m VersionManager) ListMatching(constraint string, maxCount int, stableOnly bool) ([]string, error) {
+	if maxCount <= 0 {
+		return nil, ErrMaxCount
+	}
+
+	parsedConstraint, err := version.NewConstraint(constraint)
+	if err != nil {
+		return nil, err
+	}
+
+	remoteVersions, err := m.ListRemote(true)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+	for _, remoteVersion := range remoteVersions {
+		if len(versions) == maxCount {
+			break
+		}
+
+		if stableOnly && !semantic.StableVersion(remoteVersion) {
+			continue
+		}
+
+		parsedVersion, err := version.NewVersion(remoteVersion)
+		if err == nil && parsedConstraint.Check(parsedVersion) {
+			versions = append(versions, remoteVersion)
+		}
+	}
+
+	if len(versions) == 0 {
+		return nil, ErrNoCompatible
+	}
+
+	return versions, nil
+}
+
+// ListLatestPerMinor returns the newest stable patch of each minor line matching constraint (in ascending order).
+func (m VersionManager) ListLatestPerMinor(constraint string) ([]string, error) {
+	parsedConstraint, err := version.NewConstraint(constraint)
+	if err != nil {
+		return nil, err
+	}
+
+	remoteVersions, err := m.ListRemote(true)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+	seenMinors := map[[2]int]struct{}{}
+	for _, remoteVersion := range remoteVersions {
+		parsedVersion, err := version.NewVersion(remoteVersion)
+		if err != nil || parsedVersion.Prerelease() != "" || !parsedConstraint.Check(parsedVersion) {
+			continue
+		}
+
+		segments := parsedVersion.Segments()
+		minor := [2]int{segments[0], segments[1]}
+		if _, seen := seenMinors[minor]; seen {
+			continue
+		}
+
+		seenMinors[minor] = struct{}{}
+		versions = append(versions, remoteVersion)
+	}
+
+	if len(versions) == 0 {
+		return nil, ErrNoCompatible
+	}
+	slices.Reverse(versions)
+
+	return versions, nil
+}
+
 func (m VersionManager) ListRemote(reverseOrder bool) ([]string, error) {
-	versions, err := m.retriever.ListReleases()
+	versions, err := m.listReleases()
 	if err != nil {
 		return nil, err
 	}
@@ -203,41 +441,99 @@ func (m VersionManager) ListRemote(reverseOrder bool) ([]string, error) {
 }
 
 func (m VersionManager) LocalSet() map[string]struct{} {
-	installPath, err := m.InstallPath()
+	entries, err := os.ReadDir(m.installDir())
 	if err != nil {
-		m.conf.Displayer.Log(hclog.Warn, "Can not create installation directory", loghelper.Error, err)
+		m.conf.Displayer.Log(loghelper.LevelWarnOrDebug(errors.Is(err, fs.ErrNotExist)), "Can not read installed versions", loghelper.Error, err)
 
 		return nil
 	}
 
-	entries, err := os.ReadDir(installPath)
-	if err != nil {
-		m.conf.Displayer.Log(loghelper.LevelWarnOrDebug(errors.Is(err, fs.ErrNotExist)), "Can not read installed versions", loghelper.Error, err)
-
-		return nil
+	versions, _ := filterVersionEntries(entries)
+	versionSet := make(map[string]struct{}, len(versions))
+	for _, version := range versions {
+		versionSet[version] = struct{}{}
 	}
 
-	versionSet := make(map[string]struct{}, len(entries))
-	for _, entry := range entries {
-		if entry.IsDir() {
-			versionSet[entry.Name()] = struct{}{}
+	return versionSet
+}
+
+// ListIgnored returns entries of installation directory which are not considered as installed versions.
+func (m VersionManager) ListIgnored() ([]IgnoredEntry, error) {
+	entries, err := os.ReadDir(m.installDir())
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
 		}
+
+		return nil, err
 	}
 
-	return versionSet
+	_, ignoreds := filterVersionEntries(entries)
+
+	return ignoreds, nil
 }
 
+// a soft constraint is not added to resolution predicates (see ReadSoftConstraint).
 func (m VersionManager) ReadDefaultConstraint() string {
+	constraint, _ := m.ReadDefaultConstraintWithSource()
+	if strings.HasPrefix(constraint, SoftConstraintPrefix) {
+		return ""
+	}
+
+	return constraint
+}
+
+// ReadDefaultConstraintWithSource also return where the constraint come from
+// (env var name or file path), the order is : env var, project file (searched like version files), root file.
+//
+// Shared constraint set references (like "@org-defaults") are replaced by their content.
+func (m VersionManager) ReadDefaultConstraintWithSource() (string, string) {
+	constraint, source := m.readRawDefaultConstraint()
+
+	return m.expandConstraint(constraint, source), source
+}
+
+func (m VersionManager) readRawDefaultConstraint() (string, string) {
 	if constraint := os.Getenv(m.constraintEnvName); constraint != "" {
-		return constraint
+		return constraint, m.constraintEnvName
 	}
 
-	constraint, _ := flatparser.Retrieve(m.RootConstraintFilePath(), m.conf, flatparser.NoMsg)
+	var projectFilePath string
+	projectFile := types.VersionFile{Name: m.ProjectConstraintFileName(), Parser: func(filePath string, conf *config.Config) (string, error) {
+		constraint, err := flatparser.Retrieve(filePath, conf, flatparser.NoMsg)
+		if constraint != "" {
+			projectFilePath = filePath
+		}
 
-	return constraint
+		return constraint, err
+	}}
+	if constraint, _ := semantic.RetrieveVersion([]types.VersionFile{projectFile}, m.conf); constraint != "" {
+		return constraint, projectFilePath
+	}
+
+	rootFilePath := m.RootConstraintFilePath()
+	constraint, _ := flatparser.Retrieve(rootFilePath, m.conf, flatparser.NoMsg)
+
+	return constraint, rootFilePath
 }
 
-func (m VersionManager) ResetConstraint() error {
+func (m VersionManager) ConstraintEnvName() string {
+	return m.constraintEnvName
+}
+
+func (m VersionManager) ExecName() string {
+	return m.execName
+}
+
+func (m VersionManager) ProjectConstraintFileName() string {
+	return loghelper.Concat(".", strings.ToLower(m.FolderName), "-constraint")
+}
+
+func (m VersionManager) ResetConstraint(workingDir bool) error {
+	if workingDir {
+		return removeFile(m.ProjectConstraintFileName(), m.conf)
+	}
+
 	return removeFile(m.RootConstraintFilePath(), m.conf)
 }
 
@@ -257,6 +553,10 @@ func (m VersionManager) Resolve(defaultStrategy string) (string, error) {
 		return version, err
 	}
 
+	if version, err = m.resolveWithPlugin(); err != nil || version != "" {
+		return version, err
+	}
+
 	if version = os.Getenv(m.defaultVersionEnvName); version != "" {
 		return types.DisplayDetectionInfo(m.conf.Displayer, version, m.defaultVersionEnvName), nil
 	}
@@ -274,6 +574,24 @@ func (m VersionManager) ResolveWithVersionFiles() (string, error) {
 	return semantic.RetrieveVersion(m.VersionFiles, m.conf)
 }
 
+// VersionFilePaths returns the paths of all version files which could be read by ResolveWithVersionFiles
+// (existing or not, relative to working directory for its own files).
+func (m VersionManager) VersionFilePaths() ([]string, error) {
+	dirPaths, err := semantic.SearchedDirs(m.conf)
+	if err != nil {
+		return nil, err
+	}
+
+	filePaths := make([]string, 0, len(dirPaths)*len(m.VersionFiles))
+	for _, dirPath := range dirPaths {
+		for _, versionFile := range m.VersionFiles {
+			filePaths = append(filePaths, filepath.Join(dirPath, versionFile.Name))
+		}
+	}
+
+	return filePaths, nil
+}
+
 // (made lazy method : not always useful and allows flag override for root path).
 func (m VersionManager) RootConstraintFilePath() string {
 	return filepath.Join(m.conf.RootPath, m.FolderName, "constraint")
@@ -284,22 +602,45 @@ func (m VersionManager) RootVersionFilePath() string {
 	return filepath.Join(m.conf.RootPath, m.FolderName, "version")
 }
 
-func (m VersionManager) SetConstraint(constraint string) error {
-	_, err := version.NewConstraint(constraint) // check the use of a parsable constraint
+func (m VersionManager) SetConstraint(constraint string, workingDir bool) error {
+	// check the use of a parsable constraint (shared constraint set references are written as is)
+	expanded := m.expandConstraint(constraint, "")
+	_, err := version.NewConstraint(strings.TrimSpace(strings.TrimPrefix(expanded, SoftConstraintPrefix)))
 	if err != nil {
 		return err
 	}
 
+	if workingDir {
+		return writeFile(m.ProjectConstraintFileName(), constraint, m.conf)
+	}
+
 	return writeFile(m.RootConstraintFilePath(), constraint, m.conf)
 }
 
+// Touch record date as last use of an installed version (without counting a call), so it is kept by uninstall with a not-used-since strategy.
+func (m VersionManager) Touch(version string, date time.Time) error {
+	if version == "" {
+		return errEmptyVersion
+	}
+
+	versionPath := filepath.Join(m.installDir(), version)
+	if _, err := os.Stat(versionPath); err != nil {
+		return ErrNotInstalled
+	}
+
+	return lastuse.Touch(versionPath, date, m.conf)
+}
+
 func (m VersionManager) Uninstall(requestedVersion string) error {
-	installPath, err := m.InstallPath()
+	installPath, err := m.ensureInstallDir()
 	if err != nil {
 		return err
 	}
 
-	deleteLock := lockfile.Write(installPath, m.conf.Displayer)
+	deleteLock, err := lockfile.Write(installPath, m.conf.LockTimeout, m.conf.Displayer)
+	if err != nil {
+		return err
+	}
 	disableExit := lockfile.CleanAndExitOnInterrupt(deleteLock)
 	defer disableExit()
 	defer deleteLock()
@@ -316,7 +657,7 @@ func (m VersionManager) Uninstall(requestedVersion string) error {
 		return err
 	}
 
-	selected, err := semantic.SelectVersionsToUninstall(requestedVersion, installPath, versions, m.conf.Displayer)
+	selected, err := semantic.SelectVersionsToUninstall(requestedVersion, installPath, versions, m.conf)
 	if err != nil {
 		return err
 	}
@@ -329,13 +670,7 @@ func (m VersionManager) Uninstall(requestedVersion string) error {
 
 	m.conf.Displayer.Display(loghelper.Concat("Selected ", m.FolderName, " versions for uninstallation :"))
 	m.conf.Displayer.Display(strings.Join(selected, ", "))
-	m.conf.Displayer.Display("Uninstall ? [y/N]")
-
-	buffer := make([]byte, 1)
-	os.Stdin.Read(buffer)
-	read := buffer[0]
-
-	if doUninstall := read == 'y' || read == 'Y'; !doUninstall {
+	if !m.conf.AutoApprove && !confirm.Ask(os.Stdin, m.conf.Displayer, "Uninstall ?", m.conf.PromptTimeout, m.conf.PromptDefault) {
 		return nil
 	}
 
@@ -347,12 +682,15 @@ func (m VersionManager) Uninstall(requestedVersion string) error {
 }
 
 func (m VersionManager) UninstallMultiple(versions []string) error {
-	installPath, err := m.InstallPath()
+	installPath, err := m.ensureInstallDir()
 	if err != nil {
 		return err
 	}
 
-	deleteLock := lockfile.Write(installPath, m.conf.Displayer)
+	deleteLock, err := lockfile.Write(installPath, m.conf.LockTimeout, m.conf.Displayer)
+	if err != nil {
+		return err
+	}
 	disableExit := lockfile.CleanAndExitOnInterrupt(deleteLock)
 	defer disableExit()
 	defer deleteLock()
@@ -365,21 +703,82 @@ func (m VersionManager) UninstallMultiple(versions []string) error {
 }
 
 func (m VersionManager) Use(requestedVersion string, workingDir bool) error {
-	detectedVersion, err := m.Evaluate(requestedVersion, false)
+	detectedVersion, err := m.evaluateUse(requestedVersion)
 	if err != nil {
-		if err != ErrNoCompatibleLocally {
-			return err
-		}
+		return err
+	}
 
-		m.conf.Displayer.Display(err.Error())
+	if workingDir {
+		return m.writeUseFile(m.UseFileName(), detectedVersion)
 	}
 
-	targetFilePath := m.VersionFiles[0].Name
-	if !workingDir {
-		targetFilePath = m.RootVersionFilePath()
+	if _, err = m.ensureInstallDir(); err != nil {
+		return err
+	}
+
+	return writeFile(m.RootVersionFilePath(), detectedVersion, m.conf)
+}
+
+// UseFileName returns the name of version file created by Use in working directory (TENV_USE_FILE or first supported version file).
+func (m VersionManager) UseFileName() string {
+	if m.conf.UseFile != "" {
+		return m.conf.UseFile
 	}
 
-	return writeFile(targetFilePath, detectedVersion, m.conf)
+	return m.VersionFiles[0].Name
+}
+
+// UseInFile evaluates requestedVersion like Use and writes it in filePath (a .tool-versions file is updated instead of overwritten).
+func (m VersionManager) UseInFile(requestedVersion string, filePath string) error {
+	detectedVersion, err := m.evaluateUse(requestedVersion)
+	if err != nil {
+		return err
+	}
+
+	return m.writeUseFile(filePath, detectedVersion)
+}
+
+// an exact version not available locally is only reported, it is still written (to be installed on first call),
+// a version resolved from a constraint or a strategy is only written once installed.
+func (m VersionManager) evaluateUse(requestedVersion string) (string, error) {
+	evaluation, err := m.EvaluateResult(requestedVersion, false)
+	if err != nil || evaluation.Installed {
+		return evaluation.Version, err
+	}
+
+	if evaluation.Source != SourceExact {
+		return "", ErrNoCompatibleLocally
+	}
+	m.conf.Displayer.Display(ErrNoCompatibleLocally.Error())
+
+	return evaluation.Version, nil
+}
+
+func (m VersionManager) writeUseFile(filePath string, version string) error {
+	if filepath.Base(filePath) != asdfparser.FileName {
+		return writeFile(filePath, version, m.conf)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	toolName := asdfparser.ToolName(m.execName)
+	if err = os.WriteFile(filePath, asdfparser.SetVersion(content, toolName, version), fileperm.FileMode()); err == nil {
+		m.conf.Displayer.Display(loghelper.Concat("Written ", toolName, " ", version, " in ", filePath))
+	}
+
+	return err
+}
+
+// keep error driven behavior of Evaluate and Detect for a missing version.
+func evaluationError(evaluation Evaluation, err error) error {
+	if err == nil && !evaluation.Installed {
+		return ErrNoCompatibleLocally
+	}
+
+	return err
 }
 
 func (m VersionManager) alreadyInstalledMsg(version string, proxyCall bool) {
@@ -387,24 +786,48 @@ func (m VersionManager) alreadyInstalledMsg(version string, proxyCall bool) {
 	m.conf.Displayer.Flush(proxyCall)
 }
 
-func (m VersionManager) autoInstallDisabledMsg(version string) error {
+func (m VersionManager) autoInstallDisabledMsg(version string) {
 	cmdName := strings.ToLower(m.FolderName)
 	m.conf.Displayer.Flush(false) // Always normal display when installation is missing
 	m.conf.Displayer.Display(loghelper.Concat("Auto-install is disabled. To install ", m.FolderName, " version ", version, ", you can set environment variable TENV_AUTO_INSTALL=true, or install it via any of the following command: 'tenv ", cmdName, " install', 'tenv ", cmdName, " install ", version, "'"))
+}
+
+// only warn, the incompatibility will be confirmed by the called binary.
+func (m VersionManager) checkModulesRequirement(detectedVersion string) {
+	parsedVersion, err := version.NewVersion(detectedVersion)
+	if err != nil {
+		return
+	}
+
+	moduleRequirements, err := iacparser.GatherModulesRequiredVersion(m.conf, m.iacExts)
+	if err != nil {
+		m.conf.Displayer.Log(hclog.Warn, "Failed to read downloaded modules", loghelper.Error, err)
+
+		return
+	}
 
-	return ErrNoCompatibleLocally
+	for _, moduleRequirement := range moduleRequirements {
+		for _, required := range moduleRequirement.Requireds {
+			constraint, err := version.NewConstraint(required)
+			if err != nil {
+				m.conf.Displayer.Log(hclog.Warn, "Failed to parse module required_version", "module", moduleRequirement.Key, loghelper.Error, err)
+
+				continue
+			}
+
+			if !constraint.Check(parsedVersion) {
+				m.conf.Displayer.Log(hclog.Warn, loghelper.Concat(m.FolderName, " ", detectedVersion, " does not match module requirement"), "module", moduleRequirement.Key, "source", moduleRequirement.Source, "required", required)
+			}
+		}
+	}
 }
 
 func (m VersionManager) checkVersionInstallation(installPath string, version string) (string, bool, error) {
-	var err error
 	if installPath == "" {
-		installPath, err = m.InstallPath()
-		if err != nil {
-			return "", false, err
-		}
+		installPath = m.installDir()
 	}
 
-	if _, err = os.Stat(filepath.Join(installPath, version)); err != nil {
+	if _, err := os.Stat(filepath.Join(installPath, version)); err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			return installPath, false, nil
 		}
@@ -415,17 +838,34 @@ func (m VersionManager) checkVersionInstallation(installPath string, version str
 	return installPath, true, nil
 }
 
+// a pinned version only needs a stat of its directory (proxies called in loop avoid listing and sorting installed versions).
+func (m VersionManager) localCandidates(predicateInfo types.PredicateInfo) ([]string, error) {
+	if predicateInfo.Exact == "" {
+		return m.innerListLocal(m.installDir(), predicateInfo.ReverseOrder)
+	}
+
+	_, installed, err := m.checkVersionInstallation("", predicateInfo.Exact)
+	if err != nil || !installed {
+		return nil, err
+	}
+
+	return []string{predicateInfo.Exact}, nil
+}
+
+// a missing installation directory means no installed version.
 func (m VersionManager) innerListLocal(installPath string, reverseOrder bool) ([]string, error) {
 	entries, err := os.ReadDir(installPath)
 	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+
 		return nil, err
 	}
 
-	versions := make([]string, 0, len(entries))
-	for _, entry := range entries {
-		if entry.IsDir() {
-			versions = append(versions, entry.Name())
-		}
+	versions, ignoreds := filterVersionEntries(entries)
+	for _, ignored := range ignoreds {
+		m.conf.Displayer.Log(hclog.Debug, "Ignored entry in installation directory", "name", ignored.Name, "reason", ignored.Reason)
 	}
 
 	cmpFunc := reversecmp.Reverser[string](semantic.CmpVersion, reverseOrder)
@@ -441,6 +881,19 @@ func (m VersionManager) installSpecificVersion(version string, proxyCall bool) e
 		return errEmptyVersion
 	}
 
+	if m.needDelegation() {
+		if _, err := os.Stat(filepath.Join(m.conf.RootPath, m.FolderName, version)); err == nil {
+			m.alreadyInstalledMsg(version, proxyCall)
+
+			return nil
+		}
+
+		// Always normal display when installation is needed
+		m.conf.Displayer.Flush(false)
+
+		return m.delegateInstall(version)
+	}
+
 	// first check without lock
 	installPath, installed, err := m.checkVersionInstallation("", version)
 	if err != nil {
@@ -453,7 +906,14 @@ func (m VersionManager) installSpecificVersion(version string, proxyCall bool) e
 		return nil
 	}
 
-	deleteLock := lockfile.Write(installPath, m.conf.Displayer)
+	if _, err = m.ensureInstallDir(); err != nil {
+		return err
+	}
+
+	deleteLock, err := lockfile.Write(installPath, m.conf.LockTimeout, m.conf.Displayer)
+	if err != nil {
+		return err
+	}
 	disableExit := lockfile.CleanAndExitOnInterrupt(deleteLock)
 	defer disableExit()
 	defer deleteLock()
@@ -474,35 +934,75 @@ func (m VersionManager) installSpecificVersion(version string, proxyCall bool) e
 	m.conf.Displayer.Flush(false)
 	m.conf.Displayer.Display(loghelper.Concat("Installing ", m.FolderName, " ", version))
 
-	err = m.retriever.InstallRelease(version, filepath.Join(installPath, version))
-	if err == nil {
-		m.conf.Displayer.Display(loghelper.Concat("Installation of ", m.FolderName, " ", version, " successful"))
+	versionPath := filepath.Join(installPath, version)
+	if !m.installDelta(installPath, version) {
+		if err = m.retriever.InstallRelease(version, versionPath); err != nil {
+			return err
+		}
 	}
 
-	return err
-}
+	if err = m.runPostInstall(versionPath); err != nil {
+		return err
+	}
+	m.conf.Displayer.Display(loghelper.Concat("Installation of ", m.FolderName, " ", version, " successful"))
+	m.syncLinks()
+	m.syncRegistry(version)
+	m.syncCompletion()
+	m.pruneAfterInstall(installPath)
+	m.keepAfterInstall(installPath)
+	m.archiveAfterInstall()
 
-func (m VersionManager) searchInstallRemote(predicateInfo types.PredicateInfo, noInstall bool, proxyCall bool) (string, error) {
-	versions, err := m.ListRemote(predicateInfo.ReverseOrder)
-	if err != nil {
-		m.conf.Displayer.Flush(proxyCall)
+	return nil
+}
 
-		return "", err
+// on failure, remove the version directory to not leave an incomplete installation.
+func (m VersionManager) runPostInstall(versionPath string) error {
+	if len(m.postInstallSteps) == 0 {
+		return nil
 	}
 
-	for _, version := range versions {
-		if predicateInfo.Predicate(version) {
-			m.conf.Displayer.Display("Found compatible version remotely : " + version)
-			if noInstall {
-				return version, m.autoInstallDisabledMsg(version)
+	descriptions := make([]string, 0, len(m.postInstallSteps))
+	for _, step := range m.postInstallSteps {
+		description := step.Describe()
+		m.conf.Displayer.Log(hclog.Debug, "Apply post install step", "step", description)
+		if err := step.Apply(versionPath); err != nil {
+			if errRemove := os.RemoveAll(versionPath); errRemove != nil {
+				m.conf.Displayer.Log(hclog.Warn, "Failed to remove incomplete installation", loghelper.Error, errRemove)
 			}
 
-			return version, m.installSpecificVersion(version, proxyCall)
+			return err
 		}
+		descriptions = append(descriptions, description)
 	}
-	m.conf.Displayer.Flush(proxyCall)
 
-	return "", errNoCompatible
+	installManifest, _ := manifest.Read(versionPath, m.conf.Displayer)
+	installManifest.PostInstall = descriptions
+	manifest.Write(versionPath, installManifest, m.conf.Displayer)
+
+	return nil
+}
+
+func (m VersionManager) searchInstallRemote(predicateInfo types.PredicateInfo, noInstall bool, proxyCall bool) (Evaluation, error) {
+	version, err := m.findRemote(predicateInfo)
+	if err != nil || version == "" {
+		m.conf.Displayer.Flush(proxyCall)
+		if err == nil {
+			err = ErrNoCompatible
+		}
+
+		return Evaluation{}, err
+	}
+
+	m.conf.Displayer.Display("Found compatible version remotely : " + version)
+	evaluation := Evaluation{Source: SourceRemote, Version: version}
+	if noInstall {
+		m.autoInstallDisabledMsg(version)
+
+		return evaluation, nil
+	}
+	evaluation.Installed = true
+
+	return evaluation, m.installSpecificVersion(version, proxyCall)
 }
 
 func (m VersionManager) uninstallSpecificVersion(installPath string, version string) {
@@ -513,11 +1013,18 @@ func (m VersionManager) uninstallSpecificVersion(installPath string, version str
 	}
 
 	targetPath := filepath.Join(installPath, version)
-	err := os.RemoveAll(targetPath)
-	if err == nil {
-		m.conf.Displayer.Display(loghelper.Concat("Uninstallation of ", m.FolderName, " ", version, " successful (directory ", targetPath, " removed)"))
-	} else {
+	err := m.removeVersionDir(targetPath, version)
+	switch {
+	case err != nil:
 		m.conf.Displayer.Display(loghelper.Concat("Uninstallation of ", m.FolderName, " ", version, " failed with error : ", err.Error()))
+	case m.conf.TrashTTL > 0:
+		m.conf.Displayer.Display(loghelper.Concat("Uninstallation of ", m.FolderName, " ", version, " successful (directory ", targetPath, " moved to trash, can be restored)"))
+		m.syncLinks()
+		m.syncRegistry(version)
+	default:
+		m.conf.Displayer.Display(loghelper.Concat("Uninstallation of ", m.FolderName, " ", version, " successful (directory ", targetPath, " removed)"))
+		m.syncLinks()
+		m.syncRegistry(version)
 	}
 }
 
@@ -531,10 +1038,36 @@ func removeFile(filePath string, conf *config.Config) error {
 }
 
 func writeFile(filePath string, content string, conf *config.Config) error {
-	err := os.WriteFile(filePath, []byte(content), 0o644)
+	err := os.WriteFile(filePath, []byte(content), fileperm.FileMode())
 	if err == nil {
 		conf.Displayer.Display(loghelper.Concat("Written ", content, " in ", filePath))
 	}
 
 	return err
 }
+
+// hidden directories (like ".trash" or ".staging") and directories without a version name
+// (like "aliases" or "metadata") are ignored, so new internal directories does not break versions listing.
+func filterVersionEntries(entries []fs.DirEntry) ([]string, []IgnoredEntry) {
+	versions := make([]string, 0, len(entries))
+	var ignoreds []IgnoredEntry
+	for _, entry := range entries {
+		name := entry.Name()
+		switch {
+		case !entry.IsDir():
+			if _, internal := internalFileNames[name]; !internal {
+				ignoreds = append(ignoreds, IgnoredEntry{Name: name, Reason: reasonNotDir})
+			}
+		case name[0] == '.':
+			ignoreds = append(ignoreds, IgnoredEntry{Name: name, Reason: reasonHidden})
+		default:
+			if _, err := version.NewVersion(name); err != nil {
+				ignoreds = append(ignoreds, IgnoredEntry{Name: name, Reason: reasonNotVersion})
+			} else {
+				versions = append(versions, name)
+			}
+		}
+	}
+
+	return versions, ignoreds
+}
@@ -0,0 +1,894 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/audit"
+	"github.com/tofuutils/tenv/v2/pkg/exitcode"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager"
+	"github.com/tofuutils/tenv/v2/versionmanager/lastuse"
+	"github.com/tofuutils/tenv/v2/versionmanager/semantic/types"
+)
+
+type fakeRetriever []string
+
+func (fakeRetriever) InstallRelease(string, string) error {
+	return nil
+}
+
+func (r fakeRetriever) ListReleases() ([]string, error) {
+	return slices.Clone(r), nil
+}
+
+type fakeDatedRetriever struct {
+	fakeRetriever
+	dates map[string]time.Time
+}
+
+func (r fakeDatedRetriever) ListDatedReleases() ([]string, map[string]time.Time, error) {
+	return slices.Clone(r.fakeRetriever), r.dates, nil
+}
+
+func TestReleaseDates(t *testing.T) {
+	t.Parallel()
+
+	date := time.Date(2024, 1, 10, 14, 13, 28, 0, time.UTC)
+	retriever := fakeDatedRetriever{fakeRetriever: fakeRetriever{"1.6.0", "1.7.0"}, dates: map[string]time.Time{"1.6.0": date}}
+	conf := &config.Config{Displayer: loghelper.InertDisplayer, RootPath: t.TempDir()}
+	manager := versionmanager.Make(conf, "", "tofu", "OpenTofu", nil, nil, retriever, "", "", nil)
+
+	if _, err := manager.ListRemote(false); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	dates := manager.ReleaseDates()
+	if len(dates) != 1 || !dates["1.6.0"].Equal(date) {
+		t.Error("Unmatching results, get :", dates)
+	}
+}
+
+func TestListLatestPerMinor(t *testing.T) {
+	t.Parallel()
+
+	retriever := fakeRetriever{"1.4.7", "1.5.0", "1.5.7", "1.6.0-rc1", "1.6.2", "1.6.1", "1.7.0-alpha1", "1.8.5", "1.9.0"}
+	manager := versionmanager.Make(&config.Config{Displayer: loghelper.InertDisplayer}, "", "tofu", "OpenTofu", nil, nil, retriever, "", "", nil)
+
+	versions, err := manager.ListLatestPerMinor(">= 1.5, < 1.9")
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if !slices.Equal(versions, []string{"1.5.7", "1.6.2", "1.8.5"}) {
+		t.Error("Unmatching results, get :", versions)
+	}
+
+	if _, err = manager.ListLatestPerMinor(">= 2.0"); err != versionmanager.ErrNoCompatible {
+		t.Error("Should fail with ErrNoCompatible, get :", err)
+	}
+}
+
+func TestListMatching(t *testing.T) {
+	t.Parallel()
+
+	retriever := fakeRetriever{"1.5.7", "1.6.0-rc1", "1.6.1", "1.6.2", "1.7.0"}
+	manager := versionmanager.Make(&config.Config{Displayer: loghelper.InertDisplayer}, "", "tofu", "OpenTofu", nil, nil, retriever, "", "", nil)
+
+	tests := []struct {
+		name       string
+		constraint string
+		maxCount   int
+		stableOnly bool
+		want       []string
+		wantErr    error
+	}{
+		{name: "Limited", constraint: ">= 1.6.0-rc1", maxCount: 2, want: []string{"1.7.0", "1.6.2"}},
+		{name: "StableOnly", constraint: "< 1.6.1", maxCount: 5, stableOnly: true, want: []string{"1.5.7"}},
+		{name: "NoMatch", constraint: ">= 2.0", maxCount: 5, wantErr: versionmanager.ErrNoCompatible},
+		{name: "ZeroCount", constraint: ">= 1.0", maxCount: 0, wantErr: versionmanager.ErrMaxCount},
+		{name: "NegativeCount", constraint: ">= 1.0", maxCount: -1, wantErr: versionmanager.ErrMaxCount},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			versions, err := manager.ListMatching(tt.constraint, tt.maxCount, tt.stableOnly)
+			if err != tt.wantErr || !slices.Equal(versions, tt.want) {
+				t.Error("Unmatching results, get :", versions, err)
+			}
+		})
+	}
+}
+
+func TestListIgnored(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.Config{Displayer: loghelper.InertDisplayer, RootPath: t.TempDir()}
+	manager := versionmanager.Make(conf, "", "tofu", "OpenTofu", nil, nil, fakeRetriever{}, "", "", nil)
+
+	// in ReadDir order
+	entries := []struct {
+		name       string
+		dir        bool
+		wantReason string // empty for installed versions and tenv files
+	}{
+		{name: ".lock"},
+		{name: ".trash", dir: true, wantReason: "hidden directory"},
+		{name: "1.10.0", dir: true},
+		{name: "1.6.2", dir: true},
+		{name: "aliases", dir: true, wantReason: "not a version name"},
+		{name: "notes.txt", wantReason: "not a directory"},
+		{name: "version"},
+	}
+
+	if err := os.MkdirAll(filepath.Join(conf.RootPath, "OpenTofu"), 0o755); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	var wantIgnoreds []versionmanager.IgnoredEntry
+	for _, entry := range entries {
+		entryPath := filepath.Join(conf.RootPath, "OpenTofu", entry.name)
+		if entry.dir {
+			if err := os.MkdirAll(entryPath, 0o755); err != nil {
+				t.Fatal("Unexpected error :", err)
+			}
+		} else if err := os.WriteFile(entryPath, nil, 0o600); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+
+		if entry.wantReason != "" {
+			wantIgnoreds = append(wantIgnoreds, versionmanager.IgnoredEntry{Name: entry.name, Reason: entry.wantReason})
+		}
+	}
+
+	ignoreds, err := manager.ListIgnored()
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if !slices.Equal(ignoreds, wantIgnoreds) {
+		t.Error("Unmatching results, get :", ignoreds)
+	}
+
+	datedVersions, err := manager.ListLocal(false)
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if len(datedVersions) != 2 || datedVersions[0].Version != "1.6.2" || datedVersions[1].Version != "1.10.0" {
+		t.Error("Unmatching results, get :", datedVersions)
+	}
+}
+
+// change working directory and env, so not parallel.
+func TestReadDefaultConstraintWithSource(t *testing.T) { //nolint
+	previousPath, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+	defer os.Chdir(previousPath) //nolint
+
+	basePath := t.TempDir()
+	workPath := filepath.Join(basePath, "project", "sub")
+	if err = os.MkdirAll(workPath, 0o755); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if err = os.Chdir(workPath); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	conf := &config.Config{Displayer: loghelper.InertDisplayer, RootPath: t.TempDir(), SearchBoundary: "none", UserPath: basePath}
+	manager := versionmanager.Make(conf, "TENV_TEST_DEFAULT_CONSTRAINT", "tofu", "OpenTofu", nil, nil, fakeRetriever{}, "", "", nil)
+	if err = os.MkdirAll(filepath.Join(conf.RootPath, "OpenTofu"), 0o755); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if manager.ProjectConstraintFileName() != ".opentofu-constraint" {
+		t.Fatal("Unmatching results, get :", manager.ProjectConstraintFileName())
+	}
+
+	tests := []struct {
+		name       string
+		env        string
+		project    string // written in working directory with SetConstraint (reset when empty)
+		root       string // written in root path with SetConstraint (reset when empty)
+		want       string
+		wantSource string
+	}{
+		{name: "None", wantSource: manager.RootConstraintFilePath()},
+		{name: "Root", root: "~> 1.6.0", want: "~> 1.6.0", wantSource: manager.RootConstraintFilePath()},
+		{name: "Project", project: "~> 1.7.0", root: "~> 1.6.0", want: "~> 1.7.0", wantSource: ".opentofu-constraint"},
+		{name: "Env", env: ">= 1.8.0", project: "~> 1.7.0", root: "~> 1.6.0", want: ">= 1.8.0", wantSource: "TENV_TEST_DEFAULT_CONSTRAINT"},
+	}
+
+	// sequential, files and env are shared
+	for _, tt := range tests {
+		t.Setenv("TENV_TEST_DEFAULT_CONSTRAINT", tt.env)
+		for _, workingDir := range []bool{false, true} {
+			value := tt.root
+			if workingDir {
+				value = tt.project
+			}
+
+			if value == "" {
+				err = manager.ResetConstraint(workingDir)
+			} else {
+				err = manager.SetConstraint(value, workingDir)
+			}
+			if err != nil {
+				t.Fatal("Unexpected error :", err)
+			}
+		}
+
+		if constraint, source := manager.ReadDefaultConstraintWithSource(); constraint != tt.want || source != tt.wantSource {
+			t.Error(tt.name, "unmatching results, get :", constraint, source)
+		}
+	}
+
+	// parent directory file is found like version files
+	if err = os.Rename(".opentofu-constraint", filepath.Join("..", ".opentofu-constraint")); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+	t.Setenv("TENV_TEST_DEFAULT_CONSTRAINT", "")
+
+	if constraint, source := manager.ReadDefaultConstraintWithSource(); constraint != "~> 1.7.0" || source != filepath.Join(basePath, "project", ".opentofu-constraint") {
+		t.Error("Unmatching results, get :", constraint, source)
+	}
+
+	if err = manager.SetConstraint("not a constraint", true); err == nil {
+		t.Error("Should fail on invalid constraint")
+	}
+}
+
+func TestResolveLocal(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.Config{Displayer: loghelper.InertDisplayer, RootPath: t.TempDir()}
+	manager := versionmanager.Make(conf, "", "tofu", "OpenTofu", nil, nil, fakeRetriever{}, "", "", nil)
+	if err := os.MkdirAll(filepath.Join(conf.RootPath, "OpenTofu", "1.6.2"), 0o755); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if err := os.WriteFile(manager.RootVersionFilePath(), []byte("~> 1.6.0"), 0o644); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	version, installed, err := manager.ResolveLocal()
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if version != "1.6.2" || !installed {
+		t.Error("Unmatching results, get :", version, installed)
+	}
+
+	if err = os.WriteFile(manager.RootVersionFilePath(), []byte("1.7.0"), 0o644); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if version, installed, err = manager.ResolveLocal(); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if version != "1.7.0" || installed {
+		t.Error("Unmatching results, get :", version, installed)
+	}
+}
+
+func TestUseInFile(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.Config{Displayer: loghelper.InertDisplayer, RootPath: t.TempDir()}
+	manager := versionmanager.Make(conf, "", "tofu", "OpenTofu", nil, nil, fakeRetriever{}, "", "", nil)
+	if err := os.MkdirAll(filepath.Join(conf.RootPath, "OpenTofu", "1.6.2"), 0o755); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	filePath := filepath.Join(t.TempDir(), ".tool-versions")
+	if err := os.WriteFile(filePath, []byte("terraform 1.7.0\nopentofu 1.6.0\n"), 0o644); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if err := manager.UseInFile("1.6.2", filePath); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if string(content) != "terraform 1.7.0\nopentofu 1.6.2\n" {
+		t.Error("Unmatching results, get :", string(content))
+	}
+}
+
+func TestSharedRootLastUse(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.Config{Displayer: loghelper.InertDisplayer, RootPath: t.TempDir(), SharedRoot: true}
+	manager := versionmanager.Make(conf, "", "tofu", "OpenTofu", nil, nil, fakeRetriever{}, "", "", nil)
+	versionPath := filepath.Join(conf.RootPath, "OpenTofu", "1.6.2")
+	if err := os.MkdirAll(versionPath, 0o755); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	lastuse.WriteNow(versionPath, conf)
+	if _, err := os.Stat(conf.UserStatePath("OpenTofu", "1.6.2", lastuse.FileName)); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(versionPath, lastuse.FileName)); err == nil {
+		t.Error("Use date should not be written in shared version directory")
+	}
+
+	datedVersions, err := manager.ListLocal(false)
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if len(datedVersions) != 1 || datedVersions[0].UseDate.IsZero() {
+		t.Error("Unmatching results, get :", datedVersions)
+	}
+}
+
+func TestEvaluateTerragruntCompatibility(t *testing.T) {
+	t.Setenv("TENV_RESOLVED_TERRAGRUNT", "0.46.0")
+
+	conf := &config.Config{Displayer: loghelper.InertDisplayer, NoInstall: true, RootPath: t.TempDir()}
+	manager := versionmanager.Make(conf, "", "terraform", "Terraform", nil, nil, fakeRetriever{}, "", "", nil)
+	for _, version := range []string{"1.4.6", "1.5.7"} {
+		if err := os.MkdirAll(filepath.Join(conf.RootPath, "Terraform", version), 0o755); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+	}
+
+	version, err := manager.Evaluate(">= 1.4", false)
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if version != "1.4.6" {
+		t.Error("Unmatching results, get :", version)
+	}
+
+	// no compatible version, fallback to unsupported pairing
+	if version, err = manager.Evaluate(">= 1.5", false); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if version != "1.5.7" {
+		t.Error("Unmatching results, get :", version)
+	}
+}
+
+func TestEvaluateSoftConstraint(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.Config{Displayer: loghelper.InertDisplayer, NoInstall: true, RootPath: t.TempDir()}
+	manager := versionmanager.Make(conf, "", "tofu", "OpenTofu", nil, nil, fakeRetriever{}, "", "", nil)
+	if err := os.MkdirAll(filepath.Join(conf.RootPath, "OpenTofu", "1.6.2"), 0o755); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if err := manager.SetConstraint("warn:>= 1.7", false); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if constraint := manager.ReadDefaultConstraint(); constraint != "" {
+		t.Error("Soft constraint should not be a default constraint, get :", constraint)
+	}
+
+	// violation is only a warning
+	version, err := manager.Evaluate(">= 1.5", false)
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if version != "1.6.2" {
+		t.Error("Unmatching results, get :", version)
+	}
+}
+
+func TestEvaluateSearchPreference(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		forceRemote   bool
+		preferLocal   bool
+		preferRemote  bool
+		wantVersion   string
+		wantInstalled bool
+	}{
+		{name: "Default", wantVersion: "1.6.0", wantInstalled: true},
+		{name: "ForceRemote", forceRemote: true, wantVersion: "1.6.2"},
+		{name: "PreferLocal", forceRemote: true, preferLocal: true, wantVersion: "1.6.0", wantInstalled: true},
+		{name: "PreferRemote", preferRemote: true, wantVersion: "1.6.2"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := &config.Config{Displayer: loghelper.InertDisplayer, ForceRemote: tt.forceRemote, NoInstall: true, RootPath: t.TempDir()}
+			conf.InitSearch(tt.preferLocal, tt.preferRemote)
+			manager := versionmanager.Make(conf, "", "tofu", "OpenTofu", nil, nil, fakeRetriever{"1.6.0", "1.6.2"}, "", "", nil)
+			if err := os.MkdirAll(filepath.Join(conf.RootPath, "OpenTofu", "1.6.0"), 0o755); err != nil {
+				t.Fatal("Unexpected error :", err)
+			}
+
+			evaluation, err := manager.EvaluateResult("~> 1.6.0", false)
+			if err != nil || evaluation.Version != tt.wantVersion || evaluation.Installed != tt.wantInstalled {
+				t.Error("Unmatching results, get :", evaluation, err)
+			}
+		})
+	}
+}
+
+type fakeWritingRetriever struct {
+	fakeRetriever
+}
+
+func (fakeWritingRetriever) InstallRelease(_ string, targetPath string) error {
+	if err := os.MkdirAll(targetPath, 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(targetPath, "tofu"), []byte("binary"), 0o755)
+}
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+
+	rootPath := t.TempDir()
+	conf := &config.Config{Displayer: loghelper.InertDisplayer, RegistryPath: filepath.Join(rootPath, "registry.json"), RootPath: rootPath}
+	manager := versionmanager.Make(conf, "", "tofu", "OpenTofu", nil, nil, fakeWritingRetriever{}, "", "", nil)
+	for _, version := range []string{"1.6.2", "1.7.0"} {
+		if err := manager.Install(version); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+	}
+
+	if err := manager.Uninstall("1.6.2"); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	registry, err := versionmanager.ReadRegistry(conf.RegistryPath)
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if len(registry.Entries) != 1 || registry.Entries[0].Version != "1.7.0" || len(registry.Entries[0].Files) != 1 {
+		t.Error("Unmatching results, get :", registry.Entries)
+	}
+}
+
+func TestReadWithoutInstallDir(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.Config{Displayer: loghelper.InertDisplayer, RootPath: t.TempDir()}
+	manager := versionmanager.Make(conf, "", "tofu", "OpenTofu", nil, nil, fakeRetriever{}, "", "", nil)
+	versions, err := manager.ListLocal(false)
+	if err != nil || len(versions) != 0 {
+		t.Error("Unmatching results, get :", versions, err)
+	}
+
+	if _, err = os.Stat(filepath.Join(conf.RootPath, "OpenTofu")); !os.IsNotExist(err) {
+		t.Error("Listing should not create installation directory, get :", err)
+	}
+}
+
+func TestUseUninstalled(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.Config{Displayer: loghelper.InertDisplayer, NoInstall: true, RootPath: t.TempDir()}
+	manager := versionmanager.Make(conf, "", "tofu", "OpenTofu", nil, nil, fakeRetriever{"1.6.0", "1.7.0"}, "", "", nil)
+
+	evaluation, err := manager.EvaluateResult("latest", false)
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if evaluation != (versionmanager.Evaluation{Source: versionmanager.SourceRemote, Version: "1.7.0"}) {
+		t.Error("Unmatching results, get :", evaluation)
+	}
+
+	// a resolved version is not written before its installation
+	if err = manager.Use("latest", false); !errors.Is(err, versionmanager.ErrNoCompatibleLocally) {
+		t.Error("Should fail with ErrNoCompatibleLocally, get :", err)
+	}
+
+	if _, err = os.Stat(manager.RootVersionFilePath()); !os.IsNotExist(err) {
+		t.Error("Version file should not be written, get :", err)
+	}
+
+	if err = manager.Use("1.6.0", false); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if content, err := os.ReadFile(manager.RootVersionFilePath()); err != nil || string(content) != "1.6.0" {
+		t.Error("Unmatching results, get :", string(content), err)
+	}
+}
+
+func TestPrecedence(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.Config{Displayer: loghelper.InertDisplayer, RootPath: t.TempDir(), SearchBoundary: "none"}
+	versionFiles := []types.VersionFile{{Name: ".opentofu-version"}}
+	manager := versionmanager.Make(conf, "TOFUENV_DEFAULT_CONSTRAINT", "tofu", "OpenTofu", nil, nil, fakeRetriever{}, "TOFUENV_TOFU_VERSION", "TOFUENV_DEFAULT_VERSION", versionFiles)
+
+	steps := manager.Precedence()
+	names := make([]string, 0, len(steps))
+	for _, step := range steps {
+		names = append(names, step.Name)
+	}
+
+	expected := []string{
+		"TOFUENV_TOFU_VERSION", ".opentofu-version", "TOFUENV_TOFU_VERSION_PLUGIN", "TOFUENV_DEFAULT_VERSION", manager.RootVersionFilePath(),
+		"latest-allowed", "TOFUENV_DEFAULT_CONSTRAINT", ".opentofu-constraint", manager.RootConstraintFilePath(),
+	}
+	if !slices.Equal(names, expected) {
+		t.Error("Unmatching results, get :", names)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	conf := &config.Config{Displayer: loghelper.InertDisplayer, NoInstall: true, RootPath: t.TempDir(), SearchBoundary: "none"}
+	manager := versionmanager.Make(conf, "TENV_TEST_EXPLAIN_CONSTRAINT", "tofu", "OpenTofu", nil, nil, fakeRetriever{}, "TENV_TEST_EXPLAIN_VERSION", "", nil)
+	for _, version := range []string{"1.6.2", "1.7.0"} {
+		if err := os.MkdirAll(filepath.Join(conf.RootPath, "OpenTofu", version), 0o755); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+	}
+
+	t.Setenv("TENV_TEST_EXPLAIN_VERSION", "latest-allowed")
+	t.Setenv("TENV_TEST_EXPLAIN_CONSTRAINT", "~> 1.6.0")
+
+	evaluation, steps, err := manager.Explain()
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if evaluation.Version != "1.6.2" || evaluation.Source != versionmanager.SourceLocal {
+		t.Error("Unmatching results, get :", evaluation)
+	}
+
+	expected := []versionmanager.PrecedenceStep{
+		{Kind: versionmanager.SourceKindEnv, Name: "TENV_TEST_EXPLAIN_VERSION", Detail: "requested latest-allowed"},
+		{Kind: versionmanager.SourceKindStrategy, Name: "latest", Detail: "no required_version found in project files"},
+		{Kind: versionmanager.SourceKindEnv, Name: "TENV_TEST_EXPLAIN_CONSTRAINT", Detail: "constraint ~> 1.6.0"},
+		{Kind: versionmanager.SourceKindEvaluation, Name: versionmanager.SourceLocal, Detail: "1.6.2"},
+	}
+	if !slices.Equal(steps, expected) {
+		t.Error("Unmatching results, get :", steps)
+	}
+}
+
+func TestNewReleases(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.Config{Displayer: loghelper.InertDisplayer, RootPath: t.TempDir()}
+	manager := versionmanager.Make(conf, "", "tofu", "OpenTofu", nil, nil, fakeRetriever{"1.6.0", "1.6.2"}, "", "", nil)
+
+	var news []string
+	record := func(notified []string) error {
+		news = notified
+
+		return nil
+	}
+	if err := manager.NewReleases(versionmanager.NotifyMinor, record); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if len(news) != 0 {
+		t.Error("Should only record seen version on first call, get :", news)
+	}
+
+	// failed notification is not recorded as seen
+	errNotify := errors.New("notify failure")
+	manager = versionmanager.Make(conf, "", "tofu", "OpenTofu", nil, nil, fakeRetriever{"1.6.0", "1.6.2", "1.6.3", "1.7.0-beta1", "1.7.0", "1.8.0"}, "", "", nil)
+	if err := manager.NewReleases(versionmanager.NotifyMinor, func([]string) error { return errNotify }); !errors.Is(err, errNotify) {
+		t.Error("Unmatching error, get :", err)
+	}
+
+	if err := manager.NewReleases(versionmanager.NotifyMinor, record); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if !slices.Equal(news, []string{"1.7.0", "1.8.0"}) {
+		t.Error("Unmatching results, get :", news)
+	}
+
+	news = nil
+	if err := manager.NewReleases(versionmanager.NotifyPatch, record); err != nil || len(news) != 0 {
+		t.Error("Should not report already seen versions, get :", news, err)
+	}
+
+	if err := manager.NewReleases("new-build", record); !errors.Is(err, versionmanager.ErrNotifyLevel) {
+		t.Error("Should fail on unknown level, get :", err)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.Config{Displayer: loghelper.InertDisplayer, RootPath: t.TempDir()}
+	manager := versionmanager.Make(conf, "", "tofu", "OpenTofu", nil, nil, fakeRetriever{}, "", "", nil)
+	oldDate := time.Now().AddDate(0, -6, 0)
+	for _, version := range []string{"1.6.0", "1.6.2", "1.7.0", "1.8.0"} {
+		if err := os.MkdirAll(filepath.Join(conf.RootPath, "OpenTofu", version), 0o755); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+	}
+
+	for _, version := range []string{"1.6.0", "1.6.2", "1.7.0"} {
+		if err := manager.Touch(version, oldDate); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+	}
+
+	if err := os.WriteFile(manager.RootVersionFilePath(), []byte("1.7.0"), 0o644); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	limit := time.Now().AddDate(0, 0, -90)
+	selected, err := manager.Prune(limit, true)
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if !slices.Equal(selected, []string{"1.6.0", "1.6.2"}) {
+		t.Error("Unmatching results, get :", selected)
+	}
+
+	if _, err = manager.Prune(limit, false); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if versions := manager.LocalSet(); len(versions) != 2 {
+		t.Error("Unmatching results, get :", versions)
+	}
+}
+
+func TestKeepVersions(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.Config{Displayer: loghelper.InertDisplayer, KeepVersions: 1, RootPath: t.TempDir()}
+	manager := versionmanager.Make(conf, "", "tofu", "OpenTofu", nil, nil, fakeRetriever{"1.8.0"}, "", "", nil)
+	for index, version := range []string{"1.5.0", "1.6.0", "1.7.0"} {
+		if err := os.MkdirAll(filepath.Join(conf.RootPath, "OpenTofu", version), 0o755); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+
+		if err := manager.Touch(version, time.Now().AddDate(0, 0, index-10)); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+	}
+
+	if err := os.WriteFile(manager.RootVersionFilePath(), []byte("1.5.0"), 0o644); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if err := manager.Install("1.8.0"); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	versions := manager.LocalSet()
+	if _, ok := versions["1.6.0"]; ok || len(versions) != 2 {
+		t.Error("Unmatching results, get :", versions)
+	}
+}
+
+func TestUninstallAutoApprove(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.Config{AutoApprove: true, Displayer: loghelper.InertDisplayer, RootPath: t.TempDir()}
+	manager := versionmanager.Make(conf, "", "tofu", "OpenTofu", nil, nil, fakeRetriever{}, "", "", nil)
+	for _, version := range []string{"1.6.0", "1.6.2", "1.7.0"} {
+		if err := os.MkdirAll(filepath.Join(conf.RootPath, "OpenTofu", version), 0o755); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+	}
+
+	if err := manager.Uninstall("~> 1.6.0"); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	versions := manager.LocalSet()
+	if _, ok := versions["1.7.0"]; !ok || len(versions) != 1 {
+		t.Error("Unmatching results, get :", versions)
+	}
+}
+
+func TestEvaluatePinnedLocal(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.Config{Displayer: loghelper.InertDisplayer, NoInstall: true, RootPath: t.TempDir()}
+	manager := versionmanager.Make(conf, "", "tofu", "OpenTofu", nil, nil, fakeRetriever{"1.6.2"}, "", "", nil)
+	for _, version := range []string{"1.6.0", "1.6.2", "1.7.0"} {
+		if err := os.MkdirAll(filepath.Join(conf.RootPath, "OpenTofu", version), 0o755); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+	}
+
+	evaluation, err := manager.EvaluateResult("= 1.6.2", false)
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if evaluation.Version != "1.6.2" || !evaluation.Installed || evaluation.Source != versionmanager.SourceLocal {
+		t.Error("Unmatching results, get :", evaluation)
+	}
+}
+
+func TestConstraintSet(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.Config{Displayer: loghelper.InertDisplayer, RootPath: t.TempDir()}
+	manager := versionmanager.Make(conf, "", "tofu", "OpenTofu", nil, nil, fakeRetriever{}, "", "", nil)
+	setPath := filepath.Join(conf.RootPath, "constraint-sets", "org-defaults")
+	for _, dirPath := range []string{filepath.Dir(setPath), filepath.Join(conf.RootPath, "OpenTofu")} {
+		if err := os.MkdirAll(dirPath, 0o755); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+	}
+
+	if err := os.WriteFile(setPath, []byte("opentofu: \">= 1.6, < 1.9\"\nterraform: \">= 1.5\"\n"), 0o644); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if err := os.WriteFile(manager.RootConstraintFilePath(), []byte("@org-defaults, != 1.7.1"), 0o644); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if constraint := manager.ReadDefaultConstraint(); constraint != ">= 1.6, < 1.9, != 1.7.1" {
+		t.Error("Unmatching result, get :", constraint)
+	}
+}
+
+func TestRemoteConstraintSet(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path != "/sets/org-defaults" {
+			writer.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+		writer.Write([]byte("~> 1.6.0\n"))
+	}))
+
+	conf := &config.Config{ConstraintSets: server.URL + "/sets", Displayer: loghelper.InertDisplayer, RootPath: t.TempDir()}
+	manager := versionmanager.Make(conf, "", "tofu", "OpenTofu", nil, nil, fakeRetriever{}, "", "", nil)
+	if err := os.MkdirAll(filepath.Join(conf.RootPath, "OpenTofu"), 0o755); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if err := os.WriteFile(manager.RootConstraintFilePath(), []byte("warn:@org-defaults"), 0o644); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if constraint, _ := manager.ReadDefaultConstraintWithSource(); constraint != "warn:~> 1.6.0" {
+		t.Error("Unmatching result, get :", constraint)
+	}
+
+	server.Close() // expired cache is used when the remote is unreachable
+	if constraint, _ := manager.ReadDefaultConstraintWithSource(); constraint != "warn:~> 1.6.0" {
+		t.Error("Unmatching result, get :", constraint)
+	}
+}
+
+func TestRetracted(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.Config{Displayer: loghelper.InertDisplayer, NoInstall: true, RetractedPolicy: versionmanager.RetractedPolicyBlock, RootPath: t.TempDir()}
+	manager := versionmanager.Make(conf, "", "tofu", "OpenTofu", nil, nil, fakeRetriever{}, "", "", nil)
+	for _, version := range []string{"1.6.1", "1.6.2"} {
+		if err := os.MkdirAll(filepath.Join(conf.RootPath, "OpenTofu", version), 0o755); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+	}
+
+	retractedData := []byte("opentofu:\n  1.6.2: state corruption\nterraform:\n  1.6.1: unrelated\n")
+	if err := os.WriteFile(filepath.Join(conf.RootPath, versionmanager.RetractedFileName), retractedData, 0o644); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	evaluation, err := manager.EvaluateResult("~> 1.6.0", false)
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if evaluation.Version != "1.6.1" {
+		t.Error("Unmatching results, get :", evaluation)
+	}
+
+	if _, err = manager.EvaluateResult("1.6.2", false); !errors.Is(err, versionmanager.ErrRetracted) {
+		t.Error("Should fail on pinned retracted version, get :", err)
+	}
+
+	conf.RetractedPolicy = versionmanager.RetractedPolicyWarn
+	if evaluation, err = manager.EvaluateResult("= 1.6.2", false); err != nil || evaluation.Version != "1.6.2" {
+		t.Error("Unmatching results, get :", evaluation, err)
+	}
+}
+
+func TestWithAdvisories(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.Config{Displayer: loghelper.InertDisplayer, RootPath: t.TempDir()}
+	conf.AdvisoryURL = filepath.Join(conf.RootPath, "advisories.json")
+	manager := versionmanager.Make(conf, "", "tofu", "OpenTofu", nil, nil, fakeRetriever{}, "", "", nil)
+
+	advisoryData := []byte(`[
+  {"ghsa_id": "GHSA-aaaa", "cve_id": "CVE-2024-0001", "severity": "critical", "withdrawn_at": null,
+   "vulnerabilities": [{"package": {"ecosystem": "go", "name": "github.com/opentofu/opentofu"}, "vulnerable_version_range": ">= 1.6.0, < 1.6.2", "first_patched_version": "1.6.2"}]},
+  {"ghsa_id": "GHSA-bbbb", "severity": "high", "withdrawn_at": "2024-05-01T00:00:00Z",
+   "vulnerabilities": [{"package": {"ecosystem": "go", "name": "github.com/opentofu/opentofu"}, "vulnerable_version_range": "< 2.0.0"}]},
+  {"ghsa_id": "GHSA-cccc", "severity": "low",
+   "vulnerabilities": [{"package": {"ecosystem": "go", "name": "github.com/hashicorp/terraform"}, "vulnerable_version_range": "< 2.0.0"}]}
+]`)
+	if err := os.WriteFile(conf.AdvisoryURL, advisoryData, 0o644); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(manager.RootVersionFilePath()), 0o755); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if err := os.WriteFile(manager.RootVersionFilePath(), []byte("1.6.1"), 0o644); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	entries, err := manager.WithAdvisories([]audit.Entry{{Tool: "OpenTofu", Version: "1.6.1"}, {Tool: "OpenTofu", Version: "1.6.2"}})
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if len(entries[0].Advisories) != 1 || entries[0].Advisories[0].ID != "GHSA-aaaa" || !entries[0].Pinned || len(entries[1].Advisories) != 0 || entries[1].Pinned {
+		t.Error("Unmatching results, get :", entries)
+	}
+}
+
+func TestNoCompatibleExitCode(t *testing.T) {
+	t.Parallel()
+
+	for _, err := range []error{versionmanager.ErrNoCompatible, fmt.Errorf("wrapped : %w", versionmanager.ErrNoCompatibleLocally)} {
+		if code := exitcode.FromError(err); code != exitcode.NoCompatible {
+			t.Error("Unexpected code for", err, ": get", code)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package manifest
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+
+	"github.com/tofuutils/tenv/v2/pkg/fileperm"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+)
+
+const (
+	SignatureCosign      = "cosign"
+	SignaturePGP         = "pgp"
+	SignatureSkipped     = "skipped"
+	SignatureUnavailable = "unavailable" // no signature published upstream
+
+	ProvenanceSLSA        = "slsa"
+	ProvenanceSkipped     = "skipped"     // slsa-verifier executable not found
+	ProvenanceUnavailable = "unavailable" // no provenance attestation published upstream
+
+	fileName     = "manifest.json"
+	warnFileName = "unverified-warning.txt"
+)
+
+// Manifest describe how an installed version has been verified.
+type Manifest struct {
+	Checksum     bool     `json:"checksum"`
+	PostInstall  []string `json:"post_install,omitempty"` // applied tool specific steps
+	Provenance   string   `json:"provenance,omitempty"`   // only set when TENV_VERIFY_PROVENANCE is enabled
+	Signature    string   `json:"signature,omitempty"`
+	Source       string   `json:"source,omitempty"`       // downloaded archive URL
+	Unverifiable bool     `json:"unverifiable,omitempty"` // no checksum published upstream (old releases)
+}
+
+// IsMetadata returns true for files written by tenv in a version directory.
+func IsMetadata(name string) bool {
+	return name == fileName || name == warnFileName
+}
+
+// Verified returns true when both checksum and signature have been verified.
+func (m Manifest) Verified() bool {
+	return m.Checksum && (m.Signature == SignatureCosign || m.Signature == SignaturePGP)
+}
+
+// ChecksumOnly returns true when checksum has been verified for a release published without signature.
+func (m Manifest) ChecksumOnly() bool {
+	return m.Checksum && m.Signature == SignatureUnavailable
+}
+
+// Read returns false when there is no readable manifest (version installed by an older tenv).
+func Read(dirPath string, displayer loghelper.Displayer) (Manifest, bool) {
+	var manifest Manifest
+	data, err := os.ReadFile(filepath.Join(dirPath, fileName))
+	if err != nil {
+		displayer.Log(loghelper.LevelWarnOrDebug(errors.Is(err, fs.ErrNotExist)), "Unable to read manifest file", loghelper.Error, err)
+
+		return manifest, false
+	}
+
+	if err = json.Unmarshal(data, &manifest); err != nil {
+		displayer.Log(hclog.Warn, "Unable to parse manifest file", loghelper.Error, err)
+
+		return manifest, false
+	}
+
+	return manifest, true
+}
+
+func Write(dirPath string, manifest Manifest, displayer loghelper.Displayer) {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		displayer.Log(hclog.Warn, "Unable to serialize manifest", loghelper.Error, err)
+
+		return
+	}
+
+	if err = os.WriteFile(filepath.Join(dirPath, fileName), data, fileperm.FileMode()); err != nil {
+		displayer.Log(hclog.Warn, "Unable to write manifest file", loghelper.Error, err)
+	}
+}
+
+// NeedUnverifiedWarning returns true at most once a day for a version directory (and record the current day).
+func NeedUnverifiedWarning(dirPath string, displayer loghelper.Displayer) bool {
+	warnPath := filepath.Join(dirPath, warnFileName)
+	nowData := time.Now().AppendFormat(nil, time.DateOnly) //nolint
+
+	if data, err := os.ReadFile(warnPath); err == nil && string(data) == string(nowData) {
+		return false
+	}
+
+	if err := os.WriteFile(warnPath, nowData, fileperm.FileMode()); err != nil {
+		displayer.Log(hclog.Warn, "Unable to write date in file", loghelper.Error, err)
+	}
+
+	return true
+}
@@ -0,0 +1,145 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package manifest_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager/manifest"
+)
+
+func TestReadWrite(t *testing.T) {
+	t.Parallel()
+
+	dirPath := t.TempDir()
+	written := manifest.Manifest{Checksum: true, PostInstall: []string{"chmod 0755 tofu"}, Provenance: manifest.ProvenanceSLSA, Signature: manifest.SignatureCosign, Source: "https://example.com/tofu.zip"}
+	manifest.Write(dirPath, written, loghelper.InertDisplayer)
+
+	read, found := manifest.Read(dirPath, loghelper.InertDisplayer)
+	if !found || !reflect.DeepEqual(read, written) {
+		t.Error("Unmatching results, get :", read, found)
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data string // no manifest file when empty
+	}{
+		{name: "Missing"},
+		{name: "Corrupted", data: `{"checksum": tr`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dirPath := t.TempDir()
+			if tt.data != "" {
+				if err := os.WriteFile(filepath.Join(dirPath, "manifest.json"), []byte(tt.data), 0o600); err != nil {
+					t.Fatal("Unexpected error :", err)
+				}
+			}
+
+			if read, found := manifest.Read(dirPath, loghelper.InertDisplayer); found || read.Checksum {
+				t.Error("Should not be found, get :", read)
+			}
+		})
+	}
+}
+
+func TestVerified(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		manifest         manifest.Manifest
+		wantVerified     bool
+		wantChecksumOnly bool
+	}{
+		{name: "Cosign", manifest: manifest.Manifest{Checksum: true, Signature: manifest.SignatureCosign}, wantVerified: true},
+		{name: "PGP", manifest: manifest.Manifest{Checksum: true, Signature: manifest.SignaturePGP}, wantVerified: true},
+		{name: "SignatureWithoutChecksum", manifest: manifest.Manifest{Signature: manifest.SignatureCosign}},
+		{name: "Skipped", manifest: manifest.Manifest{Checksum: true, Signature: manifest.SignatureSkipped}},
+		{name: "Unavailable", manifest: manifest.Manifest{Checksum: true, Signature: manifest.SignatureUnavailable}, wantChecksumOnly: true},
+		{name: "Unverifiable", manifest: manifest.Manifest{Signature: manifest.SignatureUnavailable, Unverifiable: true}},
+		{name: "Empty"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if verified, checksumOnly := tt.manifest.Verified(), tt.manifest.ChecksumOnly(); verified != tt.wantVerified || checksumOnly != tt.wantChecksumOnly {
+				t.Error("Unmatching results, get :", verified, checksumOnly)
+			}
+		})
+	}
+}
+
+func TestNeedUnverifiedWarning(t *testing.T) {
+	t.Parallel()
+
+	today := time.Now().Format(time.DateOnly)
+	yesterday := time.Now().AddDate(0, 0, -1).Format(time.DateOnly)
+
+	tests := []struct {
+		name      string
+		lastWarn  string // no warning file when empty
+		wantFirst bool
+	}{
+		{name: "NeverWarned", wantFirst: true},
+		{name: "WarnedYesterday", lastWarn: yesterday, wantFirst: true},
+		{name: "WarnedToday", lastWarn: today},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dirPath := t.TempDir()
+			if tt.lastWarn != "" {
+				if err := os.WriteFile(filepath.Join(dirPath, "unverified-warning.txt"), []byte(tt.lastWarn), 0o600); err != nil {
+					t.Fatal("Unexpected error :", err)
+				}
+			}
+
+			// once a day : a second call is always silent
+			first := manifest.NeedUnverifiedWarning(dirPath, loghelper.InertDisplayer)
+			second := manifest.NeedUnverifiedWarning(dirPath, loghelper.InertDisplayer)
+			if first != tt.wantFirst || second {
+				t.Error("Unmatching results, get :", first, second)
+			}
+
+			if data, err := os.ReadFile(filepath.Join(dirPath, "unverified-warning.txt")); err != nil || string(data) != today {
+				t.Error("Unmatching recorded date, get :", string(data), err)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/hashicorp/go-version"
+
+	"github.com/tofuutils/tenv/v2/pkg/fileperm"
+	"github.com/tofuutils/tenv/v2/versionmanager/semantic"
+)
+
+// Levels of new releases triggering a notification.
+const (
+	NotifyMajor = "new-major" // stable version with a higher major
+	NotifyMinor = "new-minor" // stable version with a higher major or minor
+	NotifyPatch = "new-patch" // any newer stable version
+	NotifyPre   = "new-pre"   // any newer version, including pre-releases
+
+	notifySeenFileName = "notify-seen"
+)
+
+var ErrNotifyLevel = errors.New("unknown notification level, expected new-major, new-minor, new-patch or new-pre")
+
+// NewReleases calls notify with remote versions newer than the last seen one which match the notification level,
+// then record the newest remote version as seen, only when notify succeeds (a failed notification is retried by next call).
+// The first call (nothing seen yet) only records it.
+func (m VersionManager) NewReleases(level string, notify func([]string) error) error {
+	var newer func(seen *version.Version, candidate *version.Version) bool
+	switch level {
+	case NotifyMajor:
+		newer = func(seen *version.Version, candidate *version.Version) bool {
+			return candidate.Segments()[0] > seen.Segments()[0]
+		}
+	case NotifyMinor:
+		newer = func(seen *version.Version, candidate *version.Version) bool {
+			seenSegments, candidateSegments := seen.Segments(), candidate.Segments()
+
+			return candidateSegments[0] > seenSegments[0] || (candidateSegments[0] == seenSegments[0] && candidateSegments[1] > seenSegments[1])
+		}
+	case NotifyPatch, NotifyPre:
+		newer = func(seen *version.Version, candidate *version.Version) bool {
+			return candidate.GreaterThan(seen)
+		}
+	default:
+		return ErrNotifyLevel
+	}
+
+	versions, err := m.ListRemote(false)
+	if err != nil {
+		return err
+	}
+
+	if level != NotifyPre {
+		versions = slices.DeleteFunc(versions, func(versionStr string) bool {
+			return !semantic.StableVersion(versionStr)
+		})
+	}
+	if len(versions) == 0 {
+		return nil
+	}
+
+	filePath := m.conf.UserStatePath(m.FolderName, notifySeenFileName)
+	data, err := os.ReadFile(filePath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	var news []string
+	if seen, err := version.NewVersion(string(bytes.TrimSpace(data))); err == nil {
+		for _, versionStr := range versions {
+			if candidate, err := version.NewVersion(versionStr); err == nil && newer(seen, candidate) {
+				news = append(news, versionStr)
+			}
+		}
+	}
+
+	if len(news) != 0 {
+		if err = notify(news); err != nil {
+			return err
+		}
+	}
+
+	if err = os.MkdirAll(filepath.Dir(filePath), fileperm.DirMode()); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filePath, []byte(versions[len(versions)-1]), fileperm.FileMode())
+}
@@ -0,0 +1,111 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/versionmanager/semantic/types"
+)
+
+const (
+	pluginSuffix      = "_PLUGIN"
+	pluginToolEnvName = "TENV_PLUGIN_TOOL"
+)
+
+var (
+	errPluginOutput  = errors.New("version plugin output should be a JSON object with a version or constraint field")
+	errPluginTimeout = errors.New("version plugin did not complete before timeout")
+)
+
+type pluginOutput struct {
+	Constraint string `json:"constraint"`
+	Version    string `json:"version"`
+}
+
+// pluginEnvName return the name of the env var configuring the version resolution plugin.
+func (m VersionManager) pluginEnvName() string {
+	return m.VersionEnvName + pluginSuffix
+}
+
+// resolveWithPlugin execute the configured command (split on spaces, without shell) in working directory,
+// an empty output let the resolution continue. The command is killed after TENV_PLUGIN_TIMEOUT
+// (a hung plugin must not hang every proxied call).
+func (m VersionManager) resolveWithPlugin() (string, error) {
+	pluginEnvName := m.pluginEnvName()
+	commandParts := strings.Fields(os.Getenv(pluginEnvName))
+	if len(commandParts) == 0 {
+		return "", nil
+	}
+
+	timeout := m.conf.PluginTimeout
+	if timeout <= 0 {
+		timeout = config.DefaultPluginTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var errBuffer strings.Builder
+	cmd := exec.CommandContext(ctx, commandParts[0], commandParts[1:]...)
+	cmd.Env = append(os.Environ(), pluginToolEnvName+"="+m.execName)
+	cmd.Stderr = &errBuffer
+	cmd.WaitDelay = time.Second // do not wait output of plugin subprocesses after kill
+
+	output, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("%w : %s (%s)", errPluginTimeout, pluginEnvName, timeout)
+		}
+
+		if errMsg := strings.TrimSpace(errBuffer.String()); errMsg != "" {
+			return "", errors.New(errMsg)
+		}
+
+		return "", err
+	}
+
+	if output = bytes.TrimSpace(output); len(output) == 0 {
+		return "", nil
+	}
+
+	var parsed pluginOutput
+	if err = json.Unmarshal(output, &parsed); err != nil {
+		return "", errPluginOutput
+	}
+
+	requested := parsed.Version
+	if requested == "" {
+		requested = parsed.Constraint
+	}
+
+	if requested == "" {
+		return "", nil
+	}
+
+	return types.DisplayDetectionInfo(m.conf.Displayer, strings.TrimSpace(requested), pluginEnvName), nil
+}
@@ -0,0 +1,76 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager"
+)
+
+func TestResolveWithPlugin(t *testing.T) { //nolint
+	scriptDir := t.TempDir()
+	tests := []struct {
+		name    string
+		script  string
+		want    string
+		wantErr string
+	}{
+		{name: "Version", script: `echo '{"version": "1.6.2"}'`, want: "1.6.2"},
+		{name: "Constraint", script: `echo '{"constraint": "~> 1.6.0"}'`, want: "~> 1.6.0"},
+		{name: "Tool", script: `echo "{\"version\": \"$TENV_PLUGIN_TOOL\"}"`, want: "tofu"},
+		{name: "Empty", script: "true", want: "latest-allowed"},
+		{name: "Invalid", script: "echo 1.6.2", wantErr: "JSON object"},
+		{name: "Failure", script: "echo registry unreachable >&2; exit 1", wantErr: "registry unreachable"},
+		{name: "Timeout", script: "sleep 30", wantErr: "timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scriptPath := filepath.Join(scriptDir, tt.name+".sh")
+			if err := os.WriteFile(scriptPath, []byte("#!/bin/sh\n"+tt.script+"\n"), 0o755); err != nil {
+				t.Fatal("Unexpected error :", err)
+			}
+			t.Setenv("TENV_TEST_PLUGIN_VERSION_PLUGIN", scriptPath)
+
+			conf := &config.Config{Displayer: loghelper.InertDisplayer, PluginTimeout: 200 * time.Millisecond, RootPath: t.TempDir(), SearchBoundary: "none"}
+			manager := versionmanager.Make(conf, "", "tofu", "OpenTofu", nil, nil, fakeRetriever{}, "TENV_TEST_PLUGIN_VERSION", "", nil)
+
+			start := time.Now()
+			resolved, err := manager.Resolve("latest-allowed")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Error("Unmatching error, get :", err)
+				}
+			} else if err != nil || resolved != tt.want {
+				t.Error("Unmatching results, get :", resolved, err)
+			}
+
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Error("Plugin should be stopped after timeout, get :", elapsed)
+			}
+		})
+	}
+}
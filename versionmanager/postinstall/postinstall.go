@@ -0,0 +1,54 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package postinstall
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/tofuutils/tenv/v2/pkg/fileperm"
+	"github.com/tofuutils/tenv/v2/pkg/winbin"
+)
+
+// Step is a tool specific action applied in version directory after installation,
+// its description (evaluated when applied, to reflect current configuration) is recorded in manifest.
+type Step struct {
+	Describe func() string
+	Apply    func(versionPath string) error
+}
+
+// EnsureExecutable set executable permission on binary (archives from some mirrors lose it).
+func EnsureExecutable(execName string) Step {
+	binaryName := winbin.GetBinaryName(execName)
+
+	return Step{
+		Describe: func() string {
+			return fmt.Sprintf("chmod %04o %s", fileperm.ExecMode(), binaryName)
+		},
+		Apply: func(versionPath string) error {
+			if runtime.GOOS == winbin.OsName {
+				return nil
+			}
+
+			return os.Chmod(filepath.Join(versionPath, binaryName), fileperm.ExecMode())
+		},
+	}
+}
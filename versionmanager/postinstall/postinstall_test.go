@@ -0,0 +1,72 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package postinstall_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/pkg/fileperm"
+	"github.com/tofuutils/tenv/v2/versionmanager/postinstall"
+)
+
+// change process umask, so not parallel.
+func TestEnsureExecutable(t *testing.T) { //nolint
+	if runtime.GOOS == "windows" {
+		t.Skip("no executable permission on Windows")
+	}
+	defer fileperm.SetUmask(0o022)
+
+	tests := []struct {
+		umask           os.FileMode
+		wantDescription string
+		wantMode        os.FileMode
+	}{
+		{umask: 0o022, wantDescription: "chmod 0755 tofu", wantMode: 0o755},
+		{umask: 0o027, wantDescription: "chmod 0750 tofu", wantMode: 0o750},
+		{umask: 0o077, wantDescription: "chmod 0700 tofu", wantMode: 0o700},
+	}
+
+	// sequential, umask is process wide
+	for _, tt := range tests {
+		fileperm.SetUmask(tt.umask)
+
+		versionPath := t.TempDir()
+		binaryPath := filepath.Join(versionPath, "tofu")
+		if err := os.WriteFile(binaryPath, []byte("binary"), 0o600); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+
+		step := postinstall.EnsureExecutable("tofu")
+		if err := step.Apply(versionPath); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+
+		info, err := os.Stat(binaryPath)
+		if err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+
+		if description := step.Describe(); description != tt.wantDescription || info.Mode().Perm() != tt.wantMode {
+			t.Error(tt.umask, "unmatching results, get :", description, info.Mode().Perm())
+		}
+	}
+}
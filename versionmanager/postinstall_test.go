@@ -0,0 +1,106 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager/manifest"
+	"github.com/tofuutils/tenv/v2/versionmanager/postinstall"
+)
+
+var errStep = errors.New("step failure")
+
+func TestRunPostInstall(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		failing     string // name of failing step
+		stepNames   []string
+		wantApplied []string
+		wantRemoved bool
+	}{
+		{name: "NoStep"},
+		{name: "Ordered", stepNames: []string{"first", "second", "third"}, wantApplied: []string{"first", "second", "third"}},
+		{name: "Failure", failing: "second", stepNames: []string{"first", "second", "third"}, wantApplied: []string{"first", "second"}, wantRemoved: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			versionPath := filepath.Join(t.TempDir(), "1.6.2")
+			if err := os.MkdirAll(versionPath, 0o755); err != nil {
+				t.Fatal("Unexpected error :", err)
+			}
+			written := manifest.Manifest{Checksum: true, Signature: manifest.SignatureCosign, Source: "https://example.com/tofu.zip"}
+			manifest.Write(versionPath, written, loghelper.InertDisplayer)
+
+			var applied []string
+			steps := make([]postinstall.Step, 0, len(tt.stepNames))
+			for _, stepName := range tt.stepNames {
+				stepName := stepName
+				steps = append(steps, postinstall.Step{
+					Describe: func() string { return stepName },
+					Apply: func(string) error {
+						applied = append(applied, stepName)
+						if stepName == tt.failing {
+							return errStep
+						}
+
+						return nil
+					},
+				})
+			}
+
+			manager := VersionManager{conf: &config.Config{Displayer: loghelper.InertDisplayer}, postInstallSteps: steps}
+			err := manager.runPostInstall(versionPath)
+			if tt.failing == "" && err != nil || tt.failing != "" && err != errStep {
+				t.Fatal("Unmatching error, get :", err)
+			}
+
+			if !slices.Equal(applied, tt.wantApplied) {
+				t.Error("Unmatching applied steps, get :", applied)
+			}
+
+			if _, err = os.Stat(versionPath); os.IsNotExist(err) != tt.wantRemoved {
+				t.Error("Unmatching version directory state, get :", err)
+			}
+
+			if tt.wantRemoved {
+				return
+			}
+
+			// existing manifest is completed with descriptions in application order
+			written.PostInstall = tt.wantApplied
+			read, found := manifest.Read(versionPath, loghelper.InertDisplayer)
+			if !found || read.Checksum != written.Checksum || read.Signature != written.Signature || read.Source != written.Source || !slices.Equal(read.PostInstall, written.PostInstall) {
+				t.Error("Unmatching manifest, get :", read, found)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager
+
+import (
+	"strings"
+
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager/semantic"
+)
+
+const (
+	SourceKindEnv      = "env"
+	SourceKindFile     = "file"
+	SourceKindIaC      = "iac"
+	SourceKindPlugin   = "plugin"
+	SourceKindStrategy = "strategy"
+)
+
+// PrecedenceStep is a source consulted by version resolution.
+type PrecedenceStep struct {
+	Kind   string
+	Name   string // env var name, file name or path, file extension or strategy
+	Detail string
+}
+
+// Precedence returns, in consultation order, the sources used by Detect with current configuration :
+// first non empty one gives the version, then the default strategy is evaluated with constraint sources.
+func (m VersionManager) Precedence() []PrecedenceStep {
+	searchDetail := m.searchDescription()
+	steps := []PrecedenceStep{{Kind: SourceKindEnv, Name: m.VersionEnvName, Detail: "requested version"}}
+	for _, versionFile := range m.VersionFiles {
+		if versionFile.ConflictOnly {
+			continue
+		}
+		steps = append(steps, PrecedenceStep{Kind: SourceKindFile, Name: versionFile.Name, Detail: searchDetail})
+	}
+
+	steps = append(steps,
+		PrecedenceStep{Kind: SourceKindPlugin, Name: m.pluginEnvName(), Detail: "command printing a version"},
+		PrecedenceStep{Kind: SourceKindEnv, Name: m.defaultVersionEnvName, Detail: "default version"},
+		PrecedenceStep{Kind: SourceKindFile, Name: m.RootVersionFilePath(), Detail: "default version (written by use command)"},
+		PrecedenceStep{Kind: SourceKindStrategy, Name: semantic.LatestAllowedKey, Detail: "fallback without version, combine following constraints (latest stable version without any)"},
+	)
+
+	for _, iacExt := range m.iacExts {
+		steps = append(steps, PrecedenceStep{Kind: SourceKindIaC, Name: "*" + iacExt.Value, Detail: "required_version in working directory files"})
+	}
+
+	return append(steps,
+		PrecedenceStep{Kind: SourceKindEnv, Name: m.constraintEnvName, Detail: "default constraint"},
+		PrecedenceStep{Kind: SourceKindFile, Name: m.ProjectConstraintFileName(), Detail: "default constraint, " + searchDetail},
+		PrecedenceStep{Kind: SourceKindFile, Name: m.RootConstraintFilePath(), Detail: "default constraint (written by constraint command)"},
+	)
+}
+
+func (m VersionManager) searchDescription() string {
+	var descBuilder strings.Builder
+	descBuilder.WriteString("searched in working directory")
+	if markers := m.conf.BoundaryMarkers(); len(markers) == 0 {
+		descBuilder.WriteString(", then parent directories")
+	} else {
+		descBuilder.WriteString(loghelper.Concat(" (stop when it contains ", strings.Join(markers, " or "), "), then parent directories until one contains it"))
+	}
+	descBuilder.WriteString(loghelper.Concat(", then ", m.conf.UserPath))
+
+	return descBuilder.String()
+}
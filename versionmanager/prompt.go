@@ -0,0 +1,65 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/hashicorp/go-version"
+
+	"github.com/tofuutils/tenv/v2/versionmanager/semantic"
+)
+
+// ResolveLocal search the requested version without remote call nor installation (meant for shell prompt),
+// return the matching installed version (or the requested one when none match) and its installation status,
+// an empty version means there is no version file or env var for this tool.
+func (m VersionManager) ResolveLocal() (string, bool, error) {
+	requestedVersion, err := m.Resolve("")
+	if err != nil || requestedVersion == "" {
+		return "", false, err
+	}
+
+	installPath := filepath.Join(m.conf.RootPath, m.FolderName)
+	if parsedVersion, err := version.NewVersion(requestedVersion); err == nil {
+		cleanedVersion := parsedVersion.String()
+		_, installed, err := m.checkVersionInstallation(installPath, cleanedVersion)
+
+		return cleanedVersion, installed, err
+	}
+
+	predicateInfo, err := semantic.ParsePredicate(requestedVersion, m.FolderName, m, m.iacExts, m.conf)
+	if err != nil {
+		return "", false, err
+	}
+
+	versions, err := m.innerListLocal(installPath, predicateInfo.ReverseOrder)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", false, err
+	}
+
+	for _, version := range versions {
+		if predicateInfo.Predicate(version) {
+			return version, true, nil
+		}
+	}
+
+	return requestedVersion, false, nil
+}
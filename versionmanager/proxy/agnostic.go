@@ -19,52 +19,172 @@
 package proxy
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 
+	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/hcl/v2/hclparse"
 
 	"github.com/tofuutils/tenv/v2/config"
 	"github.com/tofuutils/tenv/v2/config/cmdconst"
+	"github.com/tofuutils/tenv/v2/pkg/exitcode"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager"
 	"github.com/tofuutils/tenv/v2/versionmanager/builder"
+	iacparser "github.com/tofuutils/tenv/v2/versionmanager/semantic/parser/iac"
+)
+
+// policies choosing between OpenTofu and Terraform when version files of both are found (TENV_AGNOSTIC_POLICY).
+const (
+	AgnosticError           = "error"
+	AgnosticFiles           = "files"
+	AgnosticPreferTerraform = "prefer-terraform"
+	AgnosticPreferTofu      = "prefer-tofu"
+)
+
+const lockFileName = ".terraform.lock.hcl"
+
+var (
+	ErrAgnosticAmbiguous = errors.New("version files found for both OpenTofu and Terraform")
+	ErrAgnosticPolicy    = errors.New("unknown agnostic policy, expected prefer-tofu, prefer-terraform, error or files")
 )
 
 func ExecAgnostic(conf *config.Config, builders map[string]builder.BuilderFunc, hclParser *hclparse.Parser, cmdArgs []string) {
 	conf.InitDisplayer(true)
-	manager := builders[cmdconst.TofuName](conf, hclParser)
-	detectedVersion, err := manager.ResolveWithVersionFiles()
+
+	manager, execName, detectedVersion, err := selectAgnostic(conf, builders, hclParser)
 	if err != nil {
-		fmt.Println("Failed to resolve a version allowing to call tofu :", err) //nolint
-		os.Exit(1)
+		fmt.Println("Failed to choose between tofu and terraform :", err) //nolint
+		os.Exit(exitcode.FromError(err))
 	}
 
-	execName := cmdconst.TofuName
 	if detectedVersion == "" {
-		execName = cmdconst.TerraformName
-		manager = builders[cmdconst.TerraformName](conf, hclParser)
-		detectedVersion, err = manager.ResolveWithVersionFiles()
-		if err != nil {
-			fmt.Println("Failed to resolve a version allowing to call terraform :", err) //nolint
-			os.Exit(1)
-		}
-
-		if detectedVersion == "" {
-			fmt.Println("No version files found corresponding to opentofu or terraform") //nolint
-			os.Exit(1)
-		}
+		fmt.Println("No version files found corresponding to opentofu or terraform") //nolint
+		os.Exit(exitcode.NoCompatible)
 	}
 
 	installPath, err := manager.InstallPath()
 	if err != nil {
 		fmt.Println("Failed to create installation directory for", execName, ":", err) //nolint
-		os.Exit(1)
+		os.Exit(exitcode.FromError(err))
 	}
 
 	detectedVersion, err = manager.Evaluate(detectedVersion, true)
 	if err != nil {
 		fmt.Println("Failed to evaluate the requested version in a specific version allowing to call", execName, ":", err) //nolint
-		os.Exit(1)
+		os.Exit(exitcode.FromError(err))
+	}
+
+	RunCmd(conf, installPath, detectedVersion, execName, cmdArgs)
+}
+
+// each step of the choice is logged at info level (visible with TENV_LOG=info).
+func selectAgnostic(conf *config.Config, builders map[string]builder.BuilderFunc, hclParser *hclparse.Parser) (versionmanager.VersionManager, string, string, error) {
+	policy := conf.AgnosticPolicy
+	if policy == "" {
+		policy = AgnosticPreferTofu
+	}
+
+	var first, second string
+	switch policy {
+	case AgnosticPreferTofu, AgnosticError, AgnosticFiles:
+		first, second = cmdconst.TofuName, cmdconst.TerraformName
+	case AgnosticPreferTerraform:
+		first, second = cmdconst.TerraformName, cmdconst.TofuName
+	default:
+		return versionmanager.VersionManager{}, "", "", ErrAgnosticPolicy
+	}
+
+	firstManager := builders[first](conf, hclParser)
+	firstVersion, err := resolveAgnostic(conf, firstManager, first)
+	if err != nil {
+		return versionmanager.VersionManager{}, "", "", err
+	}
+
+	preferPolicy := policy == AgnosticPreferTofu || policy == AgnosticPreferTerraform
+	if firstVersion != "" && preferPolicy {
+		conf.Displayer.Log(hclog.Info, "Agnostic proxy choice", "policy", policy, "selected", first, "reason", "preferred tool has version files")
+
+		return firstManager, first, firstVersion, nil
+	}
+
+	secondManager := builders[second](conf, hclParser)
+	secondVersion, err := resolveAgnostic(conf, secondManager, second)
+	if err != nil {
+		return versionmanager.VersionManager{}, "", "", err
+	}
+
+	switch {
+	case firstVersion == "":
+		conf.Displayer.Log(hclog.Info, "Agnostic proxy choice", "policy", policy, "selected", second, "reason", loghelper.Concat("no version files for ", first))
+
+		return secondManager, second, secondVersion, nil
+	case secondVersion == "":
+		conf.Displayer.Log(hclog.Info, "Agnostic proxy choice", "policy", policy, "selected", first, "reason", loghelper.Concat("no version files for ", second))
+
+		return firstManager, first, firstVersion, nil
+	case policy == AgnosticError:
+		return versionmanager.VersionManager{}, "", "", fmt.Errorf("%w : tofu %s and terraform %s, set TENV_AGNOSTIC_POLICY to choose", ErrAgnosticAmbiguous, firstVersion, secondVersion)
+	}
+
+	workingDir, err := os.Getwd()
+	if err != nil {
+		return versionmanager.VersionManager{}, "", "", err
+	}
+
+	// only the files policy remains, first is tofu
+	execName, reason := DecideByFiles(workingDir)
+	if execName == "" {
+		return versionmanager.VersionManager{}, "", "", fmt.Errorf("%w : tofu %s and terraform %s, %s", ErrAgnosticAmbiguous, firstVersion, secondVersion, reason)
 	}
+	conf.Displayer.Log(hclog.Info, "Agnostic proxy choice", "policy", policy, "selected", execName, "reason", reason)
 
-	RunCmd(installPath, detectedVersion, execName, cmdArgs, conf.GithubActions, conf.Displayer)
+	if execName == first {
+		return firstManager, first, firstVersion, nil
+	}
+
+	return secondManager, second, secondVersion, nil
+}
+
+func resolveAgnostic(conf *config.Config, manager versionmanager.VersionManager, execName string) (string, error) {
+	detectedVersion, err := manager.ResolveWithVersionFiles()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve a version allowing to call %s : %w", execName, err)
+	}
+	conf.Displayer.Log(hclog.Info, "Agnostic proxy resolution", "tool", execName, "version", detectedVersion)
+
+	return detectedVersion, nil
+}
+
+// DecideByFiles returns the tool indicated by files of dirPath (empty when undecided) and the reason of the choice :
+// OpenTofu specific files (like *.tofu or *.tofu.json), then providers registry of dependency lock file.
+func DecideByFiles(dirPath string) (string, string) {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return "", err.Error()
+	}
+
+	for _, entry := range entries {
+		if name := entry.Name(); !entry.IsDir() && iacparser.IsTofuFile(name) {
+			return cmdconst.TofuName, loghelper.Concat("found OpenTofu file ", name)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dirPath, lockFileName))
+	if err != nil {
+		return "", loghelper.Concat("no OpenTofu file and no ", lockFileName, " to decide")
+	}
+
+	tofuRegistry, tfRegistry := bytes.Contains(data, []byte("registry.opentofu.org/")), bytes.Contains(data, []byte("registry.terraform.io/"))
+	switch {
+	case tofuRegistry && !tfRegistry:
+		return cmdconst.TofuName, loghelper.Concat(lockFileName, " lists OpenTofu registry providers")
+	case tfRegistry && !tofuRegistry:
+		return cmdconst.TerraformName, loghelper.Concat(lockFileName, " lists Terraform registry providers")
+	default:
+		return "", loghelper.Concat(lockFileName, " does not designate a single registry")
+	}
 }
@@ -0,0 +1,92 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/exitcode"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager"
+	"github.com/tofuutils/tenv/v2/versionmanager/semantic/types"
+)
+
+const fallbackSource = "no version file (fallback strategy)"
+
+// dryRun resolve like ExecWith, then print the call instead of installing and running the binary.
+func dryRun(conf *config.Config, manager Manager, execName string, cmdArgs []string) int {
+	autoInstall := !conf.NoInstall
+	detectedVersion := parentResolved(conf, execName)
+	source := resolvedEnvName(execName)
+	if detectedVersion == "" {
+		// detection must not install a missing version
+		recorder := &types.SourceRecorder{Displayer: conf.Displayer}
+		conf.Displayer, conf.NoInstall = recorder, true
+		var err error
+		detectedVersion, err = manager.Detect(true)
+		conf.Displayer, conf.NoInstall = recorder.Displayer, !autoInstall
+		if errors.Is(err, versionmanager.ErrNoCompatibleLocally) && detectedVersion != "" {
+			err = nil
+		}
+		if err != nil {
+			fmt.Println("Failed to detect a version allowing to call", execName, ":", err) //nolint
+
+			return exitcode.FromError(err)
+		}
+
+		source = recorder.Source
+		if source == "" {
+			source = fallbackSource
+		}
+	}
+
+	binaryPath, err := manager.BinaryPath(detectedVersion)
+	if err != nil {
+		fmt.Println("Failed to create installation directory for", execName, ":", err) //nolint
+
+		return exitcode.FromError(err)
+	}
+
+	binaryState := "installed"
+	if _, err = os.Stat(binaryPath); err != nil {
+		binaryState = "not installed"
+		if autoInstall {
+			binaryState = "not installed, would be installed"
+		}
+	}
+
+	quotedArgs := make([]string, 0, len(cmdArgs)+1)
+	for _, arg := range append([]string{binaryPath}, cmdArgs...) {
+		quotedArgs = append(quotedArgs, strconv.Quote(arg))
+	}
+
+	fmt.Println(loghelper.Concat("Dry run, ", execName, " would be called with :"))            //nolint
+	fmt.Println(loghelper.Concat("  version : ", detectedVersion))                             //nolint
+	fmt.Println(loghelper.Concat("  source : ", source))                                       //nolint
+	fmt.Println(loghelper.Concat("  binary : ", binaryPath, " (", binaryState, ")"))           //nolint
+	fmt.Println(loghelper.Concat("  argv : ", strings.Join(quotedArgs, " ")))                  //nolint
+	fmt.Println(loghelper.Concat("  env : ", resolvedEnvName(execName), "=", detectedVersion)) //nolint
+
+	return exitcode.Success
+}
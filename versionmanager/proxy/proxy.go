@@ -24,39 +24,118 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/hcl/v2/hclparse"
 
 	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/config/cmdconst"
 	cmdproxy "github.com/tofuutils/tenv/v2/pkg/cmdproxy"
+	"github.com/tofuutils/tenv/v2/pkg/exitcode"
 	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager/archive"
 	"github.com/tofuutils/tenv/v2/versionmanager/builder"
 	"github.com/tofuutils/tenv/v2/versionmanager/lastuse"
+	"github.com/tofuutils/tenv/v2/versionmanager/manifest"
 )
 
 var errDelimiter = errors.New("key and value should not contains delimiter")
 
+// Manager is the part of versionmanager.VersionManager consumed by the proxy layer
+// (allows alternative frontends and tests without filesystem or network access).
+type Manager interface {
+	BinaryPath(version string) (string, error)
+	Detect(proxyCall bool) (string, error)
+	EnsureInstalled(version string) error
+}
+
+// RunFunc call the proxied binary and exit with its code (like cmdproxy.Run).
+type RunFunc = func(execPath string, cmdArgs []string, env []string, gha bool)
+
 func Exec(conf *config.Config, builderFunc builder.BuilderFunc, hclParser *hclparse.Parser, execName string, cmdArgs []string) {
 	conf.InitDisplayer(true)
-	versionManager := builderFunc(conf, hclParser)
-	detectedVersion, err := versionManager.Detect(true)
-	if err != nil {
-		fmt.Println("Failed to detect a version allowing to call", execName, ":", err) //nolint
-		os.Exit(1)
+
+	run := cmdproxy.Run
+	if execName == cmdconst.TerragruntName {
+		run = func(execPath string, cmdArgs []string, env []string, gha bool) {
+			checkTerraformBinary(conf, hclParser)
+			cmdproxy.Run(execPath, cmdArgs, env, gha)
+		}
+	}
+
+	os.Exit(ExecWith(conf, builderFunc(conf, hclParser), execName, cmdArgs, run))
+}
+
+// ExecWith returns an exit code when the binary can not be called (or in dry run mode), otherwise run is responsible of exiting.
+func ExecWith(conf *config.Config, manager Manager, execName string, cmdArgs []string, run RunFunc) int {
+	if conf.DryRun {
+		return dryRun(conf, manager, execName, cmdArgs)
+	}
+
+	detectedVersion := parentResolved(conf, execName)
+	if detectedVersion == "" {
+		var err error
+		if detectedVersion, err = manager.Detect(true); err != nil {
+			fmt.Println("Failed to detect a version allowing to call", execName, ":", err) //nolint
+
+			return exitcode.FromError(err)
+		}
+	}
+
+	if err := manager.EnsureInstalled(detectedVersion); err != nil {
+		fmt.Println("Failed to install", execName, detectedVersion, ":", err) //nolint
+
+		return exitcode.FromError(err)
 	}
 
-	installPath, err := versionManager.InstallPath()
+	binaryPath, err := manager.BinaryPath(detectedVersion)
 	if err != nil {
 		fmt.Println("Failed to create installation directory for", execName, ":", err) //nolint
-		os.Exit(1)
+
+		return exitcode.FromError(err)
+	}
+
+	runBinary(conf, binaryPath, detectedVersion, execName, cmdArgs, run)
+
+	return exitcode.Success
+}
+
+func RunCmd(conf *config.Config, installPath string, detectedVersion string, execName string, cmdArgs []string) {
+	runBinary(conf, filepath.Join(installPath, detectedVersion, execName), detectedVersion, execName, cmdArgs, cmdproxy.Run)
+}
+
+func runBinary(conf *config.Config, binaryPath string, detectedVersion string, execName string, cmdArgs []string, run RunFunc) {
+	versionPath := filepath.Dir(binaryPath)
+
+	// archived versions are transparently expanded on demand
+	if err := archive.Expand(versionPath, conf); err != nil {
+		fmt.Println("Failed to expand archived", execName, detectedVersion, ":", err) //nolint
+		os.Exit(exitcode.FromError(err))
+	}
+
+	lastuse.WriteNow(versionPath, conf)
+	if conf.WarnUnverified {
+		warnUnverified(versionPath, detectedVersion, execName, conf.Displayer)
 	}
 
-	RunCmd(installPath, detectedVersion, execName, cmdArgs, conf.GithubActions, conf.Displayer)
+	run(binaryPath, cmdArgs, []string{sharedResolved(execName, detectedVersion)}, conf.GithubActions)
 }
 
-func RunCmd(installPath string, detectedVersion string, execName string, cmdArgs []string, gha bool, displayer loghelper.Displayer) {
-	versionPath := filepath.Join(installPath, detectedVersion)
+func warnUnverified(versionPath string, detectedVersion string, execName string, displayer loghelper.Displayer) {
+	installManifest, found := manifest.Read(versionPath, displayer)
+	if found && installManifest.Verified() {
+		return
+	}
 
-	lastuse.WriteNow(versionPath, displayer)
+	if !manifest.NeedUnverifiedWarning(versionPath, displayer) {
+		return
+	}
 
-	cmdproxy.Run(filepath.Join(versionPath, execName), cmdArgs, gha)
+	switch {
+	case installManifest.Unverifiable:
+		displayer.Log(hclog.Warn, loghelper.Concat(execName, " ", detectedVersion, " is unverifiable (no checksum published upstream for this release)"))
+	case installManifest.ChecksumOnly():
+		displayer.Log(hclog.Warn, loghelper.Concat(execName, " ", detectedVersion, " has been installed with checksum verification only (no signature published upstream)"))
+	default:
+		displayer.Log(hclog.Warn, loghelper.Concat(execName, " ", detectedVersion, " has been installed without checksum and signature verification (legacy install or skipped check), reinstall it to verify"))
+	}
 }
@@ -0,0 +1,243 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package proxy_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/download"
+	"github.com/tofuutils/tenv/v2/pkg/exitcode"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager"
+	"github.com/tofuutils/tenv/v2/versionmanager/proxy"
+)
+
+var _ proxy.Manager = versionmanager.VersionManager{}
+
+type mockManager struct {
+	detected   bool
+	detectErr  error
+	installErr error
+	installed  []string
+	version    string
+}
+
+func (m *mockManager) BinaryPath(version string) (string, error) {
+	return filepath.Join("/nonexistent", version, "tofu"), nil
+}
+
+func (m *mockManager) Detect(bool) (string, error) {
+	m.detected = true
+
+	return m.version, m.detectErr
+}
+
+func (m *mockManager) EnsureInstalled(version string) error {
+	m.installed = append(m.installed, version)
+
+	return m.installErr
+}
+
+type runRecord struct {
+	args   []string
+	called bool
+	env    []string
+	gha    bool
+	path   string
+}
+
+func (r *runRecord) run(execPath string, cmdArgs []string, env []string, gha bool) {
+	*r = runRecord{args: cmdArgs, called: true, env: env, gha: gha, path: execPath}
+}
+
+func TestExecWith(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.Config{Displayer: loghelper.InertDisplayer, GithubActions: true}
+	manager := &mockManager{version: "1.6.2"}
+
+	var record runRecord
+	if code := proxy.ExecWith(conf, manager, "tofu", []string{"plan", "-out=plan.bin"}, record.run); code != exitcode.Success {
+		t.Error("Unexpected exit code :", code)
+	}
+
+	if !record.called || record.path != filepath.Join("/nonexistent", "1.6.2", "tofu") || !record.gha || !slices.Equal(record.args, []string{"plan", "-out=plan.bin"}) {
+		t.Error("Unmatching run call, get :", record)
+	}
+
+	if !slices.Equal(manager.installed, []string{"1.6.2"}) {
+		t.Error("Unmatching install calls, get :", manager.installed)
+	}
+
+	if !slices.Equal(record.env, []string{"TENV_RESOLVED_TOFU=1.6.2"}) || os.Getenv("TENV_RESOLVED_TOFU") != "" {
+		t.Error("Resolved version should only be shared with the called binary, get :", record.env)
+	}
+}
+
+func TestExecWithParentResolved(t *testing.T) { //nolint
+	t.Setenv("TENV_RESOLVED_ATMOS", "1.70.0")
+
+	conf := &config.Config{Displayer: loghelper.InertDisplayer}
+	manager := &mockManager{version: "1.80.0"}
+
+	var record runRecord
+	if code := proxy.ExecWith(conf, manager, "atmos", nil, record.run); code != exitcode.Success {
+		t.Error("Unexpected exit code :", code)
+	}
+
+	if manager.detected || record.path != filepath.Join("/nonexistent", "1.70.0", "tofu") {
+		t.Error("Should reuse parent resolution, get :", record)
+	}
+}
+
+func TestExecWithError(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.Config{Displayer: loghelper.InertDisplayer}
+	cases := []struct {
+		manager *mockManager
+		code    int
+	}{
+		{manager: &mockManager{detectErr: versionmanager.ErrNoCompatible}, code: exitcode.NoCompatible},
+		{manager: &mockManager{version: "1.6.2", installErr: download.ErrNotFound}, code: exitcode.Network},
+		{manager: &mockManager{version: "1.6.2", installErr: errors.New("disk full")}, code: exitcode.Generic},
+	}
+
+	for _, testCase := range cases {
+		var record runRecord
+		if code := proxy.ExecWith(conf, testCase.manager, "tofu", nil, record.run); code != testCase.code {
+			t.Error("Unexpected exit code :", code, "expected :", testCase.code)
+		}
+
+		if record.called {
+			t.Error("Binary should not be called on failure")
+		}
+	}
+}
+
+func TestExecWithDryRun(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.Config{Displayer: loghelper.InertDisplayer, DryRun: true}
+	manager := &mockManager{version: "1.6.2"}
+
+	var record runRecord
+	if code := proxy.ExecWith(conf, manager, "terragrunt", []string{"apply"}, record.run); code != exitcode.Success {
+		t.Error("Unexpected exit code :", code)
+	}
+
+	if !manager.detected || len(manager.installed) != 0 || record.called {
+		t.Error("Should only resolve in dry run mode, get :", manager.installed, record)
+	}
+
+	if conf.Displayer != loghelper.InertDisplayer {
+		t.Error("Displayer should be restored after dry run")
+	}
+}
+
+type failRetriever struct {
+	installed *[]string
+}
+
+func (r failRetriever) InstallRelease(version string, _ string) error {
+	*r.installed = append(*r.installed, version)
+
+	return errors.New("dry run should not install")
+}
+
+func (failRetriever) ListReleases() ([]string, error) {
+	return []string{"1.6.2"}, nil
+}
+
+func TestExecWithDryRunNoInstall(t *testing.T) {
+	t.Parallel()
+
+	var installed []string
+	conf := &config.Config{Displayer: loghelper.InertDisplayer, DryRun: true, RootPath: t.TempDir(), SearchBoundary: "none"}
+	manager := versionmanager.Make(conf, "", "tofu", "OpenTofu", nil, nil, failRetriever{installed: &installed}, "", "", nil)
+	if err := os.MkdirAll(filepath.Dir(manager.RootVersionFilePath()), 0o755); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	for _, requested := range []string{"1.6.2", "~> 1.6.0"} {
+		if err := os.WriteFile(manager.RootVersionFilePath(), []byte(requested), 0o644); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+
+		var record runRecord
+		if code := proxy.ExecWith(conf, manager, "tofu", []string{"plan"}, record.run); code != exitcode.Success {
+			t.Error("Unexpected exit code :", code)
+		}
+
+		if len(installed) != 0 || record.called {
+			t.Error("Should not install nor run in dry run mode, get :", installed, record)
+		}
+	}
+
+	if entries, err := os.ReadDir(filepath.Join(conf.RootPath, "OpenTofu")); err != nil || len(entries) != 1 { // only version file
+		t.Error("Nothing should be installed, get :", entries, err)
+	}
+
+	if conf.NoInstall {
+		t.Error("Auto install setting should be restored after dry run")
+	}
+}
+
+func TestDecideByFiles(t *testing.T) {
+	t.Parallel()
+
+	dirPath := t.TempDir()
+	if execName, _ := proxy.DecideByFiles(dirPath); execName != "" {
+		t.Error("Should be undecided, get :", execName)
+	}
+
+	lockData := []byte(`provider "registry.terraform.io/hashicorp/aws" {
+  version = "5.31.0"
+}
+`)
+	if err := os.WriteFile(filepath.Join(dirPath, ".terraform.lock.hcl"), lockData, 0o644); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if execName, _ := proxy.DecideByFiles(dirPath); execName != "terraform" {
+		t.Error("Unmatching results, get :", execName)
+	}
+
+	jsonDirPath := t.TempDir()
+	if err := os.WriteFile(filepath.Join(jsonDirPath, "main.tofu.json"), []byte("{}"), 0o644); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if execName, _ := proxy.DecideByFiles(jsonDirPath); execName != "tofu" {
+		t.Error("Unmatching results, get :", execName)
+	}
+
+	if err := os.WriteFile(filepath.Join(dirPath, "main.tofu"), nil, 0o644); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if execName, _ := proxy.DecideByFiles(dirPath); execName != "tofu" {
+		t.Error("Unmatching results, get :", execName)
+	}
+}
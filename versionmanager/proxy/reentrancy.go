@@ -0,0 +1,63 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package proxy
+
+import (
+	"os"
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-version"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/config/cmdconst"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+)
+
+// like TENV_RESOLVED_TERRAFORM.
+func resolvedEnvName(execName string) string {
+	return cmdconst.ResolvedEnvPrefix + strings.ToUpper(execName)
+}
+
+// parentResolved returns the version resolved by a parent tenv proxy for the same tool
+// (when a managed tool calls itself through the proxy), or an empty string.
+func parentResolved(conf *config.Config, execName string) string {
+	envName := resolvedEnvName(execName)
+	inheritedVersion := os.Getenv(envName)
+	if inheritedVersion == "" {
+		return ""
+	}
+
+	if _, err := version.NewVersion(inheritedVersion); err != nil {
+		conf.Displayer.Log(hclog.Warn, loghelper.Concat("Ignore invalid ", envName, " value"), loghelper.Error, err)
+
+		return ""
+	}
+
+	conf.Displayer.Log(hclog.Debug, "Reuse version resolved by parent tenv proxy", "env", envName, "version", inheritedVersion)
+	conf.Displayer.Flush(true)
+
+	return inheritedVersion
+}
+
+// sharedResolved returns the environment entry added to the tool call,
+// so its nested proxy calls skip resolution (see parentResolved).
+func sharedResolved(execName string, detectedVersion string) string {
+	return resolvedEnvName(execName) + "=" + detectedVersion
+}
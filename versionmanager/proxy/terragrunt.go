@@ -0,0 +1,95 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package proxy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/hcl/v2/hclparse"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/config/cmdconst"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/pkg/winbin"
+	terragruntparser "github.com/tofuutils/tenv/v2/versionmanager/semantic/parser/terragrunt"
+)
+
+// terragrunt give precedence to these variables over terraform_binary attribute.
+var tfPathEnvNames = []string{"TERRAGRUNT_TFPATH", "TG_TF_PATH"} //nolint
+
+// checkTerraformBinary ensure nested calls from terragrunt stay managed by tenv :
+// a terraform_binary pointing outside tenv proxies is redirected (with TG_PROXY_TF_BINARY) or reported.
+func checkTerraformBinary(conf *config.Config, hclParser *hclparse.Parser) {
+	binary, err := terragruntparser.Make(hclParser).RetrieveTerraformBinary(conf)
+	if err != nil {
+		conf.Displayer.Log(hclog.Warn, "Failed to read terraform_binary", loghelper.Error, err)
+
+		return
+	}
+
+	name := strings.TrimSuffix(filepath.Base(binary), winbin.Suffix)
+	if binary == "" || binary == name || (name != cmdconst.TofuName && name != cmdconst.TerraformName) {
+		return // unset, resolved with PATH (tenv proxy found first) or not a managed tool
+	}
+
+	for _, envName := range tfPathEnvNames {
+		if os.Getenv(envName) != "" {
+			return // explicit choice has precedence
+		}
+	}
+
+	tenvPath, err := os.Executable()
+	if err != nil {
+		conf.Displayer.Log(hclog.Warn, "Failed to locate tenv binaries", loghelper.Error, err)
+
+		return
+	}
+
+	proxyPath := filepath.Join(filepath.Dir(tenvPath), winbin.GetBinaryName(name))
+	if sameFile(binary, proxyPath) {
+		return
+	}
+
+	if !conf.TgProxyTfBinary {
+		conf.Displayer.Log(hclog.Warn, loghelper.Concat("terraform_binary ", binary, " is not managed by tenv, set TG_PROXY_TF_BINARY to true to redirect it to ", proxyPath))
+
+		return
+	}
+
+	conf.Displayer.Log(hclog.Debug, "Redirect terraform_binary to tenv proxy", "terraform_binary", binary, "proxy", proxyPath)
+	for _, envName := range tfPathEnvNames {
+		if err = os.Setenv(envName, proxyPath); err != nil {
+			conf.Displayer.Log(hclog.Warn, "Failed to set environment variable", "name", envName, loghelper.Error, err)
+		}
+	}
+}
+
+func sameFile(path1 string, path2 string) bool {
+	info1, err := os.Stat(path1)
+	if err != nil {
+		return false
+	}
+
+	info2, err := os.Stat(path2)
+
+	return err == nil && os.SameFile(info1, info2)
+}
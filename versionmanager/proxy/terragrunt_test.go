@@ -0,0 +1,85 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package proxy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2/hclparse"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/pkg/winbin"
+)
+
+// change working directory and env, so not parallel.
+func TestCheckTerraformBinary(t *testing.T) { //nolint
+	previousPath, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+	defer os.Chdir(previousPath) //nolint
+
+	tenvPath, err := os.Executable()
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+	proxyPath := filepath.Join(filepath.Dir(tenvPath), winbin.GetBinaryName("tofu"))
+
+	tests := []struct {
+		name       string
+		hclData    string // terragrunt.hcl content, not written when empty
+		redirect   bool
+		tgTfPath   string
+		wantTfPath string
+	}{
+		{name: "NoFile", redirect: true},
+		{name: "Unset", hclData: `inputs = {}`, redirect: true},
+		{name: "PathLookup", hclData: `terraform_binary = "tofu"`, redirect: true},
+		{name: "NotManaged", hclData: `terraform_binary = "/opt/bin/tflint"`, redirect: true},
+		{name: "WarnOnly", hclData: `terraform_binary = "/opt/bin/tofu"`},
+		{name: "Redirected", hclData: `terraform_binary = "/opt/bin/tofu"`, redirect: true, wantTfPath: proxyPath},
+		{name: "ExplicitEnv", hclData: `terraform_binary = "/opt/bin/tofu"`, redirect: true, tgTfPath: "/usr/bin/tofu", wantTfPath: "/usr/bin/tofu"},
+	}
+
+	// sequential, each case change working directory and env
+	for _, tt := range tests {
+		workPath := t.TempDir()
+		if tt.hclData != "" {
+			if err = os.WriteFile(filepath.Join(workPath, "terragrunt.hcl"), []byte(tt.hclData), 0o600); err != nil {
+				t.Fatal("Unexpected error :", err)
+			}
+		}
+
+		if err = os.Chdir(workPath); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+
+		t.Setenv("TERRAGRUNT_TFPATH", "")
+		t.Setenv("TG_TF_PATH", tt.tgTfPath)
+
+		conf := &config.Config{Displayer: loghelper.InertDisplayer, TgProxyTfBinary: tt.redirect}
+		checkTerraformBinary(conf, hclparse.NewParser())
+		if tfPath := os.Getenv("TG_TF_PATH"); tfPath != tt.wantTfPath {
+			t.Error(tt.name, "unmatching results, get :", tfPath)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager
+
+import (
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+
+	"github.com/tofuutils/tenv/v2/pkg/lockfile"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	flatparser "github.com/tofuutils/tenv/v2/versionmanager/semantic/parser/flat"
+)
+
+// Prune uninstalls versions not used since limit (never used versions are dated by their installation),
+// the default version is kept. With dryRun, selected versions are only returned.
+func (m VersionManager) Prune(limit time.Time, dryRun bool) ([]string, error) {
+	installPath, err := m.ensureInstallDir()
+	if err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		return m.selectUnused(installPath, limit)
+	}
+
+	deleteLock, err := lockfile.Write(installPath, m.conf.LockTimeout, m.conf.Displayer)
+	if err != nil {
+		return nil, err
+	}
+	disableExit := lockfile.CleanAndExitOnInterrupt(deleteLock)
+	defer disableExit()
+	defer deleteLock()
+
+	return m.innerPrune(installPath, limit)
+}
+
+func (m VersionManager) innerPrune(installPath string, limit time.Time) ([]string, error) {
+	selected, err := m.selectUnused(installPath, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, version := range selected {
+		m.uninstallSpecificVersion(installPath, version)
+	}
+
+	return selected, nil
+}
+
+func (m VersionManager) selectUnused(installPath string, limit time.Time) ([]string, error) {
+	versions, err := m.innerListLocal(installPath, false)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultVersion := m.readDefaultVersion()
+
+	var selected []string
+	for _, version := range versions {
+		if version != defaultVersion && m.unusedSince(filepath.Join(installPath, version), limit) {
+			selected = append(selected, version)
+		}
+	}
+
+	return selected, nil
+}
+
+// keepAfterInstall apply TENV_KEEP_VERSIONS after installations (the install lock is already held) :
+// beyond the limit, least recently used versions are uninstalled, the default version is always kept.
+func (m VersionManager) keepAfterInstall(installPath string) {
+	if m.conf.KeepVersions <= 0 {
+		return
+	}
+
+	versions, err := m.innerListLocal(installPath, false)
+	if err != nil {
+		m.conf.Displayer.Log(hclog.Warn, "Failed to apply keep policy", loghelper.Error, err)
+
+		return
+	}
+
+	if int64(len(versions)) <= m.conf.KeepVersions {
+		return
+	}
+
+	useDates := make(map[string]time.Time, len(versions))
+	for _, version := range versions {
+		useDates[version] = m.useDate(filepath.Join(installPath, version))
+	}
+
+	// most recently used first
+	slices.SortStableFunc(versions, func(a string, b string) int {
+		return useDates[b].Compare(useDates[a])
+	})
+
+	defaultVersion := m.readDefaultVersion()
+
+	var kept int64
+	for _, version := range versions {
+		if kept < m.conf.KeepVersions || version == defaultVersion {
+			kept++
+
+			continue
+		}
+
+		m.uninstallSpecificVersion(installPath, version)
+	}
+}
+
+func (m VersionManager) readDefaultVersion() string {
+	defaultVersion, _ := flatparser.Retrieve(m.RootVersionFilePath(), m.conf, flatparser.NoMsg)
+
+	return defaultVersion
+}
+
+// apply prune policy after installations (the install lock is already held).
+func (m VersionManager) pruneAfterInstall(installPath string) {
+	if m.conf.PruneAfter <= 0 {
+		return
+	}
+
+	if _, err := m.innerPrune(installPath, time.Now().Add(-m.conf.PruneAfter)); err != nil {
+		m.conf.Displayer.Log(hclog.Warn, "Failed to apply prune policy", loghelper.Error, err)
+	}
+}
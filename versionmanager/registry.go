@@ -0,0 +1,138 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+
+	"github.com/tofuutils/tenv/v2/pkg/audit"
+	"github.com/tofuutils/tenv/v2/pkg/fileperm"
+	"github.com/tofuutils/tenv/v2/pkg/lockfile"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager/semantic"
+)
+
+// RegistryEntry describe an installed version, file digests allow to detect drift.
+type RegistryEntry struct {
+	audit.Entry
+	InstalledAt time.Time `json:"installed_at"`
+	Path        string    `json:"path"`
+}
+
+type Registry struct {
+	Entries []RegistryEntry `json:"entries"`
+}
+
+// syncRegistry record version in registry file (when installed) and remove entries of uninstalled versions, failures are only logged.
+func (m VersionManager) syncRegistry(version string) {
+	if m.conf.RegistryPath == "" {
+		return
+	}
+
+	if err := m.updateRegistry(version); err != nil {
+		m.conf.Displayer.Log(hclog.Warn, "Failed to update registry file", "filePath", m.conf.RegistryPath, loghelper.Error, err)
+	}
+}
+
+func (m VersionManager) updateRegistry(version string) error {
+	installPath := m.installDir()
+	registryDir := filepath.Dir(m.conf.RegistryPath)
+	if err := os.MkdirAll(registryDir, fileperm.DirMode()); err != nil {
+		return err
+	}
+
+	// install lock is already held by callers
+	if filepath.Clean(registryDir) != filepath.Clean(installPath) {
+		deleteLock, err := lockfile.Write(registryDir, m.conf.LockTimeout, m.conf.Displayer)
+		if err != nil {
+			return err
+		}
+		defer deleteLock()
+	}
+
+	registry, err := ReadRegistry(m.conf.RegistryPath)
+	if err != nil {
+		return err
+	}
+
+	registry.Entries = slices.DeleteFunc(registry.Entries, func(entry RegistryEntry) bool {
+		if entry.Tool != m.FolderName {
+			return false
+		}
+
+		_, err := os.Stat(entry.Path)
+
+		return entry.Version == version || err != nil
+	})
+
+	versionPath := filepath.Join(installPath, version)
+	if _, err = os.Stat(versionPath); err == nil {
+		auditEntry, err := m.auditEntry(installPath, version)
+		if err != nil {
+			return err
+		}
+
+		registry.Entries = append(registry.Entries, RegistryEntry{Entry: auditEntry, InstalledAt: time.Now().UTC(), Path: versionPath})
+	}
+
+	slices.SortFunc(registry.Entries, func(a RegistryEntry, b RegistryEntry) int {
+		if cmp := strings.Compare(a.Tool, b.Tool); cmp != 0 {
+			return cmp
+		}
+
+		return semantic.CmpVersion(a.Version, b.Version)
+	})
+
+	data, err := json.MarshalIndent(registry, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// rename is atomic, readers never see a partial file
+	tmpPath := m.conf.RegistryPath + ".tmp"
+	if err = os.WriteFile(tmpPath, data, fileperm.FileMode()); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, m.conf.RegistryPath)
+}
+
+// ReadRegistry returns an empty registry when the file does not exist.
+func ReadRegistry(filePath string) (Registry, error) {
+	var registry Registry
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return registry, nil
+		}
+
+		return registry, err
+	}
+
+	return registry, json.Unmarshal(data, &registry)
+}
@@ -0,0 +1,104 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+
+	"github.com/tofuutils/tenv/v2/pkg/fileperm"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+)
+
+const (
+	releaseDatesFileName = "release-dates.json"
+
+	// RemoteCacheFileName is the file keeping remote releases list (see TENV_REMOTE_CACHE_TTL).
+	RemoteCacheFileName = "remote-releases.json"
+)
+
+// DatedReleaseLister is implemented by retrievers able to return release publish dates (nil when unavailable).
+type DatedReleaseLister interface {
+	ListDatedReleases() ([]string, map[string]time.Time, error)
+}
+
+// ReleaseDates returns publish dates by version, recorded in local index during previous remote listings.
+func (m VersionManager) ReleaseDates() map[string]time.Time {
+	dates := map[string]time.Time{}
+	data, err := os.ReadFile(m.releaseDatesFilePath())
+	if err != nil {
+		m.conf.Displayer.Log(loghelper.LevelWarnOrDebug(errors.Is(err, fs.ErrNotExist)), "Unable to read release dates file", loghelper.Error, err)
+
+		return dates
+	}
+
+	if err = json.Unmarshal(data, &dates); err != nil {
+		m.conf.Displayer.Log(hclog.Warn, "Unable to parse release dates file", loghelper.Error, err)
+	}
+
+	return dates
+}
+
+func (m VersionManager) listReleases() ([]string, error) {
+	datedLister, ok := m.retriever.(DatedReleaseLister)
+	if !ok {
+		return m.retriever.ListReleases()
+	}
+
+	versions, dates, err := datedLister.ListDatedReleases()
+	if err == nil && len(dates) != 0 {
+		m.recordReleaseDates(dates)
+	}
+
+	return versions, err
+}
+
+// best effort, merge with known dates (html list mode does not give them).
+func (m VersionManager) recordReleaseDates(dates map[string]time.Time) {
+	known := m.ReleaseDates()
+	for version, date := range dates {
+		known[version] = date
+	}
+
+	data, err := json.Marshal(known)
+	if err != nil {
+		m.conf.Displayer.Log(hclog.Warn, "Unable to serialize release dates", loghelper.Error, err)
+
+		return
+	}
+
+	filePath := m.releaseDatesFilePath()
+	if err = os.MkdirAll(filepath.Dir(filePath), fileperm.DirMode()); err == nil {
+		err = os.WriteFile(filePath, data, fileperm.FileMode())
+	}
+
+	if err != nil {
+		m.conf.Displayer.Log(hclog.Warn, "Unable to write release dates file", loghelper.Error, err)
+	}
+}
+
+func (m VersionManager) releaseDatesFilePath() string {
+	return m.conf.UserStatePath(m.FolderName, releaseDatesFileName)
+}
@@ -0,0 +1,78 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+
+	pgpcheck "github.com/tofuutils/tenv/v2/pkg/check/pgp"
+	"github.com/tofuutils/tenv/v2/pkg/download"
+	"github.com/tofuutils/tenv/v2/pkg/fileperm"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+)
+
+// remote documents are cached in user state cacheDirName during TENV_REMOTE_CACHE_TTL,
+// an expired cache is still used when the remote is unreachable (but not when its signature is wrong).
+func (m VersionManager) fetchCached(location string, cacheDirName string, fetch func(string) ([]byte, error)) ([]byte, error) {
+	if m.conf.NoCache {
+		return fetch(location)
+	}
+
+	hash := sha256.Sum256([]byte(location))
+	cachePath := m.conf.UserStatePath(cacheDirName, hex.EncodeToString(hash[:]))
+	info, errCache := os.Stat(cachePath)
+	if errCache == nil && !m.conf.RefreshCache && time.Since(info.ModTime()) < m.conf.RemoteCacheTTL {
+		return os.ReadFile(cachePath)
+	}
+
+	data, err := fetch(location)
+	if err != nil {
+		if errCache != nil || errors.Is(err, pgpcheck.ErrCheck) {
+			return nil, err
+		}
+		m.conf.Displayer.Log(hclog.Warn, "Use expired cache", "location", location, loghelper.Error, err)
+
+		return os.ReadFile(cachePath)
+	}
+
+	if err = os.MkdirAll(filepath.Dir(cachePath), fileperm.DirMode()); err == nil {
+		err = os.WriteFile(cachePath, data, fileperm.FileMode())
+	}
+	if err != nil {
+		m.conf.Displayer.Log(hclog.Warn, "Unable to write cache", "location", location, loghelper.Error, err)
+	}
+
+	return data, nil
+}
+
+func downloadDocument(location string) ([]byte, error) {
+	return download.Bytes(location, loghelper.InertDisplayer.Display)
+}
+
+func isRemoteLocation(location string) bool {
+	return strings.Contains(location, "://")
+}
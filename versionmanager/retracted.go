@@ -0,0 +1,146 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-version"
+	"gopkg.in/yaml.v3"
+
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager/semantic/types"
+)
+
+const (
+	// RetractedFileName is the local list of retracted versions in TENV_ROOT (merged with TENV_RETRACTED_URL document).
+	RetractedFileName = "retracted.yaml"
+
+	RetractedPolicyBlock = "block"
+	RetractedPolicyWarn  = "warn"
+
+	retractedCacheDirName = "retracted"
+)
+
+var (
+	ErrRetracted       = errors.New("retracted version refused")
+	ErrRetractedPolicy = errors.New("unknown retracted policy, expected warn or block")
+)
+
+// Retracted returns reasons by retracted version, read from a YAML map of tools (lower case folder name, like "opentofu")
+// to maps of version to reason. The local file reasons take precedence over the remote document ones.
+func (m VersionManager) Retracted() map[string]string {
+	retracted := map[string]string{}
+	if location := m.conf.RetractedURL; location != "" {
+		read := os.ReadFile
+		if isRemoteLocation(location) {
+			read = func(location string) ([]byte, error) {
+				return m.fetchCached(location, retractedCacheDirName, downloadDocument)
+			}
+		}
+
+		if data, err := read(location); err == nil {
+			m.mergeRetracted(retracted, data, location)
+		} else {
+			m.conf.Displayer.Log(hclog.Warn, "Unable to read retracted versions document", "location", location, loghelper.Error, err)
+		}
+	}
+
+	filePath := filepath.Join(m.conf.RootPath, RetractedFileName)
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			m.conf.Displayer.Log(hclog.Warn, "Unable to read retracted versions file", loghelper.Error, err)
+		}
+
+		return retracted
+	}
+	m.mergeRetracted(retracted, data, filePath)
+
+	return retracted
+}
+
+func (m VersionManager) mergeRetracted(retracted map[string]string, data []byte, source string) {
+	var reasonsByTool map[string]map[string]string
+	if err := yaml.Unmarshal(data, &reasonsByTool); err != nil {
+		m.conf.Displayer.Log(hclog.Warn, "Unable to parse retracted versions", "source", source, loghelper.Error, err)
+
+		return
+	}
+
+	for versionStr, reason := range reasonsByTool[strings.ToLower(m.FolderName)] {
+		if parsedVersion, err := version.NewVersion(versionStr); err == nil {
+			versionStr = parsedVersion.String() // same form as installed and remote versions
+		}
+		retracted[versionStr] = reason
+	}
+}
+
+// a pinned retracted version triggers a warning, or an error with block policy.
+func (m VersionManager) checkRetractedPin(versionStr string) error {
+	reason, retracted := m.Retracted()[versionStr]
+	if !retracted {
+		return nil
+	}
+
+	switch m.conf.RetractedPolicy {
+	case "", RetractedPolicyWarn:
+		m.conf.Displayer.Log(hclog.Warn, loghelper.Concat(m.FolderName, " ", versionStr, " is retracted : ", reason))
+
+		return nil
+	case RetractedPolicyBlock:
+		return fmt.Errorf("%w : %s %s (%s)", ErrRetracted, m.FolderName, versionStr, reason)
+	default:
+		return ErrRetractedPolicy
+	}
+}
+
+// constraint matching skips retracted versions, a version pinned by an equality constraint is checked like an exact one.
+func (m VersionManager) skipRetracted(predicateInfo types.PredicateInfo) (types.PredicateInfo, error) {
+	if predicateInfo.Exact != "" {
+		return predicateInfo, m.checkRetractedPin(predicateInfo.Exact)
+	}
+
+	retracted := m.Retracted()
+	if len(retracted) == 0 {
+		return predicateInfo, nil
+	}
+
+	predicate := predicateInfo.Predicate
+	predicateInfo.Predicate = func(versionStr string) bool {
+		if !predicate(versionStr) {
+			return false
+		}
+
+		reason, found := retracted[versionStr]
+		if found {
+			m.conf.Displayer.Log(hclog.Info, "Skip retracted version", "version", versionStr, "reason", reason)
+		}
+
+		return !found
+	}
+
+	return predicateInfo, nil
+}
@@ -20,18 +20,19 @@ package atmosretriever
 
 import (
 	"net/url"
-	"os"
-	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/tofuutils/tenv/v2/config"
 	"github.com/tofuutils/tenv/v2/config/cmdconst"
 	"github.com/tofuutils/tenv/v2/pkg/apimsg"
-	sha256check "github.com/tofuutils/tenv/v2/pkg/check/sha256"
 	"github.com/tofuutils/tenv/v2/pkg/download"
+	"github.com/tofuutils/tenv/v2/pkg/extract"
 	"github.com/tofuutils/tenv/v2/pkg/github"
 	"github.com/tofuutils/tenv/v2/pkg/winbin"
+	"github.com/tofuutils/tenv/v2/versionmanager"
+	"github.com/tofuutils/tenv/v2/versionmanager/manifest"
 	htmlretriever "github.com/tofuutils/tenv/v2/versionmanager/retriever/html"
 
 	"github.com/hashicorp/go-hclog"
@@ -66,6 +67,7 @@ func (r AtmosRetriever) InstallRelease(versionStr string, targetPath string) err
 	}
 
 	var assetURLs []string
+	var requestOptions []download.RequestOption
 	fileName, shaFileName := buildAssetNames(versionStr, r.conf.Arch)
 	if r.conf.Displayer.IsDebug() {
 		r.conf.Displayer.Log(hclog.Debug, apimsg.MsgSearch, apimsg.AssetsName, []string{fileName, shaFileName})
@@ -80,7 +82,9 @@ func (r AtmosRetriever) InstallRelease(versionStr string, targetPath string) err
 
 		assetURLs, err = htmlretriever.BuildAssetURLs(baseAssetURL, fileName, shaFileName)
 	case config.ModeAPI:
-		assetURLs, err = github.AssetDownloadURL(tag, []string{fileName, shaFileName}, r.conf.Atmos.GetRemoteURL(), r.conf.GithubToken, r.conf.Displayer.Display)
+		githubToken := r.conf.ToolGithubToken(r.conf.Atmos, r.conf.Atmos.GetRemoteURL())
+		assetURLs, err = github.AssetDownloadURL(tag, []string{fileName, shaFileName}, r.conf.Atmos.GetRemoteURL(), githubToken, r.conf.GithubAssetAPI, r.conf.Displayer.Display)
+		requestOptions = github.AssetRequestOptions(githubToken, r.conf.GithubAssetAPI)
 	default:
 		return config.ErrInstallMode
 	}
@@ -94,32 +98,53 @@ func (r AtmosRetriever) InstallRelease(versionStr string, targetPath string) err
 		return err
 	}
 
-	data, err := download.Bytes(assetURLs[0], r.conf.Displayer.Display)
-	if err != nil {
-		return err
-	}
+	format := extract.Raw(winbin.GetBinaryName(cmdconst.AtmosName))
+	pending := format.Start(assetURLs[0], r.conf.StreamExtract, r.conf.Displayer.Display, requestOptions...)
+	defer pending.Discard()
 
-	dataSums, err := download.Bytes(assetURLs[1], r.conf.Displayer.Display)
+	dataSums, err := download.Bytes(assetURLs[1], r.conf.Displayer.Display, requestOptions...)
 	if err != nil {
 		return err
 	}
 
-	if err = sha256check.Check(data, dataSums, fileName); err != nil {
+	if err = pending.Install(dataSums, fileName, targetPath, nil); err != nil {
 		return err
 	}
+	manifest.Write(targetPath, manifest.Manifest{Checksum: true, Signature: manifest.SignatureUnavailable, Source: assetURLs[0]}, r.conf.Displayer)
+
+	return nil
+}
 
-	err = os.MkdirAll(targetPath, 0o755)
+// FindRelease stops paging at the first page with a matching release in API list mode (other modes list all releases).
+func (r AtmosRetriever) FindRelease(match func(string) bool) (string, error) {
+	err := r.conf.InitRemoteConf()
 	if err != nil {
-		return err
+		return "", err
+	}
+
+	if r.conf.Atmos.GetListMode() != config.ModeAPI {
+		releases, err := r.ListReleases()
+
+		return versionmanager.HighestMatching(releases, match), err
 	}
 
-	return os.WriteFile(filepath.Join(targetPath, winbin.GetBinaryName(cmdconst.AtmosName)), data, 0o755)
+	listURL := r.conf.Atmos.GetListURL()
+	r.conf.Displayer.Display(apimsg.MsgFetchReleases + listURL)
+
+	return github.FindRelease(listURL, r.conf.ToolGithubToken(r.conf.Atmos, listURL), match)
 }
 
 func (r AtmosRetriever) ListReleases() ([]string, error) {
+	releases, _, err := r.ListDatedReleases()
+
+	return releases, err
+}
+
+// ListDatedReleases returns publish dates only in API list mode.
+func (r AtmosRetriever) ListDatedReleases() ([]string, map[string]time.Time, error) {
 	err := r.conf.InitRemoteConf()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	listURL := r.conf.Atmos.GetListURL()
@@ -127,21 +152,52 @@ func (r AtmosRetriever) ListReleases() ([]string, error) {
 	case config.ListModeHTML:
 		baseURL, err := url.JoinPath(listURL, cloudposseName, cmdconst.AtmosName, github.Releases, github.Download) //nolint
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		r.conf.Displayer.Display(apimsg.MsgFetchAllReleases + baseURL)
+		releases, err := htmlretriever.ListReleases(baseURL, r.conf.Atmos.Data)
 
-		return htmlretriever.ListReleases(baseURL, r.conf.Atmos.Data)
+		return releases, nil, err
 	case config.ModeAPI:
 		r.conf.Displayer.Display(apimsg.MsgFetchAllReleases + listURL)
 
-		return github.ListReleases(listURL, r.conf.GithubToken)
+		return github.ListDatedReleases(listURL, r.conf.ToolGithubToken(r.conf.Atmos, listURL))
 	default:
-		return nil, config.ErrListMode
+		return nil, nil, config.ErrListMode
 	}
 }
 
+// ProbeSidecars checks that checksum file is published (atmos releases are not signed).
+func (r AtmosRetriever) ProbeSidecars(versionStr string) (bool, bool, error) {
+	err := r.conf.InitRemoteConf()
+	if err != nil {
+		return false, false, err
+	}
+
+	tag, versionStr := github.SplitTag(versionStr)
+
+	baseURL := github.BaseURL
+	if r.conf.Atmos.GetInstallMode() == config.InstallModeDirect {
+		baseURL = r.conf.Atmos.GetRemoteURL()
+	}
+
+	_, shaFileName := buildAssetNames(versionStr, r.conf.Arch)
+	sumsURL, err := url.JoinPath(baseURL, cloudposseName, cmdconst.AtmosName, github.Releases, github.Download, tag, shaFileName) //nolint
+	if err != nil {
+		return false, false, err
+	}
+
+	sumsURL, err = download.UrlTranformer(r.conf.Atmos.GetRewriteRule())(sumsURL)
+	if err != nil {
+		return false, false, err
+	}
+
+	found, err := download.Exists(sumsURL)
+
+	return found, false, err
+}
+
 func buildAssetNames(version string, arch string) (string, string) {
 	var nameBuilder strings.Builder
 	nameBuilder.WriteString(baseFileName)
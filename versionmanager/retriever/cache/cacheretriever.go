@@ -0,0 +1,192 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cacheretriever
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/fileperm"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager"
+)
+
+type cacheContent struct {
+	Dates    map[string]time.Time `json:"dates,omitempty"`
+	Fetched  time.Time            `json:"fetched"`
+	Source   string               `json:"source"` // list location, a change invalidates the cache
+	Versions []string             `json:"versions"`
+}
+
+// CacheRetriever keeps remote release list on disk during TENV_REMOTE_CACHE_TTL, install is delegated to the wrapped retriever.
+type CacheRetriever struct {
+	conf       *config.Config
+	folderName string
+	remoteConf *config.RemoteConfig
+	retriever  versionmanager.ReleaseInfoRetriever
+}
+
+func Make(conf *config.Config, remoteConf *config.RemoteConfig, retriever versionmanager.ReleaseInfoRetriever, folderName string) CacheRetriever {
+	return CacheRetriever{conf: conf, folderName: folderName, remoteConf: remoteConf, retriever: retriever}
+}
+
+func (r CacheRetriever) InstallRelease(version string, targetPath string) error {
+	return r.retriever.InstallRelease(version, targetPath)
+}
+
+func (r CacheRetriever) ListReleases() ([]string, error) {
+	versions, _, err := r.ListDatedReleases()
+
+	return versions, err
+}
+
+// ListDatedReleases returns publish dates when the wrapped retriever gives them.
+func (r CacheRetriever) ListDatedReleases() ([]string, map[string]time.Time, error) {
+	content, ok, err := r.cached()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if ok {
+		return content.Versions, content.Dates, nil
+	}
+
+	versions, dates, err := r.innerList()
+	if err == nil && r.enabled() {
+		r.write(cacheContent{Dates: dates, Fetched: time.Now(), Source: r.source(), Versions: versions})
+	}
+
+	return versions, dates, err
+}
+
+// FindRelease search in a valid cached list, otherwise it is delegated to the wrapped retriever (without filling the cache).
+func (r CacheRetriever) FindRelease(match func(string) bool) (string, error) {
+	finder, ok := r.retriever.(versionmanager.ReleaseFinder)
+	if !ok {
+		versions, err := r.ListReleases()
+
+		return versionmanager.HighestMatching(versions, match), err
+	}
+
+	content, ok, err := r.cached()
+	if err != nil {
+		return "", err
+	}
+
+	if ok {
+		return versionmanager.HighestMatching(content.Versions, match), nil
+	}
+
+	return finder.FindRelease(match)
+}
+
+func (r CacheRetriever) ProbeSidecars(version string) (bool, bool, error) {
+	prober, ok := r.retriever.(versionmanager.SidecarProber)
+	if !ok {
+		return false, false, versionmanager.ErrNoProbe
+	}
+
+	return prober.ProbeSidecars(version)
+}
+
+// cached returns false when the cache is disabled, refreshed or not valid (see read).
+func (r CacheRetriever) cached() (cacheContent, bool, error) {
+	if !r.enabled() || r.conf.RefreshCache {
+		return cacheContent{}, false, nil
+	}
+
+	if err := r.conf.InitRemoteConf(); err != nil {
+		return cacheContent{}, false, err
+	}
+
+	content, ok := r.read(r.source())
+	if ok {
+		r.conf.Displayer.Display(loghelper.Concat("Use cached ", r.folderName, " releases list (fetched at ", content.Fetched.Format(time.RFC3339), ")"))
+	}
+
+	return content, ok, nil
+}
+
+func (r CacheRetriever) enabled() bool {
+	return r.conf.RemoteCacheTTL > 0 && !r.conf.NoCache
+}
+
+func (r CacheRetriever) filePath() string {
+	return r.conf.UserStatePath(r.folderName, versionmanager.RemoteCacheFileName)
+}
+
+func (r CacheRetriever) innerList() ([]string, map[string]time.Time, error) {
+	if datedLister, ok := r.retriever.(versionmanager.DatedReleaseLister); ok {
+		return datedLister.ListDatedReleases()
+	}
+
+	versions, err := r.retriever.ListReleases()
+
+	return versions, nil, err
+}
+
+// return false when the cache is missing, expired or built from another list location.
+func (r CacheRetriever) read(source string) (cacheContent, bool) {
+	var content cacheContent
+	data, err := os.ReadFile(r.filePath())
+	if err != nil {
+		r.conf.Displayer.Log(loghelper.LevelWarnOrDebug(errors.Is(err, fs.ErrNotExist)), "Unable to read remote releases cache", loghelper.Error, err)
+
+		return content, false
+	}
+
+	if err = json.Unmarshal(data, &content); err != nil {
+		r.conf.Displayer.Log(hclog.Warn, "Unable to parse remote releases cache", loghelper.Error, err)
+
+		return content, false
+	}
+
+	return content, content.Source == source && time.Since(content.Fetched) < r.conf.RemoteCacheTTL
+}
+
+func (r CacheRetriever) source() string {
+	return strings.Join([]string{r.remoteConf.GetListMode(), r.remoteConf.GetListURL(), r.remoteConf.GetMirrorURL(), r.remoteConf.GetBucketURL(), r.remoteConf.GetOfflinePath()}, " ")
+}
+
+// best effort, a failure only disable the cache.
+func (r CacheRetriever) write(content cacheContent) {
+	data, err := json.Marshal(content)
+	if err != nil {
+		r.conf.Displayer.Log(hclog.Warn, "Unable to serialize remote releases cache", loghelper.Error, err)
+
+		return
+	}
+
+	filePath := r.filePath()
+	if err = os.MkdirAll(filepath.Dir(filePath), fileperm.DirMode()); err == nil {
+		err = os.WriteFile(filePath, data, fileperm.FileMode())
+	}
+
+	if err != nil {
+		r.conf.Displayer.Log(hclog.Warn, "Unable to write remote releases cache", loghelper.Error, err)
+	}
+}
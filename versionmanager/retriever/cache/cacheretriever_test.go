@@ -0,0 +1,131 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cacheretriever_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager"
+	cacheretriever "github.com/tofuutils/tenv/v2/versionmanager/retriever/cache"
+)
+
+type countingRetriever struct {
+	calls    *int
+	versions []string
+}
+
+func (countingRetriever) InstallRelease(string, string) error {
+	return nil
+}
+
+func (r countingRetriever) ListReleases() ([]string, error) {
+	*r.calls++
+
+	return slices.Clone(r.versions), nil
+}
+
+func TestListReleases(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	conf := &config.Config{Displayer: loghelper.InertDisplayer, RemoteCacheTTL: time.Hour, RootPath: t.TempDir()}
+	retriever := cacheretriever.Make(conf, &conf.Tofu, countingRetriever{calls: &calls, versions: []string{"1.6.0", "1.6.1"}}, "OpenTofu")
+
+	for _, refresh := range []bool{false, false, true} {
+		conf.RefreshCache = refresh
+		versions, err := retriever.ListReleases()
+		if err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+
+		if !slices.Equal(versions, []string{"1.6.0", "1.6.1"}) {
+			t.Error("Unmatching results, get :", versions)
+		}
+	}
+
+	if calls != 2 {
+		t.Error("Unmatching remote calls count, get :", calls)
+	}
+
+	conf.NoCache = true
+	if _, err := retriever.ListReleases(); err != nil || calls != 3 {
+		t.Error("Cache should be bypassed, get :", calls, err)
+	}
+}
+
+type findingRetriever struct {
+	countingRetriever
+	finds *int
+}
+
+func (r findingRetriever) FindRelease(match func(string) bool) (string, error) {
+	*r.finds++
+
+	return versionmanager.HighestMatching(r.versions, match), nil
+}
+
+func TestFindRelease(t *testing.T) {
+	t.Parallel()
+
+	calls, finds := 0, 0
+	conf := &config.Config{Displayer: loghelper.InertDisplayer, RemoteCacheTTL: time.Hour, RootPath: t.TempDir()}
+	retriever := cacheretriever.Make(conf, &conf.Tofu, findingRetriever{countingRetriever: countingRetriever{calls: &calls, versions: []string{"1.6.0", "1.6.1"}}, finds: &finds}, "OpenTofu")
+
+	match := func(string) bool { return true }
+	// without cached list, search is delegated
+	if found, err := retriever.FindRelease(match); err != nil || found != "1.6.1" || finds != 1 || calls != 0 {
+		t.Error("Unmatching results, get :", found, err, finds, calls)
+	}
+
+	if _, err := retriever.ListReleases(); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	// search in cached list
+	if found, err := retriever.FindRelease(match); err != nil || found != "1.6.1" || finds != 1 || calls != 1 {
+		t.Error("Unmatching results, get :", found, err, finds, calls)
+	}
+}
+
+func TestListReleasesOfflineSource(t *testing.T) { //nolint:paralleltest // set TENV_OFFLINE_SOURCE
+	rootPath := t.TempDir()
+	calls := 0
+	for _, offlineSource := range []string{t.TempDir(), t.TempDir()} {
+		t.Setenv("TENV_OFFLINE_SOURCE", offlineSource)
+
+		conf, err := config.InitConfigFromEnv()
+		if err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+		conf.Displayer, conf.RemoteCacheTTL, conf.RootPath = loghelper.InertDisplayer, time.Hour, rootPath
+
+		// cached list of another offline source must not be used
+		if _, err = cacheretriever.Make(&conf, &conf.Tofu, countingRetriever{calls: &calls, versions: []string{"1.6.0"}}, "OpenTofu").ListReleases(); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+	}
+
+	if calls != 2 {
+		t.Error("Unmatching remote calls count, get :", calls)
+	}
+}
@@ -0,0 +1,231 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package conftestretriever
+
+import (
+	"net/url"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/config/cmdconst"
+	"github.com/tofuutils/tenv/v2/pkg/apimsg"
+	"github.com/tofuutils/tenv/v2/pkg/download"
+	"github.com/tofuutils/tenv/v2/pkg/extract"
+	"github.com/tofuutils/tenv/v2/pkg/github"
+	"github.com/tofuutils/tenv/v2/pkg/pathfilter"
+	"github.com/tofuutils/tenv/v2/pkg/winbin"
+	"github.com/tofuutils/tenv/v2/versionmanager"
+	"github.com/tofuutils/tenv/v2/versionmanager/manifest"
+	htmlretriever "github.com/tofuutils/tenv/v2/versionmanager/retriever/html"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+const (
+	amd64Arch       = "amd64"
+	baseFileName    = "conftest_"
+	policyAgentName = "open-policy-agent"
+	sumsAssetName   = "checksums.txt"
+	tarGzSuffix     = ".tar.gz"
+	x8664Arch       = "x86_64"
+	zipSuffix       = ".zip"
+)
+
+type ConftestRetriever struct {
+	conf *config.Config
+}
+
+func Make(conf *config.Config) ConftestRetriever {
+	return ConftestRetriever{conf: conf}
+}
+
+func (r ConftestRetriever) InstallRelease(versionStr string, targetPath string) error {
+	err := r.conf.InitRemoteConf()
+	if err != nil {
+		return err
+	}
+
+	tag := versionStr
+	// assume that conftest tags start with a 'v'
+	// and version in asset name does not
+	if tag[0] == 'v' {
+		versionStr = versionStr[1:]
+	} else {
+		tag = "v" + versionStr
+	}
+
+	var assetURLs []string
+	var requestOptions []download.RequestOption
+	fileName := buildAssetName(versionStr, r.conf.Arch)
+	if r.conf.Displayer.IsDebug() {
+		r.conf.Displayer.Log(hclog.Debug, apimsg.MsgSearch, apimsg.AssetsName, []string{fileName, sumsAssetName})
+	}
+
+	switch r.conf.Conftest.GetInstallMode() {
+	case config.InstallModeDirect:
+		baseAssetURL, err2 := url.JoinPath(r.conf.Conftest.GetRemoteURL(), policyAgentName, cmdconst.ConftestName, github.Releases, github.Download, tag) //nolint
+		if err2 != nil {
+			return err2
+		}
+
+		assetURLs, err = htmlretriever.BuildAssetURLs(baseAssetURL, fileName, sumsAssetName)
+	case config.ModeAPI:
+		githubToken := r.conf.ToolGithubToken(r.conf.Conftest, r.conf.Conftest.GetRemoteURL())
+		assetURLs, err = github.AssetDownloadURL(tag, []string{fileName, sumsAssetName}, r.conf.Conftest.GetRemoteURL(), githubToken, r.conf.GithubAssetAPI, r.conf.Displayer.Display)
+		requestOptions = github.AssetRequestOptions(githubToken, r.conf.GithubAssetAPI)
+	default:
+		return config.ErrInstallMode
+	}
+	if err != nil {
+		return err
+	}
+
+	urlTranformer := download.UrlTranformer(r.conf.Conftest.GetRewriteRule())
+	assetURLs, err = download.ApplyUrlTranformer(urlTranformer, assetURLs...)
+	if err != nil {
+		return err
+	}
+
+	format := extract.TarGz
+	if runtime.GOOS == winbin.OsName {
+		format = extract.Zip
+	}
+	pending := format.Start(assetURLs[0], r.conf.StreamExtract, r.conf.Displayer.Display, requestOptions...)
+	defer pending.Discard()
+
+	dataSums, err := download.Bytes(assetURLs[1], r.conf.Displayer.Display, requestOptions...)
+	if err != nil {
+		return err
+	}
+
+	if err = pending.Install(dataSums, fileName, targetPath, pathfilter.NameEqual(winbin.GetBinaryName(cmdconst.ConftestName))); err != nil {
+		return err
+	}
+	manifest.Write(targetPath, manifest.Manifest{Checksum: true, Signature: manifest.SignatureUnavailable, Source: assetURLs[0]}, r.conf.Displayer)
+
+	return nil
+}
+
+// FindRelease stops paging at the first page with a matching release in API list mode (other modes list all releases).
+func (r ConftestRetriever) FindRelease(match func(string) bool) (string, error) {
+	err := r.conf.InitRemoteConf()
+	if err != nil {
+		return "", err
+	}
+
+	if r.conf.Conftest.GetListMode() != config.ModeAPI {
+		releases, err := r.ListReleases()
+
+		return versionmanager.HighestMatching(releases, match), err
+	}
+
+	listURL := r.conf.Conftest.GetListURL()
+	r.conf.Displayer.Display(apimsg.MsgFetchReleases + listURL)
+
+	return github.FindRelease(listURL, r.conf.ToolGithubToken(r.conf.Conftest, listURL), match)
+}
+
+func (r ConftestRetriever) ListReleases() ([]string, error) {
+	releases, _, err := r.ListDatedReleases()
+
+	return releases, err
+}
+
+// ListDatedReleases returns publish dates only in API list mode.
+func (r ConftestRetriever) ListDatedReleases() ([]string, map[string]time.Time, error) {
+	err := r.conf.InitRemoteConf()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	listURL := r.conf.Conftest.GetListURL()
+	switch r.conf.Conftest.GetListMode() {
+	case config.ListModeHTML:
+		baseURL, err := url.JoinPath(listURL, policyAgentName, cmdconst.ConftestName, github.Releases, github.Download) //nolint
+		if err != nil {
+			return nil, nil, err
+		}
+
+		r.conf.Displayer.Display(apimsg.MsgFetchAllReleases + baseURL)
+		releases, err := htmlretriever.ListReleases(baseURL, r.conf.Conftest.Data)
+
+		return releases, nil, err
+	case config.ModeAPI:
+		r.conf.Displayer.Display(apimsg.MsgFetchAllReleases + listURL)
+
+		return github.ListDatedReleases(listURL, r.conf.ToolGithubToken(r.conf.Conftest, listURL))
+	default:
+		return nil, nil, config.ErrListMode
+	}
+}
+
+// ProbeSidecars checks that checksum file is published (conftest releases are not signed).
+func (r ConftestRetriever) ProbeSidecars(versionStr string) (bool, bool, error) {
+	err := r.conf.InitRemoteConf()
+	if err != nil {
+		return false, false, err
+	}
+
+	tag, _ := github.SplitTag(versionStr)
+
+	baseURL := github.BaseURL
+	if r.conf.Conftest.GetInstallMode() == config.InstallModeDirect {
+		baseURL = r.conf.Conftest.GetRemoteURL()
+	}
+
+	sumsURL, err := url.JoinPath(baseURL, policyAgentName, cmdconst.ConftestName, github.Releases, github.Download, tag, sumsAssetName) //nolint
+	if err != nil {
+		return false, false, err
+	}
+
+	sumsURL, err = download.UrlTranformer(r.conf.Conftest.GetRewriteRule())(sumsURL)
+	if err != nil {
+		return false, false, err
+	}
+
+	found, err := download.Exists(sumsURL)
+
+	return found, false, err
+}
+
+// conftest assets use capitalized os name and uname style arch (like conftest_0.56.0_Linux_x86_64.tar.gz).
+func buildAssetName(version string, arch string) string {
+	var nameBuilder strings.Builder
+	nameBuilder.WriteString(baseFileName)
+	nameBuilder.WriteString(version)
+	nameBuilder.WriteByte('_')
+	nameBuilder.WriteString(strings.ToUpper(runtime.GOOS[:1]))
+	nameBuilder.WriteString(runtime.GOOS[1:])
+	nameBuilder.WriteByte('_')
+	if arch == amd64Arch {
+		nameBuilder.WriteString(x8664Arch)
+	} else {
+		nameBuilder.WriteString(arch)
+	}
+
+	if runtime.GOOS == winbin.OsName {
+		nameBuilder.WriteString(zipSuffix)
+	} else {
+		nameBuilder.WriteString(tarGzSuffix)
+	}
+
+	return nameBuilder.String()
+}
@@ -0,0 +1,184 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package conftestretriever_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/config"
+	sha256check "github.com/tofuutils/tenv/v2/pkg/check/sha256"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager/manifest"
+	conftestretriever "github.com/tofuutils/tenv/v2/versionmanager/retriever/conftest"
+)
+
+const binaryContent = "conftest binary"
+
+func TestInstallRelease(t *testing.T) {
+	t.Parallel()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("windows assets are zip archives")
+	}
+
+	archiveData := buildTarGz(t, "conftest", binaryContent)
+	sum := sha256.Sum256(archiveData)
+	osName := strings.ToUpper(runtime.GOOS[:1]) + runtime.GOOS[1:]
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		dirPath, name := filepath.Split(request.URL.Path)
+		switch {
+		case dirPath == "/open-policy-agent/conftest/releases/download/v0.56.0/" && name == "checksums.txt":
+			_, _ = writer.Write([]byte(hex.EncodeToString(sum[:]) + "  conftest_0.56.0_" + osName + "_x86_64.tar.gz\n" +
+				hex.EncodeToString(make([]byte, sha256.Size)) + "  conftest_0.56.0_" + osName + "_arm64.tar.gz\n"))
+		case strings.HasPrefix(name, "conftest_0.56.0_"+osName+"_"):
+			_, _ = writer.Write(archiveData)
+		default:
+			writer.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	confPath := filepath.Join(t.TempDir(), "remote.yaml")
+	if err := os.WriteFile(confPath, []byte("conftest:\n  url: "+server.URL+"\n  install_mode: direct\n"), 0o600); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	tests := []struct {
+		name     string
+		version  string
+		arch     string
+		wantErr  bool
+		checkErr bool
+	}{
+		{name: "Amd64", version: "0.56.0", arch: "amd64"},
+		{name: "TagVersion", version: "v0.56.0", arch: "amd64"},
+		{name: "UnmatchingChecksum", version: "0.56.0", arch: "arm64", wantErr: true, checkErr: true},
+		{name: "MissingRelease", version: "0.57.0", arch: "amd64", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := &config.Config{Arch: tt.arch, Displayer: loghelper.InertDisplayer, RemoteConfPath: confPath}
+			targetPath := filepath.Join(t.TempDir(), tt.version)
+			err := conftestretriever.Make(conf).InstallRelease(tt.version, targetPath)
+			if tt.wantErr {
+				if err == nil || (tt.checkErr && !errors.Is(err, sha256check.ErrCheck)) {
+					t.Error("Should fail, get :", err)
+				}
+
+				if _, err = os.Stat(filepath.Join(targetPath, "conftest")); !os.IsNotExist(err) {
+					t.Error("Nothing should be installed, get :", err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal("Unexpected error :", err)
+			}
+
+			if data, err := os.ReadFile(filepath.Join(targetPath, "conftest")); err != nil || string(data) != binaryContent {
+				t.Error("Unmatching results, get :", string(data), err)
+			}
+
+			if installManifest, found := manifest.Read(targetPath, loghelper.InertDisplayer); !found || !installManifest.Checksum || installManifest.Signature != manifest.SignatureUnavailable {
+				t.Error("Unmatching manifest, get :", installManifest, found)
+			}
+		})
+	}
+}
+
+func TestProbeSidecars(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path != "/open-policy-agent/conftest/releases/download/v0.56.0/checksums.txt" {
+			writer.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	confPath := filepath.Join(t.TempDir(), "remote.yaml")
+	if err := os.WriteFile(confPath, []byte("conftest:\n  url: "+server.URL+"\n  install_mode: direct\n"), 0o600); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	tests := []struct {
+		name      string
+		version   string
+		wantFound bool
+	}{
+		{name: "Published", version: "0.56.0", wantFound: true},
+		{name: "PublishedTag", version: "v0.56.0", wantFound: true},
+		{name: "Missing", version: "0.55.0"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := &config.Config{Arch: "amd64", Displayer: loghelper.InertDisplayer, RemoteConfPath: confPath}
+			found, signed, err := conftestretriever.Make(conf).ProbeSidecars(tt.version)
+			if err != nil || found != tt.wantFound || signed {
+				t.Error("Unmatching results, get :", found, signed, err)
+			}
+		})
+	}
+}
+
+func buildTarGz(t *testing.T, name string, content string) []byte {
+	t.Helper()
+
+	var buffer bytes.Buffer
+	gzipWriter := gzip.NewWriter(&buffer)
+	tarWriter := tar.NewWriter(gzipWriter)
+	if err := tarWriter.WriteHeader(&tar.Header{Name: name, Mode: 0o755, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if _, err := tarWriter.Write([]byte(content)); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	return buffer.Bytes()
+}
@@ -19,6 +19,7 @@
 package htmlretriever
 
 import (
+	"bytes"
 	"net/url"
 
 	"github.com/PuerkitoBio/goquery"
@@ -38,11 +39,24 @@ func BuildAssetURLs(baseAssetURL string, assetNames ...string) ([]string, error)
 }
 
 func ListReleases(baseURL string, remoteConf map[string]string) ([]string, error) {
+	selector, versionExtractor := buildExtractor(remoteConf)
+
+	return htmlquery.Request(baseURL, selector, versionExtractor)
+}
+
+// ParseReleases works like ListReleases on an already downloaded page.
+func ParseReleases(data []byte, remoteConf map[string]string) ([]string, error) {
+	selector, versionExtractor := buildExtractor(remoteConf)
+
+	return htmlquery.Extract(bytes.NewReader(data), selector, versionExtractor)
+}
+
+func buildExtractor(remoteConf map[string]string) (string, func(*goquery.Selection) string) {
 	selector := config.MapGetDefault(remoteConf, "selector", "a")
 	extractor := htmlquery.SelectionExtractor(config.MapGetDefault(remoteConf, "part", "href"))
 	versionExtractor := func(s *goquery.Selection) string {
 		return versionfinder.Find(extractor(s))
 	}
 
-	return htmlquery.Request(baseURL, selector, versionExtractor)
+	return selector, versionExtractor
 }
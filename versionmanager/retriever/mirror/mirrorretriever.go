@@ -0,0 +1,154 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package mirrorretriever
+
+import (
+	"encoding/json"
+	"net/url"
+
+	"github.com/hashicorp/go-hclog"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/apimsg"
+	"github.com/tofuutils/tenv/v2/pkg/download"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager"
+	htmlretriever "github.com/tofuutils/tenv/v2/versionmanager/retriever/html"
+	versionfinder "github.com/tofuutils/tenv/v2/versionmanager/semantic/finder"
+)
+
+const indexFileName = "index.json"
+
+// MirrorRetriever lists releases from a generic repository (like Artifactory) mirroring upstream layout,
+// install is delegated to the tool retriever (in direct mode toward the mirror, so checksum and signature are still checked).
+type MirrorRetriever struct {
+	conf         *config.Config
+	releasesPath []string
+	remoteConf   *config.RemoteConfig
+	retriever    versionmanager.ReleaseInfoRetriever
+}
+
+func Make(conf *config.Config, remoteConf *config.RemoteConfig, retriever versionmanager.ReleaseInfoRetriever, releasesPath ...string) MirrorRetriever {
+	return MirrorRetriever{conf: conf, releasesPath: releasesPath, remoteConf: remoteConf, retriever: retriever}
+}
+
+func (r MirrorRetriever) InstallRelease(version string, targetPath string) error {
+	return r.retriever.InstallRelease(version, targetPath)
+}
+
+// ListReleases use the JSON manifest (index.json) of releases directory when present, and its directory index otherwise.
+func (r MirrorRetriever) ListReleases() ([]string, error) {
+	err := r.conf.InitRemoteConf()
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL, err := url.JoinPath(r.remoteConf.GetListURL(), r.releasesPath...) //nolint
+	if err != nil {
+		return nil, err
+	}
+
+	indexURL, err := url.JoinPath(baseURL, indexFileName) //nolint
+	if err != nil {
+		return nil, err
+	}
+
+	r.conf.Displayer.Display(apimsg.MsgFetchAllReleases + baseURL)
+	data, err := download.Bytes(indexURL, loghelper.InertDisplayer.Display)
+	switch err {
+	case nil:
+		return parseIndex(data)
+	case download.ErrNotFound:
+		r.conf.Displayer.Log(hclog.Debug, "No JSON manifest in mirror, fallback to directory index", "url", indexURL)
+	default:
+		return nil, err
+	}
+
+	data, err = download.Bytes(baseURL, loghelper.InertDisplayer.Display)
+	if err != nil {
+		return nil, err
+	}
+
+	return htmlretriever.ParseReleases(data, r.remoteConf.Data)
+}
+
+func (r MirrorRetriever) ProbeSidecars(version string) (bool, bool, error) {
+	prober, ok := r.retriever.(versionmanager.SidecarProber)
+	if !ok {
+		return false, false, versionmanager.ErrNoProbe
+	}
+
+	return prober.ProbeSidecars(version)
+}
+
+type indexObject struct {
+	Children []struct {
+		URI string `json:"uri"`
+	} `json:"children"`
+	Versions json.RawMessage `json:"versions"`
+}
+
+// accepted manifests : a list of versions, an object with a "versions" list or mapping (like HashiCorp index.json),
+// or an Artifactory storage API folder info (with "children" entries).
+func parseIndex(data []byte) ([]string, error) {
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		var index indexObject
+		if err = json.Unmarshal(data, &index); err != nil {
+			return nil, err
+		}
+
+		for _, child := range index.Children {
+			names = append(names, child.URI)
+		}
+
+		if len(index.Versions) != 0 {
+			if names, err = appendVersionNames(names, index.Versions); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	versions := make([]string, 0, len(names))
+	for _, name := range names {
+		if version := versionfinder.Find(name); version != "" {
+			versions = append(versions, version)
+		}
+	}
+
+	return versions, nil
+}
+
+func appendVersionNames(names []string, rawVersions json.RawMessage) ([]string, error) {
+	var versionList []string
+	if err := json.Unmarshal(rawVersions, &versionList); err == nil {
+		return append(names, versionList...), nil
+	}
+
+	var versionMap map[string]json.RawMessage
+	if err := json.Unmarshal(rawVersions, &versionMap); err != nil {
+		return nil, err
+	}
+
+	for version := range versionMap {
+		names = append(names, version)
+	}
+
+	return names, nil
+}
@@ -0,0 +1,89 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package mirrorretriever_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	mirrorretriever "github.com/tofuutils/tenv/v2/versionmanager/retriever/mirror"
+)
+
+const directoryIndex = `<html><body><pre><a href="../">../</a>
+<a href="v1.6.0/">v1.6.0/</a>
+<a href="v1.6.1/">v1.6.1/</a>
+</pre></body></html>`
+
+func TestListReleases(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]string{
+		"artifactory": `{"repo": "generic", "children": [{"uri": "/v1.6.0", "folder": true}, {"uri": "/v1.6.1", "folder": true}]}`,
+		"hashicorp":   `{"name": "terraform", "versions": {"1.6.0": {}, "1.6.1": {}}}`,
+		"html":        "",
+		"list":        `["1.6.0", "v1.6.1"]`,
+	}
+
+	for name, indexData := range testCases {
+		indexData := indexData
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				switch request.URL.Path {
+				case "/tool/releases/index.json":
+					if indexData == "" {
+						writer.WriteHeader(http.StatusNotFound)
+
+						return
+					}
+					_, _ = writer.Write([]byte(indexData))
+				case "/tool/releases":
+					_, _ = writer.Write([]byte(directoryIndex))
+				default:
+					writer.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			confPath := filepath.Join(t.TempDir(), "remote.yaml")
+			if err := os.WriteFile(confPath, []byte("tofu:\n  list_url: "+server.URL+"\n"), 0o600); err != nil {
+				t.Fatal("Unexpected error :", err)
+			}
+
+			conf := &config.Config{Displayer: loghelper.InertDisplayer, RemoteConfPath: confPath}
+			retriever := mirrorretriever.Make(conf, &conf.Tofu, nil, "tool", "releases")
+			versions, err := retriever.ListReleases()
+			if err != nil {
+				t.Fatal("Unexpected error :", err)
+			}
+
+			slices.Sort(versions)
+			if !slices.Equal(versions, []string{"1.6.0", "1.6.1"}) {
+				t.Error("Unmatching results, get :", versions)
+			}
+		})
+	}
+}
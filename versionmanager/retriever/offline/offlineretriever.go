@@ -0,0 +1,88 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package offlineretriever
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/apimsg"
+	"github.com/tofuutils/tenv/v2/versionmanager"
+	versionfinder "github.com/tofuutils/tenv/v2/versionmanager/semantic/finder"
+)
+
+// OfflineRetriever lists releases from version directories of a local directory (<version>/<asset> files),
+// install is delegated to the tool retriever (its release urls are rewritten toward the local directory, so checksum and signature are still checked).
+type OfflineRetriever struct {
+	conf       *config.Config
+	remoteConf *config.RemoteConfig
+	retriever  versionmanager.ReleaseInfoRetriever
+}
+
+func Make(conf *config.Config, remoteConf *config.RemoteConfig, retriever versionmanager.ReleaseInfoRetriever) OfflineRetriever {
+	return OfflineRetriever{conf: conf, remoteConf: remoteConf, retriever: retriever}
+}
+
+func (r OfflineRetriever) InstallRelease(version string, targetPath string) error {
+	return r.retriever.InstallRelease(version, targetPath)
+}
+
+// ListReleases returns versions of directories containing at least one archive.
+func (r OfflineRetriever) ListReleases() ([]string, error) {
+	err := r.conf.InitRemoteConf()
+	if err != nil {
+		return nil, err
+	}
+
+	offlinePath := r.remoteConf.GetOfflinePath()
+	r.conf.Displayer.Display(apimsg.MsgFetchAllReleases + offlinePath)
+
+	entries, err := os.ReadDir(offlinePath)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		version := versionfinder.Find(entry.Name())
+		if version == "" {
+			continue
+		}
+
+		if assetEntries, err := os.ReadDir(filepath.Join(offlinePath, entry.Name())); err == nil && len(assetEntries) != 0 {
+			versions = append(versions, version)
+		}
+	}
+
+	return versions, nil
+}
+
+func (r OfflineRetriever) ProbeSidecars(version string) (bool, bool, error) {
+	prober, ok := r.retriever.(versionmanager.SidecarProber)
+	if !ok {
+		return false, false, versionmanager.ErrNoProbe
+	}
+
+	return prober.ProbeSidecars(version)
+}
@@ -0,0 +1,74 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package offlineretriever_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	offlineretriever "github.com/tofuutils/tenv/v2/versionmanager/retriever/offline"
+)
+
+func TestListReleases(t *testing.T) {
+	offlineSource := t.TempDir()
+	t.Setenv("TENV_OFFLINE_SOURCE", offlineSource)
+
+	for _, relPath := range []string{"v1.6.0/tofu_1.6.0_linux_amd64.zip", "v1.6.1/tofu_1.6.1_linux_amd64.zip", "v1.7.0/", "notes/readme.txt"} {
+		path := filepath.Join(offlineSource, "tofu", relPath)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+
+		if !strings.HasSuffix(relPath, "/") {
+			if err := os.WriteFile(path, []byte("archive"), 0o644); err != nil {
+				t.Fatal("Unexpected error :", err)
+			}
+		}
+	}
+
+	conf, err := config.InitConfigFromEnv()
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+	conf.Displayer = loghelper.InertDisplayer
+
+	if installMode := conf.Tofu.GetInstallMode(); installMode != config.InstallModeDirect {
+		t.Error("Unmatching install mode, get :", installMode)
+	}
+
+	rewriteRule := conf.Tofu.GetRewriteRule()
+	if len(rewriteRule) != 2 || !strings.HasPrefix(rewriteRule[1], "file://") {
+		t.Error("Unmatching rewrite rule, get :", rewriteRule)
+	}
+
+	versions, err := offlineretriever.Make(&conf, &conf.Tofu, nil).ListReleases()
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	slices.Sort(versions)
+	if !slices.Equal(versions, []string{"1.6.0", "1.6.1"}) {
+		t.Error("Unmatching results, get :", versions)
+	}
+}
@@ -0,0 +1,218 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package oparetriever
+
+import (
+	"net/url"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/config/cmdconst"
+	"github.com/tofuutils/tenv/v2/pkg/apimsg"
+	"github.com/tofuutils/tenv/v2/pkg/download"
+	"github.com/tofuutils/tenv/v2/pkg/extract"
+	"github.com/tofuutils/tenv/v2/pkg/github"
+	"github.com/tofuutils/tenv/v2/pkg/winbin"
+	"github.com/tofuutils/tenv/v2/versionmanager"
+	"github.com/tofuutils/tenv/v2/versionmanager/manifest"
+	htmlretriever "github.com/tofuutils/tenv/v2/versionmanager/retriever/html"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+const (
+	arm64Arch       = "arm64"
+	baseFileName    = "opa_"
+	policyAgentName = "open-policy-agent"
+	sha256Suffix    = ".sha256"
+	staticSuffix    = "_static"
+)
+
+type OpaRetriever struct {
+	conf *config.Config
+}
+
+func Make(conf *config.Config) OpaRetriever {
+	return OpaRetriever{conf: conf}
+}
+
+func (r OpaRetriever) InstallRelease(versionStr string, targetPath string) error {
+	err := r.conf.InitRemoteConf()
+	if err != nil {
+		return err
+	}
+
+	tag := versionStr
+	if tag[0] != 'v' {
+		tag = "v" + versionStr
+	}
+
+	var assetURLs []string
+	var requestOptions []download.RequestOption
+	fileName, shaFileName := buildAssetNames(r.conf.Arch)
+	if r.conf.Displayer.IsDebug() {
+		r.conf.Displayer.Log(hclog.Debug, apimsg.MsgSearch, apimsg.AssetsName, []string{fileName, shaFileName})
+	}
+
+	switch r.conf.Opa.GetInstallMode() {
+	case config.InstallModeDirect:
+		baseAssetURL, err2 := url.JoinPath(r.conf.Opa.GetRemoteURL(), policyAgentName, cmdconst.OpaName, github.Releases, github.Download, tag) //nolint
+		if err2 != nil {
+			return err2
+		}
+
+		assetURLs, err = htmlretriever.BuildAssetURLs(baseAssetURL, fileName, shaFileName)
+	case config.ModeAPI:
+		githubToken := r.conf.ToolGithubToken(r.conf.Opa, r.conf.Opa.GetRemoteURL())
+		assetURLs, err = github.AssetDownloadURL(tag, []string{fileName, shaFileName}, r.conf.Opa.GetRemoteURL(), githubToken, r.conf.GithubAssetAPI, r.conf.Displayer.Display)
+		requestOptions = github.AssetRequestOptions(githubToken, r.conf.GithubAssetAPI)
+	default:
+		return config.ErrInstallMode
+	}
+	if err != nil {
+		return err
+	}
+
+	urlTranformer := download.UrlTranformer(r.conf.Opa.GetRewriteRule())
+	assetURLs, err = download.ApplyUrlTranformer(urlTranformer, assetURLs...)
+	if err != nil {
+		return err
+	}
+
+	format := extract.Raw(winbin.GetBinaryName(cmdconst.OpaName))
+	pending := format.Start(assetURLs[0], r.conf.StreamExtract, r.conf.Displayer.Display, requestOptions...)
+	defer pending.Discard()
+
+	dataSums, err := download.Bytes(assetURLs[1], r.conf.Displayer.Display, requestOptions...)
+	if err != nil {
+		return err
+	}
+
+	if err = pending.Install(dataSums, fileName, targetPath, nil); err != nil {
+		return err
+	}
+	manifest.Write(targetPath, manifest.Manifest{Checksum: true, Signature: manifest.SignatureUnavailable, Source: assetURLs[0]}, r.conf.Displayer)
+
+	return nil
+}
+
+// FindRelease stops paging at the first page with a matching release in API list mode (other modes list all releases).
+func (r OpaRetriever) FindRelease(match func(string) bool) (string, error) {
+	err := r.conf.InitRemoteConf()
+	if err != nil {
+		return "", err
+	}
+
+	if r.conf.Opa.GetListMode() != config.ModeAPI {
+		releases, err := r.ListReleases()
+
+		return versionmanager.HighestMatching(releases, match), err
+	}
+
+	listURL := r.conf.Opa.GetListURL()
+	r.conf.Displayer.Display(apimsg.MsgFetchReleases + listURL)
+
+	return github.FindRelease(listURL, r.conf.ToolGithubToken(r.conf.Opa, listURL), match)
+}
+
+func (r OpaRetriever) ListReleases() ([]string, error) {
+	releases, _, err := r.ListDatedReleases()
+
+	return releases, err
+}
+
+// ListDatedReleases returns publish dates only in API list mode.
+func (r OpaRetriever) ListDatedReleases() ([]string, map[string]time.Time, error) {
+	err := r.conf.InitRemoteConf()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	listURL := r.conf.Opa.GetListURL()
+	switch r.conf.Opa.GetListMode() {
+	case config.ListModeHTML:
+		baseURL, err := url.JoinPath(listURL, policyAgentName, cmdconst.OpaName, github.Releases, github.Download) //nolint
+		if err != nil {
+			return nil, nil, err
+		}
+
+		r.conf.Displayer.Display(apimsg.MsgFetchAllReleases + baseURL)
+		releases, err := htmlretriever.ListReleases(baseURL, r.conf.Opa.Data)
+
+		return releases, nil, err
+	case config.ModeAPI:
+		r.conf.Displayer.Display(apimsg.MsgFetchAllReleases + listURL)
+
+		return github.ListDatedReleases(listURL, r.conf.ToolGithubToken(r.conf.Opa, listURL))
+	default:
+		return nil, nil, config.ErrListMode
+	}
+}
+
+// ProbeSidecars checks that checksum file is published (opa releases are not signed).
+func (r OpaRetriever) ProbeSidecars(versionStr string) (bool, bool, error) {
+	err := r.conf.InitRemoteConf()
+	if err != nil {
+		return false, false, err
+	}
+
+	tag, _ := github.SplitTag(versionStr)
+
+	baseURL := github.BaseURL
+	if r.conf.Opa.GetInstallMode() == config.InstallModeDirect {
+		baseURL = r.conf.Opa.GetRemoteURL()
+	}
+
+	_, shaFileName := buildAssetNames(r.conf.Arch)
+	sumsURL, err := url.JoinPath(baseURL, policyAgentName, cmdconst.OpaName, github.Releases, github.Download, tag, shaFileName) //nolint
+	if err != nil {
+		return false, false, err
+	}
+
+	sumsURL, err = download.UrlTranformer(r.conf.Opa.GetRewriteRule())(sumsURL)
+	if err != nil {
+		return false, false, err
+	}
+
+	found, err := download.Exists(sumsURL)
+
+	return found, false, err
+}
+
+// opa assets are versionless executables (like opa_linux_amd64), arm64 ones are only published as static builds,
+// each one has its own checksum file.
+func buildAssetNames(arch string) (string, string) {
+	var nameBuilder strings.Builder
+	nameBuilder.WriteString(baseFileName)
+	nameBuilder.WriteString(runtime.GOOS)
+	nameBuilder.WriteByte('_')
+	nameBuilder.WriteString(arch)
+	if arch == arm64Arch {
+		nameBuilder.WriteString(staticSuffix)
+	}
+	if runtime.GOOS == winbin.OsName {
+		nameBuilder.WriteString(winbin.Suffix)
+	}
+
+	fileName := nameBuilder.String()
+
+	return fileName, fileName + sha256Suffix
+}
@@ -0,0 +1,164 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package oparetriever_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/config"
+	sha256check "github.com/tofuutils/tenv/v2/pkg/check/sha256"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/pkg/winbin"
+	"github.com/tofuutils/tenv/v2/versionmanager/manifest"
+	oparetriever "github.com/tofuutils/tenv/v2/versionmanager/retriever/opa"
+)
+
+const binaryContent = "opa binary"
+
+func TestInstallRelease(t *testing.T) {
+	t.Parallel()
+
+	// opa assets are raw executables, arm64 one is a static build with an unmatching checksum
+	sum := sha256.Sum256([]byte(binaryContent))
+	amd64Name := winbin.GetBinaryName("opa_" + runtime.GOOS + "_amd64")
+	arm64Name := winbin.GetBinaryName("opa_" + runtime.GOOS + "_arm64_static")
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if path.Dir(request.URL.Path) != "/open-policy-agent/opa/releases/download/v0.70.0" {
+			writer.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		switch path.Base(request.URL.Path) {
+		case amd64Name + ".sha256":
+			_, _ = writer.Write([]byte(hex.EncodeToString(sum[:]) + "  " + amd64Name + "\n"))
+		case arm64Name + ".sha256":
+			_, _ = writer.Write([]byte(hex.EncodeToString(make([]byte, sha256.Size)) + "  " + arm64Name + "\n"))
+		case amd64Name, arm64Name:
+			_, _ = writer.Write([]byte(binaryContent))
+		default:
+			writer.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	confPath := filepath.Join(t.TempDir(), "remote.yaml")
+	if err := os.WriteFile(confPath, []byte("opa:\n  url: "+server.URL+"\n  install_mode: direct\n"), 0o600); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	tests := []struct {
+		name     string
+		version  string
+		arch     string
+		wantErr  bool
+		checkErr bool
+	}{
+		{name: "Amd64", version: "0.70.0", arch: "amd64"},
+		{name: "TagVersion", version: "v0.70.0", arch: "amd64"},
+		{name: "StaticArm64", version: "0.70.0", arch: "arm64", wantErr: true, checkErr: true},
+		{name: "MissingRelease", version: "0.71.0", arch: "amd64", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := &config.Config{Arch: tt.arch, Displayer: loghelper.InertDisplayer, RemoteConfPath: confPath}
+			targetPath := filepath.Join(t.TempDir(), tt.version)
+			binaryPath := filepath.Join(targetPath, winbin.GetBinaryName("opa"))
+			err := oparetriever.Make(conf).InstallRelease(tt.version, targetPath)
+			if tt.wantErr {
+				if err == nil || (tt.checkErr && !errors.Is(err, sha256check.ErrCheck)) {
+					t.Error("Should fail, get :", err)
+				}
+
+				if _, err = os.Stat(binaryPath); !os.IsNotExist(err) {
+					t.Error("Nothing should be installed, get :", err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal("Unexpected error :", err)
+			}
+
+			if data, err := os.ReadFile(binaryPath); err != nil || string(data) != binaryContent {
+				t.Error("Unmatching results, get :", string(data), err)
+			}
+
+			if installManifest, found := manifest.Read(targetPath, loghelper.InertDisplayer); !found || !installManifest.Checksum || !strings.HasSuffix(installManifest.Source, "/"+amd64Name) {
+				t.Error("Unmatching manifest, get :", installManifest, found)
+			}
+		})
+	}
+}
+
+func TestProbeSidecars(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path != "/open-policy-agent/opa/releases/download/v0.70.0/"+winbin.GetBinaryName("opa_"+runtime.GOOS+"_arm64_static")+".sha256" {
+			writer.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	confPath := filepath.Join(t.TempDir(), "remote.yaml")
+	if err := os.WriteFile(confPath, []byte("opa:\n  url: "+server.URL+"\n  install_mode: direct\n"), 0o600); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	tests := []struct {
+		name      string
+		version   string
+		arch      string
+		wantFound bool
+	}{
+		{name: "StaticArm64", version: "0.70.0", arch: "arm64", wantFound: true},
+		{name: "TagVersion", version: "v0.70.0", arch: "arm64", wantFound: true},
+		{name: "OtherArch", version: "0.70.0", arch: "amd64"},
+		{name: "Missing", version: "0.69.0", arch: "arm64"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := &config.Config{Arch: tt.arch, Displayer: loghelper.InertDisplayer, RemoteConfPath: confPath}
+			found, signed, err := oparetriever.Make(conf).ProbeSidecars(tt.version)
+			if err != nil || found != tt.wantFound || signed {
+				t.Error("Unmatching results, get :", found, signed, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package provenance
+
+import (
+	"net/url"
+	"path"
+
+	"github.com/tofuutils/tenv/v2/config"
+	slsacheck "github.com/tofuutils/tenv/v2/pkg/check/slsa"
+	"github.com/tofuutils/tenv/v2/pkg/download"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager/manifest"
+)
+
+const (
+	// DefaultAssetName is the attestation name used by slsa-github-generator (overridden by provenance_asset field of remote configuration).
+	DefaultAssetName = "multiple.intoto.jsonl"
+
+	builderID = "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_generic_slsa3.yml"
+)
+
+// Verify download the provenance attestation published next to the checksums file and check it covers dataSums
+// (which covers the archive through its verified sha256), returns the value recorded in manifest.
+//
+// Nothing is done (and an empty value returned) when TENV_VERIFY_PROVENANCE is disabled or there is no checksums file.
+func Verify(conf *config.Config, remoteConf config.RemoteConfig, dataSums []byte, sumsURL string, sourceURI string, tag string, requestOptions ...download.RequestOption) (string, error) {
+	if !conf.CheckProvenance || dataSums == nil {
+		return "", nil
+	}
+
+	provenanceURL, err := siblingURL(sumsURL, config.MapGetDefault(remoteConf.Data, "provenance_asset", DefaultAssetName))
+	if err != nil {
+		return "", err
+	}
+
+	dataProvenance, err := download.Bytes(provenanceURL, conf.Displayer.Display, requestOptions...)
+	if err == download.ErrNotFound {
+		conf.Displayer.Display(loghelper.Concat("skip provenance check : no attestation published for ", tag))
+
+		return manifest.ProvenanceUnavailable, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	err = slsacheck.Check(dataSums, dataProvenance, sourceURI, tag, builderID, conf.Displayer)
+	if err == slsacheck.ErrNotInstalled {
+		conf.Displayer.Display("skip provenance check : slsa-verifier executable not found")
+
+		return manifest.ProvenanceSkipped, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return manifest.ProvenanceSLSA, nil
+}
+
+// replace the last path segment of fileURL (working on escaped path to keep encoded characters like %2F in parent segments).
+func siblingURL(fileURL string, name string) (string, error) {
+	parsedURL, err := url.Parse(fileURL)
+	if err != nil {
+		return "", err
+	}
+
+	rawPath := path.Join(path.Dir(parsedURL.EscapedPath()), url.PathEscape(name))
+	if parsedURL.Path, err = url.PathUnescape(rawPath); err != nil {
+		return "", err
+	}
+	parsedURL.RawPath = rawPath
+
+	return parsedURL.String(), nil
+}
@@ -0,0 +1,139 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package provenance
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/config"
+	slsacheck "github.com/tofuutils/tenv/v2/pkg/check/slsa"
+	"github.com/tofuutils/tenv/v2/pkg/feature"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager/manifest"
+)
+
+func TestSiblingURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		fileURL string
+		asset   string
+		want    string
+		wantErr bool
+	}{
+		{name: "Simple", fileURL: "https://example.com/v1.6.2/SHA256SUMS", asset: DefaultAssetName, want: "https://example.com/v1.6.2/multiple.intoto.jsonl"},
+		{name: "QueryKept", fileURL: "https://example.com/v1.6.2/SHA256SUMS?token=abc", asset: DefaultAssetName, want: "https://example.com/v1.6.2/multiple.intoto.jsonl?token=abc"},
+		{name: "EncodedParent", fileURL: "https://example.com/repo%2Fmirror/v1.6.2/SHA256SUMS", asset: DefaultAssetName, want: "https://example.com/repo%2Fmirror/v1.6.2/multiple.intoto.jsonl"},
+		{name: "EscapedAsset", fileURL: "https://example.com/v1.6.2/SHA256SUMS", asset: "tofu provenance.jsonl", want: "https://example.com/v1.6.2/tofu%20provenance.jsonl"},
+		{name: "Invalid", fileURL: "://example.com", asset: DefaultAssetName, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			res, err := siblingURL(tt.fileURL, tt.asset)
+			if (err != nil) != tt.wantErr || res != tt.want {
+				t.Error("Unmatching results, get :", res, err)
+			}
+		})
+	}
+}
+
+// modify PATH, so not parallel.
+func TestVerify(t *testing.T) { //nolint
+	if runtime.GOOS == "windows" {
+		t.Skip("fake slsa-verifier is a shell script")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if path.Dir(request.URL.Path) != "/v1.6.2" {
+			writer.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		switch path.Base(request.URL.Path) {
+		case DefaultAssetName, "valid.intoto.jsonl":
+			_, _ = writer.Write([]byte("valid"))
+		case "forged.intoto.jsonl":
+			_, _ = writer.Write([]byte("forged"))
+		default:
+			writer.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	// fake slsa-verifier accepting attestation with "valid" content (only shell builtins, PATH is restricted)
+	verifierPath := t.TempDir()
+	script := "#!/bin/sh\nread -r content < \"$3\"\nif [ \"$content\" = valid ]; then echo \"PASSED: Verified SLSA provenance\"; else echo \"FAILED: SLSA verification failed\"; fi\n"
+	if err := os.WriteFile(filepath.Join(verifierPath, "slsa-verifier"), []byte(script), 0o755); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+	emptyPath := t.TempDir()
+	withVerifier := slices.Contains(feature.List(), "slsa")
+
+	tests := []struct {
+		name       string
+		disabled   bool
+		dataSums   []byte
+		sumsPath   string
+		remoteData map[string]string
+		verifier   bool
+		want       string
+		wantErr    error
+	}{
+		{name: "Disabled", disabled: true, dataSums: []byte("sums"), sumsPath: "/v1.6.2/SHA256SUMS", verifier: true},
+		{name: "NoSums", sumsPath: "/v1.6.2/SHA256SUMS", verifier: true},
+		{name: "NotPublished", dataSums: []byte("sums"), sumsPath: "/v0.1.0/SHA256SUMS", verifier: true, want: manifest.ProvenanceUnavailable},
+		{name: "NoVerifier", dataSums: []byte("sums"), sumsPath: "/v1.6.2/SHA256SUMS", want: manifest.ProvenanceSkipped},
+		{name: "Verified", dataSums: []byte("sums"), sumsPath: "/v1.6.2/SHA256SUMS", verifier: true, want: manifest.ProvenanceSLSA},
+		{name: "CustomAsset", dataSums: []byte("sums"), sumsPath: "/v1.6.2/SHA256SUMS", remoteData: map[string]string{"provenance_asset": "valid.intoto.jsonl"}, verifier: true, want: manifest.ProvenanceSLSA},
+		{name: "CheckFailed", dataSums: []byte("sums"), sumsPath: "/v1.6.2/SHA256SUMS", remoteData: map[string]string{"provenance_asset": "forged.intoto.jsonl"}, verifier: true, wantErr: slsacheck.ErrCheck},
+	}
+
+	// sequential, PATH is process wide
+	for _, tt := range tests {
+		if !withVerifier && (tt.want == manifest.ProvenanceSLSA || tt.wantErr != nil) {
+			continue // build without slsa feature always skip the check
+		}
+
+		if tt.verifier {
+			t.Setenv("PATH", verifierPath)
+		} else {
+			t.Setenv("PATH", emptyPath)
+		}
+
+		conf := &config.Config{CheckProvenance: !tt.disabled, Displayer: loghelper.InertDisplayer}
+		remoteConf := config.RemoteConfig{Data: tt.remoteData}
+		res, err := Verify(conf, remoteConf, tt.dataSums, server.URL+tt.sumsPath, "github.com/opentofu/opentofu", "v1.6.2")
+		if err != tt.wantErr || res != tt.want {
+			t.Error(tt.name, "unmatching results, get :", res, err)
+		}
+	}
+}
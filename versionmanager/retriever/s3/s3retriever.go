@@ -0,0 +1,76 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package s3retriever
+
+import (
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/apimsg"
+	"github.com/tofuutils/tenv/v2/pkg/s3"
+	"github.com/tofuutils/tenv/v2/versionmanager"
+	versionfinder "github.com/tofuutils/tenv/v2/versionmanager/semantic/finder"
+)
+
+// S3Retriever lists releases from prefixes of an S3 bucket (<prefix>/<version>/<asset> keys),
+// install is delegated to the tool retriever (its release urls are rewritten toward the bucket, so checksum and signature are still checked).
+type S3Retriever struct {
+	conf       *config.Config
+	remoteConf *config.RemoteConfig
+	retriever  versionmanager.ReleaseInfoRetriever
+}
+
+func Make(conf *config.Config, remoteConf *config.RemoteConfig, retriever versionmanager.ReleaseInfoRetriever) S3Retriever {
+	return S3Retriever{conf: conf, remoteConf: remoteConf, retriever: retriever}
+}
+
+func (r S3Retriever) InstallRelease(version string, targetPath string) error {
+	return r.retriever.InstallRelease(version, targetPath)
+}
+
+func (r S3Retriever) ListReleases() ([]string, error) {
+	err := r.conf.InitRemoteConf()
+	if err != nil {
+		return nil, err
+	}
+
+	bucketURL := r.remoteConf.GetBucketURL()
+	r.conf.Displayer.Display(apimsg.MsgFetchAllReleases + bucketURL)
+
+	names, err := s3.List(bucketURL)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]string, 0, len(names))
+	for _, name := range names {
+		if version := versionfinder.Find(name); version != "" {
+			versions = append(versions, version)
+		}
+	}
+
+	return versions, nil
+}
+
+func (r S3Retriever) ProbeSidecars(version string) (bool, bool, error) {
+	prober, ok := r.retriever.(versionmanager.SidecarProber)
+	if !ok {
+		return false, false, versionmanager.ErrNoProbe
+	}
+
+	return prober.ProbeSidecars(version)
+}
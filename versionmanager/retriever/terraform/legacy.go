@@ -0,0 +1,76 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package terraformretriever
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hashicorp/go-version"
+
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+)
+
+const (
+	amd64Arch = "amd64"
+	arm64Arch = "arm64"
+	darwinOS  = "darwin"
+)
+
+var ErrNoBuild = errors.New("no Terraform build for your platform")
+
+// first release built for platforms added after 0.x era (other platforms have builds for every version).
+var firstBuilds = map[string]*version.Version{ //nolint
+	darwinOS + "/" + arm64Arch: version.Must(version.NewVersion("1.0.2")),
+	"linux/" + arm64Arch:       version.Must(version.NewVersion("0.13.5")),
+}
+
+func hasBuild(versionStr string, goos string, arch string) bool {
+	first, ok := firstBuilds[goos+"/"+arch]
+	if !ok {
+		return true
+	}
+
+	parsed, err := version.NewVersion(versionStr)
+
+	return err != nil || !parsed.LessThan(first)
+}
+
+func noBuildError(versionStr string, goos string, arch string) error {
+	details := loghelper.Concat(goos, "/", arch, " with version ", versionStr)
+	if first, ok := firstBuilds[goos+"/"+arch]; ok {
+		details = loghelper.Concat(details, ", first build is ", first.String())
+	}
+
+	if goos == darwinOS && arch == arm64Arch {
+		details = loghelper.Concat(details, ", set TFENV_AMD64_FALLBACK=true to install amd64 build (run with Rosetta 2)")
+	}
+
+	return fmt.Errorf("%w : %s", ErrNoBuild, details)
+}
+
+// on Apple Silicon, versions without arm64 build can use the amd64 one under emulation (when enabled).
+func (r TerraformRetriever) selectArch(versionStr string, goos string) string {
+	if goos != darwinOS || r.conf.Arch != arm64Arch || !r.conf.TfAmd64Fallback || hasBuild(versionStr, goos, arm64Arch) {
+		return r.conf.Arch
+	}
+	r.conf.Displayer.Display(loghelper.Concat("No darwin arm64 build for Terraform ", versionStr, ", install amd64 build (run with Rosetta 2)"))
+
+	return amd64Arch
+}
@@ -20,7 +20,7 @@ package terraformretriever
 
 import (
 	"encoding/json"
-	"io"
+	"errors"
 	"net/http"
 	"net/url"
 	"os"
@@ -33,11 +33,12 @@ import (
 	"github.com/tofuutils/tenv/v2/config/cmdconst"
 	"github.com/tofuutils/tenv/v2/pkg/apimsg"
 	pgpcheck "github.com/tofuutils/tenv/v2/pkg/check/pgp"
-	sha256check "github.com/tofuutils/tenv/v2/pkg/check/sha256"
 	"github.com/tofuutils/tenv/v2/pkg/download"
+	"github.com/tofuutils/tenv/v2/pkg/extract"
+	"github.com/tofuutils/tenv/v2/pkg/httpclient"
 	"github.com/tofuutils/tenv/v2/pkg/pathfilter"
 	"github.com/tofuutils/tenv/v2/pkg/winbin"
-	"github.com/tofuutils/tenv/v2/pkg/zip"
+	"github.com/tofuutils/tenv/v2/versionmanager/manifest"
 	htmlretriever "github.com/tofuutils/tenv/v2/versionmanager/retriever/html"
 )
 
@@ -72,10 +73,16 @@ func (r TerraformRetriever) InstallRelease(version string, targetPath string) er
 		return err
 	}
 
+	arch := r.selectArch(version, runtime.GOOS)
+
 	var fileName, shaFileName, shaSigFileName, downloadURL, downloadSumsURL, downloadSumsSigURL string
 	switch r.conf.Tf.GetInstallMode() {
 	case config.InstallModeDirect:
-		fileName, shaFileName, shaSigFileName = buildAssetNames(version, r.conf.Arch)
+		if !hasBuild(version, runtime.GOOS, arch) {
+			return noBuildError(version, runtime.GOOS, arch)
+		}
+
+		fileName, shaFileName, shaSigFileName = buildAssetNames(version, arch)
 		if r.conf.Displayer.IsDebug() {
 			r.conf.Displayer.Log(hclog.Debug, apimsg.MsgSearch, apimsg.AssetsName, []string{fileName, shaFileName, shaSigFileName})
 		}
@@ -94,13 +101,22 @@ func (r TerraformRetriever) InstallRelease(version string, targetPath string) er
 
 		r.conf.Displayer.Display(apimsg.MsgFetchRelease + versionUrl)
 
-		value, err := apiGetRequest(versionUrl)
+		var index versionIndex
+		err = apiGetRequest(versionUrl, func(response *http.Response) (err error) {
+			index, err = apimsg.Decode[versionIndex](response)
+
+			return err
+		})
 		if err != nil {
 			return err
 		}
 
-		fileName, downloadURL, shaFileName, shaSigFileName, err = extractAssetUrls(runtime.GOOS, r.conf.Arch, value)
+		fileName, downloadURL, shaFileName, shaSigFileName, err = extractAssetUrls(runtime.GOOS, arch, index)
 		if err != nil {
+			if errors.Is(err, apimsg.ErrAsset) {
+				return noBuildError(version, runtime.GOOS, arch)
+			}
+
 			return err
 		}
 
@@ -124,16 +140,21 @@ func (r TerraformRetriever) InstallRelease(version string, targetPath string) er
 		return err
 	}
 
-	data, err := download.Bytes(assetURLs[0], r.conf.Displayer.Display)
+	pending := extract.Zip.Start(assetURLs[0], r.conf.StreamExtract, r.conf.Displayer.Display)
+	defer pending.Discard()
+
+	dataSums, signature, err := r.downloadSumsAndCheckSig(assetURLs[1], assetURLs[2])
 	if err != nil {
 		return err
 	}
 
-	if err = r.checkSumAndSig(fileName, data, assetURLs[1], assetURLs[2]); err != nil {
+	filter := pathfilter.NameEqual(winbin.GetBinaryName(cmdconst.TerraformName))
+	if err = pending.Install(dataSums, fileName, targetPath, filter); err != nil {
 		return err
 	}
+	manifest.Write(targetPath, manifest.Manifest{Checksum: true, Signature: signature, Source: assetURLs[0]}, r.conf.Displayer)
 
-	return zip.UnzipToDir(data, targetPath, pathfilter.NameEqual(winbin.GetBinaryName(cmdconst.TerraformName)))
+	return nil
 }
 
 func (r TerraformRetriever) ListReleases() ([]string, error) {
@@ -160,66 +181,107 @@ func (r TerraformRetriever) ListReleases() ([]string, error) {
 
 		r.conf.Displayer.Display(apimsg.MsgFetchAllReleases + releasesURL)
 
-		value, err := apiGetRequest(releasesURL)
-		if err != nil {
-			return nil, err
-		}
+		var releases []string
+		err = apiGetRequest(releasesURL, func(response *http.Response) (err error) {
+			releases, err = decodeReleases(apimsg.NewDecoder(response))
+
+			return err
+		})
 
-		return extractReleases(value)
+		return releases, err
 	default:
 		return nil, config.ErrListMode
 	}
 }
 
-func (r TerraformRetriever) checkSumAndSig(fileName string, data []byte, downloadSumsURL string, downloadSumsSigURL string) error {
-	dataSums, err := download.Bytes(downloadSumsURL, r.conf.Displayer.Display)
-	if err != nil {
-		return err
-	}
+// returns checksums file content and the kind of signature checked.
+func (r TerraformRetriever) downloadSumsAndCheckSig(downloadSumsURL string, downloadSumsSigURL string) ([]byte, string, error) {
+	if r.conf.SkipSignature {
+		if r.conf.StrictVerify {
+			return nil, "", pgpcheck.ErrStrict
+		}
 
-	if err = sha256check.Check(data, dataSums, fileName); err != nil {
-		return err
+		dataSums, err := download.Bytes(downloadSumsURL, r.conf.Displayer.Display)
+
+		return dataSums, manifest.SignatureSkipped, err
 	}
 
-	if r.conf.SkipSignature {
-		return nil
+	downloadURLs := []string{downloadSumsURL, downloadSumsSigURL}
+	if r.conf.TfKeyPath == "" {
+		downloadURLs = append(downloadURLs, publicKeyURL)
 	}
 
-	dataSumsSig, err := download.Bytes(downloadSumsSigURL, r.conf.Displayer.Display)
+	datas, err := download.BytesAll(downloadURLs, r.conf.Displayer.Display)
 	if err != nil {
-		return err
+		return nil, "", err
 	}
+	dataSums, dataSumsSig := datas[0], datas[1]
 
 	var dataPublicKey []byte
 	if r.conf.TfKeyPath == "" {
-		dataPublicKey, err = download.Bytes(publicKeyURL, r.conf.Displayer.Display)
-	} else {
-		dataPublicKey, err = os.ReadFile(r.conf.TfKeyPath)
+		dataPublicKey = datas[2]
+	} else if dataPublicKey, err = os.ReadFile(r.conf.TfKeyPath); err != nil {
+		return nil, "", err
 	}
 
+	return dataSums, manifest.SignaturePGP, pgpcheck.Check(dataSums, dataSumsSig, dataPublicKey)
+}
+
+// retry once on invalid JSON (transient proxy error page or truncated body),
+// decode must replace previously decoded content.
+func apiGetRequest(callURL string, decode func(*http.Response) error) error {
+	err := innerAPIGetRequest(callURL, decode)
+	var decodeErr apimsg.DecodeError
+	if errors.As(err, &decodeErr) {
+		err = innerAPIGetRequest(callURL, decode)
+	}
+
+	return err
+}
+
+func innerAPIGetRequest(callURL string, decode func(*http.Response) error) error {
+	response, err := httpclient.Get(callURL)
 	if err != nil {
 		return err
 	}
+	defer response.Body.Close()
 
-	return pgpcheck.Check(dataSums, dataSumsSig, dataPublicKey)
+	return decode(response)
 }
 
-func apiGetRequest(callURL string) (any, error) {
-	response, err := http.Get(callURL)
+// ProbeSidecars checks that checksum file and its signature are published.
+func (r TerraformRetriever) ProbeSidecars(version string) (bool, bool, error) {
+	err := r.conf.InitRemoteConf()
 	if err != nil {
-		return nil, err
+		return false, false, err
+	}
+
+	if version[0] == 'v' {
+		version = version[1:]
 	}
-	defer response.Body.Close()
 
-	data, err := io.ReadAll(response.Body)
+	baseVersionURL, err := url.JoinPath(r.conf.Tf.GetRemoteURL(), cmdconst.TerraformName, version) //nolint
 	if err != nil {
-		return nil, err
+		return false, false, err
+	}
+
+	_, shaFileName, shaSigFileName := buildAssetNames(version, r.conf.Arch)
+	assetURLs, err := htmlretriever.BuildAssetURLs(baseVersionURL, shaFileName, shaSigFileName)
+	if err != nil {
+		return false, false, err
+	}
+
+	assetURLs, err = download.ApplyUrlTranformer(download.UrlTranformer(r.conf.Tf.GetRewriteRule()), assetURLs...)
+	if err != nil {
+		return false, false, err
 	}
 
-	var value any
-	err = json.Unmarshal(data, &value)
+	founds, err := download.ExistAll(assetURLs)
+	if err != nil {
+		return false, false, err
+	}
 
-	return value, err
+	return founds[0], founds[1], nil
 }
 
 func buildAssetNames(version string, arch string) (string, string, string) {
@@ -237,45 +299,92 @@ func buildAssetNames(version string, arch string) (string, string, string) {
 	return nameBuilder.String(), sumsAssetName, sumsAssetName + ".sig"
 }
 
-func extractAssetUrls(searchedOs string, searchedArch string, value any) (string, string, string, string, error) {
-	object, _ := value.(map[string]any)
-	builds, ok := object["builds"].([]any)
-	shaFileName, ok2 := object["shasums"].(string)
-	shaSigFileName, ok3 := object["shasums_signature"].(string)
-	if !ok || !ok2 || !ok3 {
+type versionIndex struct {
+	Builds []struct {
+		Arch     string `json:"arch"`
+		FileName string `json:"filename"`
+		OS       string `json:"os"`
+		URL      string `json:"url"`
+	} `json:"builds"`
+	SumsFileName    string `json:"shasums"`
+	SumsSigFileName string `json:"shasums_signature"`
+}
+
+func extractAssetUrls(searchedOs string, searchedArch string, index versionIndex) (string, string, string, string, error) {
+	if len(index.Builds) == 0 || index.SumsFileName == "" || index.SumsSigFileName == "" {
 		return "", "", "", "", apimsg.ErrReturn
 	}
 
-	for _, build := range builds {
-		object, _ = build.(map[string]any)
-		osStr, ok := object["os"].(string)
-		archStr, ok2 := object["arch"].(string)
-		downloadURL, ok3 := object["url"].(string)
-		fileName, ok4 := object["filename"].(string)
-		if !ok || !ok2 || !ok3 || !ok4 {
+	for _, build := range index.Builds {
+		if build.OS == "" || build.Arch == "" || build.URL == "" || build.FileName == "" {
 			return "", "", "", "", apimsg.ErrReturn
 		}
 
-		if osStr != searchedOs || archStr != searchedArch {
+		if build.OS != searchedOs || build.Arch != searchedArch {
 			continue
 		}
 
-		return fileName, downloadURL, shaFileName, shaSigFileName, nil
+		return build.FileName, build.URL, index.SumsFileName, index.SumsSigFileName, nil
 	}
 
 	return "", "", "", "", apimsg.ErrAsset
 }
 
-func extractReleases(value any) ([]string, error) {
-	object, _ := value.(map[string]any)
-	versions, ok := object["versions"].(map[string]any)
-	if !ok {
-		return nil, apimsg.ErrReturn
+// the releases index describes every build of every version, only keys of its versions object are kept.
+func decodeReleases(decoder apimsg.Decoder) ([]string, error) {
+	if err := decoder.ExpectDelim('{'); err != nil {
+		return nil, err
 	}
 
-	releases := make([]string, 0, len(object))
-	for version := range versions {
-		releases = append(releases, version)
+	var releases []string
+	found := false
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return nil, decoder.Wrap(err)
+		}
+
+		if key != "versions" {
+			var skipped json.RawMessage
+			if err = decoder.Decode(&skipped); err != nil {
+				return nil, decoder.Wrap(err)
+			}
+
+			continue
+		}
+
+		found = true
+		if err = decoder.ExpectDelim('{'); err != nil {
+			return nil, err
+		}
+
+		for decoder.More() {
+			version, err := decoder.Token()
+			if err != nil {
+				return nil, decoder.Wrap(err)
+			}
+
+			var skipped struct{}
+			if err = decoder.Decode(&skipped); err != nil {
+				return nil, decoder.Wrap(err)
+			}
+
+			if versionStr, ok := version.(string); ok {
+				releases = append(releases, versionStr)
+			}
+		}
+
+		if _, err = decoder.Token(); err != nil {
+			return nil, decoder.Wrap(err)
+		}
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return nil, decoder.Wrap(err)
+	}
+
+	if !found {
+		return nil, apimsg.ErrReturn
 	}
 
 	return releases, nil
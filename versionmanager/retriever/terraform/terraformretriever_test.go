@@ -19,11 +19,19 @@
 package terraformretriever
 
 import (
+	"bytes"
 	_ "embed"
 	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
 	"slices"
+	"strings"
 	"testing"
 
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/apimsg"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
 	"github.com/tofuutils/tenv/v2/versionmanager/semantic"
 )
 
@@ -31,21 +39,15 @@ import (
 var releaseData []byte
 
 var (
-	releaseValue any
+	releaseValue versionIndex
 	releaseErr   error
 )
 
 //go:embed testdata/releases.json
 var releasesData []byte
 
-var (
-	releasesValue any
-	releasesErr   error
-)
-
 func init() {
 	releaseErr = json.Unmarshal(releaseData, &releaseValue)
-	releasesErr = json.Unmarshal(releasesData, &releasesValue)
 }
 
 func TestExtractAssetUrls(t *testing.T) {
@@ -77,11 +79,8 @@ func TestExtractAssetUrls(t *testing.T) {
 func TestExtractReleases(t *testing.T) {
 	t.Parallel()
 
-	if releasesErr != nil {
-		t.Fatal("Unexpected parsing error : ", releasesErr)
-	}
-
-	releases, err := extractReleases(releasesValue)
+	response := &http.Response{Body: io.NopCloser(bytes.NewReader(releasesData)), StatusCode: http.StatusOK}
+	releases, err := decodeReleases(apimsg.NewDecoder(response))
 	if err != nil {
 		t.Fatal("Unexpected extract error : ", err)
 	}
@@ -91,3 +90,49 @@ func TestExtractReleases(t *testing.T) {
 		t.Error("Unmatching results, get :", releases)
 	}
 }
+
+func TestHasBuild(t *testing.T) {
+	t.Parallel()
+
+	if hasBuild("0.12.31", "darwin", "arm64") || !hasBuild("1.0.2", "darwin", "arm64") {
+		t.Error("Incorrect darwin arm64 support")
+	}
+
+	if hasBuild("0.13.4", "linux", "arm64") || !hasBuild("0.13.5", "linux", "arm64") {
+		t.Error("Incorrect linux arm64 support")
+	}
+
+	if !hasBuild("0.11.15", "linux", "amd64") {
+		t.Error("Incorrect linux amd64 support")
+	}
+}
+
+func TestNoBuildError(t *testing.T) {
+	t.Parallel()
+
+	err := noBuildError("0.12.31", "darwin", "arm64")
+	if !errors.Is(err, ErrNoBuild) {
+		t.Fatal("Incorrect error reported, get :", err)
+	}
+
+	if !strings.Contains(err.Error(), "first build is 1.0.2") {
+		t.Error("Missing first build in message, get :", err)
+	}
+}
+
+func TestSelectArch(t *testing.T) {
+	t.Parallel()
+
+	retriever := Make(&config.Config{Arch: "arm64", Displayer: loghelper.InertDisplayer, TfAmd64Fallback: true})
+	if arch := retriever.selectArch("0.12.31", "darwin"); arch != "amd64" {
+		t.Error("Unmatching results, get :", arch)
+	}
+
+	if arch := retriever.selectArch("1.5.7", "darwin"); arch != "arm64" {
+		t.Error("Unmatching results, get :", arch)
+	}
+
+	if arch := retriever.selectArch("0.13.4", "linux"); arch != "arm64" {
+		t.Error("Unmatching results, get :", arch)
+	}
+}
@@ -19,27 +19,38 @@
 package terragruntretriever
 
 import (
+	"errors"
+	"fmt"
 	"net/url"
-	"os"
-	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-version"
 
 	"github.com/tofuutils/tenv/v2/config"
 	"github.com/tofuutils/tenv/v2/config/cmdconst"
 	"github.com/tofuutils/tenv/v2/pkg/apimsg"
 	sha256check "github.com/tofuutils/tenv/v2/pkg/check/sha256"
 	"github.com/tofuutils/tenv/v2/pkg/download"
+	"github.com/tofuutils/tenv/v2/pkg/extract"
 	"github.com/tofuutils/tenv/v2/pkg/github"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
 	"github.com/tofuutils/tenv/v2/pkg/winbin"
+	"github.com/tofuutils/tenv/v2/versionmanager"
+	"github.com/tofuutils/tenv/v2/versionmanager/manifest"
 	htmlretriever "github.com/tofuutils/tenv/v2/versionmanager/retriever/html"
+	"github.com/tofuutils/tenv/v2/versionmanager/retriever/provenance"
 )
 
 const (
 	baseFileName  = "terragrunt_"
 	gruntworkName = "gruntwork-io"
+	sourceURI     = "github.com/gruntwork-io/terragrunt"
+
+	// first release published with a SHA256SUMS file, a missing one for a later release is refused.
+	sumsSinceVersion = "0.18.1"
 )
 
 type TerragruntRetriever struct {
@@ -63,6 +74,7 @@ func (r TerragruntRetriever) InstallRelease(versionStr string, targetPath string
 	}
 
 	var assetURLs []string
+	var requestOptions []download.RequestOption
 	fileName, shaFileName := buildAssetNames(r.conf.Arch)
 	if r.conf.Displayer.IsDebug() {
 		r.conf.Displayer.Log(hclog.Debug, apimsg.MsgSearch, apimsg.AssetsName, []string{fileName, shaFileName})
@@ -77,7 +89,15 @@ func (r TerragruntRetriever) InstallRelease(versionStr string, targetPath string
 
 		assetURLs, err = htmlretriever.BuildAssetURLs(baseAssetURL, fileName, shaFileName)
 	case config.ModeAPI:
-		assetURLs, err = github.AssetDownloadURL(tag, []string{fileName, shaFileName}, r.conf.Tg.GetRemoteURL(), r.conf.GithubToken, r.conf.Displayer.Display)
+		githubToken := r.conf.ToolGithubToken(r.conf.Tg, r.conf.Tg.GetRemoteURL())
+		assetURLs, err = github.AssetDownloadURL(tag, []string{fileName, shaFileName}, r.conf.Tg.GetRemoteURL(), githubToken, r.conf.GithubAssetAPI, r.conf.Displayer.Display)
+		if errors.Is(err, apimsg.ErrAsset) { // older releases lack SHA256SUMS
+			if !r.allowUnverifiable(versionStr) {
+				return missingSumsError(shaFileName, versionStr)
+			}
+			assetURLs, err = github.AssetDownloadURL(tag, []string{fileName}, r.conf.Tg.GetRemoteURL(), githubToken, r.conf.GithubAssetAPI, r.conf.Displayer.Display)
+		}
+		requestOptions = github.AssetRequestOptions(githubToken, r.conf.GithubAssetAPI)
 	default:
 		return config.ErrInstallMode
 	}
@@ -91,32 +111,69 @@ func (r TerragruntRetriever) InstallRelease(versionStr string, targetPath string
 		return err
 	}
 
-	data, err := download.Bytes(assetURLs[0], r.conf.Displayer.Display)
-	if err != nil {
-		return err
+	format := extract.Raw(winbin.GetBinaryName(cmdconst.TerragruntName))
+	pending := format.Start(assetURLs[0], r.conf.StreamExtract, r.conf.Displayer.Display, requestOptions...)
+	defer pending.Discard()
+
+	installManifest := manifest.Manifest{Checksum: true, Signature: manifest.SignatureUnavailable, Source: assetURLs[0]}
+	var dataSums []byte
+	if len(assetURLs) > 1 {
+		dataSums, err = download.Bytes(assetURLs[1], r.conf.Displayer.Display, requestOptions...)
+		switch {
+		case errors.Is(err, download.ErrNotFound):
+			if !r.allowUnverifiable(versionStr) {
+				return missingSumsError(shaFileName, versionStr)
+			}
+		case err != nil:
+			return err
+		}
 	}
 
-	dataSums, err := download.Bytes(assetURLs[1], r.conf.Displayer.Display)
-	if err != nil {
+	if dataSums == nil {
+		r.conf.Displayer.Log(hclog.Warn, loghelper.Concat("No ", shaFileName, " published for Terragrunt ", versionStr, ", installed as unverifiable"))
+		installManifest = manifest.Manifest{Signature: manifest.SignatureUnavailable, Source: assetURLs[0], Unverifiable: true}
+	} else if installManifest.Provenance, err = provenance.Verify(r.conf, r.conf.Tg, dataSums, assetURLs[1], sourceURI, tag, requestOptions...); err != nil {
 		return err
 	}
 
-	if err = sha256check.Check(data, dataSums, fileName); err != nil {
+	if err = pending.Install(dataSums, fileName, targetPath, nil); err != nil {
 		return err
 	}
+	manifest.Write(targetPath, installManifest, r.conf.Displayer)
+
+	return nil
+}
 
-	err = os.MkdirAll(targetPath, 0o755)
+// FindRelease stops paging at the first page with a matching release in API list mode (other modes list all releases).
+func (r TerragruntRetriever) FindRelease(match func(string) bool) (string, error) {
+	err := r.conf.InitRemoteConf()
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	return os.WriteFile(filepath.Join(targetPath, winbin.GetBinaryName(cmdconst.TerragruntName)), data, 0o755)
+	if r.conf.Tg.GetListMode() != config.ModeAPI {
+		releases, err := r.ListReleases()
+
+		return versionmanager.HighestMatching(releases, match), err
+	}
+
+	listURL := r.conf.Tg.GetListURL()
+	r.conf.Displayer.Display(apimsg.MsgFetchReleases + listURL)
+
+	return github.FindRelease(listURL, r.conf.ToolGithubToken(r.conf.Tg, listURL), match)
 }
 
 func (r TerragruntRetriever) ListReleases() ([]string, error) {
+	releases, _, err := r.ListDatedReleases()
+
+	return releases, err
+}
+
+// ListDatedReleases returns publish dates only in API list mode.
+func (r TerragruntRetriever) ListDatedReleases() ([]string, map[string]time.Time, error) {
 	err := r.conf.InitRemoteConf()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	listURL := r.conf.Tg.GetListURL()
@@ -124,21 +181,70 @@ func (r TerragruntRetriever) ListReleases() ([]string, error) {
 	case config.ListModeHTML:
 		baseURL, err := url.JoinPath(listURL, gruntworkName, cmdconst.TerragruntName, github.Releases, github.Download) //nolint
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		r.conf.Displayer.Display(apimsg.MsgFetchAllReleases + baseURL)
+		releases, err := htmlretriever.ListReleases(baseURL, r.conf.Tg.Data)
 
-		return htmlretriever.ListReleases(baseURL, r.conf.Tg.Data)
+		return releases, nil, err
 	case config.ModeAPI:
 		r.conf.Displayer.Display(apimsg.MsgFetchAllReleases + listURL)
 
-		return github.ListReleases(listURL, r.conf.GithubToken)
+		return github.ListDatedReleases(listURL, r.conf.ToolGithubToken(r.conf.Tg, listURL))
 	default:
-		return nil, config.ErrListMode
+		return nil, nil, config.ErrListMode
 	}
 }
 
+// ProbeSidecars checks that checksum file is published (terragrunt releases are not signed).
+func (r TerragruntRetriever) ProbeSidecars(versionStr string) (bool, bool, error) {
+	err := r.conf.InitRemoteConf()
+	if err != nil {
+		return false, false, err
+	}
+
+	tag, _ := github.SplitTag(versionStr)
+
+	baseURL := github.BaseURL
+	if r.conf.Tg.GetInstallMode() == config.InstallModeDirect {
+		baseURL = r.conf.Tg.GetRemoteURL()
+	}
+
+	_, shaFileName := buildAssetNames(r.conf.Arch)
+	sumsURL, err := url.JoinPath(baseURL, gruntworkName, cmdconst.TerragruntName, github.Releases, github.Download, tag, shaFileName) //nolint
+	if err != nil {
+		return false, false, err
+	}
+
+	sumsURL, err = download.UrlTranformer(r.conf.Tg.GetRewriteRule())(sumsURL)
+	if err != nil {
+		return false, false, err
+	}
+
+	found, err := download.Exists(sumsURL)
+
+	return found, false, err
+}
+
+// only releases predating SHA256SUMS publication can be installed without checksum, and never in strict mode
+// (a missing checksum file for a recent release is treated as a verification failure, not as an old release).
+func (r TerragruntRetriever) allowUnverifiable(versionStr string) bool {
+	if r.conf.StrictVerify {
+		r.conf.Displayer.Log(hclog.Debug, "Unverifiable Terragrunt release refused", "reason", "strict verify mode")
+
+		return false
+	}
+
+	parsedVersion, err := version.NewVersion(versionStr)
+
+	return err == nil && parsedVersion.LessThan(version.Must(version.NewVersion(sumsSinceVersion)))
+}
+
+func missingSumsError(shaFileName string, versionStr string) error {
+	return fmt.Errorf("%w : no %s published for Terragrunt %s", sha256check.ErrNoSum, shaFileName, versionStr)
+}
+
 func buildAssetNames(arch string) (string, string) {
 	var nameBuilder strings.Builder
 	nameBuilder.WriteString(baseFileName)
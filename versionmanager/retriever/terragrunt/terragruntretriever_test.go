@@ -0,0 +1,102 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package terragruntretriever_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/config"
+	sha256check "github.com/tofuutils/tenv/v2/pkg/check/sha256"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/pkg/winbin"
+	"github.com/tofuutils/tenv/v2/versionmanager/manifest"
+	terragruntretriever "github.com/tofuutils/tenv/v2/versionmanager/retriever/terragrunt"
+)
+
+func TestInstallReleaseWithoutSums(t *testing.T) {
+	t.Parallel()
+
+	// no release publish SHA256SUMS on this server
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if strings.HasSuffix(request.URL.Path, "/SHA256SUMS") {
+			writer.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+		_, _ = writer.Write([]byte("terragrunt binary"))
+	}))
+	t.Cleanup(server.Close)
+
+	confPath := filepath.Join(t.TempDir(), "remote.yaml")
+	if err := os.WriteFile(confPath, []byte("terragrunt:\n  url: "+server.URL+"\n  install_mode: direct\n"), 0o600); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	tests := []struct {
+		name    string
+		version string
+		strict  bool
+		wantErr bool
+	}{
+		{name: "OldRelease", version: "0.17.4"},
+		{name: "OldReleaseStrict", version: "0.17.4", strict: true, wantErr: true},
+		{name: "RecentRelease", version: "0.55.0", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := &config.Config{Arch: runtime.GOARCH, Displayer: loghelper.InertDisplayer, RemoteConfPath: confPath, StrictVerify: tt.strict}
+			targetPath := filepath.Join(t.TempDir(), tt.version)
+			err := terragruntretriever.Make(conf).InstallRelease(tt.version, targetPath)
+			if tt.wantErr {
+				if !errors.Is(err, sha256check.ErrNoSum) {
+					t.Error("Should refuse installation without checksum, get :", err)
+				}
+
+				if _, err = os.Stat(targetPath); !os.IsNotExist(err) {
+					t.Error("Nothing should be installed, get :", err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal("Unexpected error :", err)
+			}
+
+			if _, err = os.Stat(filepath.Join(targetPath, winbin.GetBinaryName("terragrunt"))); err != nil {
+				t.Error("Binary should be installed, get :", err)
+			}
+
+			if installManifest, found := manifest.Read(targetPath, loghelper.InertDisplayer); !found || !installManifest.Unverifiable || installManifest.Checksum {
+				t.Error("Should be recorded as unverifiable, get :", installManifest, found)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package tofuretriever
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"runtime"
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
+
+	"github.com/tofuutils/tenv/v2/config"
+	sha256check "github.com/tofuutils/tenv/v2/pkg/check/sha256"
+	"github.com/tofuutils/tenv/v2/pkg/download"
+	"github.com/tofuutils/tenv/v2/pkg/github"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+)
+
+const manifestSuffix = "_manifest.json"
+
+// releaseManifest is published with recent releases, it lists artifacts with their digest by platform.
+type releaseManifest struct {
+	Artifacts []releaseArtifact `json:"artifacts"`
+}
+
+type releaseArtifact struct {
+	Arch   string `json:"arch"`
+	Name   string `json:"name"`
+	OS     string `json:"os"`
+	SHA256 string `json:"sha256"`
+	URL    string `json:"url,omitempty"` // when empty, artifact is a release asset
+}
+
+// returns an empty string when artifact must be downloaded as a release asset : no url in manifest,
+// or releases read from a mirror, a bucket or an offline source (never bypassed).
+func (r TofuRetriever) artifactURL(artifact releaseArtifact) string {
+	if r.conf.Tofu.GetMirrorURL() != "" || r.conf.Tofu.GetBucketURL() != "" || r.conf.Tofu.GetOfflinePath() != "" {
+		return ""
+	}
+
+	return artifact.URL
+}
+
+// returns false when no archive for current platform is found in a release manifest (older version, manifest disabled or not parsable).
+func (r TofuRetriever) searchManifestArtifact(tag string, versionStr string) (releaseArtifact, bool) {
+	if r.conf.TofuNoManifest {
+		return releaseArtifact{}, false
+	}
+
+	baseURL := github.BaseURL
+	if r.conf.Tofu.GetInstallMode() == config.InstallModeDirect {
+		baseURL = r.conf.Tofu.GetRemoteURL()
+	}
+
+	manifestURL, err := url.JoinPath(baseURL, opentofu, opentofu, github.Releases, github.Download, tag, baseFileName+versionStr+manifestSuffix) //nolint
+	if err == nil {
+		manifestURL, err = download.UrlTranformer(r.conf.Tofu.GetRewriteRule())(manifestURL)
+	}
+
+	var data []byte
+	if err == nil {
+		// not displayed, most releases do not have one
+		r.conf.Displayer.Log(hclog.Debug, "Search release manifest", "url", manifestURL)
+		data, err = download.Bytes(manifestURL, loghelper.InertDisplayer.Display)
+	}
+
+	var parsed releaseManifest
+	if err == nil {
+		err = json.Unmarshal(data, &parsed)
+	}
+
+	if err != nil {
+		r.conf.Displayer.Log(loghelper.LevelWarnOrDebug(errors.Is(err, download.ErrNotFound)), "No usable release manifest, fallback to asset names", loghelper.Error, err)
+
+		return releaseArtifact{}, false
+	}
+
+	for _, artifact := range parsed.Artifacts {
+		if artifact.OS == runtime.GOOS && artifact.Arch == r.conf.Arch && strings.HasSuffix(artifact.Name, ".zip") {
+			r.conf.Displayer.Log(hclog.Debug, "Use archive from release manifest", "name", artifact.Name)
+
+			return artifact, true
+		}
+	}
+
+	r.conf.Displayer.Log(hclog.Warn, "No archive for current platform in release manifest, fallback to asset names", "os", runtime.GOOS, "arch", r.conf.Arch)
+
+	return releaseArtifact{}, false
+}
+
+// the digest from manifest must match the signed checksums file.
+func checkArtifactDigest(artifact releaseArtifact, dataSums []byte) error {
+	if artifact.SHA256 == "" {
+		return nil
+	}
+
+	digest, err := hex.DecodeString(artifact.SHA256)
+	if err != nil {
+		return err
+	}
+
+	return sha256check.CheckSum(digest, dataSums, artifact.Name)
+}
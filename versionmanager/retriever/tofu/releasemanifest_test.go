@@ -0,0 +1,94 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package tofuretriever
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/config"
+	sha256check "github.com/tofuutils/tenv/v2/pkg/check/sha256"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+)
+
+const artifactDigest = "2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"
+
+func TestSearchManifestArtifact(t *testing.T) {
+	t.Parallel()
+
+	manifestData := `{"artifacts": [{"os": "` + runtime.GOOS + `", "arch": "amd64", "name": "tofu_1.10.0_` + runtime.GOOS + `_amd64.zip", "sha256": "` + artifactDigest + `"}]}`
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path != "/opentofu/opentofu/releases/download/v1.10.0/tofu_1.10.0_manifest.json" {
+			writer.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+		_, _ = writer.Write([]byte(manifestData))
+	}))
+	defer server.Close()
+
+	remoteConf := map[string]string{"install_mode": config.InstallModeDirect, "url": server.URL}
+	retriever := Make(&config.Config{Arch: "amd64", Displayer: loghelper.InertDisplayer, Tofu: config.RemoteConfig{Data: remoteConf}})
+	artifact, found := retriever.searchManifestArtifact("v1.10.0", "1.10.0")
+	if !found || artifact.Name != "tofu_1.10.0_"+runtime.GOOS+"_amd64.zip" {
+		t.Fatal("Unmatching results, get :", artifact, found)
+	}
+
+	if err := checkArtifactDigest(artifact, []byte(artifactDigest+"  "+artifact.Name+"\n")); err != nil {
+		t.Error("Unexpected error :", err)
+	}
+
+	otherDigest := "0000000000000000000000000000000000000000000000000000000000000000"
+	if err := checkArtifactDigest(artifact, []byte(otherDigest+"  "+artifact.Name+"\n")); !errors.Is(err, sha256check.ErrCheck) {
+		t.Error("Should fail on digest not matching checksums file, get :", err)
+	}
+
+	// older release without manifest
+	if _, found = retriever.searchManifestArtifact("v1.6.2", "1.6.2"); found {
+		t.Error("Should fallback without release manifest")
+	}
+}
+
+func TestArtifactURL(t *testing.T) { //nolint
+	var artifact releaseArtifact
+	manifestData := `{"os": "linux", "arch": "amd64", "name": "tofu_1.10.0_linux_amd64.zip", "url": "https://packages.example.com/tofu_1.10.0_linux_amd64.zip"}`
+	if err := json.Unmarshal([]byte(manifestData), &artifact); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	retriever := Make(&config.Config{Displayer: loghelper.InertDisplayer})
+	if artifactURL := retriever.artifactURL(artifact); artifactURL != "https://packages.example.com/tofu_1.10.0_linux_amd64.zip" {
+		t.Error("Unmatching results, get :", artifactURL)
+	}
+
+	// manifest url never bypass a configured mirror
+	t.Setenv("TENV_TOFU_MIRROR_URL", "https://mirror.example.com")
+	conf, err := config.InitConfigFromEnv()
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+
+	if artifactURL := Make(&conf).artifactURL(artifact); artifactURL != "" {
+		t.Error("Should ignore manifest url with a mirror, get :", artifactURL)
+	}
+}
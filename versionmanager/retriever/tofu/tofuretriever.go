@@ -23,6 +23,7 @@ import (
 	"os"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-version"
@@ -32,13 +33,15 @@ import (
 	"github.com/tofuutils/tenv/v2/pkg/apimsg"
 	cosigncheck "github.com/tofuutils/tenv/v2/pkg/check/cosign"
 	pgpcheck "github.com/tofuutils/tenv/v2/pkg/check/pgp"
-	sha256check "github.com/tofuutils/tenv/v2/pkg/check/sha256"
 	"github.com/tofuutils/tenv/v2/pkg/download"
+	"github.com/tofuutils/tenv/v2/pkg/extract"
 	"github.com/tofuutils/tenv/v2/pkg/github"
 	"github.com/tofuutils/tenv/v2/pkg/pathfilter"
 	"github.com/tofuutils/tenv/v2/pkg/winbin"
-	"github.com/tofuutils/tenv/v2/pkg/zip"
+	"github.com/tofuutils/tenv/v2/versionmanager"
+	"github.com/tofuutils/tenv/v2/versionmanager/manifest"
 	htmlretriever "github.com/tofuutils/tenv/v2/versionmanager/retriever/html"
+	"github.com/tofuutils/tenv/v2/versionmanager/retriever/provenance"
 )
 
 const (
@@ -50,6 +53,7 @@ const (
 
 	baseFileName = "tofu_"
 	opentofu     = "opentofu"
+	sourceURI    = "github.com/opentofu/opentofu"
 )
 
 type TofuRetriever struct {
@@ -82,7 +86,12 @@ func (r TofuRetriever) InstallRelease(versionStr string, targetPath string) erro
 	stable := v.Prerelease() == ""
 
 	var assetURLs []string
+	var requestOptions []download.RequestOption
 	assetNames := buildAssetNames(versionStr, r.conf.Arch, stable)
+	artifact, fromManifest := r.searchManifestArtifact(tag, versionStr)
+	if fromManifest {
+		assetNames[0] = artifact.Name
+	}
 	if r.conf.Displayer.IsDebug() {
 		r.conf.Displayer.Log(hclog.Debug, apimsg.MsgSearch, apimsg.AssetsName, assetNames)
 	}
@@ -96,7 +105,9 @@ func (r TofuRetriever) InstallRelease(versionStr string, targetPath string) erro
 
 		assetURLs, err = htmlretriever.BuildAssetURLs(baseAssetURL, assetNames...)
 	case config.ModeAPI:
-		assetURLs, err = github.AssetDownloadURL(tag, assetNames, r.conf.Tofu.GetRemoteURL(), r.conf.GithubToken, r.conf.Displayer.Display)
+		githubToken := r.conf.ToolGithubToken(r.conf.Tofu, r.conf.Tofu.GetRemoteURL())
+		assetURLs, err = github.AssetDownloadURL(tag, assetNames, r.conf.Tofu.GetRemoteURL(), githubToken, r.conf.GithubAssetAPI, r.conf.Displayer.Display)
+		requestOptions = github.AssetRequestOptions(githubToken, r.conf.GithubAssetAPI)
 	default:
 		return config.ErrInstallMode
 	}
@@ -104,28 +115,74 @@ func (r TofuRetriever) InstallRelease(versionStr string, targetPath string) erro
 		return err
 	}
 
+	if artifactURL := r.artifactURL(artifact); fromManifest && artifactURL != "" {
+		assetURLs[0] = artifactURL
+	}
+
 	urlTranformer := download.UrlTranformer(r.conf.Tofu.GetRewriteRule())
 	assetURLs, err = download.ApplyUrlTranformer(urlTranformer, assetURLs...)
 	if err != nil {
 		return err
 	}
 
-	data, err := download.Bytes(assetURLs[0], r.conf.Displayer.Display)
+	filter := pathfilter.NameEqual(winbin.GetBinaryName(cmdconst.TofuName))
+	pending := extract.Zip.Start(assetURLs[0], r.conf.StreamExtract, r.conf.Displayer.Display, requestOptions...)
+	defer pending.Discard()
+
+	dataSums, signature, err := r.downloadSumsAndCheckSig(v, stable, assetURLs, requestOptions)
+	if err != nil {
+		return err
+	}
+
+	if fromManifest {
+		if err = checkArtifactDigest(artifact, dataSums); err != nil {
+			return err
+		}
+	}
+
+	provenanceStatus, err := provenance.Verify(r.conf, r.conf.Tofu, dataSums, assetURLs[1], sourceURI, tag, requestOptions...)
 	if err != nil {
 		return err
 	}
 
-	if err = r.checkSumAndSig(v, stable, data, assetNames[0], assetURLs); err != nil {
+	if err = pending.Install(dataSums, assetNames[0], targetPath, filter); err != nil {
 		return err
 	}
+	manifest.Write(targetPath, manifest.Manifest{Checksum: true, Provenance: provenanceStatus, Signature: signature, Source: assetURLs[0]}, r.conf.Displayer)
+
+	return nil
+}
+
+// FindRelease stops paging at the first page with a matching release in API list mode (other modes list all releases).
+func (r TofuRetriever) FindRelease(match func(string) bool) (string, error) {
+	err := r.conf.InitRemoteConf()
+	if err != nil {
+		return "", err
+	}
+
+	if r.conf.Tofu.GetListMode() != config.ModeAPI {
+		releases, err := r.ListReleases()
+
+		return versionmanager.HighestMatching(releases, match), err
+	}
+
+	listURL := r.conf.Tofu.GetListURL()
+	r.conf.Displayer.Display(apimsg.MsgFetchReleases + listURL)
 
-	return zip.UnzipToDir(data, targetPath, pathfilter.NameEqual(winbin.GetBinaryName(cmdconst.TofuName)))
+	return github.FindRelease(listURL, r.conf.ToolGithubToken(r.conf.Tofu, listURL), match)
 }
 
 func (r TofuRetriever) ListReleases() ([]string, error) {
+	releases, _, err := r.ListDatedReleases()
+
+	return releases, err
+}
+
+// ListDatedReleases returns publish dates only in API list mode.
+func (r TofuRetriever) ListDatedReleases() ([]string, map[string]time.Time, error) {
 	err := r.conf.InitRemoteConf()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	listURL := r.conf.Tofu.GetListURL()
@@ -133,62 +190,103 @@ func (r TofuRetriever) ListReleases() ([]string, error) {
 	case config.ListModeHTML:
 		baseURL, err := url.JoinPath(listURL, opentofu, opentofu, github.Releases, github.Download) //nolint
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		r.conf.Displayer.Display(apimsg.MsgFetchAllReleases + baseURL)
+		releases, err := htmlretriever.ListReleases(baseURL, r.conf.Tofu.Data)
 
-		return htmlretriever.ListReleases(baseURL, r.conf.Tofu.Data)
+		return releases, nil, err
 	case config.ModeAPI:
 		r.conf.Displayer.Display(apimsg.MsgFetchAllReleases + listURL)
 
-		return github.ListReleases(listURL, r.conf.GithubToken)
+		return github.ListDatedReleases(listURL, r.conf.ToolGithubToken(r.conf.Tofu, listURL))
 	default:
-		return nil, config.ErrListMode
+		return nil, nil, config.ErrListMode
 	}
 }
 
-func (r TofuRetriever) checkSumAndSig(version *version.Version, stable bool, data []byte, fileName string, assetURLs []string) error {
-	dataSums, err := download.Bytes(assetURLs[1], r.conf.Displayer.Display)
+// ProbeSidecars checks that checksum file and one signature kind (cosign or gpg) are published.
+func (r TofuRetriever) ProbeSidecars(versionStr string) (bool, bool, error) {
+	err := r.conf.InitRemoteConf()
 	if err != nil {
-		return err
+		return false, false, err
 	}
 
-	if err = sha256check.Check(data, dataSums, fileName); err != nil {
-		return err
+	tag, versionStr := github.SplitTag(versionStr)
+
+	v, err := version.NewVersion(versionStr) //nolint
+	if err != nil {
+		return false, false, err
 	}
 
-	if r.conf.SkipSignature {
-		return nil
+	baseURL := github.BaseURL
+	if r.conf.Tofu.GetInstallMode() == config.InstallModeDirect {
+		baseURL = r.conf.Tofu.GetRemoteURL()
 	}
 
-	dataSumsSig, err := download.Bytes(assetURLs[3], r.conf.Displayer.Display)
+	baseAssetURL, err := url.JoinPath(baseURL, opentofu, opentofu, github.Releases, github.Download, tag) //nolint
 	if err != nil {
-		return err
+		return false, false, err
 	}
 
-	dataSumsCert, err := download.Bytes(assetURLs[2], r.conf.Displayer.Display)
+	assetURLs, err := htmlretriever.BuildAssetURLs(baseAssetURL, buildAssetNames(versionStr, r.conf.Arch, v.Prerelease() == "")[1:]...)
 	if err != nil {
-		return err
+		return false, false, err
+	}
+
+	assetURLs, err = download.ApplyUrlTranformer(download.UrlTranformer(r.conf.Tofu.GetRewriteRule()), assetURLs...)
+	if err != nil {
+		return false, false, err
+	}
+
+	founds, err := download.ExistAll(assetURLs)
+	if err != nil {
+		return false, false, err
+	}
+
+	return founds[0], (founds[1] && founds[2]) || (len(founds) > 3 && founds[3]), nil
+}
+
+// returns checksums file content and the kind of signature checked.
+func (r TofuRetriever) downloadSumsAndCheckSig(version *version.Version, stable bool, assetURLs []string, requestOptions []download.RequestOption) ([]byte, string, error) {
+	if r.conf.SkipSignature {
+		if r.conf.TofuStrictSig {
+			return nil, "", cosigncheck.ErrStrict
+		}
+
+		dataSums, err := download.Bytes(assetURLs[1], r.conf.Displayer.Display, requestOptions...)
+
+		return dataSums, manifest.SignatureSkipped, err
+	}
+
+	datas, err := download.BytesAll(assetURLs[1:4], r.conf.Displayer.Display, requestOptions...)
+	if err != nil {
+		return nil, "", err
 	}
+	dataSums, dataSumsCert, dataSumsSig := datas[0], datas[1], datas[2]
 
 	identity := buildIdentity(version, stable)
 	err = cosigncheck.Check(dataSums, dataSumsSig, dataSumsCert, identity, issuer, r.conf.Displayer)
 	if err == nil || err != cosigncheck.ErrNotInstalled {
-		return err
+		return dataSums, manifest.SignatureCosign, err
 	}
 
 	if !stable {
+		if r.conf.TofuStrictSig {
+			return nil, "", cosigncheck.ErrStrict
+		}
+
 		r.conf.Displayer.Display("skip signature check : cosign executable not found and pgp check not available for unstable version")
 
-		return nil
+		return dataSums, manifest.SignatureSkipped, nil
 	}
 
 	r.conf.Displayer.Display("cosign executable not found, fallback to pgp check")
 
-	dataSumsSig, err = download.Bytes(assetURLs[4], r.conf.Displayer.Display)
+	dataSumsSig, err = download.Bytes(assetURLs[4], r.conf.Displayer.Display, requestOptions...)
 	if err != nil {
-		return err
+		return nil, "", err
 	}
 
 	var dataPublicKey []byte
@@ -199,10 +297,10 @@ func (r TofuRetriever) checkSumAndSig(version *version.Version, stable bool, dat
 	}
 
 	if err != nil {
-		return err
+		return nil, "", err
 	}
 
-	return pgpcheck.Check(dataSums, dataSumsSig, dataPublicKey)
+	return dataSums, manifest.SignaturePGP, pgpcheck.Check(dataSums, dataSumsSig, dataPublicKey)
 }
 
 func buildAssetNames(version string, arch string, stable bool) []string {
@@ -0,0 +1,84 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package tofuretriever
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+
+	"github.com/tofuutils/tenv/v2/config"
+	cosigncheck "github.com/tofuutils/tenv/v2/pkg/check/cosign"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager/manifest"
+)
+
+func TestDownloadSumsStrictSignature(t *testing.T) {
+	t.Parallel()
+
+	if _, err := exec.LookPath("cosign"); err == nil {
+		t.Skip("cases without cosign executable")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		_, _ = writer.Write([]byte("sums"))
+	}))
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		name          string
+		version       string
+		skipSignature bool
+		strict        bool
+		wantSignature string
+		wantErr       error
+	}{
+		{name: "SkipFlag", version: "1.6.2", skipSignature: true, wantSignature: manifest.SignatureSkipped},
+		{name: "SkipFlagStrict", version: "1.6.2", skipSignature: true, strict: true, wantErr: cosigncheck.ErrStrict},
+		{name: "UnstableWithoutCosign", version: "1.7.0-rc1", wantSignature: manifest.SignatureSkipped},
+		{name: "UnstableWithoutCosignStrict", version: "1.7.0-rc1", strict: true, wantErr: cosigncheck.ErrStrict},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			v, err := version.NewVersion(tt.version)
+			if err != nil {
+				t.Fatal("Unexpected error :", err)
+			}
+
+			stable := v.Prerelease() == ""
+			assetURLs := []string{server.URL + "/zip", server.URL + "/sums", server.URL + "/pem", server.URL + "/sig", server.URL + "/gpgsig"}
+			retriever := Make(&config.Config{Displayer: loghelper.InertDisplayer, SkipSignature: tt.skipSignature, TofuStrictSig: tt.strict})
+			dataSums, signature, err := retriever.downloadSumsAndCheckSig(v, stable, assetURLs, nil)
+			if err != tt.wantErr {
+				t.Fatal("Unmatching error, get :", err)
+			}
+
+			if tt.wantErr == nil && (string(dataSums) != "sums" || signature != tt.wantSignature) {
+				t.Error("Unmatching results, get :", string(dataSums), signature)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package semantic_test
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-version"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager/semantic"
+)
+
+//go:embed testdata/required_versions.txt
+var requiredVersionsData []byte
+
+var sampleVersions = []string{ //nolint
+	"0.11.14", "0.12.0", "0.13.7", "1.0.0", "1.1.5", "1.2.9", "1.3.0", "1.5.3", "1.5.7", "1.6.0-alpha1",
+	"1.6.0-rc1", "1.6.0", "1.6.2", "1.7.0-rc1", "1.7.0", "1.7.1", "2.0.0", "v1.6.0", "1.6", "not-a-version",
+}
+
+func requiredVersionsCorpus(t testing.TB) []string {
+	t.Helper()
+
+	var corpus []string
+	scanner := bufio.NewScanner(bytes.NewReader(requiredVersionsData))
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" && !strings.HasPrefix(line, "#") {
+			corpus = append(corpus, line)
+		}
+	}
+
+	return corpus
+}
+
+// checkPredicate parses constraintStr and compares the predicate with go-version reference behaviour on versionStr.
+func checkPredicate(t *testing.T, constraintStr string, versionStr string) {
+	t.Helper()
+
+	conf := &config.Config{Displayer: loghelper.InertDisplayer}
+	predicateInfo, err := semantic.ParsePredicate(constraintStr, "OpenTofu", noDefaultConstraint{}, nil, conf)
+	if err != nil {
+		return // rejected input, no crash
+	}
+
+	if predicateInfo.Exact != "" && !predicateInfo.Predicate(predicateInfo.Exact) {
+		t.Errorf("Exact version %q of %q should match its predicate", predicateInfo.Exact, constraintStr)
+	}
+
+	matched := predicateInfo.Predicate(versionStr)
+	if strings.HasPrefix(constraintStr, semantic.LatestPrefix) || strings.HasPrefix(constraintStr, semantic.MinPrefix) {
+		return // regexp predicates are not version constraints
+	}
+
+	switch constraintStr {
+	case semantic.LatestAllowedKey, semantic.LatestKey, semantic.LatestStableKey, semantic.MinRequiredKey:
+		if matched != semantic.StableVersion(versionStr) {
+			t.Errorf("Predicate of %q on %q should match stable versions", constraintStr, versionStr)
+		}
+
+		return
+	case semantic.LatestPreKey:
+		if !matched {
+			t.Errorf("Predicate of %q on %q should match any version", constraintStr, versionStr)
+		}
+
+		return
+	}
+
+	constraint, err := version.NewConstraint(constraintStr)
+	if err != nil {
+		t.Fatalf("ParsePredicate accepted %q rejected by go-version : %s", constraintStr, err)
+	}
+
+	v, err := version.NewVersion(versionStr)
+	if expected := err == nil && constraint.Check(v); matched != expected {
+		t.Errorf("Predicate of %q on %q returned %t, go-version returns %t", constraintStr, versionStr, matched, expected)
+	}
+
+	// round trip : the constraint string form gives the same predicate
+	reparsed, err := semantic.ParsePredicate(constraint.String(), "OpenTofu", noDefaultConstraint{}, nil, conf)
+	if err != nil {
+		t.Fatalf("Reparsing %q (from %q) failed : %s", constraint.String(), constraintStr, err)
+	}
+	if reparsed.Predicate(versionStr) != matched || reparsed.Exact != predicateInfo.Exact {
+		t.Errorf("Reparsing %q (from %q) changes the predicate", constraint.String(), constraintStr)
+	}
+}
+
+func TestPredicateProperties(t *testing.T) {
+	t.Parallel()
+
+	for _, constraintStr := range requiredVersionsCorpus(t) {
+		for _, versionStr := range sampleVersions {
+			checkPredicate(t, constraintStr, versionStr)
+		}
+	}
+}
+
+func TestCmpVersionProperties(t *testing.T) {
+	t.Parallel()
+
+	for _, v1 := range sampleVersions {
+		for _, v2 := range sampleVersions {
+			if cmp, reverseCmp := semantic.CmpVersion(v1, v2), semantic.CmpVersion(v2, v1); cmp != -reverseCmp {
+				t.Errorf("CmpVersion is not antisymmetric on %q and %q : %d and %d", v1, v2, cmp, reverseCmp)
+			}
+		}
+	}
+}
+
+func FuzzParsePredicate(f *testing.F) {
+	for _, constraintStr := range requiredVersionsCorpus(f) {
+		for _, versionStr := range sampleVersions {
+			f.Add(constraintStr, versionStr)
+		}
+	}
+
+	f.Fuzz(checkPredicate)
+}
+
+func FuzzCmpVersion(f *testing.F) {
+	for index, versionStr := range sampleVersions {
+		f.Add(versionStr, sampleVersions[len(sampleVersions)-1-index])
+	}
+
+	f.Fuzz(func(t *testing.T, v1 string, v2 string) {
+		if cmp := semantic.CmpVersion(v1, v1); cmp != 0 {
+			t.Errorf("CmpVersion(%q, %q) should be 0, get %d", v1, v1, cmp)
+		}
+
+		if cmp, reverseCmp := semantic.CmpVersion(v1, v2), semantic.CmpVersion(v2, v1); cmp != -reverseCmp {
+			t.Errorf("CmpVersion is not antisymmetric on %q and %q : %d and %d", v1, v2, cmp, reverseCmp)
+		}
+	})
+}
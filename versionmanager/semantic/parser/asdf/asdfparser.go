@@ -0,0 +1,124 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package asdfparser
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/config/cmdconst"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager/semantic/types"
+)
+
+const (
+	FileName = ".tool-versions"
+
+	commentPrefix = "#"
+	systemVersion = "system"
+)
+
+var toolNames = map[string]string{ //nolint
+	cmdconst.AtmosName:      "atmos",
+	cmdconst.ConftestName:   "conftest",
+	cmdconst.OpaName:        "opa",
+	cmdconst.TerraformName:  "terraform",
+	cmdconst.TerragruntName: "terragrunt",
+	cmdconst.TofuName:       "opentofu",
+}
+
+// ToolName returns the asdf plugin name of a tool (its executable name when unknown).
+func ToolName(execName string) string {
+	if toolName, ok := toolNames[execName]; ok {
+		return toolName
+	}
+
+	return execName
+}
+
+// MakeRetriever returns a version file parser reading the line of toolName (asdf plugin name).
+func MakeRetriever(toolName string) func(string, *config.Config) (string, error) {
+	return func(filePath string, conf *config.Config) (string, error) {
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			conf.Displayer.Log(loghelper.LevelWarnOrDebug(errors.Is(err, fs.ErrNotExist)), "Failed to read asdf file", loghelper.Error, err)
+
+			return "", nil
+		}
+
+		resolvedVersion := ParseVersion(data, toolName)
+		if resolvedVersion == "" {
+			return "", nil
+		}
+
+		return types.DisplayDetectionInfo(conf.Displayer, resolvedVersion, filePath), nil
+	}
+}
+
+// ParseVersion returns the first version of toolName line, fallback versions and non installable ones
+// ("system", "ref:..." or "path:...") are ignored.
+func ParseVersion(content []byte, toolName string) string {
+	for _, line := range strings.Split(string(content), "\n") {
+		line, _, _ = strings.Cut(line, commentPrefix)
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != toolName {
+			continue
+		}
+
+		if version := fields[1]; version != systemVersion && !strings.Contains(version, ":") {
+			return version
+		}
+
+		return ""
+	}
+
+	return ""
+}
+
+// SetVersion returns content with the line of toolName replaced (or appended), other lines and comments are kept.
+func SetVersion(content []byte, toolName string, version string) []byte {
+	newLine := toolName + " " + version
+
+	var lines []string
+	found := false
+	for _, line := range strings.Split(string(bytes.TrimRight(content, "\n")), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 0 && fields[0] == toolName {
+			if found {
+				continue // keep a single entry
+			}
+			found = true
+			line = newLine
+		}
+		lines = append(lines, line)
+	}
+
+	if len(lines) == 1 && lines[0] == "" {
+		lines = lines[:0]
+	}
+	if !found {
+		lines = append(lines, newLine)
+	}
+
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
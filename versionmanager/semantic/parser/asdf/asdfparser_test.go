@@ -0,0 +1,53 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package asdfparser_test
+
+import (
+	"testing"
+
+	asdfparser "github.com/tofuutils/tenv/v2/versionmanager/semantic/parser/asdf"
+)
+
+func TestSetVersion(t *testing.T) {
+	t.Parallel()
+
+	content := "# pinned tools\nopentofu 1.6.0\nnodejs 20.11.0\n"
+	if result := string(asdfparser.SetVersion([]byte(content), "opentofu", "1.6.2")); result != "# pinned tools\nopentofu 1.6.2\nnodejs 20.11.0\n" {
+		t.Error("Unmatching results, get :", result)
+	}
+
+	if result := string(asdfparser.SetVersion([]byte(content), "terraform", "1.7.0")); result != content+"terraform 1.7.0\n" {
+		t.Error("Unmatching results, get :", result)
+	}
+
+	if result := string(asdfparser.SetVersion(nil, "atmos", "1.80.0")); result != "atmos 1.80.0\n" {
+		t.Error("Unmatching results, get :", result)
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	t.Parallel()
+
+	content := []byte("# pinned tools\nopentofu 1.6.2 1.6.1 # fallback\nterraform system\nterragrunt ref:v0.55.0\nnodejs 20.11.0\n")
+	for toolName, want := range map[string]string{"opentofu": "1.6.2", "terraform": "", "terragrunt": "", "atmos": ""} {
+		if result := asdfparser.ParseVersion(content, toolName); result != want {
+			t.Errorf("Unmatching results for %s, want %q, get %q", toolName, want, result)
+		}
+	}
+}
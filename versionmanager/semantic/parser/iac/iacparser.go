@@ -20,25 +20,62 @@ package iacparser
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/convert"
 
 	"github.com/tofuutils/tenv/v2/config"
 	"github.com/tofuutils/tenv/v2/config/cmdconst"
+	"github.com/tofuutils/tenv/v2/pkg/ignorefile"
 	"github.com/tofuutils/tenv/v2/pkg/loghelper"
 )
 
-const requiredVersionName = "required_version"
+const (
+	IgnoreFileName      = ".tenvignore"
+	requiredVersionName = "required_version"
+
+	tofuExt     = ".tofu"
+	tofuJSONExt = ".tofu.json"
+)
 
 type ExtDescription struct {
 	Value  string
 	Parser func(string) (*hcl.File, hcl.Diagnostics)
 }
 
+// TfExts returns descriptions of Terraform files extensions (also read by OpenTofu).
+func TfExts(hclParser *hclparse.Parser) []ExtDescription {
+	return []ExtDescription{
+		{Value: ".tf", Parser: hclParser.ParseHCLFile},
+		{Value: ".tf.json", Parser: hclParser.ParseJSONFile},
+	}
+}
+
+// TofuExts returns descriptions of OpenTofu files extensions : specific ones first,
+// because like OpenTofu, a file like versions.tofu hides versions.tf (see filterExts).
+func TofuExts(hclParser *hclparse.Parser) []ExtDescription {
+	return append([]ExtDescription{
+		{Value: tofuExt, Parser: hclParser.ParseHCLFile},
+		{Value: tofuJSONExt, Parser: hclParser.ParseJSONFile},
+	}, TfExts(hclParser)...)
+}
+
+// IsTofuFile reports whether name has an OpenTofu specific extension.
+func IsTofuFile(name string) bool {
+	return strings.HasSuffix(name, tofuExt) || strings.HasSuffix(name, tofuJSONExt)
+}
+
+// FileRequirement is a required_version constraint with the path of the file declaring it.
+type FileRequirement struct {
+	FilePath string
+	Required string
+}
+
 var terraformPartialSchema = &hcl.BodySchema{ //nolint
 	Blocks: []hcl.BlockHeaderSchema{{Type: cmdconst.TerraformName}},
 }
@@ -48,6 +85,13 @@ var versionPartialSchema = &hcl.BodySchema{ //nolint
 }
 
 func GatherRequiredVersion(conf *config.Config, exts []ExtDescription) ([]string, error) {
+	fileRequirements, err := GatherFileRequirements(conf, exts)
+
+	return requiredValues(fileRequirements), err
+}
+
+// GatherFileRequirements is like GatherRequiredVersion, it keeps the file declaring each constraint.
+func GatherFileRequirements(conf *config.Config, exts []ExtDescription) ([]FileRequirement, error) {
 	if len(exts) == 0 {
 		return nil, nil
 	}
@@ -65,11 +109,25 @@ func GatherRequiredVersion(conf *config.Config, exts []ExtDescription) ([]string
 		}()
 	}
 
-	entries, err := os.ReadDir(".")
+	ignoreMatcher, err := readIgnoreFile(conf)
 	if err != nil {
 		return nil, err
 	}
 
+	foundFiles, fileRequirements, err := gatherRequiredVersionInDir(".", conf, exts, ignoreMatcher)
+	if err != nil || conf.IaCScanDepth <= 0 {
+		return fileRequirements, err
+	}
+
+	return gatherRecursive(conf, exts, &foundFiles, fileRequirements)
+}
+
+func gatherRequiredVersionInDir(dirPath string, conf *config.Config, exts []ExtDescription, ignoreMatcher ignorefile.Matcher) ([]string, []FileRequirement, error) {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	similar := map[string][]string{}
 	for _, entry := range entries {
 		if entry.IsDir() {
@@ -77,6 +135,11 @@ func GatherRequiredVersion(conf *config.Config, exts []ExtDescription) ([]string
 		}
 
 		name := entry.Name()
+		if relPath := filepath.ToSlash(filepath.Join(dirPath, name)); ignoreMatcher.Match(relPath, false) {
+			conf.Displayer.Log(hclog.Debug, "Ignored by "+IgnoreFileName, "filePath", relPath)
+
+			continue
+		}
 		for _, extDesc := range exts {
 			if cleanedName, found := strings.CutSuffix(name, extDesc.Value); found { //nolint
 				similar[cleanedName] = append(similar[cleanedName], extDesc.Value)
@@ -86,28 +149,56 @@ func GatherRequiredVersion(conf *config.Config, exts []ExtDescription) ([]string
 		}
 	}
 
-	var requireds []string
+	var fileRequirements []FileRequirement
 	var parsedFile *hcl.File
 	var diags hcl.Diagnostics
-	foundFiles = make([]string, 0, len(similar))
+	foundFiles := make([]string, 0, len(similar))
 	for cleanedName, fileExts := range similar {
 		ext := filterExts(fileExts, exts)
-		name := cleanedName + ext.Value
+		name := filepath.Join(dirPath, cleanedName+ext.Value)
 		foundFiles = append(foundFiles, name)
 
 		parsedFile, diags = ext.Parser(name)
 		if diags.HasErrors() {
-			return nil, diags
+			legacyRequireds, found := scanLegacyRequiredVersion(name)
+			if !found {
+				return foundFiles, nil, diags
+			}
+			conf.Displayer.Log(hclog.Warn, "Failed to parse hcl file, required_version read with legacy (HCL1) syntax", "filePath", name, loghelper.Error, diags)
+			fileRequirements = appendFileRequirements(fileRequirements, name, legacyRequireds)
+
+			continue
 		}
 		if parsedFile == nil {
 			continue
 		}
 
 		extracted := extractRequiredVersion(parsedFile.Body, conf)
-		requireds = append(requireds, extracted...)
+		fileRequirements = appendFileRequirements(fileRequirements, name, extracted)
 	}
 
-	return requireds, nil
+	return foundFiles, fileRequirements, nil
+}
+
+func appendFileRequirements(fileRequirements []FileRequirement, filePath string, requireds []string) []FileRequirement {
+	for _, required := range requireds {
+		fileRequirements = append(fileRequirements, FileRequirement{FilePath: filePath, Required: required})
+	}
+
+	return fileRequirements
+}
+
+func requiredValues(fileRequirements []FileRequirement) []string {
+	if len(fileRequirements) == 0 {
+		return nil
+	}
+
+	requireds := make([]string, 0, len(fileRequirements))
+	for _, fileRequirement := range fileRequirements {
+		requireds = append(requireds, fileRequirement.Required)
+	}
+
+	return requireds
 }
 
 func extractRequiredVersion(body hcl.Body, conf *config.Config) []string {
@@ -163,6 +254,15 @@ func extractRequiredVersion(body hcl.Body, conf *config.Config) []string {
 	return requireds
 }
 
+func readIgnoreFile(conf *config.Config) (ignorefile.Matcher, error) {
+	ignoreMatcher, err := ignorefile.Read(IgnoreFileName)
+	if err == nil && !ignoreMatcher.Empty() {
+		conf.Displayer.Log(hclog.Debug, "Apply exclusion rules", "filePath", IgnoreFileName)
+	}
+
+	return ignoreMatcher, err
+}
+
 func filterExts(fileExts []string, exts []ExtDescription) ExtDescription {
 	for _, ext := range exts { // has a meaningful order
 		for _, fileExt := range fileExts {
@@ -0,0 +1,133 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package iacparser_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2/hclparse"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	iacparser "github.com/tofuutils/tenv/v2/versionmanager/semantic/parser/iac"
+)
+
+func TestIsTofuFile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "versions.tofu", want: true},
+		{name: "main.tofu.json", want: true},
+		{name: "main.tf"},
+		{name: "main.tf.json"},
+		{name: "tofu.txt"},
+		{name: "main.tofu.bak"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := iacparser.IsTofuFile(tt.name); got != tt.want {
+				t.Error("Unmatching results, get :", got)
+			}
+		})
+	}
+}
+
+// the agnostic proxy (IsTofuFile) and detection (TofuExts) must agree on OpenTofu specific extensions.
+func TestTofuExtsShared(t *testing.T) {
+	t.Parallel()
+
+	hclParser := hclparse.NewParser()
+	tfExts := map[string]bool{}
+	for _, ext := range iacparser.TfExts(hclParser) {
+		tfExts[ext.Value] = true
+	}
+
+	values := make([]string, 0, 4)
+	for _, ext := range iacparser.TofuExts(hclParser) {
+		values = append(values, ext.Value)
+		if got := iacparser.IsTofuFile("versions" + ext.Value); got == tfExts[ext.Value] {
+			t.Error("Unmatching results for", ext.Value, ", get :", got)
+		}
+	}
+
+	if want := []string{".tofu", ".tofu.json", ".tf", ".tf.json"}; !reflect.DeepEqual(values, want) {
+		t.Error("Unmatching results, get :", values, ", want :", want)
+	}
+}
+
+// change working directory, so not parallel.
+func TestGatherRequiredVersionTofuExts(t *testing.T) { //nolint
+	previousPath, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+	defer os.Chdir(previousPath) //nolint
+
+	hclParser := hclparse.NewParser()
+	tests := []struct {
+		name  string
+		exts  []iacparser.ExtDescription
+		files map[string]string
+		want  []string
+	}{
+		{name: "TofuHidesTf", exts: iacparser.TofuExts(hclParser), files: map[string]string{
+			"versions.tf":   `terraform { required_version = "1.5.7" }`,
+			"versions.tofu": `terraform { required_version = "1.6.2" }`,
+		}, want: []string{"1.6.2"}},
+		{name: "TofuJSON", exts: iacparser.TofuExts(hclParser), files: map[string]string{
+			"versions.tofu.json": `{"terraform": {"required_version": "1.7.0"}}`,
+		}, want: []string{"1.7.0"}},
+		{name: "TfOnly", exts: iacparser.TofuExts(hclParser), files: map[string]string{
+			"versions.tf": `terraform { required_version = "1.5.7" }`,
+		}, want: []string{"1.5.7"}},
+		{name: "TerraformIgnoreTofu", exts: iacparser.TfExts(hclParser), files: map[string]string{
+			"versions.tf":   `terraform { required_version = "1.5.7" }`,
+			"versions.tofu": `terraform { required_version = "1.6.2" }`,
+		}, want: []string{"1.5.7"}},
+	}
+
+	// sequential, each case change working directory
+	for _, tt := range tests {
+		basePath := t.TempDir()
+		for name, content := range tt.files {
+			if err = os.WriteFile(filepath.Join(basePath, name), []byte(content), 0o600); err != nil {
+				t.Fatal("Unexpected error :", err)
+			}
+		}
+
+		if err = os.Chdir(basePath); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+
+		conf := config.Config{Displayer: loghelper.InertDisplayer}
+		if requireds, err := iacparser.GatherRequiredVersion(&conf, tt.exts); err != nil || !reflect.DeepEqual(requireds, tt.want) {
+			t.Error(tt.name, "unmatching results, get :", requireds, err)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package iacparser
+
+import (
+	"os"
+	"regexp"
+)
+
+// Terraform 0.11 and older files use HCL1 syntax which HCL2 parser can reject,
+// their required_version is always a literal string.
+var legacyRequiredVersionRegexp = regexp.MustCompile(`(?m)^\s*` + requiredVersionName + `\s*=\s*"([^"$]+)"`) //nolint
+
+func scanLegacyRequiredVersion(filePath string) ([]string, bool) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, false
+	}
+
+	matches := legacyRequiredVersionRegexp.FindAllSubmatch(data, -1)
+	requireds := make([]string, 0, len(matches))
+	for _, match := range matches {
+		requireds = append(requireds, string(match[1]))
+	}
+
+	return requireds, len(requireds) != 0
+}
@@ -0,0 +1,101 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package iacparser
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/go-hclog"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+)
+
+type ModuleRequirement struct {
+	Key       string
+	Source    string
+	Requireds []string
+}
+
+type modulesManifest struct {
+	Modules []struct {
+		Key    string `json:"Key"`
+		Source string `json:"Source"`
+		Dir    string `json:"Dir"`
+	} `json:"Modules"`
+}
+
+// GatherModulesRequiredVersion read required_version of modules already downloaded by init
+// (listed in .terraform/modules/modules.json), without any network call.
+func GatherModulesRequiredVersion(conf *config.Config, exts []ExtDescription) ([]ModuleRequirement, error) {
+	if len(exts) == 0 {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(".terraform", "modules", "modules.json"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			conf.Displayer.Log(hclog.Debug, "No downloaded modules found")
+
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var manifest modulesManifest
+	if err = json.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+
+	ignoreMatcher, err := readIgnoreFile(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	var moduleRequirements []ModuleRequirement
+	for _, module := range manifest.Modules {
+		if module.Key == "" { // root module, already handled by GatherRequiredVersion
+			continue
+		}
+
+		if ignoreMatcher.Match(filepath.ToSlash(module.Dir), true) {
+			conf.Displayer.Log(hclog.Debug, "Ignored by "+IgnoreFileName, "module", module.Key)
+
+			continue
+		}
+
+		_, fileRequirements, err := gatherRequiredVersionInDir(module.Dir, conf, exts, ignoreMatcher)
+		if err != nil {
+			conf.Displayer.Log(hclog.Warn, "Failed to read module", "module", module.Key, loghelper.Error, err)
+
+			continue
+		}
+
+		if len(fileRequirements) != 0 {
+			moduleRequirements = append(moduleRequirements, ModuleRequirement{Key: module.Key, Source: module.Source, Requireds: requiredValues(fileRequirements)})
+		}
+	}
+
+	return moduleRequirements, nil
+}
@@ -0,0 +1,94 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package iacparser_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2/hclparse"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	iacparser "github.com/tofuutils/tenv/v2/versionmanager/semantic/parser/iac"
+)
+
+const modulesJSON = `{"Modules": [
+	{"Key": "", "Source": "", "Dir": "."},
+	{"Key": "vpc", "Source": "registry.terraform.io/example/vpc/aws", "Dir": ".terraform/modules/vpc"},
+	{"Key": "free", "Source": "./modules/free", "Dir": "modules/free"}
+]}`
+
+// change working directory, so not parallel.
+func TestGatherModulesRequiredVersion(t *testing.T) { //nolint
+	previousPath, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+	defer os.Chdir(previousPath) //nolint
+
+	vpcRequirement := iacparser.ModuleRequirement{Key: "vpc", Source: "registry.terraform.io/example/vpc/aws", Requireds: []string{">= 1.5.0"}}
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []iacparser.ModuleRequirement
+	}{
+		{name: "NotInitialized", files: map[string]string{"main.tf": `terraform { required_version = "1.6.2" }`}},
+		{name: "Downloaded", files: map[string]string{
+			".terraform/modules/modules.json":    modulesJSON,
+			".terraform/modules/vpc/versions.tf": `terraform { required_version = ">= 1.5.0" }`,
+			"main.tf":                            `terraform { required_version = "1.6.2" }`,
+			"modules/free/main.tf":               `variable "name" {}`,
+		}, want: []iacparser.ModuleRequirement{vpcRequirement}},
+		{name: "Ignored", files: map[string]string{
+			".tenvignore":                        ".terraform/modules/vpc/\n",
+			".terraform/modules/modules.json":    modulesJSON,
+			".terraform/modules/vpc/versions.tf": `terraform { required_version = ">= 1.5.0" }`,
+			"modules/free/main.tf":               `variable "name" {}`,
+		}},
+		{name: "MissingModuleDir", files: map[string]string{".terraform/modules/modules.json": modulesJSON}},
+	}
+
+	// sequential, each case change working directory
+	exts := iacparser.TfExts(hclparse.NewParser())
+	for _, tt := range tests {
+		basePath := t.TempDir()
+		for relPath, content := range tt.files {
+			filePath := filepath.Join(basePath, relPath)
+			if err = os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+				t.Fatal("Unexpected error :", err)
+			}
+
+			if err = os.WriteFile(filePath, []byte(content), 0o600); err != nil {
+				t.Fatal("Unexpected error :", err)
+			}
+		}
+
+		if err = os.Chdir(basePath); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+
+		conf := config.Config{Displayer: loghelper.InertDisplayer}
+		if moduleRequirements, err := iacparser.GatherModulesRequiredVersion(&conf, exts); err != nil || !reflect.DeepEqual(moduleRequirements, tt.want) {
+			t.Error(tt.name, "unmatching results, get :", moduleRequirements, err)
+		}
+	}
+}
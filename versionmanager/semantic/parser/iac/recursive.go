@@ -0,0 +1,92 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package iacparser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/ignorefile"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+)
+
+// read at project root in recursive mode, before .tenvignore (which has the final say).
+var recursiveIgnoreFileNames = []string{".gitignore", ".terraformignore"} //nolint
+
+// gatherRecursive adds requirements found in subdirectories (up to TENV_IAC_SCAN_DEPTH levels) to the current directory ones,
+// hidden directories (like .terraform or .git) are skipped. All constraints apply, so the selected version satisfies each of them.
+func gatherRecursive(conf *config.Config, exts []ExtDescription, pFoundFiles *[]string, fileRequirements []FileRequirement) ([]FileRequirement, error) {
+	matchers := make([]ignorefile.Matcher, 0, len(recursiveIgnoreFileNames)+1)
+	for _, fileName := range recursiveIgnoreFileNames {
+		matcher, err := ignorefile.Read(fileName)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, matcher)
+	}
+
+	ignoreMatcher, err := readIgnoreFile(conf)
+	if err != nil {
+		return nil, err
+	}
+	ignoreMatcher = ignorefile.Merge(append(matchers, ignoreMatcher)...)
+
+	return gatherInSubDirs(".", 1, conf, exts, ignoreMatcher, pFoundFiles, fileRequirements), nil
+}
+
+func gatherInSubDirs(dirPath string, depth int64, conf *config.Config, exts []ExtDescription, ignoreMatcher ignorefile.Matcher, pFoundFiles *[]string, fileRequirements []FileRequirement) []FileRequirement {
+	entries, err := os.ReadDir(dirPath)
+	if err != nil {
+		conf.Displayer.Log(hclog.Warn, "Failed to read directory", "dirPath", dirPath, loghelper.Error, err)
+
+		return fileRequirements
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		subDirPath := filepath.Join(dirPath, name)
+		if ignoreMatcher.Match(filepath.ToSlash(subDirPath), true) {
+			conf.Displayer.Log(hclog.Debug, "Ignored directory", "dirPath", subDirPath)
+
+			continue
+		}
+
+		foundFiles, subRequirements, err := gatherRequiredVersionInDir(subDirPath, conf, exts, ignoreMatcher)
+		*pFoundFiles = append(*pFoundFiles, foundFiles...)
+		if err != nil {
+			conf.Displayer.Log(hclog.Warn, "Failed to read IAC files", "dirPath", subDirPath, loghelper.Error, err)
+		} else {
+			fileRequirements = append(fileRequirements, subRequirements...)
+		}
+
+		if depth < conf.IaCScanDepth {
+			fileRequirements = gatherInSubDirs(subDirPath, depth+1, conf, exts, ignoreMatcher, pFoundFiles, fileRequirements)
+		}
+	}
+
+	return fileRequirements
+}
@@ -0,0 +1,109 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package miseparser
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager/semantic/types"
+)
+
+const (
+	// searched like other version files, .config/mise/config.toml is also the global mise configuration in user home.
+	ConfigFileName       = ".config/mise/config.toml"
+	FileName             = "mise.toml"
+	HiddenFileName       = ".mise.toml"
+	systemVersion        = "system"
+	versionName          = "version"
+	prefixConstraintHead = "~> "
+)
+
+type miseConfig struct {
+	Tools map[string]any `toml:"tools"`
+}
+
+// MakeRetriever returns a version file parser reading the version of toolName (mise short name, same as asdf plugin name)
+// in tools section.
+func MakeRetriever(toolName string) func(string, *config.Config) (string, error) {
+	return func(filePath string, conf *config.Config) (string, error) {
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			conf.Displayer.Log(loghelper.LevelWarnOrDebug(errors.Is(err, fs.ErrNotExist)), "Failed to read mise file", loghelper.Error, err)
+
+			return "", nil
+		}
+
+		resolvedVersion, err := ParseVersion(data, toolName)
+		if err != nil || resolvedVersion == "" {
+			return "", err
+		}
+
+		return types.DisplayDetectionInfo(conf.Displayer, resolvedVersion, filePath), nil
+	}
+}
+
+// ParseVersion returns the version of toolName, which can be a string, an array (first one is used) or a table with a version key.
+// Non installable values ("system", "ref:...", "path:...") are ignored and fuzzy versions (like "1.6") are converted to constraints.
+func ParseVersion(content []byte, toolName string) (string, error) {
+	var parsed miseConfig
+	if _, err := toml.Decode(string(content), &parsed); err != nil {
+		return "", err
+	}
+
+	var version string
+	switch value := parsed.Tools[toolName].(type) {
+	case string:
+		version = value
+	case []any:
+		if len(value) != 0 {
+			version, _ = value[0].(string)
+		}
+	case map[string]any:
+		version, _ = value[versionName].(string)
+	}
+
+	if version == systemVersion || strings.Contains(version, ":") {
+		return "", nil
+	}
+
+	return convertFuzzy(version), nil
+}
+
+// mise matches "1.6" with latest 1.6.x and "1" with latest 1.x.y, which is the meaning of pessimistic constraint with one more part.
+func convertFuzzy(version string) string {
+	parts := strings.Split(version, ".")
+	if len(parts) > 2 {
+		return version
+	}
+
+	for _, part := range parts {
+		if part == "" || strings.Trim(part, "0123456789") != "" {
+			return version
+		}
+	}
+
+	return prefixConstraintHead + version + ".0" // "~> 1.0" or "~> 1.6.0"
+}
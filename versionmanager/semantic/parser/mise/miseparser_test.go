@@ -0,0 +1,57 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package miseparser_test
+
+import (
+	"testing"
+
+	miseparser "github.com/tofuutils/tenv/v2/versionmanager/semantic/parser/mise"
+)
+
+func TestParseVersion(t *testing.T) {
+	t.Parallel()
+
+	content := []byte(`[env]
+TF_LOG = "info"
+
+[tools]
+opentofu = "1.6"
+terraform = ["1.5.7", "1.5.6"]
+terragrunt = { version = "0.55.1", os = ["linux"] }
+atmos = "system"
+conftest = "ref:main"
+opa = "latest"
+`)
+
+	for toolName, want := range map[string]string{
+		"opentofu": "~> 1.6.0", "terraform": "1.5.7", "terragrunt": "0.55.1", "atmos": "", "conftest": "", "opa": "latest", "node": "",
+	} {
+		result, err := miseparser.ParseVersion(content, toolName)
+		if err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+		if result != want {
+			t.Errorf("Unmatching results for %s, want %q, get %q", toolName, want, result)
+		}
+	}
+
+	if _, err := miseparser.ParseVersion([]byte("[tools"), "opentofu"); err == nil {
+		t.Error("Should fail on invalid toml")
+	}
+}
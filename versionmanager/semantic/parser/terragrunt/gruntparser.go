@@ -38,10 +38,15 @@ const (
 	HCLName  = "terragrunt.hcl"
 	JSONName = "terragrunt.hcl.json"
 
+	terraformBinaryName             = "terraform_binary"
 	terraformVersionConstraintName  = "terraform_version_constraint"
 	terragruntVersionConstraintName = "terragrunt_version_constraint"
 )
 
+var terraformBinaryPartialSchema = &hcl.BodySchema{ //nolint
+	Attributes: []hcl.AttributeSchema{{Name: terraformBinaryName}},
+}
+
 var terraformVersionPartialSchema = &hcl.BodySchema{ //nolint
 	Attributes: []hcl.AttributeSchema{{Name: terraformVersionConstraintName}},
 }
@@ -58,6 +63,16 @@ func Make(parser *hclparse.Parser) TerragruntParser {
 	return TerragruntParser{parser: parser}
 }
 
+// RetrieveTerraformBinary return terraform_binary attribute from terragrunt file in working directory (hcl before json).
+func (p TerragruntParser) RetrieveTerraformBinary(conf *config.Config) (string, error) {
+	binary, err := retrieveStringAttribute(HCLName, p.parser.ParseHCL, terraformBinaryPartialSchema, terraformBinaryName, conf)
+	if err != nil || binary != "" {
+		return binary, err
+	}
+
+	return retrieveStringAttribute(JSONName, p.parser.ParseJSON, terraformBinaryPartialSchema, terraformBinaryName, conf)
+}
+
 func (p TerragruntParser) RetrieveTerraformVersionConstraintFromHCL(filePath string, conf *config.Config) (string, error) {
 	return retrieveVersionConstraintFromFile(filePath, p.parser.ParseHCL, terraformVersionPartialSchema, terraformVersionConstraintName, conf)
 }
@@ -75,6 +90,15 @@ func (p TerragruntParser) RetrieveTerragruntVersionConstraintFromJSON(filePath s
 }
 
 func retrieveVersionConstraintFromFile(filePath string, fileParser func([]byte, string) (*hcl.File, hcl.Diagnostics), versionPartialShema *hcl.BodySchema, versionConstraintName string, conf *config.Config) (string, error) {
+	value, err := retrieveStringAttribute(filePath, fileParser, versionPartialShema, versionConstraintName, conf)
+	if err != nil || value == "" {
+		return "", err
+	}
+
+	return types.DisplayDetectionInfo(conf.Displayer, value, filePath), nil
+}
+
+func retrieveStringAttribute(filePath string, fileParser func([]byte, string) (*hcl.File, hcl.Diagnostics), partialShema *hcl.BodySchema, attributeName string, conf *config.Config) (string, error) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
 		conf.Displayer.Log(loghelper.LevelWarnOrDebug(errors.Is(err, fs.ErrNotExist)), "Failed to read terragrunt file", loghelper.Error, err)
@@ -92,14 +116,14 @@ func retrieveVersionConstraintFromFile(filePath string, fileParser func([]byte,
 		return "", nil
 	}
 
-	content, _, diags := parsedFile.Body.PartialContent(versionPartialShema)
+	content, _, diags := parsedFile.Body.PartialContent(partialShema)
 	if diags.HasErrors() {
 		conf.Displayer.Log(hclog.Warn, "Failed to parse terragrunt file", loghelper.Error, diags)
 
 		return "", nil
 	}
 
-	attr, exists := content.Attributes[versionConstraintName]
+	attr, exists := content.Attributes[attributeName]
 	if !exists {
 		return "", nil
 	}
@@ -130,5 +154,5 @@ func retrieveVersionConstraintFromFile(filePath string, fileParser func([]byte,
 		return "", nil
 	}
 
-	return types.DisplayDetectionInfo(conf.Displayer, val.AsString(), filePath), nil
+	return val.AsString(), nil
 }
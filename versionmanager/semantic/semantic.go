@@ -72,7 +72,7 @@ func ParsePredicate(behaviourOrConstraint string, displayName string, constraint
 			return types.PredicateInfo{}, err
 		}
 		if len(constraints) != 0 {
-			return types.PredicateInfo{Predicate: predicateFromConstraint(constraints), ReverseOrder: reverseOrder}, nil
+			return types.PredicateInfo{Exact: pinnedVersion(constraints), Predicate: predicateFromConstraint(constraints), ReverseOrder: reverseOrder}, nil
 		}
 
 		conf.Displayer.Display(loghelper.Concat("No ", displayName, " version requirement found in project files, fallback to ", LatestKey, " strategy"))
@@ -101,7 +101,7 @@ func ParsePredicate(behaviourOrConstraint string, displayName string, constraint
 			return types.PredicateInfo{}, err
 		}
 
-		return types.PredicateInfo{Predicate: predicateFromConstraint(constraint), ReverseOrder: true}, nil
+		return types.PredicateInfo{Exact: pinnedVersion(constraint), Predicate: predicateFromConstraint(constraint), ReverseOrder: true}, nil
 	}
 }
 
@@ -133,6 +133,25 @@ func alwaysTrue(string) bool {
 	return true
 }
 
+// return the version pinned by an equality constraint, or an empty string (no equality or conflicting constraints).
+func pinnedVersion(constraint version.Constraints) string {
+	for _, required := range constraint {
+		requiredStr := strings.TrimSpace(required.String())
+		if requiredStr == "" || strings.ContainsAny(requiredStr[:1], "!<>~") {
+			continue
+		}
+
+		v, err := version.NewVersion(strings.TrimSpace(strings.TrimPrefix(requiredStr, "=")))
+		if err != nil || !constraint.Check(v) {
+			return ""
+		}
+
+		return v.String()
+	}
+
+	return ""
+}
+
 func predicateFromConstraint(constraint version.Constraints) func(string) bool {
 	return func(versionStr string) bool {
 		v, err := version.NewVersion(versionStr)
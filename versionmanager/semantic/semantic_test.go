@@ -22,9 +22,17 @@ import (
 	"slices"
 	"testing"
 
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
 	"github.com/tofuutils/tenv/v2/versionmanager/semantic"
 )
 
+type noDefaultConstraint struct{}
+
+func (noDefaultConstraint) ReadDefaultConstraint() string {
+	return ""
+}
+
 func TestCmpVersion(t *testing.T) {
 	t.Parallel()
 
@@ -49,3 +57,19 @@ func TestStableVersion(t *testing.T) {
 		t.Error("Unmatching results, get :", filtered)
 	}
 }
+
+func TestParsePredicateExact(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.Config{Displayer: loghelper.InertDisplayer}
+	for constraint, expected := range map[string]string{"1.6.2": "1.6.2", "= 1.6": "1.6.0", ">= 1.6.0, =1.7.0": "1.7.0", "=1.7.0-rc1": "1.7.0-rc1", "~> 1.6.0": "", "= 1.6.2, < 1.6.0": ""} {
+		predicateInfo, err := semantic.ParsePredicate(constraint, "OpenTofu", noDefaultConstraint{}, nil, conf)
+		if err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+
+		if predicateInfo.Exact != expected {
+			t.Error("Unmatching result for", constraint, ", get :", predicateInfo.Exact)
+		}
+	}
+}
@@ -19,26 +19,49 @@
 package types
 
 import (
+	"strings"
+
 	"github.com/tofuutils/tenv/v2/config"
 	"github.com/tofuutils/tenv/v2/pkg/loghelper"
 )
 
+// DetectionInfoPrefix starts messages displayed with DisplayDetectionInfo.
+const DetectionInfoPrefix = "Resolved version from "
+
+// SourceRecorder keeps the source of the last displayed detection info.
+type SourceRecorder struct {
+	loghelper.Displayer
+	Source string
+}
+
+func (r *SourceRecorder) Display(msg string) {
+	if detectionInfo, found := strings.CutPrefix(msg, DetectionInfoPrefix); found {
+		if index := strings.LastIndex(detectionInfo, " : "); index != -1 {
+			r.Source = detectionInfo[:index]
+		}
+	}
+
+	r.Displayer.Display(msg)
+}
+
 type ConstraintInfo interface {
 	ReadDefaultConstraint() string
 }
 
 func DisplayDetectionInfo(displayer loghelper.Displayer, version string, source string) string {
-	displayer.Display(loghelper.Concat("Resolved version from ", source, " : ", version))
+	displayer.Display(loghelper.Concat(DetectionInfoPrefix, source, " : ", version))
 
 	return version
 }
 
 type PredicateInfo struct {
+	Exact        string // version pinned by an equality constraint, searched locally without listing installed versions
 	Predicate    func(string) bool
 	ReverseOrder bool
 }
 
 type VersionFile struct {
-	Name   string
-	Parser func(filePath string, conf *config.Config) (string, error)
+	ConflictOnly bool // file of a related tool, never used for resolution, only compared with the used file of the same directory
+	Name         string
+	Parser       func(filePath string, conf *config.Config) (string, error)
 }
@@ -26,7 +26,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/go-version"
-	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/config"
 	"github.com/tofuutils/tenv/v2/versionmanager/lastuse"
 )
 
@@ -44,7 +44,7 @@ const (
 var errDurationParsing = errors.New("unrecognized duration format")
 
 // versions must be sorted in descending order.
-func SelectVersionsToUninstall(behaviourOrConstraint string, installPath string, versions []string, displayer loghelper.Displayer) ([]string, error) {
+func SelectVersionsToUninstall(behaviourOrConstraint string, installPath string, versions []string, conf *config.Config) ([]string, error) {
 	switch {
 	case behaviourOrConstraint == allKey:
 		return versions, nil
@@ -55,28 +55,11 @@ func SelectVersionsToUninstall(behaviourOrConstraint string, installPath string,
 
 		return versions[1:], nil // allowed by descending order
 	case strings.HasPrefix(behaviourOrConstraint, notUsedForPrefix):
-		forStr := behaviourOrConstraint[notUsedForPrefixLen:]
-
-		var err error
-		daysInt, monthsInt := 0, 0
-		lastIndex := len(forStr) - 1
-		switch forStr[lastIndex] {
-		case 'd', 'D':
-			daysInt, err = strconv.Atoi(forStr[:lastIndex])
-			if err != nil {
-				return nil, err
-			}
-		case 'm', 'M':
-			monthsInt, err = strconv.Atoi(forStr[:lastIndex])
-			if err != nil {
-				return nil, err
-			}
-		default:
-			return nil, errDurationParsing
+		beforeDate, err := UnusedForLimit(behaviourOrConstraint[notUsedForPrefixLen:])
+		if err != nil {
+			return nil, err
 		}
-
-		beforeDate := time.Now().AddDate(0, -monthsInt, -daysInt)
-		pred := predicateBeforeDate(installPath, beforeDate, displayer)
+		pred := predicateBeforeDate(installPath, beforeDate, conf)
 
 		return filterStrings(versions, pred), nil
 	case strings.HasPrefix(behaviourOrConstraint, notUsedSincePrefix):
@@ -86,7 +69,7 @@ func SelectVersionsToUninstall(behaviourOrConstraint string, installPath string,
 		if err != nil {
 			return nil, err
 		}
-		pred := predicateBeforeDate(installPath, beforeDate, displayer)
+		pred := predicateBeforeDate(installPath, beforeDate, conf)
 
 		return filterStrings(versions, pred), nil
 	default:
@@ -100,6 +83,31 @@ func SelectVersionsToUninstall(behaviourOrConstraint string, installPath string,
 	}
 }
 
+// UnusedForLimit convert a duration in days or months (like "14d" or "2m") to the date before which a version is considered unused.
+func UnusedForLimit(forStr string) (time.Time, error) {
+	lastIndex := len(forStr) - 1
+	if lastIndex < 1 {
+		return time.Time{}, errDurationParsing
+	}
+
+	var err error
+	daysInt, monthsInt := 0, 0
+	switch forStr[lastIndex] {
+	case 'd', 'D':
+		daysInt, err = strconv.Atoi(forStr[:lastIndex])
+	case 'm', 'M':
+		monthsInt, err = strconv.Atoi(forStr[:lastIndex])
+	default:
+		err = errDurationParsing
+	}
+
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Now().AddDate(0, -monthsInt, -daysInt), nil
+}
+
 func filterStrings(stringSlice []string, pred func(string) bool) []string {
 	selected := make([]string, 0, len(stringSlice))
 	for _, str := range stringSlice {
@@ -111,9 +119,9 @@ func filterStrings(stringSlice []string, pred func(string) bool) []string {
 	return selected
 }
 
-func predicateBeforeDate(installPath string, beforeDate time.Time, displayer loghelper.Displayer) func(string) bool {
+func predicateBeforeDate(installPath string, beforeDate time.Time, conf *config.Config) func(string) bool {
 	return func(versionStr string) bool {
-		useDate := lastuse.Read(filepath.Join(installPath, versionStr), displayer)
+		useDate := lastuse.Read(filepath.Join(installPath, versionStr), conf)
 
 		return useDate.Before(beforeDate)
 	}
@@ -19,49 +19,140 @@
 package semantic
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-version"
+
 	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
 	"github.com/tofuutils/tenv/v2/versionmanager/semantic/types"
 )
 
+var ErrConflictingFiles = errors.New("conflicting version files")
+
 func RetrieveVersion(versionFiles []types.VersionFile, conf *config.Config) (string, error) {
-	for _, versionFile := range versionFiles {
-		if version, err := versionFile.Parser(versionFile.Name, conf); err != nil || version != "" {
+	if version, err := retrieveVersionFromDir(versionFiles, "", conf); err != nil || version != "" {
+		return version, err
+	}
+
+	dirPaths, err := SearchedDirs(conf)
+	if err != nil {
+		return "", err
+	}
+
+	for _, dirPath := range dirPaths[1:] {
+		if version, err := retrieveVersionFromDir(versionFiles, dirPath, conf); err != nil || version != "" {
 			return version, err
 		}
 	}
 
+	return "", nil
+}
+
+// SearchedDirs returns the directories where version files are searched, by precedence :
+// working directory (as an empty path), its parents and user home.
+func SearchedDirs(conf *config.Config) ([]string, error) {
 	previousPath, err := os.Getwd()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
+	// inside a repository, files from its parents are ignored (user home is still checked)
+	dirPaths := []string{""}
+	markers := conf.BoundaryMarkers()
 	userPathDone := false
-	for currentPath := filepath.Dir(previousPath); currentPath != previousPath; previousPath, currentPath = currentPath, filepath.Dir(currentPath) {
-		if version, err := retrieveVersionFromDir(versionFiles, currentPath, conf); err != nil || version != "" {
-			return version, err
-		}
+	if !hasMarker(previousPath, markers) {
+		for currentPath := filepath.Dir(previousPath); currentPath != previousPath; previousPath, currentPath = currentPath, filepath.Dir(currentPath) {
+			dirPaths = append(dirPaths, currentPath)
+			if currentPath == conf.UserPath {
+				userPathDone = true
+			}
 
-		if currentPath == conf.UserPath {
-			userPathDone = true
+			if hasMarker(currentPath, markers) {
+				break
+			}
 		}
 	}
 
-	if userPathDone {
-		return "", nil
+	if !userPathDone {
+		dirPaths = append(dirPaths, conf.UserPath)
 	}
 
-	return retrieveVersionFromDir(versionFiles, conf.UserPath, conf)
+	return dirPaths, nil
 }
 
+func hasMarker(dirPath string, markers []string) bool {
+	for _, marker := range markers {
+		if _, err := os.Stat(filepath.Join(dirPath, marker)); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// an empty dirPath is the working directory.
 func retrieveVersionFromDir(versionFiles []types.VersionFile, dirPath string, conf *config.Config) (string, error) {
-	for _, versionFile := range versionFiles {
-		if version, err := versionFile.Parser(filepath.Join(dirPath, versionFile.Name), conf); err != nil || version != "" {
+	for index, versionFile := range versionFiles {
+		if versionFile.ConflictOnly {
+			continue
+		}
+
+		filePath := filepath.Join(dirPath, versionFile.Name)
+		if version, err := versionFile.Parser(filePath, conf); err != nil || version != "" {
+			if err == nil {
+				err = checkConflicts(versionFiles[index+1:], dirPath, filePath, version, conf)
+			}
+
 			return version, err
 		}
 	}
 
 	return "", nil
 }
+
+// checkConflicts parses files with lower precedence in the same directory,
+// a disagreement is a warning or an error in strict mode.
+func checkConflicts(versionFiles []types.VersionFile, dirPath string, usedPath string, usedVersion string, conf *config.Config) error {
+	quietConf := *conf
+	quietConf.Displayer = loghelper.InertDisplayer
+	for _, versionFile := range versionFiles {
+		filePath := filepath.Join(dirPath, versionFile.Name)
+		otherVersion, err := versionFile.Parser(filePath, &quietConf)
+		if err != nil || otherVersion == "" || agree(usedVersion, otherVersion) {
+			continue
+		}
+
+		if conf.StrictFiles {
+			return fmt.Errorf("%w : %s (%s) and %s (%s)", ErrConflictingFiles, usedPath, usedVersion, filePath, otherVersion)
+		}
+
+		conf.Displayer.Log(hclog.Warn, "Conflicting version files, the first one is used", "used", usedPath, "usedVersion", usedVersion, "ignored", filePath, "ignoredVersion", otherVersion)
+	}
+
+	return nil
+}
+
+// agree returns true when values are equal or when one is a version matching the other as constraint.
+func agree(value string, otherValue string) bool {
+	if value == otherValue {
+		return true
+	}
+
+	return matchConstraint(value, otherValue) || matchConstraint(otherValue, value)
+}
+
+func matchConstraint(versionStr string, constraintStr string) bool {
+	v, err := version.NewVersion(versionStr)
+	if err != nil {
+		return false
+	}
+
+	constraint, err := version.NewConstraint(constraintStr)
+
+	return err == nil && constraint.Check(v)
+}
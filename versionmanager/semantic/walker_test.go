@@ -0,0 +1,160 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package semantic_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager/semantic"
+	flatparser "github.com/tofuutils/tenv/v2/versionmanager/semantic/parser/flat"
+	"github.com/tofuutils/tenv/v2/versionmanager/semantic/types"
+)
+
+// change working directory, so not parallel.
+func TestRetrieveVersionConflict(t *testing.T) { //nolint
+	dirPath := t.TempDir()
+	for name, value := range map[string]string{".terraform-version": "1.5.7", ".tfswitchrc": "1.6.0", ".other-version": "~> 1.5.0"} {
+		if err := os.WriteFile(filepath.Join(dirPath, name), []byte(value), 0o600); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+	}
+
+	previousPath, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+	if err = os.Chdir(dirPath); err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+	defer os.Chdir(previousPath) //nolint
+
+	conf := config.Config{Displayer: loghelper.InertDisplayer, UserPath: dirPath}
+	agreeingFiles := []types.VersionFile{{Name: ".terraform-version", Parser: flatparser.RetrieveVersion}, {Name: ".other-version", Parser: flatparser.RetrieveVersion}}
+	if version, err := semantic.RetrieveVersion(agreeingFiles, &conf); err != nil || version != "1.5.7" {
+		t.Error("Unmatching results, get :", version, err)
+	}
+
+	conflictingFiles := []types.VersionFile{{Name: ".terraform-version", Parser: flatparser.RetrieveVersion}, {Name: ".tfswitchrc", Parser: flatparser.RetrieveVersion}}
+	if version, err := semantic.RetrieveVersion(conflictingFiles, &conf); err != nil || version != "1.5.7" {
+		t.Error("Unmatching results, get :", version, err)
+	}
+
+	conf.StrictFiles = true
+	if _, err := semantic.RetrieveVersion(conflictingFiles, &conf); !errors.Is(err, semantic.ErrConflictingFiles) {
+		t.Error("Should fail on conflicting version files, get :", err)
+	}
+}
+
+// change working directory, so not parallel.
+func TestRetrieveVersionBoundary(t *testing.T) { //nolint
+	basePath := t.TempDir()
+	for relPath, value := range map[string]string{"home/.terraform-version": "1.0.0", "parent/.terraform-version": "1.1.0", "parent/repo/.git/HEAD": "", "parent/repo/sub/.keep": "", "parent/other/.git/HEAD": "", "parent/other/.terraform-version": "1.2.0", "parent/other/sub/.keep": "", "empty/.keep": ""} {
+		filePath := filepath.Join(basePath, relPath)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+
+		if err := os.WriteFile(filePath, []byte(value), 0o600); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+	}
+
+	previousPath, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+	defer os.Chdir(previousPath) //nolint
+
+	tests := []struct {
+		name     string
+		boundary string
+		userPath string
+		workPath string
+		want     string
+	}{
+		{name: "BoundaryHomeFallback", boundary: ".git", userPath: "home", workPath: "parent/repo/sub", want: "1.0.0"},
+		{name: "BoundaryNoHomeFile", boundary: ".git", userPath: "empty", workPath: "parent/repo/sub", want: ""},
+		{name: "BoundaryRootChecked", boundary: ".git", userPath: "home", workPath: "parent/other/sub", want: "1.2.0"},
+		{name: "BoundaryWorkingDir", boundary: ".git", userPath: "home", workPath: "parent/repo", want: "1.0.0"},
+		{name: "NoBoundary", boundary: "none", userPath: "home", workPath: "parent/repo/sub", want: "1.1.0"},
+	}
+
+	versionFiles := []types.VersionFile{{Name: ".terraform-version", Parser: flatparser.RetrieveVersion}}
+	for _, tt := range tests {
+		if err = os.Chdir(filepath.Join(basePath, tt.workPath)); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+
+		conf := config.Config{Displayer: loghelper.InertDisplayer, SearchBoundary: tt.boundary, UserPath: filepath.Join(basePath, tt.userPath)}
+		if version, err := semantic.RetrieveVersion(versionFiles, &conf); err != nil || version != tt.want {
+			t.Error(tt.name, "unmatching results, get :", version, err)
+		}
+	}
+}
+
+// change working directory, so not parallel.
+func TestRetrieveVersionRelatedToolConflict(t *testing.T) { //nolint
+	basePath := t.TempDir()
+	for relPath, value := range map[string]string{"both/.opentofu-version": "1.6.2", "both/.terraform-version": "1.5.7", "agree/.opentofu-version": "1.6.2", "agree/.terraform-version": "1.6.2", "other/.terraform-version": "1.5.7"} {
+		filePath := filepath.Join(basePath, relPath)
+		if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+
+		if err := os.WriteFile(filePath, []byte(value), 0o600); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+	}
+
+	previousPath, err := os.Getwd()
+	if err != nil {
+		t.Fatal("Unexpected error :", err)
+	}
+	defer os.Chdir(previousPath) //nolint
+
+	tests := []struct {
+		name     string
+		strict   bool
+		workPath string
+		want     string
+		wantErr  error
+	}{
+		{name: "Warning", workPath: "both", want: "1.6.2"},
+		{name: "Strict", strict: true, workPath: "both", wantErr: semantic.ErrConflictingFiles},
+		{name: "Agreeing", strict: true, workPath: "agree", want: "1.6.2"},
+		{name: "OtherToolOnly", strict: true, workPath: "other", want: ""},
+	}
+
+	versionFiles := []types.VersionFile{{Name: ".opentofu-version", Parser: flatparser.RetrieveVersion}, {ConflictOnly: true, Name: ".terraform-version", Parser: flatparser.RetrieveVersion}}
+	for _, tt := range tests {
+		if err = os.Chdir(filepath.Join(basePath, tt.workPath)); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+
+		conf := config.Config{Displayer: loghelper.InertDisplayer, SearchBoundary: "none", StrictFiles: tt.strict, UserPath: basePath}
+		if version, err := semantic.RetrieveVersion(versionFiles, &conf); !errors.Is(err, tt.wantErr) || (err == nil && version != tt.want) {
+			t.Error(tt.name, "unmatching results, get :", version, err)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager
+
+import (
+	"github.com/hashicorp/go-hclog"
+
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager/lastuse"
+)
+
+// MigrateUserState move release dates cache and use dates of installed versions from their legacy location
+// to the directory of current user (files owned by another user are left in place, use dates stay readable).
+func (m VersionManager) MigrateUserState() (int, error) {
+	moved, err := m.conf.MoveToUserStatePath(m.FolderName, releaseDatesFileName)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	if moved {
+		count++
+	}
+
+	versions, err := m.innerListLocal(m.installDir(), false)
+	if err != nil {
+		return count, err
+	}
+
+	for _, version := range versions {
+		for _, fileName := range []string{lastuse.FileName, lastuse.LegacyFileName} {
+			moved, err = m.conf.MoveToUserStatePath(m.FolderName, version, fileName)
+			if err != nil {
+				m.conf.Displayer.Log(hclog.Warn, "Unable to migrate use date", "version", version, loghelper.Error, err)
+
+				continue
+			}
+
+			if moved {
+				count++
+			}
+		}
+	}
+
+	return count, nil
+}
@@ -0,0 +1,38 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager
+
+import "errors"
+
+var ErrNoProbe = errors.New("retriever does not support sidecar assets probing")
+
+// SidecarProber is implemented by retrievers able to check published checksum and signature files of a release.
+type SidecarProber interface {
+	ProbeSidecars(version string) (bool, bool, error)
+}
+
+// ProbeSidecars returns whether checksum and signature files are published for version (without downloading them).
+func (m VersionManager) ProbeSidecars(version string) (bool, bool, error) {
+	prober, ok := m.retriever.(SidecarProber)
+	if !ok {
+		return false, false, ErrNoProbe
+	}
+
+	return prober.ProbeSidecars(version)
+}
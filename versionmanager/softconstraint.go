@@ -0,0 +1,57 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager
+
+import (
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-version"
+
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+)
+
+// SoftConstraintPrefix marks a default constraint only checked after resolution (violation is a warning).
+const SoftConstraintPrefix = "warn:"
+
+// ReadSoftConstraint returns the default constraint without its prefix and its source, when it is a soft one.
+func (m VersionManager) ReadSoftConstraint() (string, string, bool) {
+	constraint, source := m.ReadDefaultConstraintWithSource()
+	softConstraint, found := strings.CutPrefix(constraint, SoftConstraintPrefix)
+
+	return strings.TrimSpace(softConstraint), source, found
+}
+
+func (m VersionManager) warnSoftConstraint(versionStr string) {
+	softConstraint, source, found := m.ReadSoftConstraint()
+	if !found {
+		return
+	}
+
+	constraint, err := version.NewConstraint(softConstraint)
+	if err != nil {
+		m.conf.Displayer.Log(hclog.Warn, "Invalid soft constraint", "constraint", softConstraint, "source", source, loghelper.Error, err)
+
+		return
+	}
+
+	if v, err := version.NewVersion(versionStr); err == nil && !constraint.Check(v) {
+		m.conf.Displayer.Log(hclog.Warn, loghelper.Concat(m.FolderName, " ", versionStr, " does not match constraint ", softConstraint, " (from ", source, "), it will be rejected once the ", SoftConstraintPrefix, " prefix is removed"))
+	}
+}
@@ -0,0 +1,68 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager
+
+import (
+	"os"
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
+
+	"github.com/tofuutils/tenv/v2/config/cmdconst"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager/compat"
+	"github.com/tofuutils/tenv/v2/versionmanager/semantic/types"
+)
+
+// version of the Terragrunt proxy calling this OpenTofu or Terraform proxy (see proxy.sharedResolved).
+func (m VersionManager) callingTerragrunt() string {
+	if m.execName != cmdconst.TofuName && m.execName != cmdconst.TerraformName {
+		return ""
+	}
+
+	return os.Getenv(cmdconst.ResolvedEnvPrefix + strings.ToUpper(cmdconst.TerragruntName))
+}
+
+// restrict predicate to versions supported by calling Terragrunt (returned boolean is false without calling Terragrunt).
+func (m VersionManager) terragruntCompatiblePredicate(predicateInfo types.PredicateInfo) (types.PredicateInfo, bool) {
+	tgVersion := m.callingTerragrunt()
+	if tgVersion == "" {
+		return predicateInfo, false
+	}
+
+	compatible := compat.Predicate(m.execName, tgVersion)
+	predicate := predicateInfo.Predicate
+
+	return types.PredicateInfo{
+		Exact:        predicateInfo.Exact,
+		Predicate:    func(version string) bool { return predicate(version) && compatible(version) },
+		ReverseOrder: predicateInfo.ReverseOrder,
+	}, true
+}
+
+func (m VersionManager) warnTerragruntCompatibility(version string) {
+	tgVersion := m.callingTerragrunt()
+	if tgVersion == "" {
+		return
+	}
+
+	if compatible, known := compat.Compatible(m.execName, version, tgVersion); known && !compatible {
+		m.conf.Displayer.Log(hclog.Warn, loghelper.Concat(m.FolderName, " ", version, " is not supported by Terragrunt ", tgVersion, " (see Terragrunt supported versions)"))
+	}
+}
@@ -0,0 +1,136 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-version"
+
+	"github.com/tofuutils/tenv/v2/pkg/fileperm"
+	"github.com/tofuutils/tenv/v2/pkg/lockfile"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+)
+
+const trashDirName = ".trash"
+
+var (
+	ErrAlreadyInstalled = errors.New("version already installed")
+	ErrNotInTrash       = errors.New("version not found in trash")
+	ErrRestoreVersion   = errors.New("restore expects an exact version")
+)
+
+// Restore move back an uninstalled version from trash.
+func (m VersionManager) Restore(requestedVersion string) error {
+	// version is joined to paths, so it must be a single clean element (like "1.6.2", never "../1.6.2")
+	parsedVersion, err := version.NewVersion(requestedVersion)
+	if err != nil || filepath.Base(requestedVersion) != requestedVersion {
+		return ErrRestoreVersion
+	}
+	version := parsedVersion.String() // same form as installed versions
+
+	installPath, err := m.ensureInstallDir()
+	if err != nil {
+		return err
+	}
+
+	trashedPath := filepath.Join(m.trashPath(), version)
+	if _, err = os.Stat(trashedPath); err != nil {
+		return ErrNotInTrash
+	}
+
+	deleteLock, err := lockfile.Write(installPath, m.conf.LockTimeout, m.conf.Displayer)
+	if err != nil {
+		return err
+	}
+	disableExit := lockfile.CleanAndExitOnInterrupt(deleteLock)
+	defer disableExit()
+	defer deleteLock()
+
+	targetPath := filepath.Join(installPath, version)
+	if _, err = os.Stat(targetPath); err == nil {
+		return ErrAlreadyInstalled
+	}
+
+	if err = os.Rename(trashedPath, targetPath); err != nil {
+		return err
+	}
+	m.conf.Displayer.Display(loghelper.Concat("Restoration of ", m.FolderName, " ", version, " successful"))
+	m.syncLinks()
+	m.syncRegistry(version)
+
+	return nil
+}
+
+// move a version directory to trash (or remove it when trash is disabled with a zero TTL),
+// trashed versions older than TTL are purged at the same time.
+func (m VersionManager) removeVersionDir(targetPath string, version string) error {
+	if m.conf.TrashTTL <= 0 {
+		return os.RemoveAll(targetPath)
+	}
+
+	trashPath := m.trashPath()
+	if err := os.MkdirAll(trashPath, fileperm.DirMode()); err != nil {
+		return err
+	}
+	m.purgeTrash(trashPath)
+
+	trashedPath := filepath.Join(trashPath, version)
+	if err := os.RemoveAll(trashedPath); err != nil { // previous trashed copy
+		return err
+	}
+
+	if err := os.Rename(targetPath, trashedPath); err != nil {
+		return err
+	}
+
+	// modification time record the deletion date for purge
+	now := time.Now()
+
+	return os.Chtimes(trashedPath, now, now)
+}
+
+func (m VersionManager) purgeTrash(trashPath string) {
+	entries, err := os.ReadDir(trashPath)
+	if err != nil {
+		m.conf.Displayer.Log(hclog.Warn, "Failed to read trash directory", loghelper.Error, err)
+
+		return
+	}
+
+	limit := time.Now().Add(-m.conf.TrashTTL)
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(limit) {
+			continue
+		}
+
+		if err = os.RemoveAll(filepath.Join(trashPath, entry.Name())); err != nil {
+			m.conf.Displayer.Log(hclog.Warn, "Failed to purge trashed version", "version", entry.Name(), loghelper.Error, err)
+		}
+	}
+}
+
+func (m VersionManager) trashPath() string {
+	return filepath.Join(m.conf.RootPath, trashDirName, m.FolderName)
+}
@@ -0,0 +1,73 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/tofuutils/tenv/v2/config"
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/versionmanager"
+)
+
+func TestRestore(t *testing.T) {
+	t.Parallel()
+
+	conf := &config.Config{Displayer: loghelper.InertDisplayer, RootPath: t.TempDir()}
+	manager := versionmanager.Make(conf, "", "tofu", "OpenTofu", nil, nil, fakeRetriever{}, "", "", nil)
+
+	trashPath := filepath.Join(conf.RootPath, ".trash", "OpenTofu")
+	for _, dirPath := range []string{filepath.Join(trashPath, "1.6.2"), filepath.Join(trashPath, "1.6.1"), filepath.Join(conf.RootPath, "OpenTofu", "1.6.1"), filepath.Join(conf.RootPath, "outside")} {
+		if err := os.MkdirAll(dirPath, 0o755); err != nil {
+			t.Fatal("Unexpected error :", err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		version string
+		wantErr error
+	}{
+		{name: "Restored", version: "v1.6.2"},
+		{name: "AlreadyRestored", version: "1.6.2", wantErr: versionmanager.ErrNotInTrash},
+		{name: "AlreadyInstalled", version: "1.6.1", wantErr: versionmanager.ErrAlreadyInstalled},
+		{name: "Missing", version: "1.7.0", wantErr: versionmanager.ErrNotInTrash},
+		{name: "ParentTraversal", version: "../../outside", wantErr: versionmanager.ErrRestoreVersion},
+		{name: "NestedTraversal", version: "1.6.1/../../../outside", wantErr: versionmanager.ErrRestoreVersion},
+		{name: "ParentDir", version: "..", wantErr: versionmanager.ErrRestoreVersion},
+		{name: "Empty", version: "", wantErr: versionmanager.ErrRestoreVersion},
+	}
+
+	for _, tt := range tests { // sequential, cases depend on previous ones
+		if err := manager.Restore(tt.version); !errors.Is(err, tt.wantErr) {
+			t.Error(tt.name, "unmatching error, get :", err)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(conf.RootPath, "OpenTofu", "1.6.2")); err != nil {
+		t.Error("Restored version should be installed, get :", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(conf.RootPath, "outside")); err != nil {
+		t.Error("Directory outside trash should not be moved, get :", err)
+	}
+}
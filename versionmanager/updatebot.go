@@ -0,0 +1,114 @@
+/*
+ *
+ * Copyright 2024 tofuutils authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package versionmanager
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/go-version"
+
+	"github.com/tofuutils/tenv/v2/pkg/loghelper"
+	"github.com/tofuutils/tenv/v2/pkg/updatebot"
+)
+
+var ErrNotPinned = errors.New("version files do not contain an exact version")
+
+// CheckBotRules returns the version found in version files and the update bot rules it violates.
+// Rules with a constraint not supported by tenv are logged and ignored.
+func (m VersionManager) CheckBotRules(rules []updatebot.Rule) (string, []updatebot.Rule, error) {
+	versionStr, err := m.ResolveWithVersionFiles()
+	if err != nil {
+		return "", nil, err
+	}
+
+	parsedVersion, err := version.NewVersion(versionStr)
+	if err != nil {
+		return versionStr, nil, ErrNotPinned
+	}
+
+	var violated []updatebot.Rule
+	for _, rule := range m.parseBotRules(rules) {
+		if rule.constraint.Check(parsedVersion) == rule.Exclude {
+			violated = append(violated, rule.Rule)
+		}
+	}
+
+	return versionStr, violated, nil
+}
+
+// FixBotRules writes in working directory version file the latest stable version respecting all update bot rules.
+func (m VersionManager) FixBotRules(rules []updatebot.Rule) (string, error) {
+	parsedRules := m.parseBotRules(rules)
+
+	allowed := []string{">= 0"}
+	for _, rule := range parsedRules {
+		if !rule.Exclude {
+			allowed = append(allowed, rule.Constraint)
+		}
+	}
+
+	versions, err := m.ListMatching(strings.Join(allowed, ", "), -1, true)
+	if err != nil {
+		return "", err
+	}
+
+	for _, versionStr := range versions {
+		parsedVersion, err := version.NewVersion(versionStr)
+		if err != nil || excluded(parsedRules, parsedVersion) {
+			continue
+		}
+
+		return versionStr, m.UseInFile(versionStr, m.UseFileName())
+	}
+
+	return "", ErrNoCompatible
+}
+
+type parsedBotRule struct {
+	updatebot.Rule
+	constraint version.Constraints
+}
+
+func (m VersionManager) parseBotRules(rules []updatebot.Rule) []parsedBotRule {
+	parsedRules := make([]parsedBotRule, 0, len(rules))
+	for _, rule := range rules {
+		constraint, err := version.NewConstraint(rule.Constraint)
+		if err != nil {
+			m.conf.Displayer.Log(hclog.Warn, loghelper.Concat("Ignoring unsupported constraint from ", rule.Source), "constraint", rule.Constraint, loghelper.Error, err)
+
+			continue
+		}
+
+		parsedRules = append(parsedRules, parsedBotRule{Rule: rule, constraint: constraint})
+	}
+
+	return parsedRules
+}
+
+func excluded(parsedRules []parsedBotRule, parsedVersion *version.Version) bool {
+	for _, rule := range parsedRules {
+		if rule.Exclude && rule.constraint.Check(parsedVersion) {
+			return true
+		}
+	}
+
+	return false
+}